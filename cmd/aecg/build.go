@@ -0,0 +1,237 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// description is the JSON description of a document read by "aecg build".
+//
+// Example:
+//
+//	{
+//	  "id": {"root": "2.16.840.1.113883.3.1", "extension": "ECG_001"},
+//	  "effectiveTime": {"low": "20240315101500", "high": "20240315101510"},
+//	  "confidentiality": "S",
+//	  "subject": {"root": "2.16.840.1.113883.3.6", "id": "SUBJ_1", "gender": "F"},
+//	  "study": {"profile": "study.yaml", "site": "SITE_001"},
+//	  "series": [{
+//	    "start": "20240315101500.000", "end": "20240315101510.000",
+//	    "sampleRate": 500, "scale": 5,
+//	    "leads": {"I": [1, 2, 3], "II": [4, 5, 6]}
+//	  }]
+//	}
+type description struct {
+	// Code is the CPT code of the procedure (93000 by default).
+	Code            string          `json:"code"`
+	ID              idDescription   `json:"id"`
+	Text            string          `json:"text"`
+	EffectiveTime   timeDescription `json:"effectiveTime"`
+	Confidentiality string          `json:"confidentiality"`
+	Reason          string          `json:"reason"`
+
+	Subject *subjectDescription `json:"subject"`
+	Study   *studyDescription   `json:"study"`
+	Series  []seriesDescription `json:"series"`
+}
+
+type idDescription struct {
+	Root      string `json:"root"`
+	Extension string `json:"extension"`
+}
+
+type timeDescription struct {
+	Low  string `json:"low"`
+	High string `json:"high"`
+}
+
+type subjectDescription struct {
+	Root      string `json:"root"`
+	ID        string `json:"id"`
+	Role      string `json:"role"` // ENROLLED by default
+	Name      string `json:"name"`
+	PatientID string `json:"patientId"`
+	Gender    string `json:"gender"`    // Any form accepted by types.GetGender
+	BirthTime string `json:"birthTime"` // YYYYMMDD
+	Race      string `json:"race"`      // Any form accepted by types.GetRaceCode
+}
+
+// studyDescription selects a study profile and site (see
+// hl7aecg.LoadStudyProfile). A relative profile path is resolved against
+// the directory of the description.
+type studyDescription struct {
+	Profile string `json:"profile"`
+	Site    string `json:"site"`
+}
+
+type seriesDescription struct {
+	// Type is "rhythm" (default) or "representativeBeat".
+	Type       string  `json:"type"`
+	Start      string  `json:"start"`
+	End        string  `json:"end"`
+	SampleRate float64 `json:"sampleRate"`
+	Origin     float64 `json:"origin"`
+	Scale      float64 `json:"scale"`
+
+	// Leads maps lead names (e.g. "II", "aVR", "MDC_ECG_LEAD_V1") to digits.
+	Leads map[string][]int `json:"leads"`
+}
+
+// runBuild builds a document from a JSON description and writes its XML.
+//
+// The document is validated first; if invalid, the report is printed to
+// stderr and nothing is written, unless -novalidate is set.
+func runBuild(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("build", "description.json", stderr)
+	out := fs.String("o", "", "output file (default stdout)")
+	noValidate := fs.Bool("novalidate", false, "write the document without validating it")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+
+	desc, err := readDescription(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	h, err := desc.build(filepath.Dir(fs.Arg(0)))
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+
+	if !*noValidate {
+		if err := h.Validate(); err != nil {
+			printReport(stderr, fs.Arg(0), h.ValidationReport())
+			return exitInvalid
+		}
+	}
+
+	data, err := h.Marshal()
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	w, closeOutput, err := createOutput(*out, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	_, err = w.Write(data)
+	if cerr := closeOutput(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// readDescription reads a JSON description, rejecting unknown fields.
+func readDescription(path string) (*description, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.DisallowUnknownFields()
+	var desc description
+	if err := dec.Decode(&desc); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return &desc, nil
+}
+
+// build returns the document described. dir is the directory relative
+// study profile paths are resolved against.
+func (d *description) build(dir string) (*hl7aecg.Hl7xml, error) {
+	h := hl7aecg.NewHl7xml("")
+	if d.Study != nil {
+		path := d.Study.Profile
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(dir, path)
+		}
+		profile, err := hl7aecg.LoadStudyProfile(path)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.ApplyStudyProfile(profile, d.Study.Site); err != nil {
+			return nil, err
+		}
+	}
+
+	code := types.CPT_CODE_ECG_Routine
+	if d.Code != "" {
+		code = types.CPT_CODE(d.Code)
+	}
+	h.Initialize(code, types.CPT_OID, "CPT-4", "")
+	if d.ID.Root != "" {
+		// The document root also completes the series and trial IDs
+		h.HL7AEcg.SetRootID(d.ID.Root, "annotatedEcg")
+	}
+	if d.ID.Root != "" || d.ID.Extension != "" {
+		h.HL7AEcg.SetID(d.ID.Root, d.ID.Extension)
+	}
+	if d.Text != "" {
+		h.SetText(d.Text)
+	}
+	h.SetEffectiveTime(d.EffectiveTime.Low, d.EffectiveTime.High, nil, nil)
+
+	// Both codes are allocated empty by NewHl7xml; drop those not described
+	if d.Confidentiality != "" {
+		h.AddConfidentialityCode(types.ConfidentialityCode(d.Confidentiality))
+	} else {
+		h.HL7AEcg.ConfidentialityCode = nil
+	}
+	if d.Reason != "" {
+		h.AddReasonCode(types.ReasonCode(d.Reason))
+	} else {
+		h.HL7AEcg.ReasonCode = nil
+	}
+
+	if s := d.Subject; s != nil {
+		role := types.SUBJECT_ROLE_ENROLLED
+		if s.Role != "" {
+			role = types.CodeRole(s.Role)
+		}
+		h.SetSubject(s.Root, s.ID, role)
+		h.SetSubjectDemographics(s.Name, s.PatientID, types.GetGender(s.Gender), s.BirthTime, types.GetRaceCode(s.Race))
+
+		// The setter always writes both codes; drop those not described
+		demo := h.HL7AEcg.GetTrialSubject().SubjectDemographicPerson
+		if s.Gender == "" {
+			demo.AdministrativeGenderCode = nil
+		}
+		if s.Race == "" {
+			demo.RaceCode = nil
+		}
+	}
+
+	for i, s := range d.Series {
+		leads := make(map[types.LeadCode][]int, len(s.Leads))
+		for name, digits := range s.Leads {
+			leads[types.NormalizeLeadCode(name)] = digits
+		}
+		switch s.Type {
+		case "", "rhythm":
+			h.AddRhythmSeries(s.Start, s.End, nil, nil, s.SampleRate, leads, s.Origin, s.Scale)
+		case "representativeBeat":
+			h.AddRepresentativeBeatSeries(s.Start, s.End, s.SampleRate, leads, s.Origin, s.Scale)
+		default:
+			return nil, fmt.Errorf("series[%d]: unknown type %q (want rhythm or representativeBeat)", i, s.Type)
+		}
+	}
+	return h, nil
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/export"
+)
+
+// runConvert exports a document as CSV, JSON or SVG.
+//
+// CSV and SVG export one series, selected with -series and -derived; JSON
+// exports the whole document.
+func runConvert(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("convert", "file.xml", stderr)
+	to := fs.String("to", "", "output format: csv, json or svg")
+	out := fs.String("o", "", "output file (default stdout)")
+	index := fs.Int("series", 0, "index of the series to export (csv, svg)")
+	derived := fs.Int("derived", -1, "index of the derived series to export, -1 for the series itself (csv, svg)")
+	duration := fs.Float64("duration", 0, "seconds to draw, 0 for the whole series (svg)")
+	speed := fs.Float64("speed", 25, "paper speed in mm/s (svg)")
+	gain := fs.Float64("gain", 10, "gain in mm/mV (svg)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 {
+		fs.Usage()
+		return exitError
+	}
+	switch *to {
+	case "csv", "json", "svg":
+	default:
+		fmt.Fprintf(stderr, "aecg: unknown output format %q (want csv, json or svg)\n", *to)
+		return exitError
+	}
+
+	h := hl7aecg.NewHl7xml("")
+	if err := h.UnmarshalFromFile(fs.Arg(0)); err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+
+	w, closeOutput, err := createOutput(*out, stdout)
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+
+	if *to == "json" {
+		err = export.WriteJSON(w, &h.HL7AEcg)
+	} else if series, serr := export.SelectSeries(&h.HL7AEcg, *index, *derived); serr != nil {
+		err = serr
+	} else if *to == "csv" {
+		err = export.WriteCSV(w, series)
+	} else {
+		err = export.WriteSVG(w, series, export.SVGOptions{Speed: *speed, Gain: *gain, Duration: *duration})
+	}
+	if cerr := closeOutput(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/diff"
+)
+
+// runDiff prints the structural differences between two documents (see
+// package diff). Like diff(1), it exits with 0 when the documents are
+// identical and 1 when they differ.
+func runDiff(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("diff", "old.xml new.xml", stderr)
+	format := fs.String("format", "text", "output format: text or json")
+	defaults := diff.DefaultOptions()
+	tolerance := fs.Float64("tolerance", defaults.Tolerance, "largest sample difference considered equal, in µV")
+	ranges := fs.Int("ranges", defaults.MaxRanges, "differing sample ranges listed per lead")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 2 || !checkFormat(*format, stderr) {
+		fs.Usage()
+		return exitError
+	}
+
+	var docs [2]*hl7aecg.Hl7xml
+	for i := range docs {
+		docs[i] = hl7aecg.NewHl7xml("")
+		if err := docs[i].UnmarshalFromFile(fs.Arg(i)); err != nil {
+			fmt.Fprintf(stderr, "aecg: %v\n", err)
+			return exitError
+		}
+	}
+
+	d := diff.Compare(&docs[0].HL7AEcg, &docs[1].HL7AEcg, diff.Options{Tolerance: *tolerance, MaxRanges: *ranges})
+	var err error
+	if *format == "json" {
+		err = d.WriteJSON(stdout)
+	} else {
+		err = d.WriteText(stdout)
+	}
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	if !d.Empty() {
+		return exitInvalid
+	}
+	return exitOK
+}
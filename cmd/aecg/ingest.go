@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/ingest"
+)
+
+// runIngest watches a drop folder until interrupted (see package ingest),
+// printing one line per processed file.
+//
+// Sinks are given as -sink kind:target, repeatable:
+//
+//	dir:PATH      copy the documents to PATH, named after their ID
+//	index:PATH    append one JSON line per document to PATH
+//	webhook:URL   post a JSON notification per document to URL
+func runIngest(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("ingest", "dir", stderr)
+	interval := fs.Duration("interval", ingest.DefaultInterval, "polling interval")
+	accepted := fs.String("accepted", "", "folder of accepted files (default dir/accepted)")
+	rejected := fs.String("rejected", "", "folder of rejected files (default dir/rejected)")
+	ledger := fs.String("ledger", "", "ledger of delivered IDs (default dir/"+ingest.DefaultLedger+")")
+	var sinks ingest.MultiSink
+	fs.Func("sink", "dir:PATH, index:PATH or webhook:URL (repeatable)", func(s string) error {
+		sink, err := parseSink(s)
+		if err == nil {
+			sinks = append(sinks, sink)
+		}
+		return err
+	})
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 || len(sinks) == 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	w, err := ingest.New(fs.Arg(0), ingest.Options{
+		Sink:        sinks,
+		Interval:    *interval,
+		AcceptedDir: *accepted,
+		RejectedDir: *rejected,
+		Ledger:      *ledger,
+		OnEvent: func(e ingest.Event) {
+			switch {
+			case e.Err != nil:
+				fmt.Fprintf(stdout, "%s: %s: %v\n", e.Path, e.Status, e.Err)
+			case e.Status == ingest.StatusRejected:
+				fmt.Fprintf(stdout, "%s: %s, %d error(s) -> %s\n", e.Path, e.Status, len(e.Report.Errors), e.Dest)
+			default:
+				fmt.Fprintf(stdout, "%s: %s %s -> %s\n", e.Path, e.Status, e.ID, e.Dest)
+			}
+		},
+	})
+	if err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := w.Run(ctx); err != nil && !errors.Is(err, context.Canceled) {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
+
+// parseSink parses a -sink flag.
+func parseSink(s string) (ingest.Sink, error) {
+	kind, target, ok := strings.Cut(s, ":")
+	if !ok || target == "" {
+		return nil, fmt.Errorf("want kind:target, got %q", s)
+	}
+	switch kind {
+	case "dir":
+		if err := os.MkdirAll(target, 0o755); err != nil {
+			return nil, err
+		}
+		return ingest.DirSink{Dir: target}, nil
+	case "index":
+		return ingest.IndexSink{Path: target}, nil
+	case "webhook":
+		return ingest.WebhookSink{URL: target}, nil
+	}
+	return nil, fmt.Errorf("unknown sink %q (want dir, index or webhook)", kind)
+}
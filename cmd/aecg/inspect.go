@@ -0,0 +1,106 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// runInspect prints the summary of a document.
+func runInspect(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("inspect", "file.xml", stderr)
+	format := fs.String("format", "text", "output format: text or json")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 1 || !checkFormat(*format, stderr) {
+		fs.Usage()
+		return exitError
+	}
+
+	h := hl7aecg.NewHl7xml("")
+	if err := h.UnmarshalFromFile(fs.Arg(0)); err != nil {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	summary := h.HL7AEcg.Summary()
+
+	if *format == "json" {
+		enc := json.NewEncoder(stdout)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(summary); err != nil {
+			fmt.Fprintf(stderr, "aecg: %v\n", err)
+			return exitError
+		}
+		return exitOK
+	}
+	printSummary(stdout, summary)
+	return exitOK
+}
+
+// printSummary prints a summary as text.
+func printSummary(w io.Writer, s *types.Summary) {
+	fmt.Fprintf(w, "Document        %s\n", s.ID)
+	fmt.Fprintf(w, "Code            %s\n", s.Code)
+	fmt.Fprintf(w, "Time            %s - %s\n", s.Start, s.End)
+	if s.Confidentiality != "" {
+		fmt.Fprintf(w, "Confidentiality %s\n", s.Confidentiality)
+	}
+	fmt.Fprintf(w, "Subject         %s\n", joinFields(s.Subject.ID, s.Subject.Role, s.Subject.Gender, s.Subject.BirthTime))
+	fmt.Fprintf(w, "Trial           %s\n", joinFields(s.Trial.ID, s.Trial.Title))
+	if s.Trial.Protocol != "" {
+		fmt.Fprintf(w, "Protocol        %s\n", s.Trial.Protocol)
+	}
+	if s.Trial.Sponsor != "" {
+		fmt.Fprintf(w, "Sponsor         %s\n", s.Trial.Sponsor)
+	}
+	if s.Trial.Site != "" {
+		fmt.Fprintf(w, "Site            %s\n", s.Trial.Site)
+	}
+	if s.Trial.Visit != "" {
+		fmt.Fprintf(w, "Visit           %s\n", s.Trial.Visit)
+	}
+	for i, series := range s.Series {
+		printSeries(w, fmt.Sprintf("Series %d", i), "", series)
+	}
+}
+
+// printSeries prints a series summary and its derived series.
+func printSeries(w io.Writer, label, indent string, s types.SeriesSummary) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%s%s: %s\n", indent, label, s.Code)
+	fmt.Fprintf(w, "%s  Time        %s - %s\n", indent, s.Start, s.End)
+	fmt.Fprintf(w, "%s  Sampling    %g Hz, %d samples, %g s\n", indent, s.SampleRate, s.Samples, s.Duration)
+
+	leads := make([]string, len(s.Leads))
+	for i, lead := range s.Leads {
+		leads[i] = strings.TrimPrefix(string(lead), "MDC_ECG_LEAD_")
+	}
+	fmt.Fprintf(w, "%s  Leads       %s\n", indent, strings.Join(leads, " "))
+
+	for _, m := range s.Measurements {
+		lead := ""
+		if m.Lead != "" {
+			lead = " [" + strings.TrimPrefix(m.Lead, "MDC_ECG_LEAD_") + "]"
+		}
+		fmt.Fprintf(w, "%s  %-11s %s%s = %g %s\n", indent, fmt.Sprintf("Set %d", m.Set), m.Code, lead, m.Value, m.Unit)
+	}
+	for i, derived := range s.Derived {
+		printSeries(w, fmt.Sprintf("Derived %d", i), indent+"  ", derived)
+	}
+}
+
+// joinFields joins the non-empty fields with spaces.
+func joinFields(fields ...string) string {
+	var out []string
+	for _, f := range fields {
+		if f != "" {
+			out = append(out, f)
+		}
+	}
+	return strings.Join(out, " ")
+}
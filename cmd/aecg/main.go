@@ -0,0 +1,128 @@
+// Command aecg validates, inspects, converts and builds HL7 aECG documents.
+//
+// Usage:
+//
+//	aecg validate [-format text|json] [-workers N] [-q] file.xml|dir...
+//	aecg inspect  [-format text|json] file.xml
+//	aecg convert  -to csv|json|svg [-series N] [-derived N] [-o out] file.xml
+//	aecg diff     [-format text|json] [-tolerance µV] [-ranges N] old.xml new.xml
+//	aecg build    [-o out.xml] [-novalidate] description.json
+//	aecg serve    [-addr host:port] [-max-size MiB] [-timeout d]
+//	aecg ingest   -sink dir:PATH|index:PATH|webhook:URL [-interval d] dir
+//
+// Exit status is 0 on success, 1 when a document is invalid (or, for diff,
+// when the documents differ) and 2 on usage or I/O errors.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// Exit codes.
+const (
+	exitOK      = 0 // Success, or every document valid
+	exitInvalid = 1 // At least one document invalid, or documents differ
+	exitError   = 2 // Usage or I/O error
+)
+
+// command is an aecg subcommand.
+type command struct {
+	name    string
+	summary string
+	run     func(args []string, stdout, stderr io.Writer) int
+}
+
+var commands = []command{
+	{"validate", "validate documents and report errors", runValidate},
+	{"inspect", "print subject, trial, series and measurements", runInspect},
+	{"convert", "export waveforms as CSV, JSON or SVG", runConvert},
+	{"diff", "compare two documents structurally", runDiff},
+	{"build", "build a document from a JSON description", runBuild},
+	{"serve", "serve validation and conversion over HTTP", runServe},
+	{"ingest", "validate and deliver the documents dropped in a folder", runIngest},
+}
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run dispatches to the subcommand named by args[0].
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 {
+		usage(stderr)
+		return exitError
+	}
+	for _, cmd := range commands {
+		if cmd.name == args[0] {
+			return cmd.run(args[1:], stdout, stderr)
+		}
+	}
+	if args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		usage(stdout)
+		return exitOK
+	}
+	fmt.Fprintf(stderr, "aecg: unknown command %q\n", args[0])
+	usage(stderr)
+	return exitError
+}
+
+// usage prints the list of subcommands.
+func usage(w io.Writer) {
+	fmt.Fprintln(w, "Usage: aecg <command> [flags] [files]")
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, "Commands:")
+	for _, cmd := range commands {
+		fmt.Fprintf(w, "  %-9s %s\n", cmd.name, cmd.summary)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintln(w, `Run "aecg <command> -h" for the flags of a command.`)
+}
+
+// newFlagSet returns a flag set printing its errors and usage to stderr.
+func newFlagSet(name, args string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	fs.Usage = func() {
+		fmt.Fprintf(stderr, "Usage: aecg %s [flags] %s\n", name, args)
+		fs.PrintDefaults()
+	}
+	return fs
+}
+
+// parseFlags parses the flags of a subcommand. It returns the exit code to
+// use and false when the command must stop (-h or a flag error).
+func parseFlags(fs *flag.FlagSet, args []string) (int, bool) {
+	if err := fs.Parse(args); err != nil {
+		if errors.Is(err, flag.ErrHelp) {
+			return exitOK, false
+		}
+		return exitError, false
+	}
+	return exitOK, true
+}
+
+// checkFormat reports whether format is text or json.
+func checkFormat(format string, stderr io.Writer) bool {
+	if format != "text" && format != "json" {
+		fmt.Fprintf(stderr, "aecg: unknown format %q (want text or json)\n", format)
+		return false
+	}
+	return true
+}
+
+// createOutput returns the writer for the -o flag: stdout when empty.
+// The returned function closes the file, if any.
+func createOutput(path string, stdout io.Writer) (io.Writer, func() error, error) {
+	if path == "" {
+		return stdout, func() error { return nil }, nil
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, nil, err
+	}
+	return f, f.Close, nil
+}
@@ -0,0 +1,245 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testDescription = `{
+  "id": {"root": "2.16.840.1.113883.3.1", "extension": "ECG_001"},
+  "effectiveTime": {"low": "20240315101500", "high": "20240315101510"},
+  "confidentiality": "S",
+  "subject": {"root": "2.16.840.1.113883.3.6", "id": "SUBJ_1", "birthTime": "19800101"},
+  "study": {"profile": "study.yaml", "site": "SITE_001"},
+  "series": [{
+    "start": "20240315101500.000", "end": "20240315101510.000",
+    "sampleRate": 500, "scale": 5,
+    "leads": {"I": [1, 2, 3], "aVR": [4, 5, 6]}
+  }]
+}`
+
+const testProfile = `
+trial: {root: 2.16.840.1.113883.3.1, extension: TQT-001, title: Thorough QT}
+sponsor: {root: 2.16.840.1.113883.3, name: ABC Pharma}
+siteRoot: 2.16.840.1.113883.3.5
+sites:
+  - id: SITE_001
+    name: 1st Clinic
+    country: FRA
+`
+
+// runCommand runs aecg with the arguments and returns the exit code and outputs.
+func runCommand(args ...string) (int, string, string) {
+	var stdout, stderr bytes.Buffer
+	code := run(args, &stdout, &stderr)
+	return code, stdout.String(), stderr.String()
+}
+
+// buildTestFile builds the test description into dir and returns the XML path.
+func buildTestFile(t *testing.T, dir string) string {
+	t.Helper()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ecg.json"), []byte(testDescription), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "study.yaml"), []byte(testProfile), 0o644))
+
+	out := filepath.Join(dir, "ecg.xml")
+	code, _, stderr := runCommand("build", "-o", out, filepath.Join(dir, "ecg.json"))
+	require.Equal(t, exitOK, code, stderr)
+	return out
+}
+
+// TestBuildAndValidate tests that a built document validates
+func TestBuildAndValidate(t *testing.T) {
+	file := buildTestFile(t, t.TempDir())
+
+	code, stdout, _ := runCommand("validate", file)
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "ecg.xml: valid")
+
+	code, stdout, _ = runCommand("validate", "-format", "json", file)
+	assert.Equal(t, exitOK, code)
+	var report struct {
+		File  string `json:"file"`
+		Valid bool   `json:"valid"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &report))
+	assert.True(t, report.Valid)
+	assert.Equal(t, file, report.File)
+}
+
+// TestValidate_Invalid tests the exit codes of invalid and missing files
+func TestValidate_Invalid(t *testing.T) {
+	dir := t.TempDir()
+	bad := filepath.Join(dir, "bad.xml")
+	require.NoError(t, os.WriteFile(bad, []byte(`<AnnotatedECG xmlns="urn:hl7-org:v3"><effectiveTime><low value="yesterday"/></effectiveTime></AnnotatedECG>`), 0o644))
+	notXML := filepath.Join(dir, "notxml.xml")
+	require.NoError(t, os.WriteFile(notXML, []byte("not xml"), 0o644))
+
+	code, stdout, _ := runCommand("validate", bad, notXML)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, "bad.xml: invalid")
+	assert.Contains(t, stdout, "notxml.xml: invalid, 1 error(s)")
+
+	code, stdout, _ = runCommand("validate", filepath.Join(dir, "missing.xml"))
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stdout, "missing.xml: error")
+}
+
+// TestValidate_Directory tests batch validation of a directory tree
+func TestValidate_Directory(t *testing.T) {
+	dir := t.TempDir()
+	buildTestFile(t, dir)
+	sub := filepath.Join(dir, "site2")
+	require.NoError(t, os.Mkdir(sub, 0o755))
+	buildTestFile(t, sub)
+	require.NoError(t, os.WriteFile(filepath.Join(sub, "bad.xml"), []byte("<AnnotatedECG"), 0o644))
+
+	code, stdout, _ := runCommand("validate", "-q", "-workers", "2", dir)
+	assert.Equal(t, exitInvalid, code)
+	assert.Contains(t, stdout, "bad.xml: invalid")
+	assert.NotContains(t, stdout, "ecg.xml: valid", "-q hides valid files")
+	assert.Contains(t, stdout, "3 file(s): 2 valid, 1 invalid, 0 failed")
+	assert.Contains(t, stdout, "2.16.840.1.113883.3.5^SITE_001")
+
+	code, stdout, _ = runCommand("validate", "-format", "json", dir)
+	assert.Equal(t, exitInvalid, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	require.Len(t, lines, 4)
+	var last struct {
+		Summary struct {
+			Files int            `json:"files"`
+			Rules map[string]int `json:"rules"`
+		} `json:"summary"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(lines[3]), &last))
+	assert.Equal(t, 3, last.Summary.Files)
+	assert.Equal(t, map[string]int{"xml": 1}, last.Summary.Rules)
+}
+
+// TestInspect tests the text and JSON summaries
+func TestInspect(t *testing.T) {
+	file := buildTestFile(t, t.TempDir())
+
+	code, stdout, _ := runCommand("inspect", file)
+	require.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "2.16.840.1.113883.3.6^SUBJ_1 ENROLLED 19800101")
+	assert.Contains(t, stdout, "Thorough QT")
+	assert.Contains(t, stdout, "500 Hz, 3 samples, 0.006 s")
+	assert.Contains(t, stdout, "Leads       I AVR")
+
+	code, stdout, _ = runCommand("inspect", "-format", "json", file)
+	require.Equal(t, exitOK, code)
+	var summary struct {
+		Trial struct {
+			Sponsor string `json:"sponsor"`
+		} `json:"trial"`
+	}
+	require.NoError(t, json.Unmarshal([]byte(stdout), &summary))
+	assert.Equal(t, "ABC Pharma", summary.Trial.Sponsor)
+}
+
+// TestConvert tests the CSV, JSON and SVG conversions
+func TestConvert(t *testing.T) {
+	dir := t.TempDir()
+	file := buildTestFile(t, dir)
+
+	code, stdout, _ := runCommand("convert", "-to", "csv", file)
+	require.Equal(t, exitOK, code)
+	lines := strings.Split(strings.TrimSpace(stdout), "\n")
+	assert.Equal(t, []string{"time_s,MDC_ECG_LEAD_I,MDC_ECG_LEAD_AVR", "0,5,20", "0.002,10,25", "0.004,15,30"}, lines)
+
+	code, stdout, _ = runCommand("convert", "-to", "json", file)
+	require.Equal(t, exitOK, code)
+	assert.True(t, json.Valid([]byte(stdout)))
+
+	svg := filepath.Join(dir, "ecg.svg")
+	code, _, _ = runCommand("convert", "-to", "svg", "-o", svg, file)
+	require.Equal(t, exitOK, code)
+	data, err := os.ReadFile(svg)
+	require.NoError(t, err)
+	assert.True(t, bytes.HasPrefix(data, []byte("<svg")))
+
+	code, _, stderr := runCommand("convert", "-to", "csv", "-series", "3", file)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "no such series")
+
+	code, _, _ = runCommand("convert", "-to", "pdf", file)
+	assert.Equal(t, exitError, code)
+}
+
+// TestRun_Usage tests the dispatch of unknown commands and help
+func TestRun_Usage(t *testing.T) {
+	code, _, stderr := runCommand()
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "Usage: aecg")
+
+	code, _, _ = runCommand("frobnicate")
+	assert.Equal(t, exitError, code)
+
+	code, stdout, _ := runCommand("help")
+	assert.Equal(t, exitOK, code)
+	assert.Contains(t, stdout, "validate")
+
+	code, _, _ = runCommand("validate", "-h")
+	assert.Equal(t, exitOK, code)
+}
+
+// TestServe_Errors tests the serve flags and listen errors
+func TestServe_Errors(t *testing.T) {
+	code, _, _ := runCommand("serve", "extra")
+	assert.Equal(t, exitError, code)
+
+	code, _, stderr := runCommand("serve", "-addr", "no-port")
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "missing port")
+}
+
+// TestIngest_Errors tests the ingest flags
+func TestIngest_Errors(t *testing.T) {
+	dir := t.TempDir()
+
+	code, _, _ := runCommand("ingest", dir)
+	assert.Equal(t, exitError, code, "a sink is required")
+
+	code, _, stderr := runCommand("ingest", "-sink", "ftp:host", dir)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, `unknown sink "ftp"`)
+
+	code, _, stderr = runCommand("ingest", "-sink", "webhook", dir)
+	assert.Equal(t, exitError, code)
+	assert.Contains(t, stderr, "want kind:target")
+}
+
+// TestDiff tests identical and differing documents
+func TestDiff(t *testing.T) {
+	file := buildTestFile(t, t.TempDir())
+
+	code, stdout, stderr := runCommand("diff", file, file)
+	require.Equal(t, exitOK, code, stderr)
+	assert.Empty(t, stdout)
+
+	dir := t.TempDir()
+	edited := strings.Replace(testDescription, `"I": [1, 2, 3]`, `"I": [1, 2, 4]`, 1)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "ecg.json"), []byte(edited), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "study.yaml"), []byte(testProfile), 0o644))
+	other := filepath.Join(dir, "ecg.xml")
+	code, _, stderr = runCommand("build", "-o", other, filepath.Join(dir, "ecg.json"))
+	require.Equal(t, exitOK, code, stderr)
+
+	code, stdout, _ = runCommand("diff", file, other)
+	assert.Equal(t, exitInvalid, code)
+	assert.Equal(t, "~ component[0].series MDC_ECG_LEAD_I: max |Δ| 5 µV, samples 2-3\n", stdout)
+
+	code, stdout, _ = runCommand("diff", "-format", "json", "-tolerance", "5", file, other)
+	assert.Equal(t, exitOK, code)
+	assert.True(t, json.Valid([]byte(stdout)))
+
+	code, _, _ = runCommand("diff", file)
+	assert.Equal(t, exitError, code)
+}
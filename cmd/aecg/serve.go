@@ -0,0 +1,62 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/server"
+)
+
+// runServe serves the HTTP validation and conversion API (see package
+// server) until interrupted, then waits for the requests in progress.
+func runServe(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("serve", "", stderr)
+	addr := fs.String("addr", "localhost:8080", "listen address")
+	maxSize := fs.Int64("max-size", server.DefaultMaxBodyBytes>>20, "maximum upload size in MiB")
+	timeout := fs.Duration("timeout", server.DefaultTimeout, "maximum duration of a request")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() != 0 {
+		fs.Usage()
+		return exitError
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: server.New(server.Config{
+			MaxBodyBytes: *maxSize << 20,
+			Timeout:      *timeout,
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	errc := make(chan error, 1)
+	go func() {
+		fmt.Fprintf(stdout, "aecg: listening on %s\n", *addr)
+		errc <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errc:
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	case <-ctx.Done():
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+	if err := srv.Shutdown(shutdownCtx); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	}
+	return exitOK
+}
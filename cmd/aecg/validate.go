@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"os"
+	"os/signal"
+	"slices"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/batch"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// fileReport is the validation report of one file.
+type fileReport struct {
+	File  string `json:"file"`
+	Error string `json:"error,omitempty"`
+	*types.ValidationReport
+}
+
+// runValidate validates files and directory trees and prints one report
+// per file, as text or as JSON lines, in completion order.
+//
+// Directories are walked for *.xml files, validated by a pool of workers.
+// When more than one file is validated, a summary follows: counts per
+// rule, per site and per subject (the last JSON line, as {"summary": ...}).
+// Files that are not well-formed aECG XML are reported invalid with a
+// single "xml" error.
+//
+// Interrupting the command cancels the validations in progress.
+func runValidate(args []string, stdout, stderr io.Writer) int {
+	fs := newFlagSet("validate", "file.xml|dir...", stderr)
+	format := fs.String("format", "text", "output format: text or json")
+	workers := fs.Int("workers", 0, "number of files validated in parallel (default: number of CPUs)")
+	quiet := fs.Bool("q", false, "only report invalid files (text)")
+	if code, ok := parseFlags(fs, args); !ok {
+		return code
+	}
+	if fs.NArg() == 0 || !checkFormat(*format, stderr) {
+		fs.Usage()
+		return exitError
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	enc := json.NewEncoder(stdout)
+	summary, err := batch.Run(ctx, fs.Args(), batch.Options{Workers: *workers}, func(r batch.Result) {
+		if *format == "json" {
+			report := fileReport{File: r.Path, ValidationReport: r.Report}
+			if r.Err != nil {
+				report.Error = r.Err.Error()
+			}
+			enc.Encode(report)
+			return
+		}
+		if r.Err != nil {
+			fmt.Fprintf(stdout, "%s: error: %v\n", r.Path, r.Err)
+			return
+		}
+		if !*quiet || !r.Valid() {
+			printReport(stdout, r.Path, r.Report)
+		}
+	})
+
+	if summary.Files > 1 {
+		if *format == "json" {
+			enc.Encode(struct {
+				Summary *batch.Summary `json:"summary"`
+			}{summary})
+		} else {
+			printBatchSummary(stdout, summary)
+		}
+	}
+
+	switch {
+	case err != nil:
+		fmt.Fprintf(stderr, "aecg: %v\n", err)
+		return exitError
+	case summary.Failed > 0:
+		return exitError
+	case summary.Invalid > 0:
+		return exitInvalid
+	default:
+		return exitOK
+	}
+}
+
+// printReport prints a report as text.
+func printReport(w io.Writer, path string, report *types.ValidationReport) {
+	if report.Valid {
+		fmt.Fprintf(w, "%s: valid", path)
+	} else {
+		fmt.Fprintf(w, "%s: invalid, %d error(s)", path, len(report.Errors))
+	}
+	if len(report.Warnings) > 0 {
+		fmt.Fprintf(w, ", %d warning(s)", len(report.Warnings))
+	}
+	fmt.Fprintln(w)
+
+	for _, issue := range report.Errors {
+		field := issue.Field
+		if issue.Path != "" {
+			field = issue.Path + ": " + field
+		}
+		if issue.Value != "" {
+			fmt.Fprintf(w, "  error   %s: %s (%s)\n", field, issue.Message, issue.Value)
+		} else {
+			fmt.Fprintf(w, "  error   %s: %s\n", field, issue.Message)
+		}
+	}
+	for _, warning := range report.Warnings {
+		fmt.Fprintf(w, "  warning %s\n", warning)
+	}
+}
+
+// printBatchSummary prints the summary of a run as text. Subjects are
+// listed only when they have invalid or unreadable files.
+func printBatchSummary(w io.Writer, s *batch.Summary) {
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%d file(s): %d valid, %d invalid, %d failed\n", s.Files, s.Valid, s.Invalid, s.Failed)
+
+	if len(s.Rules) > 0 {
+		fmt.Fprintln(w, "Errors per rule:")
+		for _, rule := range slices.Sorted(maps.Keys(s.Rules)) {
+			fmt.Fprintf(w, "  %-30s %d\n", orUnknown(rule), s.Rules[rule])
+		}
+	}
+	fmt.Fprintln(w, "Files per site:")
+	for _, site := range slices.Sorted(maps.Keys(s.Sites)) {
+		printCounts(w, site, s.Sites[site])
+	}
+	header := false
+	for _, subject := range slices.Sorted(maps.Keys(s.Subjects)) {
+		c := s.Subjects[subject]
+		if c.Invalid+c.Failed == 0 {
+			continue
+		}
+		if !header {
+			fmt.Fprintln(w, "Subjects with invalid files:")
+			header = true
+		}
+		printCounts(w, subject, c)
+	}
+}
+
+// printCounts prints one line of counts.
+func printCounts(w io.Writer, key string, c *batch.Counts) {
+	fmt.Fprintf(w, "  %-30s %d file(s): %d valid, %d invalid, %d failed\n", orUnknown(key), c.Files, c.Valid, c.Invalid, c.Failed)
+}
+
+// orUnknown returns key, or "(unknown)" when empty.
+func orUnknown(key string) string {
+	if key == "" {
+		return "(unknown)"
+	}
+	return key
+}
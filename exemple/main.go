@@ -274,6 +274,7 @@ func main() {
 
 	// Initialize annotation set with activity time
 	annSet := rhythmSeries.InitAnnotationSet("20250923103600")
+	annSet.AddDeviceAuthor(types.AUTHOR_FUNCTION_ALGORITHM, "", "", "MINDRAY BeneHeart R12", "")
 	fmt.Println("✓ Initialized annotation set")
 
 	// Add global annotations (without supportingROI)
@@ -438,11 +439,13 @@ func main() {
 						fmt.Printf("    Origin: %s %s\n", slistPq.Origin.Value, slistPq.Origin.Unit)
 						fmt.Printf("    Scale: %s %s\n", slistPq.Scale.Value, slistPq.Scale.Unit)
 						// Show first few digits
-						digits := slistPq.Digits
-						if len(digits) > 50 {
-							digits = digits[:50] + "..."
+						if digits, err := slistPq.GetDigitValues(); err == nil {
+							if len(digits) > 10 {
+								fmt.Printf("    Digits: %v... (%d samples)\n", digits[:10], len(digits))
+							} else {
+								fmt.Printf("    Digits: %v\n", digits)
+							}
 						}
-						fmt.Printf("    Digits: %s\n", digits)
 					}
 				}
 			}
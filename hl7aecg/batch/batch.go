@@ -0,0 +1,228 @@
+// Package batch validates trees of aECG files with a bounded pool of
+// workers, as done on a full submission package before it is sent.
+//
+// Each file is read and validated with Hl7xml.UnmarshalAndValidate. Results
+// are streamed to a callback as they complete, and an aggregate Summary
+// counts the files per validation rule, per trial site and per subject.
+//
+// Example:
+//
+//	summary, err := batch.Run(ctx, []string{"submission/"}, batch.Options{Workers: 8},
+//		func(r batch.Result) {
+//			if !r.Valid() {
+//				fmt.Println(r.Path)
+//			}
+//		})
+package batch
+
+import (
+	"context"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Options and Results
+// =============================================================================
+
+// Options configures a batch run.
+type Options struct {
+	// Workers is the number of files validated concurrently
+	// (runtime.NumCPU() by default).
+	Workers int
+
+	// Extensions lists the file extensions validated when walking a
+	// directory, compared case-insensitively ([".xml"] by default). Files
+	// named explicitly are always validated.
+	Extensions []string
+
+	// Registry is passed to hl7aecg.NewReader for every file.
+	Registry *types.TrialRegistry
+}
+
+// withDefaults fills the zero fields with the defaults.
+func (o Options) withDefaults() Options {
+	if o.Workers <= 0 {
+		o.Workers = runtime.NumCPU()
+	}
+	if len(o.Extensions) == 0 {
+		o.Extensions = []string{".xml"}
+	}
+	extensions := make([]string, len(o.Extensions))
+	for i, ext := range o.Extensions {
+		extensions[i] = strings.ToLower(ext)
+	}
+	o.Extensions = extensions
+	return o
+}
+
+// Result is the outcome of one file.
+type Result struct {
+	// Path is the path of the file.
+	Path string
+
+	// Site and Subject identify the trial site and subject of the document
+	// (root^extension), empty when absent or when the file is not parsed.
+	Site    string
+	Subject string
+
+	// Report is the validation report. Files that are not well-formed aECG
+	// XML are reported invalid with a single "xml" issue. Nil when Err is set.
+	Report *types.ValidationReport
+
+	// Err is set when the file could not be read.
+	Err error
+}
+
+// Valid reports whether the file was read and is valid.
+func (r *Result) Valid() bool {
+	return r.Err == nil && r.Report.Valid
+}
+
+// ValidateFile reads and validates one file under ctx.
+//
+// It returns the context error, and no result, if ctx is done before the
+// validation completes.
+func ValidateFile(ctx context.Context, path string, registry *types.TrialRegistry) (Result, error) {
+	result := Result{Path: path}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		result.Err = err
+		return result, nil
+	}
+
+	h := hl7aecg.NewReader(ctx, registry)
+	if result.Report, err = h.UnmarshalAndReport(data); err != nil {
+		return Result{}, err
+	}
+	result.Site, result.Subject = identify(&h.HL7AEcg)
+	return result, nil
+}
+
+// identify returns the site and subject identifiers of a document.
+func identify(doc *types.HL7AEcg) (site, subject string) {
+	if ts := doc.GetTrialSubject(); ts != nil && ts.ID != nil && !ts.ID.IsEmpty() {
+		subject = ts.ID.String()
+	}
+	if doc.ComponentOf != nil {
+		ct := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial
+		if ct.Location != nil && !ct.Location.TrialSite.ID.IsEmpty() {
+			site = ct.Location.TrialSite.ID.String()
+		}
+	}
+	return site, subject
+}
+
+// =============================================================================
+// Run
+// =============================================================================
+
+// Run validates the files under roots, which may be files or directories
+// walked recursively, with a pool of opts.Workers workers.
+//
+// fn, if not nil, is called with each result as it completes, in completion
+// order, from the goroutine calling Run. Unreadable files and directories
+// are reported with Err set.
+//
+// When ctx is done, Run stops walking, waits for the workers and returns
+// the summary of the files completed so far with the context error.
+func Run(ctx context.Context, roots []string, opts Options, fn func(Result)) (*Summary, error) {
+	opts = opts.withDefaults()
+
+	paths := make(chan string)
+	results := make(chan Result)
+
+	// Walker: reports walk errors directly as results
+	go func() {
+		defer close(paths)
+		for _, root := range roots {
+			if err := walk(ctx, root, opts.Extensions, paths, results); err != nil {
+				return
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	for range opts.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for path := range paths {
+				result, err := ValidateFile(ctx, path, opts.Registry)
+				if err != nil {
+					continue // Canceled: drain the remaining paths
+				}
+				select {
+				case results <- result:
+				case <-ctx.Done():
+				}
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	summary := newSummary()
+	for result := range results {
+		summary.add(&result)
+		if fn != nil {
+			fn(result)
+		}
+	}
+	return summary, ctx.Err()
+}
+
+// walk sends the files under root to paths. It returns the context error
+// when ctx is done.
+func walk(ctx context.Context, root string, extensions []string, paths chan<- string, results chan<- Result) error {
+	send := func(path string) error {
+		select {
+		case paths <- path:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	fail := func(path string, err error) error {
+		select {
+		case results <- Result{Path: path, Err: err}:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	info, err := os.Stat(root)
+	if err != nil {
+		return fail(root, err)
+	}
+	if !info.IsDir() {
+		return send(root)
+	}
+
+	return filepath.WalkDir(root, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if ferr := fail(path, err); ferr != nil {
+				return ferr
+			}
+			if d != nil && d.IsDir() {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if d.IsDir() || !slices.Contains(extensions, strings.ToLower(filepath.Ext(path))) {
+			return nil
+		}
+		return send(path)
+	})
+}
@@ -0,0 +1,109 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+)
+
+// newSubmission writes a submission tree: two sites, three subjects, one
+// invalid document, one malformed file and one file that is not XML.
+func newSubmission(t *testing.T) string {
+	dir := t.TempDir()
+	testdoc.Write(t, filepath.Join(dir, "SITE_1", "S1_V1.xml"), testdoc.Doc{Subject: "S1", Site: "SITE_1"})
+	testdoc.Write(t, filepath.Join(dir, "SITE_1", "S1_V2.XML"), testdoc.Doc{Subject: "S1", Site: "SITE_1"})
+	testdoc.Write(t, filepath.Join(dir, "SITE_1", "S2_V1.xml"), testdoc.Doc{Subject: "S2", Site: "SITE_1", Invalid: true})
+	testdoc.Write(t, filepath.Join(dir, "SITE_2", "deep", "S3_V1.xml"), testdoc.Doc{Subject: "S3", Site: "SITE_2"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SITE_2", "broken.xml"), []byte("<AnnotatedECG"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "SITE_2", "notes.txt"), []byte("not an ECG"), 0o644))
+	return dir
+}
+
+// TestRun tests the per-file results and the aggregate counts
+func TestRun(t *testing.T) {
+	dir := newSubmission(t)
+
+	var results []Result
+	summary, err := Run(context.Background(), []string{dir}, Options{Workers: 3}, func(r Result) {
+		results = append(results, r)
+	})
+	require.NoError(t, err)
+	assert.Len(t, results, 5, "notes.txt is skipped")
+
+	assert.Equal(t, Counts{Files: 5, Valid: 3, Invalid: 2}, summary.Counts)
+	assert.Equal(t, 1, summary.Rules["xml"])
+	assert.Positive(t, summary.Rules["Time"])
+
+	site1 := fmt.Sprintf("%s^SITE_1", testdoc.Root)
+	require.Contains(t, summary.Sites, site1)
+	assert.Equal(t, Counts{Files: 3, Valid: 2, Invalid: 1}, *summary.Sites[site1])
+	assert.Equal(t, Counts{Files: 1, Invalid: 1}, *summary.Sites[""], "the broken file has no site")
+	assert.Equal(t, Counts{Files: 2, Valid: 2}, *summary.Subjects[testdoc.Root+"^S1"])
+
+	for _, r := range results {
+		if filepath.Base(r.Path) == "broken.xml" {
+			assert.False(t, r.Valid())
+			assert.Equal(t, "xml", r.Report.Errors[0].Field)
+		}
+	}
+}
+
+// TestRun_Files tests explicit files and unreadable roots
+func TestRun_Files(t *testing.T) {
+	dir := newSubmission(t)
+
+	summary, err := Run(context.Background(), []string{
+		filepath.Join(dir, "SITE_2", "notes.txt"),
+		filepath.Join(dir, "missing"),
+	}, Options{}, nil)
+	require.NoError(t, err)
+	assert.Equal(t, Counts{Files: 2, Invalid: 1, Failed: 1}, summary.Counts)
+}
+
+// TestRun_Canceled tests that a canceled run stops and reports the context error
+func TestRun_Canceled(t *testing.T) {
+	dir := t.TempDir()
+	for i := range 50 {
+		testdoc.Write(t, filepath.Join(dir, fmt.Sprintf("ECG_%02d.xml", i)), testdoc.Doc{ID: fmt.Sprintf("S%d", i), Subject: fmt.Sprintf("S%d", i), Site: "SITE_1"})
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	summary, err := Run(ctx, []string{dir}, Options{Workers: 2}, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Zero(t, summary.Files)
+
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	var seen atomic.Int32
+	summary, err = Run(ctx, []string{dir}, Options{Workers: 2}, func(Result) {
+		seen.Add(1)
+		cancel()
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Less(t, summary.Files, 50)
+	assert.Equal(t, int(seen.Load()), summary.Files)
+}
+
+// TestValidateFile_Canceled tests that validation under a done context returns its error
+func TestValidateFile_Canceled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ecg.xml")
+	testdoc.Write(t, path, testdoc.Doc{Subject: "S1", Site: "SITE_1"})
+
+	result, err := ValidateFile(context.Background(), path, nil)
+	require.NoError(t, err)
+	assert.True(t, result.Valid(), "%+v", result.Report)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = ValidateFile(ctx, path, nil)
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -0,0 +1,85 @@
+package batch
+
+import (
+	"encoding/json"
+	"io"
+)
+
+// =============================================================================
+// Summary
+// =============================================================================
+
+// Counts counts files by outcome.
+type Counts struct {
+	Files   int `json:"files"`
+	Valid   int `json:"valid"`
+	Invalid int `json:"invalid"`
+
+	// Failed counts the files that could not be read.
+	Failed int `json:"failed"`
+}
+
+// add counts one result.
+func (c *Counts) add(r *Result) {
+	c.Files++
+	switch {
+	case r.Err != nil:
+		c.Failed++
+	case r.Report.Valid:
+		c.Valid++
+	default:
+		c.Invalid++
+	}
+}
+
+// Summary aggregates the results of a run.
+type Summary struct {
+	Counts
+
+	// Rules counts the validation errors per field (see
+	// types.ValidationReport.Rules), over all files.
+	Rules map[string]int `json:"rules"`
+
+	// Sites and Subjects count the files per trial site and per subject.
+	// Files without site or subject are counted under "".
+	Sites    map[string]*Counts `json:"sites"`
+	Subjects map[string]*Counts `json:"subjects"`
+}
+
+// newSummary returns an empty summary.
+func newSummary() *Summary {
+	return &Summary{
+		Rules:    map[string]int{},
+		Sites:    map[string]*Counts{},
+		Subjects: map[string]*Counts{},
+	}
+}
+
+// add counts one result.
+func (s *Summary) add(r *Result) {
+	s.Counts.add(r)
+	countIn(s.Sites, r.Site).add(r)
+	countIn(s.Subjects, r.Subject).add(r)
+	if r.Report != nil {
+		for rule, n := range r.Report.Rules() {
+			s.Rules[rule] += n
+		}
+	}
+}
+
+// countIn returns the counts of key, creating them if needed.
+func countIn(m map[string]*Counts, key string) *Counts {
+	c, ok := m[key]
+	if !ok {
+		c = &Counts{}
+		m[key] = c
+	}
+	return c
+}
+
+// WriteJSON writes the summary as indented JSON.
+func (s *Summary) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(s)
+}
@@ -2,7 +2,6 @@ package hl7aecg
 
 import (
 	"log"
-	"slices"
 	"strconv"
 
 	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
@@ -14,13 +13,16 @@ import (
 
 // AddRhythmSeries creates and adds a rhythm series to the aECG.
 //
+// The time and lead sequences are encoded as selected with SetSeriesEncoding:
+// by default an absolute GLIST_TS time sequence and SLIST_PQ leads in µV.
+//
 // Parameters:
 //   - startTime: Start timestamp (YYYYMMDDHHmmss.SSS format)
 //   - endTime: End timestamp
 //   - sampleRate: Sampling rate in Hz (e.g., 500 for 500 Hz)
 //   - leads: Map of lead codes to their raw sample data
 //   - origin: Baseline voltage value (typically 0)
-//   - scale: Voltage resolution per digit (e.g., 5 for 5 µV)
+//   - scale: Voltage resolution per digit, in the encoding unit (e.g., 5 for 5 µV)
 //
 // Example:
 //
@@ -36,14 +38,19 @@ func (h *Hl7xml) AddRhythmSeries(
 	leads map[types.LeadCode][]int,
 	origin, scale float64,
 ) *Hl7xml {
+	if !h.checkLeadScale(origin, scale) {
+		return h
+	}
+	data, ok := scaledLeads(leads, origin, scale)
+	if !ok {
+		return h
+	}
 	series := h.buildSeries(
 		types.RHYTHM_CODE,
 		startTime,
 		endTime,
-		sampleRate,
-		leads,
-		origin,
-		scale,
+		buildTimeSequence(h.SeriesEncoding().Time, startTime, sampleRate, leadLength(data)),
+		data,
 	)
 	if inclusive_low != nil {
 		series.EffectiveTime.Low.Inclusive = inclusive_low
@@ -53,6 +60,57 @@ func (h *Hl7xml) AddRhythmSeries(
 	}
 
 	h.HL7AEcg.Component = append(h.HL7AEcg.Component, types.Component{Series: *series})
+	h.applyStudySeries()
+	return h
+}
+
+// AddIrregularRhythmSeries adds a rhythm series whose samples are not evenly
+// spaced, with the time of every sample listed in a TIME_RELATIVE SLIST_PQ
+// sequence whatever the time encoding. The leads are encoded as selected
+// with SetSeriesEncoding.
+//
+// Parameters:
+//   - startTime: Start timestamp (YYYYMMDDHHmmss.SSS format)
+//   - endTime: End timestamp
+//   - times: Time of each sample from startTime, in units of timeScale
+//   - timeScale: Time resolution per digit in seconds (e.g., 0.001 for ms)
+//   - leads: Map of lead codes to their raw sample data, one per time
+//   - origin: Baseline voltage value (typically 0)
+//   - scale: Voltage resolution per digit, in the encoding unit
+//
+// Example:
+//
+//	// Samples at 0, 2, 5 and 7 ms
+//	h.AddIrregularRhythmSeries("20021122091000.000", "20021122091000.008",
+//	    []int{0, 2, 5, 7}, 0.001, leads, 0, 5)
+func (h *Hl7xml) AddIrregularRhythmSeries(
+	startTime, endTime string,
+	times []int,
+	timeScale float64,
+	leads map[types.LeadCode][]int,
+	origin, scale float64,
+) *Hl7xml {
+	if !h.checkLeadScale(origin, scale) {
+		return h
+	}
+	digits, ok := toDigits("time", times)
+	if !ok {
+		return h
+	}
+	data, ok := scaledLeads(leads, origin, scale)
+	if !ok {
+		return h
+	}
+	series := h.buildSeries(
+		types.RHYTHM_CODE,
+		startTime,
+		endTime,
+		buildSlistTimeSequence(digits, timeScale),
+		data,
+	)
+
+	h.HL7AEcg.Component = append(h.HL7AEcg.Component, types.Component{Series: *series})
+	h.applyStudySeries()
 	return h
 }
 
@@ -65,17 +123,23 @@ func (h *Hl7xml) AddRepresentativeBeatSeries(
 	leads map[types.LeadCode][]int,
 	origin, scale float64,
 ) *Hl7xml {
+	if !h.checkLeadScale(origin, scale) {
+		return h
+	}
+	data, ok := scaledLeads(leads, origin, scale)
+	if !ok {
+		return h
+	}
 	series := h.buildSeries(
 		types.REPRESENTATIVE_BEAT_CODE,
 		startTime,
 		endTime,
-		sampleRate,
-		leads,
-		origin,
-		scale,
+		buildTimeSequence(h.SeriesEncoding().Time, startTime, sampleRate, leadLength(data)),
+		data,
 	)
 
 	h.HL7AEcg.Component = append(h.HL7AEcg.Component, types.Component{Series: *series})
+	h.applyStudySeries()
 	return h
 }
 
@@ -85,7 +149,8 @@ func (h *Hl7xml) AddRepresentativeBeatSeries(
 // (e.g., representative beats, median beats, filtered waveforms).
 //
 // Key differences from rhythm series:
-//   - Uses TIME_RELATIVE (not TIME_ABSOLUTE) for time sequences
+//   - Uses TIME_RELATIVE (not TIME_ABSOLUTE) for time sequences: GLIST_PQ, or
+//     SLIST_PQ if the time encoding is TIME_SLIST_PQ
 //   - Time starts at 0 (relative to beat/segment start)
 //   - Nested within parent series via <derivation> element
 //
@@ -98,7 +163,7 @@ func (h *Hl7xml) AddRepresentativeBeatSeries(
 //   - sampleRate: Sampling rate in Hz (e.g., 500 for 500 Hz)
 //   - leads: Map of lead codes to their raw sample data
 //   - origin: Baseline voltage value (typically 0)
-//   - scale: Voltage resolution per digit (e.g., 5 for 5 µV)
+//   - scale: Voltage resolution per digit, in the encoding unit (e.g., 5 for 5 µV)
 //
 // Example:
 //
@@ -122,6 +187,13 @@ func (h *Hl7xml) AddDerivedSeries(
 		log.Println("Warning: No parent series found. Create a rhythm series first.")
 		return h
 	}
+	if !h.checkLeadScale(origin, scale) {
+		return h
+	}
+	data, ok := scaledLeads(leads, origin, scale)
+	if !ok {
+		return h
+	}
 
 	// Build derived series structure
 	derivedSeries := h.buildDerivedSeries(
@@ -131,9 +203,7 @@ func (h *Hl7xml) AddDerivedSeries(
 		startTimeLowInclusive,
 		endTimeHighInclusive,
 		sampleRate,
-		leads,
-		origin,
-		scale,
+		data,
 	)
 
 	// Add to most recent parent series
@@ -149,7 +219,7 @@ func (h *Hl7xml) AddDerivedSeries(
 //
 // Similar to buildSeries but:
 //  1. Uses TIME_RELATIVE_CODE instead of TIME_ABSOLUTE_CODE
-//  2. Uses GLIST_PQ instead of GLIST_TS for time sequence
+//  2. Uses GLIST_PQ (or SLIST_PQ) instead of GLIST_TS for time sequence
 //  3. Time starts at 0.000 (relative to beat/segment onset)
 //  4. effectiveTime still uses absolute timestamps (when the beat occurred)
 func (h *Hl7xml) buildDerivedSeries(
@@ -157,8 +227,7 @@ func (h *Hl7xml) buildDerivedSeries(
 	startTime, endTime string,
 	startTimeLowInclusive, endTimeHighInclusive *bool,
 	sampleRate float64,
-	leads map[types.LeadCode][]int,
-	origin, scale float64,
+	leads map[types.LeadCode]leadData,
 ) *types.Series {
 	series := &types.Series{
 		ID:   &types.ID{},
@@ -176,72 +245,15 @@ func (h *Hl7xml) buildDerivedSeries(
 	}
 	series.Code.SetCode(seriesType, types.HL7_ActCode_OID, "ActCode", "")
 
-	// Calculate increment (1 / sample rate)
-	increment := 1.0 / sampleRate
-
-	sequenceSet := types.SequenceSet{}
-
-	// Add TIME_RELATIVE sequence with GLIST_PQ
-	// Time starts at 0.000 (relative to beat/segment start)
-	timeSeq := types.SequenceComponent{
-		Sequence: types.Sequence{
-			Code: types.SequenceCode{
-				Time: &types.Code[types.TimeSequenceCode, types.CodeSystemOID]{},
-			},
-			Value: &types.SequenceValue{
-				XsiType: "GLIST_PQ",
-				Typed: &types.GLIST_PQ{
-					Head: types.PhysicalQuantity{
-						Value: "0.000",
-						Unit:  "s",
-					},
-					Increment: types.PhysicalQuantity{
-						Value: formatFloat(increment),
-						Unit:  "s",
-					},
-				},
-			},
-		},
-	}
-	timeSeq.Sequence.Code.Time.SetCode(
-		types.TIME_RELATIVE_CODE,
-		types.HL7_ActCode_OID,
-		"ActCode",
-		"Relative Time",
-	)
-	sequenceSet.Component = append(sequenceSet.Component, timeSeq)
-
-	// Add lead sequences in standard medical order (same as buildSeries)
-	standardOrder := []types.LeadCode{
-		types.MDC_ECG_LEAD_I,
-		types.MDC_ECG_LEAD_II,
-		types.MDC_ECG_LEAD_III,
-		types.MDC_ECG_LEAD_AVR,
-		types.MDC_ECG_LEAD_AVL,
-		types.MDC_ECG_LEAD_AVF,
-		types.MDC_ECG_LEAD_V1,
-		types.MDC_ECG_LEAD_V2,
-		types.MDC_ECG_LEAD_V3,
-		types.MDC_ECG_LEAD_V4,
-		types.MDC_ECG_LEAD_V5,
-		types.MDC_ECG_LEAD_V6,
-	}
-
-	// Iterate in standard order, only adding leads that are present in the map
-	for _, leadCode := range standardOrder {
-		if samples, exists := leads[leadCode]; exists {
-			leadSeq := h.buildLeadSequence(leadCode, samples, origin, scale)
-			sequenceSet.Component = append(sequenceSet.Component, leadSeq)
-		}
+	// Derived series are never in absolute time
+	timeEncoding := h.SeriesEncoding().Time
+	if timeEncoding == TIME_GLIST_TS {
+		timeEncoding = TIME_GLIST_PQ
 	}
 
-	// Add any remaining leads that aren't in the standard 12-lead set
-	for leadCode, samples := range leads {
-		if !slices.Contains(standardOrder, leadCode) {
-			leadSeq := h.buildLeadSequence(leadCode, samples, origin, scale)
-			sequenceSet.Component = append(sequenceSet.Component, leadSeq)
-		}
-	}
+	sequenceSet := types.SequenceSet{}
+	sequenceSet.Component = append(sequenceSet.Component, buildTimeSequence(timeEncoding, startTime, sampleRate, leadLength(leads)))
+	sequenceSet.Component = append(sequenceSet.Component, h.buildLeadSequences(leads)...)
 
 	series.Component = []types.SeriesComponent{
 		{SequenceSet: sequenceSet},
@@ -250,13 +262,12 @@ func (h *Hl7xml) buildDerivedSeries(
 	return series
 }
 
-// buildSeries constructs a Series with sequences for time and leads.
+// buildSeries constructs a Series with the time sequence and the lead sequences.
 func (h *Hl7xml) buildSeries(
 	seriesType types.SeriesTypeCode,
 	startTime, endTime string,
-	sampleRate float64,
-	leads map[types.LeadCode][]int,
-	origin, scale float64,
+	timeSeq types.SequenceComponent,
+	leads map[types.LeadCode]leadData,
 ) *types.Series {
 	series := &types.Series{
 		ID:   &types.ID{},
@@ -268,69 +279,10 @@ func (h *Hl7xml) buildSeries(
 	}
 	series.Code.SetCode(seriesType, types.HL7_ActCode_OID, "", "")
 
-	// Calculate increment from sample rate: increment = 1 / sampleRate seconds
-	increment := 1.0 / sampleRate
-
 	// Create sequence set with time sequence + lead sequences
 	sequenceSet := types.SequenceSet{}
-
-	// Add time sequence using the polymorphic SequenceValue (Typed + XsiType)
-	timeSeq := types.SequenceComponent{
-		Sequence: types.Sequence{
-			Value: &types.SequenceValue{
-				XsiType: "GLIST_TS",
-				Typed: &types.GLIST_TS{
-					Head: types.HeadTimestamp{
-						Value: startTime,
-						Unit:  "s",
-					},
-					Increment: types.Increment{
-						Value: formatFloat(increment),
-						Unit:  "s",
-					},
-				},
-			},
-		},
-	}
-	// Set time code
-	timeSeq.Sequence.Code.Time = &types.Code[types.TimeSequenceCode, types.CodeSystemOID]{}
-	timeSeq.Sequence.Code.Time.SetCode(types.TIME_ABSOLUTE_CODE, types.HL7_ActCode_OID, "ActCode", "")
 	sequenceSet.Component = append(sequenceSet.Component, timeSeq)
-
-	// Add lead sequences in the standard medical order:
-	// Limb leads: I, II, III
-	// Augmented leads: aVR, aVL, aVF
-	// Precordial leads: V1, V2, V3, V4, V5, V6
-	standardOrder := []types.LeadCode{
-		types.MDC_ECG_LEAD_I,
-		types.MDC_ECG_LEAD_II,
-		types.MDC_ECG_LEAD_III,
-		types.MDC_ECG_LEAD_AVR,
-		types.MDC_ECG_LEAD_AVL,
-		types.MDC_ECG_LEAD_AVF,
-		types.MDC_ECG_LEAD_V1,
-		types.MDC_ECG_LEAD_V2,
-		types.MDC_ECG_LEAD_V3,
-		types.MDC_ECG_LEAD_V4,
-		types.MDC_ECG_LEAD_V5,
-		types.MDC_ECG_LEAD_V6,
-	}
-
-	// Iterate in standard order, only adding leads that are present in the map
-	for _, leadCode := range standardOrder {
-		if samples, exists := leads[leadCode]; exists {
-			leadSeq := h.buildLeadSequence(leadCode, samples, origin, scale)
-			sequenceSet.Component = append(sequenceSet.Component, leadSeq)
-		}
-	}
-
-	// Add any remaining leads that aren't in the standard 12-lead set
-	for leadCode, samples := range leads {
-		if !slices.Contains(standardOrder, leadCode) {
-			leadSeq := h.buildLeadSequence(leadCode, samples, origin, scale)
-			sequenceSet.Component = append(sequenceSet.Component, leadSeq)
-		}
-	}
+	sequenceSet.Component = append(sequenceSet.Component, h.buildLeadSequences(leads)...)
 
 	series.Component = []types.SeriesComponent{
 		{SequenceSet: sequenceSet},
@@ -339,43 +291,6 @@ func (h *Hl7xml) buildSeries(
 	return series
 }
 
-func (h *Hl7xml) buildLeadSequence(
-	leadCode types.LeadCode,
-	samples []int,
-	origin, scale float64,
-) types.SequenceComponent {
-	// Convert samples to space-separated string
-	digitsStr := ""
-	for i, sample := range samples {
-		if i > 0 {
-			digitsStr += " "
-		}
-		digitsStr += formatInt(sample)
-	}
-
-	seq := types.SequenceComponent{
-		Sequence: types.Sequence{
-			Value: &types.SequenceValue{
-				XsiType: "SLIST_PQ",
-				Typed: &types.SLIST_PQ{
-					Origin: types.PhysicalQuantity{
-						Value: formatFloat(origin),
-						Unit:  "uV",
-					},
-					Scale: types.PhysicalQuantity{
-						Value: formatFloat(scale),
-						Unit:  "uV",
-					},
-					Digits: digitsStr,
-				},
-			},
-		},
-	}
-	seq.Sequence.Code.Lead = &types.Code[types.LeadCode, types.CodeSystemOID]{}
-	seq.Sequence.Code.Lead.SetCode(leadCode, types.MDC_OID, "MDC", "")
-	return seq
-}
-
 // SetSeriesAuthor sets the device that authored the series.
 func (h *Hl7xml) SetSeriesAuthor(
 	deviceID string,
@@ -592,7 +507,3 @@ func (h *Hl7xml) AddReasonCode(code types.ReasonCode) *Hl7xml {
 func formatFloat(f float64) string {
 	return strconv.FormatFloat(f, 'f', -1, 64)
 }
-
-func formatInt(i int) string {
-	return strconv.Itoa(i)
-}
@@ -0,0 +1,453 @@
+// Package compare matches the annotations of several reads of the same
+// series and reports the disagreements between readers.
+//
+// Measurements are matched by annotation code and lead: global PQ
+// annotations (e.g. MDC_ECG_TIME_PD_QT) are matched by code, and the nested
+// PQ values of lead annotations (e.g. MDC_ECG_AMPL_T in MDC_ECG_LEAD_V2) by
+// code and lead. QTc annotations are also matched by their recorded
+// correction method, so that QTcF is never compared against QTcB.
+//
+// For each measurement the report gives the value of each reader, the
+// pairwise deltas and whether the spread exceeds the configured threshold.
+// Reports can be written as JSON or CSV for adjudication.
+//
+// Example:
+//
+//	reads := compare.ReadsFromSeries(&doc.Component[0].Series)
+//	report := compare.Compare(reads, compare.DefaultOptions())
+//	report.WriteCSV(os.Stdout)
+package compare
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/qtc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Reads
+// =============================================================================
+
+// Read is one reader's annotation set.
+type Read struct {
+	// Reader labels the read in the report (e.g. "READER-01").
+	Reader string
+
+	// Function is the author function of the read, if known.
+	Function types.AuthorFunctionCode
+
+	// Set holds the annotations of the read.
+	Set *types.AnnotationSet
+}
+
+// ReadsFromSeries returns one Read per annotation set of the series.
+//
+// Each read is labelled with its author ID extension, or its author function
+// code, or its position ("set-1", "set-2", ...) when the set has no author.
+// Duplicate labels are made unique by appending the position.
+func ReadsFromSeries(s *types.Series) []Read {
+	var reads []Read
+	seen := make(map[string]bool)
+	for i, as := range s.GetAnnotationSets() {
+		read := Read{Reader: fmt.Sprintf("set-%d", i+1), Set: as}
+		if len(as.Author) > 0 {
+			author := as.Author[0]
+			if author.FunctionCode != nil {
+				read.Function = author.FunctionCode.Code
+				read.Reader = string(author.FunctionCode.Code)
+			}
+			if id := author.AssignedEntity.ID; id != nil && id.Extension != "" {
+				read.Reader = id.Extension
+			}
+		}
+		if seen[read.Reader] {
+			read.Reader = fmt.Sprintf("%s#%d", read.Reader, i+1)
+		}
+		seen[read.Reader] = true
+		reads = append(reads, read)
+	}
+	return reads
+}
+
+// =============================================================================
+// Measurements
+// =============================================================================
+
+// Key identifies a measurement across reads.
+type Key struct {
+	// Code is the annotation code (e.g. MDC_ECG_TIME_PD_QT).
+	Code string
+
+	// Lead is the lead code for lead-specific measurements, empty for global ones.
+	Lead string
+
+	// Method is the QTc correction method recorded in a nested
+	// QTC_CORRECTION_METHOD annotation (e.g. "QTcF"), empty otherwise.
+	Method string
+}
+
+// String returns "CODE", "CODE@LEAD", "CODE/METHOD" or "CODE/METHOD@LEAD".
+func (k Key) String() string {
+	s := k.Code
+	if k.Method != "" {
+		s += "/" + k.Method
+	}
+	if k.Lead != "" {
+		s += "@" + k.Lead
+	}
+	return s
+}
+
+// Measurement is a numeric annotation value normalized to its canonical unit.
+type Measurement struct {
+	Key   Key
+	Value float64
+	Unit  string
+}
+
+// Extract returns the numeric measurements of an annotation set keyed by
+// code, lead and correction method. When a code appears more than once for
+// the same lead and method, the first occurrence is kept.
+func Extract(as *types.AnnotationSet) map[Key]Measurement {
+	out := make(map[Key]Measurement)
+	if as == nil {
+		return out
+	}
+
+	add := func(key Key, ann *types.Annotation) {
+		if _, exists := out[key]; exists {
+			return
+		}
+		value, ok := ann.GetValueFloat()
+		if !ok {
+			return
+		}
+		value, unit := normalizeUnit(value, ann.GetValueUnit())
+		out[key] = Measurement{Key: key, Value: value, Unit: unit}
+	}
+
+	for i := range as.Component {
+		ann := &as.Component[i].Annotation
+		if ann.Code == nil {
+			continue
+		}
+		lead := leadOf(ann)
+		if lead == "" {
+			add(Key{Code: ann.Code.Code, Method: methodOf(ann)}, ann)
+			continue
+		}
+		for j := range ann.Component {
+			nested := &ann.Component[j].Annotation
+			if nested.Code != nil {
+				add(Key{Code: nested.Code.Code, Lead: lead}, nested)
+			}
+		}
+	}
+	return out
+}
+
+// leadOf returns the lead code of a lead annotation, or "" for global annotations.
+func leadOf(ann *types.Annotation) string {
+	if ann.Support == nil {
+		return ""
+	}
+	for _, comp := range ann.Support.SupportingROI.Component {
+		code := comp.Boundary.Code.Code
+		if strings.HasPrefix(code, "MDC_ECG_LEAD_") {
+			return code
+		}
+	}
+	return ""
+}
+
+// methodOf returns the correction method recorded on a QTc annotation, or ""
+// when it has none.
+func methodOf(ann *types.Annotation) string {
+	formula, ok := qtc.FormulaOf(ann)
+	if !ok {
+		return ""
+	}
+	return string(formula.Method)
+}
+
+// normalizeUnit converts time values to ms and voltage values to uV so that
+// reads using different units can be compared.
+func normalizeUnit(value float64, unit string) (float64, string) {
+	switch unit {
+	case types.UNIT_SECOND:
+		return value * 1000, types.UNIT_MILLISECOND
+	case types.UNIT_MILLIVOLT:
+		return value * 1000, types.UNIT_MICROVOLT
+	case types.UNIT_VOLT:
+		return value * 1e6, types.UNIT_MICROVOLT
+	}
+	return value, unit
+}
+
+// =============================================================================
+// Thresholds
+// =============================================================================
+
+// Threshold is the maximum accepted spread between readers.
+//
+// A measurement is flagged when the spread exceeds both the absolute and the
+// relative limit. A zero limit is ignored, so that a threshold with only
+// Absolute set is purely absolute.
+type Threshold struct {
+	// Absolute is the limit in the canonical unit of the measurement (ms, uV, bpm, deg).
+	Absolute float64 `json:"absolute,omitempty"`
+
+	// Relative is the limit as a fraction of the mean value (e.g. 0.1 for 10%).
+	Relative float64 `json:"relative,omitempty"`
+}
+
+// Exceeded reports whether spread exceeds the threshold for a given mean value.
+func (t Threshold) Exceeded(spread, mean float64) bool {
+	if t.Absolute <= 0 && t.Relative <= 0 {
+		return false
+	}
+	if t.Absolute > 0 && spread <= t.Absolute {
+		return false
+	}
+	if t.Relative > 0 && spread <= t.Relative*math.Abs(mean) {
+		return false
+	}
+	return true
+}
+
+// Options configures a comparison.
+type Options struct {
+	// Thresholds holds per-code thresholds, keyed by annotation code.
+	Thresholds map[string]Threshold
+
+	// Default applies to codes absent from Thresholds.
+	Default Threshold
+
+	// FlagMissing flags measurements reported by some readers but not others.
+	FlagMissing bool
+}
+
+// DefaultOptions returns thresholds commonly used for core-lab reads.
+func DefaultOptions() Options {
+	return Options{
+		Thresholds: map[string]Threshold{
+			string(types.MDC_ECG_HEART_RATE):      {Absolute: 5},
+			string(types.MDC_ECG_TIME_PD_RR):      {Absolute: 50},
+			string(types.MDC_ECG_TIME_PD_PR):      {Absolute: 20},
+			string(types.MDC_ECG_TIME_PD_QRS):     {Absolute: 10},
+			string(types.MDC_ECG_TIME_PD_QT):      {Absolute: 15},
+			string(types.MDC_ECG_TIME_PD_QTc):     {Absolute: 15},
+			string(types.MDC_ECG_TIME_PD_QTC):     {Absolute: 15},
+			string(types.MDC_ECG_ANGLE_P_FRONT):   {Absolute: 30},
+			string(types.MDC_ECG_ANGLE_QRS_FRONT): {Absolute: 30},
+			string(types.MDC_ECG_ANGLE_T_FRONT):   {Absolute: 30},
+		},
+		Default:     Threshold{Relative: 0.1},
+		FlagMissing: true,
+	}
+}
+
+// thresholdFor returns the threshold applying to a code.
+func (o Options) thresholdFor(code string) Threshold {
+	if t, ok := o.Thresholds[code]; ok {
+		return t
+	}
+	return o.Default
+}
+
+// =============================================================================
+// Comparison
+// =============================================================================
+
+// Value is one reader's value for a measurement.
+type Value struct {
+	Reader  string  `json:"reader"`
+	Value   float64 `json:"value"`
+	Present bool    `json:"present"`
+}
+
+// Delta is the difference between two readers (B − A).
+type Delta struct {
+	A     string  `json:"a"`
+	B     string  `json:"b"`
+	Delta float64 `json:"delta"`
+}
+
+// Item is the comparison of one measurement across all readers.
+type Item struct {
+	Code      string    `json:"code"`
+	Lead      string    `json:"lead,omitempty"`
+	Method    string    `json:"method,omitempty"`
+	Unit      string    `json:"unit"`
+	Values    []Value   `json:"values"`
+	Deltas    []Delta   `json:"deltas"`
+	Mean      float64   `json:"mean"`
+	Spread    float64   `json:"spread"`
+	Threshold Threshold `json:"threshold"`
+
+	// Missing lists readers that did not report the measurement.
+	Missing []string `json:"missing,omitempty"`
+
+	// UnitMismatch is set when readers used incompatible units.
+	UnitMismatch bool `json:"unitMismatch,omitempty"`
+
+	// Disagreement is set when the item needs adjudication.
+	Disagreement bool `json:"disagreement"`
+}
+
+// Key returns the measurement key of the item.
+func (it Item) Key() Key {
+	return Key{Code: it.Code, Lead: it.Lead, Method: it.Method}
+}
+
+// Reader describes a read included in a report.
+type Reader struct {
+	Name         string `json:"name"`
+	Function     string `json:"function,omitempty"`
+	ActivityTime string `json:"activityTime,omitempty"`
+}
+
+// Report is the result of a comparison.
+type Report struct {
+	Readers       []Reader `json:"readers"`
+	Items         []Item   `json:"items"`
+	Disagreements int      `json:"disagreements"`
+}
+
+// Compare matches the measurements of all reads and flags disagreements.
+//
+// Items are ordered by code, then lead, with global measurements first, then
+// correction method.
+func Compare(reads []Read, opts Options) *Report {
+	report := &Report{Items: []Item{}}
+
+	extracted := make([]map[Key]Measurement, len(reads))
+	keys := make(map[Key]bool)
+	for i, read := range reads {
+		r := Reader{Name: read.Reader, Function: string(read.Function)}
+		if read.Set != nil && read.Set.ActivityTime != nil {
+			r.ActivityTime = read.Set.ActivityTime.Value
+		}
+		report.Readers = append(report.Readers, r)
+
+		extracted[i] = Extract(read.Set)
+		for key := range extracted[i] {
+			keys[key] = true
+		}
+	}
+
+	ordered := make([]Key, 0, len(keys))
+	for key := range keys {
+		ordered = append(ordered, key)
+	}
+	slices.SortFunc(ordered, func(a, b Key) int {
+		if c := strings.Compare(a.Code, b.Code); c != 0 {
+			return c
+		}
+		if c := strings.Compare(a.Lead, b.Lead); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Method, b.Method)
+	})
+
+	for _, key := range ordered {
+		item := compareKey(key, reads, extracted, opts)
+		if item.Disagreement {
+			report.Disagreements++
+		}
+		report.Items = append(report.Items, item)
+	}
+	return report
+}
+
+// compareKey builds the comparison item of one measurement.
+func compareKey(key Key, reads []Read, extracted []map[Key]Measurement, opts Options) Item {
+	item := Item{
+		Code:      key.Code,
+		Lead:      key.Lead,
+		Method:    key.Method,
+		Values:    []Value{},
+		Deltas:    []Delta{},
+		Threshold: opts.thresholdFor(key.Code),
+	}
+
+	var present []Value
+	for i, read := range reads {
+		m, ok := extracted[i][key]
+		if !ok {
+			item.Missing = append(item.Missing, read.Reader)
+			item.Values = append(item.Values, Value{Reader: read.Reader})
+			continue
+		}
+		if item.Unit == "" {
+			item.Unit = m.Unit
+		} else if m.Unit != item.Unit {
+			item.UnitMismatch = true
+		}
+		v := Value{Reader: read.Reader, Value: m.Value, Present: true}
+		item.Values = append(item.Values, v)
+		present = append(present, v)
+	}
+
+	if len(present) > 0 {
+		low, high := present[0].Value, present[0].Value
+		var sum float64
+		for _, v := range present {
+			low = min(low, v.Value)
+			high = max(high, v.Value)
+			sum += v.Value
+		}
+		item.Mean = sum / float64(len(present))
+		item.Spread = high - low
+	}
+	for i := range present {
+		for j := i + 1; j < len(present); j++ {
+			item.Deltas = append(item.Deltas, Delta{
+				A:     present[i].Reader,
+				B:     present[j].Reader,
+				Delta: present[j].Value - present[i].Value,
+			})
+		}
+	}
+
+	item.Disagreement = item.UnitMismatch ||
+		(opts.FlagMissing && len(item.Missing) > 0) ||
+		item.Threshold.Exceeded(item.Spread, item.Mean)
+	return item
+}
+
+// DisagreementItems returns only the items needing adjudication.
+func (r *Report) DisagreementItems() []Item {
+	items := []Item{}
+	for _, it := range r.Items {
+		if it.Disagreement {
+			items = append(items, it)
+		}
+	}
+	return items
+}
+
+// Find returns the first item for a code and lead (empty for global
+// measurements), whatever its correction method.
+func (r *Report) Find(code, lead string) (Item, bool) {
+	for _, it := range r.Items {
+		if it.Code == code && it.Lead == lead {
+			return it, true
+		}
+	}
+	return Item{}, false
+}
+
+// FindKey returns the item for a key, including its correction method.
+func (r *Report) FindKey(key Key) (Item, bool) {
+	for _, it := range r.Items {
+		if it.Key() == key {
+			return it, true
+		}
+	}
+	return Item{}, false
+}
@@ -0,0 +1,214 @@
+package compare
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/qtc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+const testReaderRoot = "2.16.840.1.113883.3.5"
+
+// newReadSeries builds a series read by two readers who disagree on QT and
+// on the T amplitude in V2.
+func newReadSeries() *types.Series {
+	series := &types.Series{}
+
+	first := series.AddAnnotationSet("20250924090000").
+		AddPersonAuthor(types.AUTHOR_FUNCTION_PRIMARY_READER, testReaderRoot, "READER-01", "")
+	first.AddHeartRate(62)
+	first.AddQTInterval(398)
+	first.AddPRInterval(160)
+	idx := first.AddLeadAnnotation("MDC_ECG_LEAD_V2", "MEASUREMENT_MATRIX", "", "HL7V3AECG")
+	first.GetAnnotation(idx).AddNestedAnnotation("MDC_ECG_AMPL_T", "", 450, types.UNIT_MICROVOLT)
+
+	second := series.AddAnnotationSet("20250924093000").
+		AddPersonAuthor(types.AUTHOR_FUNCTION_SECONDARY_READER, testReaderRoot, "READER-02", "")
+	second.AddHeartRate(63)
+	second.AddQTInterval(420)
+	second.AddAnnotation(string(types.MDC_ECG_TIME_PD_PR), string(types.MDC_OID), 0.164, types.UNIT_SECOND)
+	idx = second.AddLeadAnnotation("MDC_ECG_LEAD_V2", "MEASUREMENT_MATRIX", "", "HL7V3AECG")
+	second.GetAnnotation(idx).AddNestedAnnotation("MDC_ECG_AMPL_T", "", 0.6, types.UNIT_MILLIVOLT)
+	second.AddQRSDuration(92)
+
+	return series
+}
+
+// TestReadsFromSeries tests reader labelling from annotation set authors
+func TestReadsFromSeries(t *testing.T) {
+	series := newReadSeries()
+	series.AddAnnotationSet("20250925000000").AddQTInterval(400)
+
+	reads := ReadsFromSeries(series)
+	require.Len(t, reads, 3)
+	assert.Equal(t, "READER-01", reads[0].Reader)
+	assert.Equal(t, types.AUTHOR_FUNCTION_PRIMARY_READER, reads[0].Function)
+	assert.Equal(t, "READER-02", reads[1].Reader)
+	assert.Equal(t, "set-3", reads[2].Reader)
+}
+
+// TestExtract tests measurement extraction and unit normalization
+func TestExtract(t *testing.T) {
+	reads := ReadsFromSeries(newReadSeries())
+	m := Extract(reads[1].Set)
+
+	pr := m[Key{Code: string(types.MDC_ECG_TIME_PD_PR)}]
+	assert.InDelta(t, 164, pr.Value, 1e-9)
+	assert.Equal(t, types.UNIT_MILLISECOND, pr.Unit)
+
+	tAmpl, ok := m[Key{Code: "MDC_ECG_AMPL_T", Lead: "MDC_ECG_LEAD_V2"}]
+	require.True(t, ok)
+	assert.InDelta(t, 600, tAmpl.Value, 1e-9)
+	assert.Equal(t, types.UNIT_MICROVOLT, tAmpl.Unit)
+
+	_, ok = m[Key{Code: "MEASUREMENT_MATRIX"}]
+	assert.False(t, ok, "lead matrices are not measurements")
+	assert.Empty(t, Extract(nil))
+}
+
+// TestCompare tests deltas and disagreement flags
+func TestCompare(t *testing.T) {
+	report := Compare(ReadsFromSeries(newReadSeries()), DefaultOptions())
+
+	require.Len(t, report.Readers, 2)
+	assert.Equal(t, "20250924090000", report.Readers[0].ActivityTime)
+
+	tests := []struct {
+		code         string
+		lead         string
+		spread       float64
+		disagreement bool
+	}{
+		{string(types.MDC_ECG_HEART_RATE), "", 1, false},
+		{string(types.MDC_ECG_TIME_PD_QT), "", 22, true},
+		{string(types.MDC_ECG_TIME_PD_PR), "", 4, false},
+		{string(types.MDC_ECG_TIME_PD_QRS), "", 0, true},
+		{"MDC_ECG_AMPL_T", "MDC_ECG_LEAD_V2", 150, true},
+	}
+	for _, tt := range tests {
+		t.Run(Key{Code: tt.code, Lead: tt.lead}.String(), func(t *testing.T) {
+			item, ok := report.Find(tt.code, tt.lead)
+			require.True(t, ok)
+			assert.InDelta(t, tt.spread, item.Spread, 1e-9)
+			assert.Equal(t, tt.disagreement, item.Disagreement)
+		})
+	}
+
+	qt, _ := report.Find(string(types.MDC_ECG_TIME_PD_QT), "")
+	require.Len(t, qt.Deltas, 1)
+	assert.Equal(t, Delta{A: "READER-01", B: "READER-02", Delta: 22}, qt.Deltas[0])
+
+	qrs, _ := report.Find(string(types.MDC_ECG_TIME_PD_QRS), "")
+	assert.Equal(t, []string{"READER-01"}, qrs.Missing)
+
+	assert.Equal(t, 3, report.Disagreements)
+	assert.Len(t, report.DisagreementItems(), 3)
+}
+
+// TestCompare_CorrectionMethods tests that QTc values are matched by their
+// correction method whatever the order in which readers wrote them
+func TestCompare_CorrectionMethods(t *testing.T) {
+	series := &types.Series{}
+	first := series.AddAnnotationSet("20250924090000").
+		AddPersonAuthor(types.AUTHOR_FUNCTION_PRIMARY_READER, testReaderRoot, "READER-01", "")
+	second := series.AddAnnotationSet("20250924093000").
+		AddPersonAuthor(types.AUTHOR_FUNCTION_SECONDARY_READER, testReaderRoot, "READER-02", "")
+	for _, as := range []*types.AnnotationSet{first, second} {
+		as.AddQTInterval(400)
+		as.AddRRInterval(800)
+	}
+	for _, f := range []qtc.Formula{qtc.Fridericia, qtc.Bazett} {
+		_, _, err := f.Annotate(first)
+		require.NoError(t, err)
+	}
+	for _, f := range []qtc.Formula{qtc.Bazett, qtc.Fridericia} {
+		_, _, err := f.Annotate(second)
+		require.NoError(t, err)
+	}
+
+	report := Compare(ReadsFromSeries(series), DefaultOptions())
+	code := string(types.MDC_ECG_TIME_PD_QTc)
+	for _, method := range []qtc.Method{qtc.MethodFridericia, qtc.MethodBazett} {
+		item, ok := report.FindKey(Key{Code: code, Method: string(method)})
+		require.True(t, ok, method)
+		assert.Empty(t, item.Missing, method)
+		assert.InDelta(t, 0, item.Spread, 1e-9, method)
+		assert.False(t, item.Disagreement, method)
+	}
+	assert.Equal(t, "MDC_ECG_TIME_PD_QTc/QTcF", Key{Code: code, Method: "QTcF"}.String())
+	assert.Equal(t, 0, report.Disagreements)
+}
+
+// TestCompare_Options tests custom thresholds and missing-value handling
+func TestCompare_Options(t *testing.T) {
+	opts := Options{
+		Thresholds: map[string]Threshold{string(types.MDC_ECG_TIME_PD_QT): {Absolute: 30}},
+	}
+	report := Compare(ReadsFromSeries(newReadSeries()), opts)
+
+	qt, _ := report.Find(string(types.MDC_ECG_TIME_PD_QT), "")
+	assert.False(t, qt.Disagreement)
+	qrs, _ := report.Find(string(types.MDC_ECG_TIME_PD_QRS), "")
+	assert.False(t, qrs.Disagreement, "missing values are not flagged without FlagMissing")
+	assert.Equal(t, 0, report.Disagreements)
+}
+
+// TestThreshold_Exceeded tests absolute and relative limits
+func TestThreshold_Exceeded(t *testing.T) {
+	tests := []struct {
+		name      string
+		threshold Threshold
+		spread    float64
+		mean      float64
+		want      bool
+	}{
+		{"zero threshold", Threshold{}, 100, 400, false},
+		{"within absolute", Threshold{Absolute: 10}, 10, 400, false},
+		{"beyond absolute", Threshold{Absolute: 10}, 11, 400, true},
+		{"within relative", Threshold{Relative: 0.1}, 40, 400, false},
+		{"beyond relative", Threshold{Relative: 0.1}, 41, 400, true},
+		{"both required", Threshold{Absolute: 10, Relative: 0.1}, 20, 400, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, tt.threshold.Exceeded(tt.spread, tt.mean))
+		})
+	}
+}
+
+// TestReport_Output tests the JSON and CSV adjudication reports
+func TestReport_Output(t *testing.T) {
+	report := Compare(ReadsFromSeries(newReadSeries()), DefaultOptions())
+
+	var buf bytes.Buffer
+	require.NoError(t, report.WriteJSON(&buf))
+	var decoded Report
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, report.Disagreements, decoded.Disagreements)
+	assert.Len(t, decoded.Items, len(report.Items))
+
+	buf.Reset()
+	require.NoError(t, report.WriteCSV(&buf))
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, len(report.Items)+1)
+	assert.Equal(t, []string{"code", "lead", "method", "unit", "READER-01", "READER-02",
+		"mean", "spread", "threshold_abs", "threshold_rel", "missing", "disagreement"}, rows[0])
+
+	for _, row := range rows[1:] {
+		if row[0] == string(types.MDC_ECG_TIME_PD_QT) {
+			assert.Equal(t, []string{"398", "420"}, row[4:6])
+			assert.Equal(t, "true", row[11])
+		}
+		if row[0] == string(types.MDC_ECG_TIME_PD_QRS) {
+			assert.Equal(t, "", row[4])
+			assert.Equal(t, "READER-01", row[10])
+		}
+	}
+}
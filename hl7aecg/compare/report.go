@@ -0,0 +1,67 @@
+package compare
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Report Output
+// =============================================================================
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// WriteCSV writes the report as CSV, one row per measurement.
+//
+// Columns: code, lead, method, unit, one column per reader (empty when missing),
+// mean, spread, threshold_abs, threshold_rel, missing, disagreement.
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+
+	header := []string{"code", "lead", "method", "unit"}
+	for _, reader := range r.Readers {
+		header = append(header, reader.Name)
+	}
+	header = append(header, "mean", "spread", "threshold_abs", "threshold_rel", "missing", "disagreement")
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	for _, it := range r.Items {
+		row := []string{it.Code, it.Lead, it.Method, it.Unit}
+		for _, v := range it.Values {
+			if v.Present {
+				row = append(row, formatFloat(v.Value))
+			} else {
+				row = append(row, "")
+			}
+		}
+		row = append(row,
+			formatFloat(it.Mean),
+			formatFloat(it.Spread),
+			formatFloat(it.Threshold.Absolute),
+			formatFloat(it.Threshold.Relative),
+			strings.Join(it.Missing, ";"),
+			strconv.FormatBool(it.Disagreement),
+		)
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// formatFloat formats a float64 with the shortest exact representation.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
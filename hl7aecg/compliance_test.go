@@ -0,0 +1,210 @@
+package hl7aecg
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// legacyVendorXML is a vendor file carrying non-standard demographics elements
+const legacyVendorXML = `<?xml version="1.0" encoding="UTF-8"?>
+<AnnotatedECG xmlns="urn:hl7-org:v3" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+  <id root="1.2.3.4"/>
+  <code code="93000" codeSystem="2.16.840.1.113883.6.12"/>
+  <effectiveTime><low value="20240101120000"/><high value="20240101120010"/></effectiveTime>
+  <componentOf>
+    <timepointEvent>
+      <componentOf>
+        <subjectAssignment>
+          <subject>
+            <trialSubject>
+              <id root="2.16.840.1.113883.3.5"/>
+              <subjectDemographicPerson>
+                <name>JDO</name>
+                <administrativeGenderCode code="M" codeSystem="2.16.840.1.113883.5.1"/>
+                <PatientID>25060897140</PatientID>
+                <SecondPatientID>A-77</SecondPatientID>
+                <Age>64</Age>
+                <Paced>true</Paced>
+                <Medications>
+                  <Medication>Aspirin 100mg</Medication>
+                  <Medication>Metoprolol 50mg</Medication>
+                </Medications>
+                <ClinicalClassifications>
+                  <ClinicalClassification>Hypertension</ClinicalClassification>
+                </ClinicalClassifications>
+                <Bed>12A</Bed>
+                <Room>302</Room>
+                <PointOfCare>Cardiology ICU</PointOfCare>
+              </subjectDemographicPerson>
+            </trialSubject>
+          </subject>
+          <componentOf>
+            <clinicalTrial>
+              <id root="2.16.840.1.113883.3.6" extension="TRIAL-1"/>
+            </clinicalTrial>
+          </componentOf>
+        </subjectAssignment>
+      </componentOf>
+    </timepointEvent>
+  </componentOf>
+  <component>
+    <series>
+      <id root="1.2.3.5"/>
+      <code code="RHYTHM" codeSystem="2.16.840.1.113883.5.4"/>
+      <effectiveTime><low value="20240101120000"/><high value="20240101120010"/></effectiveTime>
+    </series>
+  </component>
+</AnnotatedECG>`
+
+var vendorTags = []string{
+	"<PatientID>", "<SecondPatientID>", "<Age>", "<Paced>", "<Medications>",
+	"<ClinicalClassifications>", "<Bed>", "<Room>", "<PointOfCare>",
+}
+
+// TestHl7xml_ComplianceLegacyRead tests that vendor elements are still decoded
+func TestHl7xml_ComplianceLegacyRead(t *testing.T) {
+	h := NewHl7xml("")
+	if err := h.Unmarshal([]byte(legacyVendorXML)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	demo := h.HL7AEcg.GetTrialSubject().SubjectDemographicPerson
+	if demo.PatientID != "25060897140" || demo.SecondPatientID != "A-77" || demo.Age != "64" || !demo.Paced {
+		t.Errorf("vendor fields not decoded: %+v", demo)
+	}
+	if demo.Medications == nil || len(demo.Medications.Medication) != 2 {
+		t.Errorf("Medications = %+v, want 2 entries", demo.Medications)
+	}
+	if demo.Bed != "12A" || demo.Room != "302" || demo.PointOfCare != "Cardiology ICU" {
+		t.Errorf("location fields not decoded: %+v", demo)
+	}
+}
+
+// TestHl7xml_ComplianceStrict tests that strict output maps or drops vendor elements
+func TestHl7xml_ComplianceStrict(t *testing.T) {
+	h := NewHl7xml("")
+	if err := h.Unmarshal([]byte(legacyVendorXML)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	out, err := h.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	for _, tag := range vendorTags {
+		if strings.Contains(out, tag) {
+			t.Errorf("strict output contains vendor element %s", tag)
+		}
+	}
+
+	doc, warnings := h.HL7AEcg.Compliant(types.COMPLIANCE_STRICT)
+	if len(warnings) != 0 {
+		t.Errorf("Compliant() warnings = %v, want none", warnings)
+	}
+	ts := doc.GetTrialSubject()
+	if ts.ID.Root != "2.16.840.1.113883.3.5" || ts.ID.Extension != "25060897140" {
+		t.Errorf("trialSubject id = %+v, want PatientID as extension", ts.ID)
+	}
+	if ts.SubjectDemographicPerson.Name == nil || *ts.SubjectDemographicPerson.Name != "JDO" {
+		t.Errorf("standard demographics were not kept")
+	}
+
+	sa := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	if age := sa.GetRelatedObservationByCode(types.OBS_AGE); age == nil {
+		t.Error("Age was not mapped to a related observation")
+	} else if v, ok := age.GetValueFloat(); !ok || v != 64 || age.GetValueUnit() != types.UNIT_YEAR {
+		t.Errorf("age observation = %v %q", v, age.GetValueUnit())
+	}
+	var meds, problems int
+	for _, so := range sa.SubjectOf {
+		switch so.RelatedObservation.Code.Code {
+		case types.OBS_MEDICATION:
+			meds++
+		case types.OBS_PROBLEM:
+			problems++
+		}
+	}
+	if meds != 2 || problems != 1 {
+		t.Errorf("medications = %d, problems = %d, want 2 and 1", meds, problems)
+	}
+
+	cvs := doc.Component[0].Series.ControlVariable
+	if len(cvs) != 1 || cvs[0].ControlVariable.Code.Code != types.PacedControlVariableCode ||
+		cvs[0].ControlVariable.Code.CodeSystem != types.SNOMED_CT_OID {
+		t.Errorf("series control variables = %+v, want pacemaker in situ", cvs)
+	}
+
+	// The document held by the encoder is left untouched
+	orig := h.HL7AEcg.GetTrialSubject()
+	if orig.ID.Extension != "" || orig.SubjectDemographicPerson.PatientID == "" {
+		t.Error("Compliant() modified the original document")
+	}
+	if len(h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.SubjectOf) != 0 {
+		t.Error("Compliant() added observations to the original document")
+	}
+	if len(h.HL7AEcg.Component[0].Series.ControlVariable) != 0 {
+		t.Error("Compliant() added control variables to the original document")
+	}
+}
+
+// TestHl7xml_ComplianceWarnings tests that Validate warns about a dropped
+// non-numeric Age under the strict profile only
+func TestHl7xml_ComplianceWarnings(t *testing.T) {
+	data := []byte(strings.Replace(legacyVendorXML, "<Age>64</Age>", "<Age>64 Y</Age>", 1))
+	want := `compliance: Age "64 Y" is not numeric and is dropped`
+
+	for _, profile := range []types.ComplianceProfile{types.COMPLIANCE_STRICT, types.COMPLIANCE_LEGACY} {
+		h := NewHl7xml("").SetComplianceProfile(profile)
+		if err := h.Unmarshal(data); err != nil {
+			t.Fatalf("Unmarshal() error = %v", err)
+		}
+		_ = h.Validate()
+		warned := false
+		for _, w := range h.ValidationReport().Warnings {
+			warned = warned || w == want
+		}
+		if wantWarned := profile == types.COMPLIANCE_STRICT; warned != wantWarned {
+			t.Errorf("%s: warned %q = %v, want %v", profile, want, warned, wantWarned)
+		}
+	}
+}
+
+// TestHl7xml_ComplianceLegacy tests that the legacy profile keeps vendor elements
+func TestHl7xml_ComplianceLegacy(t *testing.T) {
+	h := NewHl7xml("").SetComplianceProfile(types.COMPLIANCE_LEGACY)
+	if err := h.Unmarshal([]byte(legacyVendorXML)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	out, err := h.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	for _, tag := range vendorTags {
+		if !strings.Contains(out, tag) {
+			t.Errorf("legacy output is missing vendor element %s", tag)
+		}
+	}
+}
+
+// TestHl7xml_ComplianceEmptyVendorFields tests that unset vendor fields are never written
+func TestHl7xml_ComplianceEmptyVendorFields(t *testing.T) {
+	h := NewHl7xml("").SetComplianceProfile(types.COMPLIANCE_LEGACY)
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "")
+	h.SetSubject("2.16.840.1.113883.3.5", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+		SetSubjectDemographics("JDO", "", types.GENDER_MALE, "19530508", types.RACE_WHITE)
+	// Validation must not re-introduce placeholder entries
+	_ = h.Validate()
+
+	out, err := h.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	for _, tag := range vendorTags {
+		if strings.Contains(out, tag) {
+			t.Errorf("output contains empty vendor element %s", tag)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package deid
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"io"
+)
+
+// =============================================================================
+// Audit Log
+// =============================================================================
+
+// Change records one de-identified field.
+type Change struct {
+	// Field is the path of the field in the document
+	// (e.g. "component[0].series.effectiveTime.low").
+	Field string `json:"field"`
+
+	// Action is what was done with the field.
+	Action Action `json:"action"`
+
+	// Value is the new value; empty when the field was removed.
+	Value string `json:"value,omitempty"`
+}
+
+// AuditLog lists the changes made by Deidentify.
+type AuditLog struct {
+	// Profile is the name of the profile applied.
+	Profile string `json:"profile"`
+
+	// ShiftDays is the number of days absolute times were moved by.
+	ShiftDays int `json:"shiftDays"`
+
+	// Changes lists every changed field, in document order.
+	Changes []Change `json:"changes"`
+}
+
+// Fields returns the changes of a field path, in order.
+func (l *AuditLog) Fields(field string) []Change {
+	var out []Change
+	for _, c := range l.Changes {
+		if c.Field == field {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+// WriteJSON writes the audit log as indented JSON.
+func (l *AuditLog) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(l)
+}
+
+// WriteCSV writes the audit log as CSV, one row per change.
+//
+// Columns: field, action, value.
+func (l *AuditLog) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write([]string{"field", "action", "value"}); err != nil {
+		return err
+	}
+	for _, c := range l.Changes {
+		if err := cw.Write([]string{c.Field, string(c.Action), c.Value}); err != nil {
+			return err
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
@@ -0,0 +1,606 @@
+// Package deid removes or pseudonymizes the identifiers of an aECG document
+// before it is shared outside the trial (e.g. with academic collaborators).
+//
+// A Profile tells what to do with each class of identifier:
+//   - names: subject initials, investigator, performers and annotation or
+//     observation authors
+//   - identifiers: the vendor PatientID, SecondPatientID, Bed, Room and
+//     PointOfCare extensions
+//   - the subject birth time
+//   - the site address
+//   - free text: ST annotation values and the document text
+//   - extensions: the attributes and elements the types do not model (see
+//     types.Extensions), whose content is unknown
+//   - absolute times: every effectiveTime, activityTime, author time and
+//     GLIST_TS head, shifted by the same number of days so that intervals and
+//     times of day are preserved
+//
+// Trial subject IDs are sponsor-assigned codes and are kept.
+//
+// Deidentify returns an AuditLog listing every changed field. The log never
+// holds the original values, but it does hold the date shift and must stay
+// with the data custodian.
+//
+// Example:
+//
+//	log, err := deid.Deidentify(&h.HL7AEcg, deid.Pseudonymization(key))
+//	if err != nil {
+//	    return err
+//	}
+//	log.WriteJSON(auditFile)
+package deid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Profiles
+// =============================================================================
+
+// Action is what is done with a class of fields.
+type Action string
+
+const (
+	// Keep leaves the field unchanged.
+	Keep Action = "keep"
+
+	// Remove deletes the field.
+	Remove Action = "remove"
+
+	// Pseudonymize replaces the field with a keyed HMAC pseudonym. The same
+	// value always gets the same pseudonym under the same key, so documents
+	// of a subject can still be linked.
+	Pseudonymize Action = "pseudonymize"
+
+	// Truncate keeps the year of a date only (birth time).
+	Truncate Action = "truncate"
+
+	// Shift moves a date by the document date shift (birth time).
+	Shift Action = "shift"
+)
+
+// DateShift selects how absolute times are moved.
+type DateShift string
+
+const (
+	// DateShiftNone keeps times unchanged.
+	DateShiftNone DateShift = "none"
+
+	// DateShiftYearStart moves the document to January 1st of the year it
+	// was recorded, keeping the year and the time of day.
+	DateShiftYearStart DateShift = "year-start"
+
+	// DateShiftSubject moves every document of a subject by the same number
+	// of days, derived from the key and the trial subject ID, within
+	// ±MaxShiftDays (never 0).
+	DateShiftSubject DateShift = "subject"
+)
+
+// DefaultMaxShiftDays is the shift range used by Pseudonymization.
+const DefaultMaxShiftDays = 365
+
+var (
+	// ErrMissingKey is returned when a profile needs a key and has none.
+	ErrMissingKey = errors.New("deid: profile requires a key")
+
+	// ErrInvalidProfile is returned when a profile uses an action on a
+	// field class that does not support it.
+	ErrInvalidProfile = errors.New("deid: invalid profile")
+
+	// ErrNoSubjectID is returned when the per-subject date shift is
+	// requested for a document without a trial subject ID.
+	ErrNoSubjectID = errors.New("deid: no trial subject ID to derive the date shift from")
+)
+
+// Profile configures the de-identification.
+type Profile struct {
+	// Name labels the profile in the audit log.
+	Name string
+
+	// Names applies to person names: Keep, Remove or Pseudonymize.
+	Names Action
+
+	// Identifiers applies to PatientID and SecondPatientID: Keep, Remove or
+	// Pseudonymize. Bed, Room and PointOfCare are removed unless Keep.
+	Identifiers Action
+
+	// BirthTime: Keep, Remove, Truncate or Shift.
+	//
+	// Truncate keeps the birth year only. As required by HIPAA Safe Harbor,
+	// it removes the birth time entirely for subjects aged 90 or over, whose
+	// ages are then reported as 90.
+	BirthTime Action
+
+	// Address applies to the site city and state: Keep or Remove.
+	// The country is kept.
+	Address Action
+
+	// FreeText applies to ST annotation and related observation values and
+	// the document text: Keep or Remove.
+	FreeText Action
+
+	// Extensions applies to the unmodeled attributes and elements of the
+	// document (vendor extensions, ...), which may hold identifiers:
+	// Keep or Remove.
+	Extensions Action
+
+	// Dates selects how absolute times are shifted.
+	Dates DateShift
+
+	// MaxShiftDays bounds the per-subject date shift.
+	MaxShiftDays int
+
+	// Key is the secret used for pseudonyms and the per-subject date shift.
+	Key []byte
+}
+
+// SafeHarbor returns a profile following the HIPAA Safe Harbor method:
+// names, identifiers, site city and state, free text and extensions are
+// removed, the birth time is truncated to the year and every time is moved
+// to January 1st of its year.
+func SafeHarbor() Profile {
+	return Profile{
+		Name:        "safe-harbor",
+		Names:       Remove,
+		Identifiers: Remove,
+		BirthTime:   Truncate,
+		Address:     Remove,
+		FreeText:    Remove,
+		Extensions:  Remove,
+		Dates:       DateShiftYearStart,
+	}
+}
+
+// Pseudonymization returns a profile replacing names and identifiers with
+// keyed HMAC pseudonyms and shifting all dates, including the birth time,
+// per subject. The site city and state, free text and extensions are removed.
+func Pseudonymization(key []byte) Profile {
+	return Profile{
+		Name:         "pseudonymization",
+		Names:        Pseudonymize,
+		Identifiers:  Pseudonymize,
+		BirthTime:    Shift,
+		Address:      Remove,
+		FreeText:     Remove,
+		Extensions:   Remove,
+		Dates:        DateShiftSubject,
+		MaxShiftDays: DefaultMaxShiftDays,
+		Key:          key,
+	}
+}
+
+// WithSubjectDateShift returns the profile with per-subject date shifting.
+func (p Profile) WithSubjectDateShift(key []byte, maxDays int) Profile {
+	p.Dates = DateShiftSubject
+	p.Key = key
+	p.MaxShiftDays = maxDays
+	return p
+}
+
+// Validate checks that each action is supported by its field class and that
+// a key is set when needed.
+func (p Profile) Validate() error {
+	checks := []struct {
+		field   string
+		action  Action
+		allowed []Action
+	}{
+		{"names", p.Names, []Action{Keep, Remove, Pseudonymize}},
+		{"identifiers", p.Identifiers, []Action{Keep, Remove, Pseudonymize}},
+		{"birthTime", p.BirthTime, []Action{Keep, Remove, Truncate, Shift}},
+		{"address", p.Address, []Action{Keep, Remove}},
+		{"freeText", p.FreeText, []Action{Keep, Remove}},
+		{"extensions", p.Extensions, []Action{Keep, Remove}},
+	}
+	for _, c := range checks {
+		if !isAllowed(c.action, c.allowed) {
+			return fmt.Errorf("%w: %s cannot be %q", ErrInvalidProfile, c.field, c.action)
+		}
+	}
+
+	switch p.Dates {
+	case "", DateShiftNone, DateShiftYearStart:
+	case DateShiftSubject:
+		if p.MaxShiftDays <= 0 {
+			return fmt.Errorf("%w: max shift days must be positive", ErrInvalidProfile)
+		}
+	default:
+		return fmt.Errorf("%w: unknown date shift %q", ErrInvalidProfile, p.Dates)
+	}
+
+	needsKey := p.Names == Pseudonymize || p.Identifiers == Pseudonymize || p.Dates == DateShiftSubject
+	if needsKey && len(p.Key) == 0 {
+		return ErrMissingKey
+	}
+	return nil
+}
+
+// isAllowed reports whether the action is in the list. An empty action means Keep.
+func isAllowed(a Action, allowed []Action) bool {
+	if a == "" {
+		return true
+	}
+	for _, b := range allowed {
+		if a == b {
+			return true
+		}
+	}
+	return false
+}
+
+// =============================================================================
+// De-identification
+// =============================================================================
+
+// deidentifier holds the state of one Deidentify call.
+type deidentifier struct {
+	p    Profile
+	days int
+	log  *AuditLog
+}
+
+// Deidentify applies the profile to the document, in place, and returns the
+// audit log of the changed fields.
+//
+// The document is left untouched if the profile is invalid or the date shift
+// cannot be computed.
+func Deidentify(doc *types.HL7AEcg, p Profile) (*AuditLog, error) {
+	if err := p.Validate(); err != nil {
+		return nil, err
+	}
+
+	d := &deidentifier{p: p, log: &AuditLog{Profile: p.Name}}
+	days, err := d.shiftDays(doc)
+	if err != nil {
+		return nil, err
+	}
+	d.days = days
+	d.log.ShiftDays = days
+
+	// Ages are checked against the recording year before any shift
+	refYear := 0
+	if t, ok := referenceTime(doc); ok {
+		refYear, _ = strconv.Atoi(t[:4])
+	}
+
+	if doc.Text != "" && p.FreeText == Remove {
+		doc.Text = ""
+		d.record("text", Remove, "")
+	}
+	if p.Extensions == Remove && doc.StripExtensions() > 0 {
+		d.record("extensions", Remove, "")
+	}
+	d.times("effectiveTime", doc.EffectiveTime)
+
+	if ts := doc.GetTrialSubject(); ts != nil {
+		path := "subject.trialSubject"
+		if doc.ComponentOf != nil {
+			path = "timepointEvent.subjectAssignment.subject.trialSubject"
+		}
+		d.demographics(path+".subjectDemographicPerson", ts.SubjectDemographicPerson, refYear)
+	}
+
+	if doc.ComponentOf != nil {
+		te := &doc.ComponentOf.TimepointEvent
+		d.times("timepointEvent.effectiveTime", te.EffectiveTime)
+		if te.Performer != nil {
+			d.assignedPerson("timepointEvent.performer", te.Performer.StudyEventPerformer.AssignedPerson)
+		}
+
+		sa := &te.ComponentOf.SubjectAssignment
+		for i := range sa.SubjectOf {
+			d.relatedObservation(fmt.Sprintf("subjectAssignment.subjectOf[%d]", i), &sa.SubjectOf[i].RelatedObservation, refYear)
+		}
+		d.clinicalTrial("clinicalTrial", &sa.ComponentOf.ClinicalTrial)
+	}
+
+	for i := range doc.Component {
+		d.series(fmt.Sprintf("component[%d].series", i), &doc.Component[i].Series)
+	}
+
+	return d.log, nil
+}
+
+// shiftDays computes the number of days absolute times are moved by.
+func (d *deidentifier) shiftDays(doc *types.HL7AEcg) (int, error) {
+	switch d.p.Dates {
+	case DateShiftYearStart:
+		ref, ok := referenceTime(doc)
+		if !ok {
+			return 0, nil
+		}
+		t, _ := parseDate(ref)
+		return -(t.YearDay() - 1), nil
+
+	case DateShiftSubject:
+		ts := doc.GetTrialSubject()
+		if ts == nil || ts.ID == nil || (ts.ID.Root == "" && ts.ID.Extension == "") {
+			return 0, ErrNoSubjectID
+		}
+		sum := d.mac("date-shift", ts.ID.Root+"/"+ts.ID.Extension)
+		n := binary.BigEndian.Uint64(sum[:8])
+		span := uint64(2 * d.p.MaxShiftDays)
+		r := int(n % span)
+		if r < d.p.MaxShiftDays {
+			return -(r + 1), nil
+		}
+		return r - d.p.MaxShiftDays + 1, nil
+	}
+	return 0, nil
+}
+
+// demographics de-identifies the subject name, birth time and vendor extensions.
+func (d *deidentifier) demographics(path string, demo *types.SubjectDemographicPerson, refYear int) {
+	if demo == nil {
+		return
+	}
+	demo.Name = d.name(path+".name", "subject", demo.Name)
+
+	if demo.BirthTime != nil && demo.BirthTime.Value != "" {
+		bt := path + ".birthTime"
+		switch d.p.BirthTime {
+		case Remove:
+			demo.BirthTime = nil
+			d.record(bt, Remove, "")
+		case Truncate:
+			year, err := strconv.Atoi(demo.BirthTime.Value[:min(4, len(demo.BirthTime.Value))])
+			if err == nil && refYear > 0 && refYear-year >= 90 {
+				demo.BirthTime = nil
+				d.record(bt, Remove, "")
+			} else if len(demo.BirthTime.Value) > 4 {
+				demo.BirthTime = &types.Time{Value: demo.BirthTime.Value[:4]}
+				d.record(bt, Truncate, demo.BirthTime.Value)
+			}
+		case Shift:
+			d.time(bt, demo.BirthTime)
+		}
+	}
+
+	if d.p.BirthTime == Truncate {
+		if age, err := strconv.ParseFloat(demo.Age, 64); err == nil && age >= 90 && demo.Age != "90" {
+			demo.Age = "90"
+			d.record(path+".Age", Truncate, demo.Age)
+		}
+	}
+
+	demo.PatientID = d.identifier(path+".PatientID", "patientID", demo.PatientID)
+	demo.SecondPatientID = d.identifier(path+".SecondPatientID", "patientID", demo.SecondPatientID)
+	if d.p.Identifiers != "" && d.p.Identifiers != Keep {
+		for _, f := range []struct {
+			name  string
+			value *string
+		}{{"Bed", &demo.Bed}, {"Room", &demo.Room}, {"PointOfCare", &demo.PointOfCare}} {
+			if *f.value != "" {
+				*f.value = ""
+				d.record(path+"."+f.name, Remove, "")
+			}
+		}
+	}
+}
+
+// relatedObservation shifts the author time, de-identifies the author name,
+// removes free-text values and caps age observations under Truncate.
+func (d *deidentifier) relatedObservation(path string, ro *types.RelatedObservation, refYear int) {
+	if d.p.FreeText == Remove && ro.Value != nil && ro.Value.IsST() {
+		ro.Value = nil
+		d.record(path+".value", Remove, "")
+	}
+	if d.p.BirthTime == Truncate && ro.Code != nil && ro.Code.Code == types.OBS_AGE {
+		if age, ok := ro.GetValueFloat(); ok && age >= 90 && ro.GetValueUnit() == types.UNIT_YEAR && age != 90 {
+			ro.Value.Typed.(*types.PhysicalQuantity).Value = "90"
+			d.record(path+".value", Truncate, "90")
+		}
+	}
+	if ro.Author != nil {
+		d.time(path+".author.time", ro.Author.Time)
+		d.assignedPerson(path+".author.assignedEntity", ro.Author.AssignedEntity.AssignedPerson)
+	}
+}
+
+// clinicalTrial shifts the trial activity time and de-identifies the site.
+func (d *deidentifier) clinicalTrial(path string, ct *types.ClinicalTrial) {
+	d.times(path+".activityTime", ct.ActivityTime)
+	if ct.Location == nil {
+		return
+	}
+	site := &ct.Location.TrialSite
+	sitePath := path + ".location.trialSite"
+
+	if d.p.Address == Remove && site.Location != nil && site.Location.Addr != nil {
+		addr := site.Location.Addr
+		if addr.City != nil {
+			addr.City = nil
+			d.record(sitePath+".location.addr.city", Remove, "")
+		}
+		if addr.State != nil {
+			addr.State = nil
+			d.record(sitePath+".location.addr.state", Remove, "")
+		}
+	}
+
+	if rp := site.ResponsibleParty; rp != nil && rp.TrialInvestigator.InvestigatorPerson != nil {
+		person := rp.TrialInvestigator.InvestigatorPerson
+		namePath := sitePath + ".responsibleParty.trialInvestigator.investigatorPerson.name"
+		if person.Name == nil || !hasPersonName(person.Name) {
+			return
+		}
+		switch d.p.Names {
+		case Remove:
+			person.Name = nil
+			d.record(namePath, Remove, "")
+		case Pseudonymize:
+			pseudo := d.pseudonym("person", formatPersonName(person.Name))
+			person.Name = &types.PersonName{Family: &pseudo}
+			d.record(namePath, Pseudonymize, pseudo)
+		}
+	}
+}
+
+// series de-identifies a series and its derived series.
+func (d *deidentifier) series(path string, s *types.Series) {
+	d.times(path+".effectiveTime", &s.EffectiveTime)
+
+	for i := range s.SecondaryPerformer {
+		sp := &s.SecondaryPerformer[i]
+		spPath := fmt.Sprintf("%s.secondaryPerformer[%d]", path, i)
+		d.times(spPath+".time", sp.Time)
+		if ap := sp.SeriesPerformer.AssignedPerson; ap != nil {
+			ap.Name = d.name(spPath+".seriesPerformer.assignedPerson.name", "person", ap.Name)
+		}
+	}
+
+	for i := range s.Component {
+		for j := range s.Component[i].SequenceSet.Component {
+			seq := &s.Component[i].SequenceSet.Component[j].Sequence
+			if seq.Value == nil {
+				continue
+			}
+			if gl, ok := seq.Value.Typed.(*types.GLIST_TS); ok {
+				headPath := fmt.Sprintf("%s.component[%d].sequenceSet.component[%d].value.head", path, i, j)
+				if shifted, ok := shiftTimestamp(gl.Head.Value, d.days); ok {
+					gl.Head.Value = shifted
+					d.record(headPath, Shift, shifted)
+				}
+			}
+		}
+	}
+
+	for i := range s.SubjectOf {
+		as := s.SubjectOf[i].AnnotationSet
+		if as == nil {
+			continue
+		}
+		asPath := fmt.Sprintf("%s.subjectOf[%d].annotationSet", path, i)
+		d.time(asPath+".activityTime", as.ActivityTime)
+		for j := range as.Author {
+			d.assignedPerson(fmt.Sprintf("%s.author[%d].assignedEntity", asPath, j), as.Author[j].AssignedEntity.AssignedPerson)
+		}
+		d.annotations(asPath, as.Component)
+	}
+
+	for i := range s.Derivation {
+		d.series(fmt.Sprintf("%s.derivation[%d].derivedSeries", path, i), &s.Derivation[i].DerivedSeries)
+	}
+}
+
+// annotations removes free-text values, recursing into nested annotations.
+func (d *deidentifier) annotations(path string, components []types.AnnotationComponent) {
+	for i := range components {
+		ann := &components[i].Annotation
+		annPath := fmt.Sprintf("%s.component[%d].annotation", path, i)
+		if d.p.FreeText == Remove && ann.Value != nil && ann.Value.IsST() {
+			ann.Value = nil
+			d.record(annPath+".value", Remove, "")
+		}
+		d.annotations(annPath, ann.Component)
+	}
+}
+
+// assignedPerson de-identifies the name of an assigned person.
+func (d *deidentifier) assignedPerson(path string, ap *types.AssignedPerson) {
+	if ap == nil {
+		return
+	}
+	ap.Name = d.name(path+".assignedPerson.name", "person", ap.Name)
+}
+
+// name applies the Names action to a simple name.
+func (d *deidentifier) name(path, kind string, name *string) *string {
+	if name == nil || *name == "" {
+		return name
+	}
+	switch d.p.Names {
+	case Remove:
+		d.record(path, Remove, "")
+		return nil
+	case Pseudonymize:
+		pseudo := d.pseudonym(kind, *name)
+		d.record(path, Pseudonymize, pseudo)
+		return &pseudo
+	}
+	return name
+}
+
+// identifier applies the Identifiers action to a vendor identifier.
+func (d *deidentifier) identifier(path, kind, value string) string {
+	if value == "" {
+		return value
+	}
+	switch d.p.Identifiers {
+	case Remove:
+		d.record(path, Remove, "")
+		return ""
+	case Pseudonymize:
+		pseudo := d.pseudonym(kind, value)
+		d.record(path, Pseudonymize, pseudo)
+		return pseudo
+	}
+	return value
+}
+
+// pseudonym returns the keyed HMAC pseudonym of a value. The kind keeps
+// pseudonyms of different field classes apart.
+func (d *deidentifier) pseudonym(kind, value string) string {
+	sum := d.mac(kind, value)
+	return strings.ToUpper(hex.EncodeToString(sum[:6]))
+}
+
+// mac returns the HMAC-SHA256 of kind and value under the profile key.
+func (d *deidentifier) mac(kind, value string) []byte {
+	m := hmac.New(sha256.New, d.p.Key)
+	m.Write([]byte(kind))
+	m.Write([]byte{0})
+	m.Write([]byte(value))
+	return m.Sum(nil)
+}
+
+// times shifts both bounds of an interval.
+func (d *deidentifier) times(path string, et *types.EffectiveTime) {
+	if et == nil {
+		return
+	}
+	d.time(path+".low", &et.Low)
+	d.time(path+".high", &et.High)
+}
+
+// time shifts a timestamp by the document date shift.
+func (d *deidentifier) time(path string, t *types.Time) {
+	if t == nil {
+		return
+	}
+	if shifted, ok := shiftTimestamp(t.Value, d.days); ok {
+		t.Value = shifted
+		d.record(path, Shift, shifted)
+	}
+}
+
+// record appends a change to the audit log.
+func (d *deidentifier) record(field string, action Action, value string) {
+	d.log.Changes = append(d.log.Changes, Change{Field: field, Action: action, Value: value})
+}
+
+// hasPersonName reports whether any name part is set.
+func hasPersonName(n *types.PersonName) bool {
+	return formatPersonName(n) != ""
+}
+
+// formatPersonName joins the name parts.
+func formatPersonName(n *types.PersonName) string {
+	var parts []string
+	for _, p := range []*string{n.Prefix, n.Given, n.Family, n.Suffix} {
+		if p != nil && *p != "" {
+			parts = append(parts, *p)
+		}
+	}
+	return strings.Join(parts, " ")
+}
@@ -0,0 +1,228 @@
+package deid
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+var testKey = []byte("collaboration-2025")
+
+// newIdentifiedDoc builds a document carrying every identifier class.
+func newIdentifiedDoc(t *testing.T, subjectID string) *types.HL7AEcg {
+	h := testdoc.New(t, testdoc.Doc{Subject: subjectID})
+	h.SetText("Recorded by Dr. Smith in room 302").
+		SetSubjectDemographics("JDO", "25060897140", types.GENDER_MALE, "19530508", types.RACE_WHITE).
+		SetLocation("SITE_001", "2.16.840.1.113883.3.5", "1st Clinic", "Milwaukee", "WI", "USA").
+		SetResponsibleParty("2.16.840.1.113883.3.5", "INV_001", "Dr.", "John", "Smith", "MD").
+		SetTimepointEventTime("20240315100000", "20240315110000").
+		SetTimepointPerformer("2.16.840.1.113883.3.5", "TECH_1", "Julie Tech").
+		AddSecondaryPerformer(types.PERFORMER_ECG_TECHNICIAN, "", "TECH_1", "KAB")
+
+	demo := h.HL7AEcg.GetTrialSubject().SubjectDemographicPerson
+	demo.SecondPatientID = "A-77"
+	demo.Bed = "12A"
+	demo.ExtraElements = []types.ExtraElement{{XMLName: xml.Name{Local: "acme:mrn"}, InnerXML: []byte("25060897140")}}
+
+	series := &h.HL7AEcg.Component[0].Series
+	as := series.AddAnnotationSet("20240315120000").
+		AddPersonAuthor(types.AUTHOR_FUNCTION_PRIMARY_READER, "2.16.840.1.113883.3.5", "READER-01", "Dr. Reader")
+	as.AddQTInterval(398)
+	as.AddTextAnnotation("COMMENT", string(types.MDC_OID), "Patient JDO agitated")
+
+	sa := &h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	sa.AddRelatedObservation(types.OBS_WEIGHT, "", 72, types.UNIT_KILOGRAM)
+	sa.AddTextObservation(types.OBS_MEDICATION, "", "Sotalol 80 mg prescribed to JDO")
+	return &h.HL7AEcg
+}
+
+// TestDeidentify_SafeHarbor tests that identifiers are removed and dates moved to January 1st
+func TestDeidentify_SafeHarbor(t *testing.T) {
+	doc := newIdentifiedDoc(t, "SUBJ_1")
+	log, err := Deidentify(doc, SafeHarbor())
+	require.NoError(t, err)
+
+	demo := doc.GetTrialSubject().SubjectDemographicPerson
+	assert.Nil(t, demo.Name)
+	assert.Equal(t, "1953", demo.BirthTime.Value)
+	assert.Empty(t, demo.PatientID)
+	assert.Empty(t, demo.SecondPatientID)
+	assert.Empty(t, demo.Bed)
+	assert.Empty(t, demo.ExtraElements, "extensions may hold identifiers")
+	assert.Empty(t, doc.Text)
+
+	ct := doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial
+	addr := ct.Location.TrialSite.Location.Addr
+	assert.Nil(t, addr.City)
+	assert.Nil(t, addr.State)
+	require.NotNil(t, addr.Country)
+	assert.Equal(t, "USA", *addr.Country)
+	assert.Nil(t, ct.Location.TrialSite.ResponsibleParty.TrialInvestigator.InvestigatorPerson.Name)
+	assert.Nil(t, doc.ComponentOf.TimepointEvent.Performer.StudyEventPerformer.AssignedPerson.Name)
+
+	// 2024-03-15 is day 75 of 2024: everything moves back 74 days
+	assert.Equal(t, -74, log.ShiftDays)
+	assert.Equal(t, "20240101101500", doc.EffectiveTime.Low.Value)
+	series := doc.Component[0].Series
+	assert.Equal(t, "20240101101500.000", series.EffectiveTime.Low.Value)
+	head := series.Component[0].SequenceSet.Component[0].Sequence.Value.Typed.(*types.GLIST_TS).Head.Value
+	assert.Equal(t, "20240101101500.000", head)
+
+	as := series.SubjectOf[0].AnnotationSet
+	assert.Equal(t, "20240101120000", as.ActivityTime.Value)
+	assert.Nil(t, as.Author[0].AssignedEntity.AssignedPerson.Name)
+	assert.NotNil(t, as.GetAnnotationByCode(string(types.MDC_ECG_TIME_PD_QT)).Value, "measurements are kept")
+	assert.Nil(t, as.GetAnnotationByCode("COMMENT").Value)
+
+	sa := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	assert.Nil(t, sa.GetRelatedObservationByCode(types.OBS_MEDICATION).Value)
+	weight, ok := sa.GetRelatedObservationByCode(types.OBS_WEIGHT).GetValueFloat()
+	assert.True(t, ok, "numeric observations are kept")
+	assert.Equal(t, 72.0, weight)
+
+	assert.Len(t, log.Fields("text"), 1)
+	assert.Len(t, log.Fields("subjectAssignment.subjectOf[1].value"), 1)
+	assert.Len(t, log.Fields("extensions"), 1)
+	assert.Len(t, log.Fields("component[0].series.secondaryPerformer[0].seriesPerformer.assignedPerson.name"), 1)
+	for _, c := range log.Changes {
+		assert.NotContains(t, c.Value, "JDO", "audit log leaks an original value")
+	}
+}
+
+// TestDeidentify_SafeHarborOver89 tests that birth years of subjects aged 90 or over are removed
+func TestDeidentify_SafeHarborOver89(t *testing.T) {
+	doc := newIdentifiedDoc(t, "SUBJ_1")
+	demo := doc.GetTrialSubject().SubjectDemographicPerson
+	demo.BirthTime.Value = "19300101"
+	demo.Age = "94"
+	sa := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	sa.AddRelatedObservation(types.OBS_AGE, "", 94, types.UNIT_YEAR)
+
+	_, err := Deidentify(doc, SafeHarbor())
+	require.NoError(t, err)
+
+	assert.Nil(t, demo.BirthTime)
+	assert.Equal(t, "90", demo.Age)
+	age, _ := sa.GetRelatedObservationByCode(types.OBS_AGE).GetValueFloat()
+	assert.Equal(t, 90.0, age)
+}
+
+// TestDeidentify_Pseudonymization tests keyed pseudonyms and per-subject date shifting
+func TestDeidentify_Pseudonymization(t *testing.T) {
+	first := newIdentifiedDoc(t, "SUBJ_1")
+	log, err := Deidentify(first, Pseudonymization(testKey))
+	require.NoError(t, err)
+
+	demo := first.GetTrialSubject().SubjectDemographicPerson
+	require.NotNil(t, demo.Name)
+	assert.NotEqual(t, "JDO", *demo.Name)
+	assert.Len(t, *demo.Name, 12)
+	assert.NotEqual(t, "25060897140", demo.PatientID)
+	inv := first.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial.
+		Location.TrialSite.ResponsibleParty.TrialInvestigator.InvestigatorPerson.Name
+	assert.Nil(t, inv.Given)
+	assert.NotEqual(t, "Smith", *inv.Family)
+
+	assert.NotZero(t, log.ShiftDays)
+	assert.LessOrEqual(t, log.ShiftDays, DefaultMaxShiftDays)
+	assert.GreaterOrEqual(t, log.ShiftDays, -DefaultMaxShiftDays)
+	shifted, _ := shiftTimestamp("19530508", log.ShiftDays)
+	assert.Equal(t, shifted, demo.BirthTime.Value)
+	assert.Equal(t, "101500", first.EffectiveTime.Low.Value[8:], "time of day is kept")
+
+	// Same subject, same key: same pseudonyms and shift
+	second := newIdentifiedDoc(t, "SUBJ_1")
+	log2, err := Deidentify(second, Pseudonymization(testKey))
+	require.NoError(t, err)
+	assert.Equal(t, log.ShiftDays, log2.ShiftDays)
+	assert.Equal(t, *demo.Name, *second.GetTrialSubject().SubjectDemographicPerson.Name)
+
+	// Another key gives other pseudonyms
+	third := newIdentifiedDoc(t, "SUBJ_1")
+	_, err = Deidentify(third, Pseudonymization([]byte("other")))
+	require.NoError(t, err)
+	assert.NotEqual(t, *demo.Name, *third.GetTrialSubject().SubjectDemographicPerson.Name)
+}
+
+// TestDeidentify_SubjectDateShiftOnSafeHarbor tests combining Safe Harbor with a per-subject shift
+func TestDeidentify_SubjectDateShiftOnSafeHarbor(t *testing.T) {
+	doc := newIdentifiedDoc(t, "SUBJ_1")
+	log, err := Deidentify(doc, SafeHarbor().WithSubjectDateShift(testKey, 30))
+	require.NoError(t, err)
+	assert.NotZero(t, log.ShiftDays)
+	assert.LessOrEqual(t, log.ShiftDays, 30)
+	assert.Equal(t, "1953", doc.GetTrialSubject().SubjectDemographicPerson.BirthTime.Value)
+}
+
+// TestDeidentify_InvalidProfile tests profile validation
+func TestDeidentify_InvalidProfile(t *testing.T) {
+	tests := []struct {
+		name    string
+		profile Profile
+		wantErr error
+	}{
+		{"pseudonyms without key", Pseudonymization(nil), ErrMissingKey},
+		{"truncated names", Profile{Names: Truncate}, ErrInvalidProfile},
+		{"pseudonymized address", Profile{Address: Pseudonymize, Key: testKey}, ErrInvalidProfile},
+		{"truncated extensions", Profile{Extensions: Truncate}, ErrInvalidProfile},
+		{"no shift range", Profile{Dates: DateShiftSubject, Key: testKey}, ErrInvalidProfile},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newIdentifiedDoc(t, "SUBJ_1")
+			_, err := Deidentify(doc, tt.profile)
+			assert.ErrorIs(t, err, tt.wantErr)
+			assert.Equal(t, "JDO", *doc.GetTrialSubject().SubjectDemographicPerson.Name, "document modified")
+		})
+	}
+
+	doc := newIdentifiedDoc(t, "")
+	doc.GetTrialSubject().ID = nil
+	_, err := Deidentify(doc, Pseudonymization(testKey))
+	assert.ErrorIs(t, err, ErrNoSubjectID)
+}
+
+// TestShiftTimestamp tests that only the date part is shifted
+func TestShiftTimestamp(t *testing.T) {
+	tests := []struct {
+		value string
+		days  int
+		want  string
+		ok    bool
+	}{
+		{"20240301", -1, "20240229", true},
+		{"20241231235959.123+0100", 1, "20250101235959.123+0100", true},
+		{"2024", 10, "2024", false},
+		{"20240301", 0, "20240301", false},
+		{"garbage!", 3, "garbage!", false},
+	}
+	for _, tt := range tests {
+		got, ok := shiftTimestamp(tt.value, tt.days)
+		assert.Equal(t, tt.want, got, tt.value)
+		assert.Equal(t, tt.ok, ok, tt.value)
+	}
+}
+
+// TestAuditLog_Write tests the JSON and CSV outputs
+func TestAuditLog_Write(t *testing.T) {
+	log, err := Deidentify(newIdentifiedDoc(t, "SUBJ_1"), SafeHarbor())
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, log.WriteJSON(&buf))
+	var decoded AuditLog
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "safe-harbor", decoded.Profile)
+	assert.Len(t, decoded.Changes, len(log.Changes))
+
+	buf.Reset()
+	require.NoError(t, log.WriteCSV(&buf))
+	assert.Equal(t, len(log.Changes)+1, bytes.Count(buf.Bytes(), []byte("\n")))
+}
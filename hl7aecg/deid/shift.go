@@ -0,0 +1,59 @@
+package deid
+
+import (
+	"time"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Date Shifting
+// =============================================================================
+
+// hl7DateLayout is the date part of an HL7 timestamp.
+const hl7DateLayout = "20060102"
+
+// shiftTimestamp moves an HL7 timestamp by a number of days.
+//
+// Only the YYYYMMDD part changes: the time of day, fraction and time zone
+// are kept as written. Values with less than day precision are not shifted.
+func shiftTimestamp(value string, days int) (string, bool) {
+	if days == 0 {
+		return value, false
+	}
+	t, ok := parseDate(value)
+	if !ok {
+		return value, false
+	}
+	return t.AddDate(0, 0, days).Format(hl7DateLayout) + value[len(hl7DateLayout):], true
+}
+
+// parseDate parses the YYYYMMDD part of an HL7 timestamp.
+func parseDate(value string) (time.Time, bool) {
+	if len(value) < len(hl7DateLayout) {
+		return time.Time{}, false
+	}
+	t, err := time.Parse(hl7DateLayout, value[:len(hl7DateLayout)])
+	if err != nil {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// referenceTime returns the time the document was recorded: the document
+// effective time, or the first series effective time.
+func referenceTime(doc *types.HL7AEcg) (string, bool) {
+	candidates := []string{}
+	if doc.EffectiveTime != nil {
+		candidates = append(candidates, doc.EffectiveTime.Low.Value)
+	}
+	for _, c := range doc.Component {
+		candidates = append(candidates, c.Series.EffectiveTime.Low.Value)
+	}
+	for _, v := range candidates {
+		if _, ok := parseDate(v); ok {
+			return v, true
+		}
+	}
+	return "", false
+}
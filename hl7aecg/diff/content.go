@@ -0,0 +1,224 @@
+package diff
+
+import (
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Annotations
+// =============================================================================
+
+// entry is an annotation flattened for matching.
+type entry struct {
+	code       string
+	lead       string
+	occurrence int
+	value      string
+}
+
+// key identifies an entry within its set.
+func (e entry) key() string {
+	return e.code + "\x00" + e.lead + "\x00" + strconv.Itoa(e.occurrence)
+}
+
+// compareAnnotations compares the annotation sets of two series, matched by
+// position.
+func (d *Diff) compareAnnotations(series string, a, b []*types.AnnotationSet) {
+	for i := 0; i < max(len(a), len(b)); i++ {
+		var ea, eb []entry
+		if i < len(a) {
+			ea = flatten(a[i].Component)
+		}
+		if i < len(b) {
+			eb = flatten(b[i].Component)
+		}
+
+		newValues := make(map[string]entry, len(eb))
+		for _, e := range eb {
+			newValues[e.key()] = e
+		}
+		oldKeys := make(map[string]bool, len(ea))
+		for _, e := range ea {
+			oldKeys[e.key()] = true
+			change := AnnotationChange{Series: series, Set: i, Code: e.code, Lead: e.lead, Occurrence: e.occurrence, Old: e.value}
+			if n, ok := newValues[e.key()]; !ok {
+				change.Kind = Removed
+			} else if n.value != e.value {
+				change.Kind, change.New = Changed, n.value
+			} else {
+				continue
+			}
+			d.Annotations = append(d.Annotations, change)
+		}
+		for _, e := range eb {
+			if !oldKeys[e.key()] {
+				d.Annotations = append(d.Annotations, AnnotationChange{
+					Kind: Added, Series: series, Set: i, Code: e.code, Lead: e.lead, Occurrence: e.occurrence, New: e.value,
+				})
+			}
+		}
+	}
+}
+
+// flatten returns the annotations of a set in document order. Annotations
+// with neither value nor children (e.g. empty placeholders) are kept;
+// pure containers (e.g. a lead annotation grouping measurements) are
+// represented by their children.
+func flatten(components []types.AnnotationComponent) []entry {
+	var out []entry
+	seen := map[string]int{}
+	var visit func(prefix, lead string, components []types.AnnotationComponent)
+	visit = func(prefix, lead string, components []types.AnnotationComponent) {
+		for i := range components {
+			ann := &components[i].Annotation
+			code := "?"
+			if ann.Code != nil && ann.Code.Code != "" {
+				code = ann.Code.Code
+			}
+			if prefix != "" {
+				code = prefix + "/" + code
+			}
+			annLead := lead
+			if l := annotationLead(ann); l != "" {
+				annLead = l
+			}
+			if value := formatAnnotation(ann); value != "" || len(ann.Component) == 0 {
+				e := entry{code: code, lead: annLead, value: value}
+				base := e.key()
+				e.occurrence = seen[base]
+				seen[base]++
+				out = append(out, e)
+			}
+			visit(code, annLead, ann.Component)
+		}
+	}
+	visit("", "", components)
+	return out
+}
+
+// annotationLead returns the lead of a lead annotation, or "".
+func annotationLead(ann *types.Annotation) string {
+	if ann.Support == nil {
+		return ""
+	}
+	for _, comp := range ann.Support.SupportingROI.Component {
+		if code := comp.Boundary.Code.Code; strings.HasPrefix(code, "MDC_ECG_LEAD_") {
+			return code
+		}
+	}
+	return ""
+}
+
+// formatAnnotation formats the value of an annotation and the intervals of
+// its boundaries (e.g. "MDC_ECG_WAVC_PWAVE [112 ms, 214 ms]").
+func formatAnnotation(ann *types.Annotation) string {
+	var parts []string
+	if v := ann.Value; v != nil {
+		switch typed := v.Typed.(type) {
+		case *types.PhysicalQuantity:
+			parts = append(parts, formatPQ(typed))
+		case *types.StringValue:
+			parts = append(parts, strconv.Quote(typed.Value))
+		case *types.CodedValue:
+			parts = append(parts, typed.Code)
+		}
+	}
+	if ann.Support != nil {
+		for _, comp := range ann.Support.SupportingROI.Component {
+			if bv := comp.Boundary.Value; bv != nil {
+				parts = append(parts, "["+formatPQ(bv.Low)+", "+formatPQ(bv.High)+"]")
+			}
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// formatPQ formats a physical quantity as "value unit".
+func formatPQ(pq *types.PhysicalQuantity) string {
+	if pq == nil {
+		return ""
+	}
+	return strings.TrimSpace(pq.Value + " " + pq.Unit)
+}
+
+// =============================================================================
+// Waveforms
+// =============================================================================
+
+// compareWaveforms compares the leads of two series, decoded in µV.
+func (d *Diff) compareWaveforms(series string, a, b *types.Series, opts Options) {
+	leadsA, leadsB := a.GetLeadCodes(), b.GetLeadCodes()
+	for _, lead := range leadsA {
+		ld := LeadDiff{Series: series, Lead: string(lead)}
+		va, errA := a.GetLeadValues(lead)
+		ld.SamplesOld = len(va)
+		if !slices.Contains(leadsB, lead) {
+			ld.Kind = Removed
+			d.Waveforms = append(d.Waveforms, ld)
+			continue
+		}
+		vb, errB := b.GetLeadValues(lead)
+		ld.SamplesNew = len(vb)
+		if errA != nil || errB != nil {
+			ld.Kind = Changed
+			if errA != nil {
+				ld.Err = "old: " + errA.Error()
+			} else {
+				ld.Err = "new: " + errB.Error()
+			}
+			d.Waveforms = append(d.Waveforms, ld)
+			continue
+		}
+		if compareSamples(&ld, va, vb, opts) {
+			ld.Kind = Changed
+			d.Waveforms = append(d.Waveforms, ld)
+		}
+	}
+	for _, lead := range leadsB {
+		if !slices.Contains(leadsA, lead) {
+			vb, _ := b.GetLeadValues(lead)
+			d.Waveforms = append(d.Waveforms, LeadDiff{Kind: Added, Series: series, Lead: string(lead), SamplesNew: len(vb)})
+		}
+	}
+}
+
+// compareSamples fills the maximum difference and the differing ranges of
+// two leads, and reports whether they differ.
+func compareSamples(ld *LeadDiff, a, b []float64, opts Options) bool {
+	var ranges []Range
+	open := false
+	n := min(len(a), len(b))
+	for i := 0; i < n; i++ {
+		delta := math.Abs(a[i] - b[i])
+		ld.MaxAbsDiff = max(ld.MaxAbsDiff, delta)
+		differs := delta > opts.Tolerance
+		switch {
+		case differs && !open:
+			ranges = append(ranges, Range{Start: i, End: i + 1})
+			open = true
+		case differs:
+			ranges[len(ranges)-1].End = i + 1
+		default:
+			open = false
+		}
+	}
+	if len(a) != len(b) {
+		if open {
+			ranges[len(ranges)-1].End = max(len(a), len(b))
+		} else {
+			ranges = append(ranges, Range{Start: n, End: max(len(a), len(b))})
+		}
+	}
+
+	if len(ranges) > opts.MaxRanges {
+		ld.Ranges, ld.MoreRanges = ranges[:max(opts.MaxRanges, 0)], len(ranges)-max(opts.MaxRanges, 0)
+	} else {
+		ld.Ranges = ranges
+	}
+	return len(ranges) > 0
+}
@@ -0,0 +1,328 @@
+// Package diff compares two aECG documents structurally, e.g. a vendor
+// re-export with its original or a read before and after edition.
+//
+// The differences are reported in three parts:
+//
+//   - Metadata: every element and attribute outside the annotations and
+//     the waveform digits, with its path in the document (e.g.
+//     "component[0].series.effectiveTime.low.value").
+//   - Annotations: added, removed and changed annotations of each
+//     annotation set, matched by code path, lead and occurrence, with their
+//     old and new values.
+//   - Waveforms: per lead, the maximum absolute difference in µV and the
+//     ranges of differing samples, instead of the digits themselves.
+//
+// Series, derived series and annotation sets are matched by position.
+//
+// Example:
+//
+//	d := diff.Compare(&original.HL7AEcg, &reexport.HL7AEcg, diff.DefaultOptions())
+//	if !d.Empty() {
+//		d.WriteText(os.Stdout)
+//	}
+package diff
+
+import (
+	"encoding/xml"
+	"fmt"
+	"reflect"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Options
+// =============================================================================
+
+// Options configures a comparison.
+type Options struct {
+	// Tolerance is the largest difference between two samples, in µV,
+	// considered equal. Use it to ignore rounding by re-exports.
+	Tolerance float64
+
+	// MaxRanges bounds the differing sample ranges listed per lead; the
+	// remaining ones are only counted. 0 lists none.
+	MaxRanges int
+}
+
+// DefaultOptions returns exact comparison, listing up to 10 ranges per lead.
+func DefaultOptions() Options {
+	return Options{Tolerance: 0, MaxRanges: 10}
+}
+
+// =============================================================================
+// Differences
+// =============================================================================
+
+// Kind is the kind of a difference.
+type Kind string
+
+const (
+	Added   Kind = "added"
+	Removed Kind = "removed"
+	Changed Kind = "changed"
+)
+
+// Change is a metadata difference.
+type Change struct {
+	Kind Kind `json:"kind"`
+
+	// Path locates the element or attribute, by XML names.
+	Path string `json:"path"`
+
+	// Old and New are the values of an attribute or text, empty for
+	// elements.
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// AnnotationChange is a difference in an annotation set.
+type AnnotationChange struct {
+	Kind Kind `json:"kind"`
+
+	// Series is the path of the series (e.g. "component[0].series").
+	Series string `json:"series"`
+
+	// Set is the position of the annotation set in the series.
+	Set int `json:"set"`
+
+	// Code is the path of annotation codes from the set, e.g.
+	// "MDC_ECG_BEAT/MDC_ECG_TIME_PD_QT".
+	Code string `json:"code"`
+
+	// Lead is the lead of the annotation or of its enclosing lead
+	// annotation, if any.
+	Lead string `json:"lead,omitempty"`
+
+	// Occurrence tells apart annotations with the same code and lead in
+	// the same set (0 for the first).
+	Occurrence int `json:"occurrence,omitempty"`
+
+	Old string `json:"old,omitempty"`
+	New string `json:"new,omitempty"`
+}
+
+// Range is a range of sample indexes, End excluded.
+type Range struct {
+	Start int `json:"start"`
+	End   int `json:"end"`
+}
+
+// LeadDiff is a waveform difference.
+type LeadDiff struct {
+	// Kind is Added or Removed when the lead is in one series only.
+	Kind   Kind   `json:"kind"`
+	Series string `json:"series"`
+	Lead   string `json:"lead"`
+
+	// SamplesOld and SamplesNew are the number of samples of each side.
+	SamplesOld int `json:"samplesOld"`
+	SamplesNew int `json:"samplesNew"`
+
+	// MaxAbsDiff is the largest difference between samples present on both
+	// sides, in µV.
+	MaxAbsDiff float64 `json:"maxAbsDiff"`
+
+	// Ranges are the first Options.MaxRanges ranges of differing samples.
+	// Samples present on one side only form the last range.
+	Ranges []Range `json:"ranges,omitempty"`
+
+	// MoreRanges counts the differing ranges not listed.
+	MoreRanges int `json:"moreRanges,omitempty"`
+
+	// Err is set when the lead could not be decoded.
+	Err string `json:"error,omitempty"`
+}
+
+// Diff is the difference between two documents.
+type Diff struct {
+	Metadata    []Change           `json:"metadata"`
+	Annotations []AnnotationChange `json:"annotations"`
+	Waveforms   []LeadDiff         `json:"waveforms"`
+}
+
+// Empty reports whether the documents are identical.
+func (d *Diff) Empty() bool {
+	return len(d.Metadata)+len(d.Annotations)+len(d.Waveforms) == 0
+}
+
+// =============================================================================
+// Compare
+// =============================================================================
+
+// Compare returns the differences from document a (old) to b (new).
+func Compare(a, b *types.HL7AEcg, opts Options) *Diff {
+	d := &Diff{Metadata: []Change{}, Annotations: []AnnotationChange{}, Waveforms: []LeadDiff{}}
+	d.walk("", reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem())
+
+	for i := 0; i < max(len(a.Component), len(b.Component)); i++ {
+		path := fmt.Sprintf("component[%d].series", i)
+		var sa, sb *types.Series
+		if i < len(a.Component) {
+			sa = &a.Component[i].Series
+		}
+		if i < len(b.Component) {
+			sb = &b.Component[i].Series
+		}
+		d.compareSeries(path, sa, sb, opts)
+	}
+	return d
+}
+
+// compareSeries compares the annotations and waveforms of two series and
+// of their derived series. A nil series has no annotations nor waveforms.
+func (d *Diff) compareSeries(path string, a, b *types.Series, opts Options) {
+	d.compareAnnotations(path, a.GetAnnotationSets(), b.GetAnnotationSets())
+	if a != nil && b != nil {
+		d.compareWaveforms(path, a, b, opts)
+	}
+
+	var da, db []types.Derivation
+	if a != nil {
+		da = a.Derivation
+	}
+	if b != nil {
+		db = b.Derivation
+	}
+	for i := 0; i < max(len(da), len(db)); i++ {
+		var sa, sb *types.Series
+		if i < len(da) {
+			sa = &da[i].DerivedSeries
+		}
+		if i < len(db) {
+			sb = &db[i].DerivedSeries
+		}
+		d.compareSeries(fmt.Sprintf("%s.derivation[%d].derivedSeries", path, i), sa, sb, opts)
+	}
+}
+
+// =============================================================================
+// Metadata
+// =============================================================================
+
+var (
+	xmlNameType      = reflect.TypeOf(xml.Name{})
+	xmlAttrType      = reflect.TypeOf(xml.Attr{})
+	extraElementType = reflect.TypeOf(types.ExtraElement{})
+	annotationType   = reflect.TypeOf([]types.AnnotationComponent{})
+)
+
+// walk compares two values of the same type, appending a Change per
+// differing leaf. Annotations (compared by compareAnnotations), waveform
+// digits (compared by compareWaveforms) and raw inner XML are skipped.
+// Unmodeled attributes and elements (see types.Extensions) are leaves,
+// compared as written.
+func (d *Diff) walk(path string, a, b reflect.Value) {
+	if a.IsValid() && (a.Type() == xmlAttrType || a.Type() == extraElementType) {
+		if oldXML, newXML := leaf(a), leaf(b); oldXML != newXML {
+			d.Metadata = append(d.Metadata, Change{Kind: Changed, Path: path, Old: oldXML, New: newXML})
+		}
+		return
+	}
+
+	switch a.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		switch {
+		case a.IsNil() && b.IsNil():
+		case a.IsNil():
+			d.Metadata = append(d.Metadata, Change{Kind: Added, Path: path, New: leaf(b.Elem())})
+		case b.IsNil():
+			d.Metadata = append(d.Metadata, Change{Kind: Removed, Path: path, Old: leaf(a.Elem())})
+		case a.Elem().Type() != b.Elem().Type():
+			d.Metadata = append(d.Metadata, Change{Kind: Changed, Path: path, Old: a.Elem().Type().String(), New: b.Elem().Type().String()})
+		default:
+			d.walk(path, a.Elem(), b.Elem())
+		}
+
+	case reflect.Struct:
+		t := a.Type()
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, skip := fieldName(f)
+			if skip {
+				continue
+			}
+			if !f.Anonymous {
+				name = join(path, name)
+			} else {
+				name = path
+			}
+			d.walk(name, a.Field(i), b.Field(i))
+		}
+
+	case reflect.Slice, reflect.Array:
+		for i := 0; i < max(a.Len(), b.Len()); i++ {
+			p := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= a.Len():
+				d.Metadata = append(d.Metadata, Change{Kind: Added, Path: p, New: leaf(b.Index(i))})
+			case i >= b.Len():
+				d.Metadata = append(d.Metadata, Change{Kind: Removed, Path: p, Old: leaf(a.Index(i))})
+			default:
+				d.walk(p, a.Index(i), b.Index(i))
+			}
+		}
+
+	case reflect.Map, reflect.Func, reflect.Chan, reflect.UnsafePointer, reflect.Invalid:
+		// Not part of the document model
+
+	default:
+		if !a.Equal(b) {
+			d.Metadata = append(d.Metadata, Change{Kind: Changed, Path: path, Old: leaf(a), New: leaf(b)})
+		}
+	}
+}
+
+// fieldName returns the XML name of a struct field, or its lowercased Go
+// name when the tag has none, and whether the field is skipped.
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() || f.Type == xmlNameType || f.Type == annotationType {
+		return "", true
+	}
+	tag := f.Tag.Get("xml")
+	name, flags, _ := strings.Cut(tag, ",")
+	if name == "-" || name == "digits" || strings.Contains(flags, "innerxml") {
+		return "", true
+	}
+	if name == "" {
+		r, size := utf8.DecodeRuneInString(f.Name)
+		name = string(unicode.ToLower(r)) + f.Name[size:]
+	}
+	return name, false
+}
+
+// leaf formats a scalar value, an unmodeled attribute (name="value") or
+// element (as XML), or returns "" for other values.
+func leaf(v reflect.Value) string {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+	switch v.Type() {
+	case xmlAttrType:
+		attr := v.Interface().(xml.Attr)
+		return fmt.Sprintf("%s=%q", attr.Name.Local, attr.Value)
+	case extraElementType:
+		el := v.Interface().(types.ExtraElement)
+		data, _ := xml.Marshal(el)
+		return string(data)
+	}
+	switch v.Kind() {
+	case reflect.Struct, reflect.Slice, reflect.Array, reflect.Map:
+		return ""
+	}
+	return fmt.Sprint(v.Interface())
+}
+
+// join appends a field name to a path.
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
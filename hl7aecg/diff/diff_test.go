@@ -0,0 +1,188 @@
+package diff
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// params describes the test document.
+type params struct {
+	low   string
+	leadI []int
+	qt    float64
+	pr    bool
+}
+
+// defaults returns the parameters of the original document.
+func defaults() params {
+	return params{
+		low:   "20240315101500",
+		leadI: []int{0, 1, 2, 3, 4, 5, 6, 7, 8, 9},
+		qt:    400,
+		pr:    true,
+	}
+}
+
+// newDoc builds the document described by p and parses it back, as a
+// document read from a file.
+func newDoc(t *testing.T, p params) *types.HL7AEcg {
+	t.Helper()
+	h := testdoc.New(t, testdoc.Doc{Low: p.low, Leads: map[types.LeadCode][]int{
+		types.MDC_ECG_LEAD_I:  p.leadI,
+		types.MDC_ECG_LEAD_II: {5, 5, 5},
+	}})
+
+	set := h.HL7AEcg.Component[0].Series.AddAnnotationSet("20240315120000")
+	set.AddHeartRate(60)
+	set.AddQTInterval(p.qt)
+	if p.pr {
+		set.AddPRInterval(160)
+	}
+	idx := set.AddLeadAnnotation("MDC_ECG_LEAD_V2", "MEASUREMENT_MATRIX", "", "HL7V3AECG")
+	set.GetAnnotation(idx).AddNestedAnnotation("MDC_ECG_AMPL_T", "", 450, types.UNIT_MICROVOLT)
+
+	data, err := h.Marshal()
+	require.NoError(t, err)
+	doc := hl7aecg.NewHl7xml("")
+	require.NoError(t, doc.Unmarshal(data))
+	return &doc.HL7AEcg
+}
+
+// TestCompare_Identical tests that a document equals itself
+func TestCompare_Identical(t *testing.T) {
+	d := Compare(newDoc(t, defaults()), newDoc(t, defaults()), DefaultOptions())
+	assert.True(t, d.Empty(), "%+v", d)
+}
+
+// TestCompare_Metadata tests metadata changes with their paths
+func TestCompare_Metadata(t *testing.T) {
+	p := defaults()
+	p.low = "20240315101501"
+	a, b := newDoc(t, defaults()), newDoc(t, p)
+	b.Text = "re-exported"
+
+	d := Compare(a, b, DefaultOptions())
+	assert.Contains(t, d.Metadata, Change{Kind: Changed, Path: "effectiveTime.low.value", Old: "20240315101500", New: "20240315101501"})
+	assert.Contains(t, d.Metadata, Change{Kind: Changed, Path: "text", New: "re-exported"})
+	assert.Empty(t, d.Annotations)
+	assert.Empty(t, d.Waveforms)
+
+	b.ReasonCode = nil
+	d = Compare(a, b, DefaultOptions())
+	assert.Contains(t, d.Metadata, Change{Kind: Removed, Path: "reasonCode"})
+}
+
+// TestCompare_Extensions tests unmodeled elements and attributes
+func TestCompare_Extensions(t *testing.T) {
+	a, b := newDoc(t, defaults()), newDoc(t, defaults())
+	firmware := types.ExtraElement{XMLName: xml.Name{Local: "acme:firmware"}, InnerXML: []byte("2.7.19")}
+	a.Component[0].Series.ExtraElements = []types.ExtraElement{firmware}
+	firmware.InnerXML = []byte("2.8.0")
+	b.Component[0].Series.ExtraElements = []types.ExtraElement{firmware}
+	b.ExtraAttrs = []xml.Attr{{Name: xml.Name{Local: "classCode"}, Value: "OBS"}}
+
+	d := Compare(a, b, DefaultOptions())
+	assert.ElementsMatch(t, []Change{
+		{Kind: Changed, Path: "component[0].series.extraElements[0]", Old: "<acme:firmware>2.7.19</acme:firmware>", New: "<acme:firmware>2.8.0</acme:firmware>"},
+		{Kind: Added, Path: "extraAttrs[0]", New: `classCode="OBS"`},
+	}, d.Metadata)
+}
+
+// TestCompare_Annotations tests added, removed and changed annotations
+func TestCompare_Annotations(t *testing.T) {
+	p := defaults()
+	p.qt, p.pr = 412, false
+	a, b := newDoc(t, defaults()), newDoc(t, p)
+	set := b.Component[0].Series.GetAnnotationSets()[0]
+	set.AddQRSDuration(92)
+	set.GetAnnotationByCode("MEASUREMENT_MATRIX").Component[0].Annotation.Value.Typed.(*types.PhysicalQuantity).Value = "500"
+
+	d := Compare(a, b, DefaultOptions())
+	assert.Empty(t, d.Metadata, "annotations are not metadata")
+	series := "component[0].series"
+	assert.ElementsMatch(t, []AnnotationChange{
+		{Kind: Changed, Series: series, Code: "MDC_ECG_TIME_PD_QT", Old: "400 ms", New: "412 ms"},
+		{Kind: Removed, Series: series, Code: "MDC_ECG_TIME_PD_PR", Old: "160 ms"},
+		{Kind: Changed, Series: series, Code: "MEASUREMENT_MATRIX/MDC_ECG_AMPL_T", Lead: "MDC_ECG_LEAD_V2", Old: "450 uV", New: "500 uV"},
+		{Kind: Added, Series: series, Code: "MDC_ECG_TIME_PD_QRS", New: "92 ms"},
+	}, d.Annotations)
+
+	// A new set lists all its annotations
+	b.Component[0].Series.AddAnnotationSet("20240316120000").AddHeartRate(61)
+	d = Compare(a, b, DefaultOptions())
+	assert.Contains(t, d.Annotations, AnnotationChange{Kind: Added, Series: series, Set: 1, Code: "MDC_ECG_HEART_RATE", New: "61 bpm"})
+	assert.Contains(t, d.Metadata, Change{Kind: Added, Path: "component[0].series.subjectOf[1]"})
+}
+
+// TestCompare_Waveforms tests the lead differences and ranges
+func TestCompare_Waveforms(t *testing.T) {
+	p := defaults()
+	p.leadI = []int{0, 1, 9, 9, 4, 5, 6, 8, 8, 9, 10, 11}
+	a, b := newDoc(t, defaults()), newDoc(t, p)
+
+	d := Compare(a, b, DefaultOptions())
+	assert.Empty(t, d.Annotations)
+	require.Len(t, d.Waveforms, 1)
+	ld := d.Waveforms[0]
+	assert.Equal(t, Changed, ld.Kind)
+	assert.Equal(t, "MDC_ECG_LEAD_I", ld.Lead)
+	assert.Equal(t, 10, ld.SamplesOld)
+	assert.Equal(t, 12, ld.SamplesNew)
+	assert.InDelta(t, 35, ld.MaxAbsDiff, 1e-9, "7 digits at 5 µV")
+	assert.Equal(t, []Range{{2, 4}, {7, 8}, {10, 12}}, ld.Ranges)
+
+	d = Compare(a, b, Options{Tolerance: 5, MaxRanges: 1})
+	ld = d.Waveforms[0]
+	assert.Equal(t, []Range{{2, 4}}, ld.Ranges, "sample 7 is within tolerance")
+	assert.Equal(t, 1, ld.MoreRanges)
+
+	for _, m := range d.Metadata {
+		assert.NotContains(t, m.Path, "digits")
+	}
+}
+
+// TestCompare_Leads tests added and removed leads
+func TestCompare_Leads(t *testing.T) {
+	a, b := newDoc(t, defaults()), newDoc(t, defaults())
+	components := b.Component[0].Series.Component[0].SequenceSet.Component
+	b.Component[0].Series.Component[0].SequenceSet.Component = components[:len(components)-1]
+
+	d := Compare(a, b, DefaultOptions())
+	require.Len(t, d.Waveforms, 1)
+	assert.Equal(t, Removed, d.Waveforms[0].Kind)
+
+	d = Compare(b, a, DefaultOptions())
+	require.Len(t, d.Waveforms, 1)
+	assert.Equal(t, Added, d.Waveforms[0].Kind)
+	assert.Equal(t, 1, len(d.Metadata), "the sequence itself")
+}
+
+// TestDiff_Write tests the text and JSON output
+func TestDiff_Write(t *testing.T) {
+	p := defaults()
+	p.low, p.qt = "20240315101501", 412
+	p.leadI = []int{0, 1, 9, 9, 4, 5, 6, 7, 8, 9}
+	d := Compare(newDoc(t, defaults()), newDoc(t, p), DefaultOptions())
+
+	var buf bytes.Buffer
+	require.NoError(t, d.WriteText(&buf))
+	assert.Equal(t, `~ effectiveTime.low.value: 20240315101500 -> 20240315101501
+~ component[0].series set 0 MDC_ECG_TIME_PD_QT: 400 ms -> 412 ms
+~ component[0].series MDC_ECG_LEAD_I: max |Δ| 35 µV, samples 2-4
+`, buf.String())
+
+	buf.Reset()
+	require.NoError(t, d.WriteJSON(&buf))
+	var decoded Diff
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, *d, decoded)
+}
@@ -0,0 +1,98 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Diff Output
+// =============================================================================
+
+// WriteJSON writes the diff as indented JSON.
+func (d *Diff) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(d)
+}
+
+// WriteText writes the diff as text, one line per difference, prefixed by
+// "+" (added), "-" (removed) or "~" (changed).
+//
+// Example:
+//
+//	~ effectiveTime.low.value: 20240315101500 -> 20240315101501
+//	~ component[0].series set 0 MDC_ECG_TIME_PD_QT: 400 ms -> 412 ms
+//	~ component[0].series MDC_ECG_LEAD_II: max |Δ| 12 µV, samples 100-200
+func (d *Diff) WriteText(w io.Writer) error {
+	var b strings.Builder
+	for _, c := range d.Metadata {
+		fmt.Fprintf(&b, "%s %s%s\n", marker(c.Kind), c.Path, values(c.Kind, c.Old, c.New))
+	}
+	for _, c := range d.Annotations {
+		fmt.Fprintf(&b, "%s %s set %d %s", marker(c.Kind), c.Series, c.Set, c.Code)
+		if c.Lead != "" {
+			fmt.Fprintf(&b, " (%s)", c.Lead)
+		}
+		if c.Occurrence > 0 {
+			fmt.Fprintf(&b, " #%d", c.Occurrence+1)
+		}
+		fmt.Fprintf(&b, "%s\n", values(c.Kind, c.Old, c.New))
+	}
+	for _, l := range d.Waveforms {
+		fmt.Fprintf(&b, "%s %s %s", marker(l.Kind), l.Series, l.Lead)
+		switch {
+		case l.Err != "":
+			fmt.Fprintf(&b, ": %s", l.Err)
+		case l.Kind == Added:
+			fmt.Fprintf(&b, ": %d samples", l.SamplesNew)
+		case l.Kind == Removed:
+			fmt.Fprintf(&b, ": %d samples", l.SamplesOld)
+		default:
+			fmt.Fprintf(&b, ": max |Δ| %s µV", strconv.FormatFloat(l.MaxAbsDiff, 'f', -1, 64))
+			if l.SamplesOld != l.SamplesNew {
+				fmt.Fprintf(&b, ", %d -> %d samples", l.SamplesOld, l.SamplesNew)
+			}
+			if len(l.Ranges) > 0 {
+				ranges := make([]string, len(l.Ranges))
+				for i, r := range l.Ranges {
+					ranges[i] = fmt.Sprintf("%d-%d", r.Start, r.End)
+				}
+				fmt.Fprintf(&b, ", samples %s", strings.Join(ranges, ", "))
+			}
+			if l.MoreRanges > 0 {
+				fmt.Fprintf(&b, " (+%d more ranges)", l.MoreRanges)
+			}
+		}
+		b.WriteString("\n")
+	}
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// marker returns the line prefix of a kind.
+func marker(k Kind) string {
+	switch k {
+	case Added:
+		return "+"
+	case Removed:
+		return "-"
+	}
+	return "~"
+}
+
+// values formats the old and new values of a change.
+func values(k Kind, old, new string) string {
+	switch {
+	case k == Changed:
+		return fmt.Sprintf(": %s -> %s", old, new)
+	case old != "":
+		return ": " + old
+	case new != "":
+		return ": " + new
+	}
+	return ""
+}
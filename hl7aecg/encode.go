@@ -0,0 +1,83 @@
+package hl7aecg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+)
+
+// encode returns v as indented XML, with the elements without content closed
+// in short form (<id root="..."/>).
+//
+// The standard encoder writes the document, namespace declarations and
+// unmodeled elements in place included (see types.HL7AEcg.MarshalXML); its
+// output is then rewritten token by token, names kept with the prefixes they
+// are written with.
+func encode(v any) ([]byte, error) {
+	data, err := xml.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+
+	var b bytes.Buffer
+	b.Grow(len(data))
+	d := xml.NewDecoder(bytes.NewReader(data))
+	// open is set while a start tag is written without its closing '>'
+	open := false
+	for {
+		tok, err := d.RawToken()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := tok.(xml.EndElement); ok && open {
+			b.WriteString("/>")
+			open = false
+			continue
+		}
+		if open {
+			b.WriteByte('>')
+			open = false
+		}
+
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			b.WriteString("<" + qualifiedName(tok.Name))
+			for _, attr := range tok.Attr {
+				b.WriteString(" " + qualifiedName(attr.Name) + `="`)
+				attrEscaper.WriteString(&b, attr.Value)
+				b.WriteByte('"')
+			}
+			open = true
+		case xml.EndElement:
+			b.WriteString("</" + qualifiedName(tok.Name) + ">")
+		case xml.CharData:
+			textEscaper.WriteString(&b, string(tok))
+		case xml.Comment:
+			b.WriteString("<!--" + string(tok) + "-->")
+		case xml.ProcInst:
+			b.WriteString("<?" + tok.Target + " " + string(tok.Inst) + "?>")
+		case xml.Directive:
+			b.WriteString("<!" + string(tok) + ">")
+		}
+	}
+	return b.Bytes(), nil
+}
+
+// qualifiedName returns a name read by RawToken as written, its prefix left
+// in Space.
+func qualifiedName(name xml.Name) string {
+	if name.Space == "" {
+		return name.Local
+	}
+	return name.Space + ":" + name.Local
+}
+
+var (
+	textEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", "&#xD;")
+	attrEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", `"`, "&quot;",
+		"\t", "&#x9;", "\n", "&#xA;", "\r", "&#xD;")
+)
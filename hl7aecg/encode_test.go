@@ -0,0 +1,123 @@
+package hl7aecg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// newAnnotatedDoc returns a document with a rhythm series, a control variable
+// and annotations, covering the elements written with xsi:type.
+func newAnnotatedDoc() *Hl7xml {
+	h := NewHl7xml("")
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "").
+		SetText(`Resting ECG <12 leads> & "notes"`).
+		SetEffectiveTime("20240315101500", "20240315101510", nil, nil).
+		AddRhythmSeries("20240315101500.000", "20240315101510.000", nil, nil, 500,
+			map[types.LeadCode][]int{types.MDC_ECG_LEAD_II: {1, 2, 3}}, 0, 5).
+		AddControlVariable(types.NewLowPassFilter("150", "Hz"))
+
+	set := h.HL7AEcg.Component[0].Series.AddAnnotationSet("20240315120000")
+	set.AddAnnotation("MDC_ECG_HEART_RATE", string(types.MDC_OID), 72, "bpm")
+	set.AddTextAnnotation("MDC_ECG_INTERPRETATION_STATEMENT", string(types.MDC_OID), "Sinus rhythm")
+	return h
+}
+
+// TestHl7xml_MarshalNamespaces tests that every element of the output is in
+// the HL7 v3 namespace and every xsi:type attribute in the schema instance one
+func TestHl7xml_MarshalNamespaces(t *testing.T) {
+	data, err := newAnnotatedDoc().Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+
+	xsiTypes := 0
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("Token() error = %v", err)
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if start.Name.Space != types.NAMESPACE_HL7 {
+			t.Errorf("element %s is in namespace %q, want %q", start.Name.Local, start.Name.Space, types.NAMESPACE_HL7)
+		}
+		for _, attr := range start.Attr {
+			if attr.Name.Local == "type" && attr.Name.Space != "" {
+				if attr.Name.Space != types.NAMESPACE_XSI {
+					t.Errorf("%s type attribute is in namespace %q, want %q", start.Name.Local, attr.Name.Space, types.NAMESPACE_XSI)
+				}
+				xsiTypes++
+			}
+		}
+	}
+	// The sequences, the control variable and the two annotations
+	if xsiTypes != 5 {
+		t.Errorf("output has %d xsi:type attributes, want 5", xsiTypes)
+	}
+}
+
+// TestHl7xml_MarshalEmptyDeclarations tests that unset root attributes are not written empty
+func TestHl7xml_MarshalEmptyDeclarations(t *testing.T) {
+	h := NewHl7xml("")
+	h.HL7AEcg.Xmlns, h.HL7AEcg.XmlnsVoc, h.HL7AEcg.XmlnsXsi = "", "", ""
+
+	out, err := h.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	root, _, _ := strings.Cut(out, ">")
+	for _, want := range []string{`xmlns="urn:hl7-org:v3"`, `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`} {
+		if !strings.Contains(root, want) {
+			t.Errorf("root %s is missing %s", root, want)
+		}
+	}
+	for _, unwanted := range []string{"xmlns:voc", "schemaLocation"} {
+		if strings.Contains(root, unwanted) {
+			t.Errorf("root %s has an empty %s", root, unwanted)
+		}
+	}
+}
+
+// TestHl7xml_StringShortForm tests that String and Marshal close empty elements
+// in short form and escape text and attribute values
+func TestHl7xml_StringShortForm(t *testing.T) {
+	h := newAnnotatedDoc()
+	out, err := h.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	if strings.HasPrefix(out, "<?xml") {
+		t.Errorf("String() output has an XML declaration")
+	}
+	for _, want := range []string{
+		`<code code="93000" codeSystem="2.16.840.1.113883.6.12" codeSystemName="CPT-4"/>`,
+		`<value xsi:type="PQ" value="72" unit="bpm"/>`,
+		`<text>Resting ECG &lt;12 leads&gt; &amp; "notes"</text>`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("String() output is missing %s", want)
+		}
+	}
+	if strings.Contains(out, "></code>") {
+		t.Errorf("String() output has empty elements in long form")
+	}
+
+	data, err := h.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if got := strings.TrimPrefix(strings.TrimSuffix(string(data), "\n"), xml.Header); got != out {
+		t.Errorf("Marshal() is not String() with an XML declaration")
+	}
+}
@@ -0,0 +1,185 @@
+// Package export converts aECG documents to formats for analysis and
+// review tools: CSV and JSON for the waveforms, SVG for a printable ECG
+// strip.
+//
+// Waveforms are exported in microvolts, as decoded by
+// types.Series.GetLeadValues.
+//
+// Example:
+//
+//	series, err := export.SelectSeries(&h.HL7AEcg, 0, -1)
+//	if err != nil {
+//		return err
+//	}
+//	err = export.WriteCSV(os.Stdout, series)
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+var (
+	// ErrNoSeries is returned when the requested series does not exist.
+	ErrNoSeries = errors.New("export: no such series")
+
+	// ErrNoWaveforms is returned when a series has no lead sequence.
+	ErrNoWaveforms = errors.New("export: series has no lead waveforms")
+)
+
+// =============================================================================
+// Series Selection
+// =============================================================================
+
+// SelectSeries returns the series at index of the document, or its derived
+// series at index derived when derived is not negative.
+func SelectSeries(doc *types.HL7AEcg, index, derived int) (*types.Series, error) {
+	if index < 0 || index >= len(doc.Component) {
+		return nil, fmt.Errorf("%w: component[%d]", ErrNoSeries, index)
+	}
+	series := &doc.Component[index].Series
+	if derived < 0 {
+		return series, nil
+	}
+	if derived >= len(series.Derivation) {
+		return nil, fmt.Errorf("%w: component[%d].derivation[%d]", ErrNoSeries, index, derived)
+	}
+	return &series.Derivation[derived].DerivedSeries, nil
+}
+
+// waveforms holds the decoded leads of a series, in document order.
+type waveforms struct {
+	rate    float64
+	leads   []types.LeadCode
+	values  [][]float64
+	samples int
+}
+
+// decode returns the decoded leads of the series.
+func decode(series *types.Series) (*waveforms, error) {
+	rate, err := series.GetSampleRate()
+	if err != nil {
+		return nil, err
+	}
+	wf := &waveforms{rate: rate, leads: series.GetLeadCodes()}
+	if len(wf.leads) == 0 {
+		return nil, ErrNoWaveforms
+	}
+	for _, lead := range wf.leads {
+		values, err := series.GetLeadValues(lead)
+		if err != nil {
+			return nil, fmt.Errorf("lead %s: %w", lead, err)
+		}
+		wf.values = append(wf.values, values)
+		wf.samples = max(wf.samples, len(values))
+	}
+	return wf, nil
+}
+
+// =============================================================================
+// CSV
+// =============================================================================
+
+// WriteCSV writes the waveforms of a series as CSV, one row per sample.
+//
+// Columns: time_s (seconds from the start of the series), then one column
+// per lead in µV, named after the lead code. Leads shorter than the others
+// leave their trailing cells empty.
+func WriteCSV(w io.Writer, series *types.Series) error {
+	wf, err := decode(series)
+	if err != nil {
+		return err
+	}
+
+	cw := csv.NewWriter(w)
+	header := []string{"time_s"}
+	for _, lead := range wf.leads {
+		header = append(header, string(lead))
+	}
+	if err := cw.Write(header); err != nil {
+		return err
+	}
+
+	row := make([]string, len(header))
+	for i := 0; i < wf.samples; i++ {
+		row[0] = formatFloat(float64(i) / wf.rate)
+		for j, values := range wf.values {
+			row[j+1] = ""
+			if i < len(values) {
+				row[j+1] = formatFloat(values[i])
+			}
+		}
+		if err := cw.Write(row); err != nil {
+			return err
+		}
+	}
+
+	cw.Flush()
+	return cw.Error()
+}
+
+// =============================================================================
+// JSON
+// =============================================================================
+
+// Document is the JSON form of a document written by WriteJSON.
+type Document struct {
+	Summary *types.Summary `json:"summary"`
+	Series  []Series       `json:"series"`
+}
+
+// Series is the JSON form of a series and its derived series.
+type Series struct {
+	Code       string                       `json:"code,omitempty"`
+	SampleRate float64                      `json:"sampleRate"`
+	Unit       string                       `json:"unit"`
+	Leads      map[types.LeadCode][]float64 `json:"leads"`
+	Derived    []Series                     `json:"derived,omitempty"`
+}
+
+// NewDocument returns the JSON form of a document: its summary and the
+// waveforms of every series. Series without waveforms are exported with an
+// empty lead map.
+func NewDocument(doc *types.HL7AEcg) *Document {
+	out := &Document{Summary: doc.Summary(), Series: []Series{}}
+	for i := range doc.Component {
+		out.Series = append(out.Series, newSeries(&doc.Component[i].Series))
+	}
+	return out
+}
+
+// newSeries returns the JSON form of a series.
+func newSeries(series *types.Series) Series {
+	out := Series{Unit: types.UNIT_MICROVOLT, Leads: map[types.LeadCode][]float64{}}
+	if series.Code != nil {
+		out.Code = string(series.Code.Code)
+	}
+	if wf, err := decode(series); err == nil {
+		out.SampleRate = wf.rate
+		for i, lead := range wf.leads {
+			out.Leads[lead] = wf.values[i]
+		}
+	}
+	for i := range series.Derivation {
+		out.Derived = append(out.Derived, newSeries(&series.Derivation[i].DerivedSeries))
+	}
+	return out
+}
+
+// WriteJSON writes the document summary and waveforms as indented JSON.
+func WriteJSON(w io.Writer, doc *types.HL7AEcg) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(NewDocument(doc))
+}
+
+// formatFloat formats a float64 with the shortest exact representation.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', -1, 64)
+}
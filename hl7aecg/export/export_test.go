@@ -0,0 +1,163 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"encoding/xml"
+	"io"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// newTestDoc builds a two-lead rhythm series at 500 Hz with a derived
+// representative beat.
+func newTestDoc(t *testing.T) *types.HL7AEcg {
+	h := testdoc.New(t, testdoc.Doc{Leads: map[types.LeadCode][]int{
+		types.MDC_ECG_LEAD_I:  {0, 100, 200, 100},
+		types.MDC_ECG_LEAD_II: {0, -100, -200, -100},
+	}})
+	h.AddDerivedSeries(types.REPRESENTATIVE_BEAT_CODE, "0", "0.004", nil, nil, 500,
+		map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {1, 2}}, 0, 5)
+	return &h.HL7AEcg
+}
+
+// TestSelectSeries tests series and derived series selection
+func TestSelectSeries(t *testing.T) {
+	doc := newTestDoc(t)
+
+	series, err := SelectSeries(doc, 0, -1)
+	require.NoError(t, err)
+	assert.Same(t, &doc.Component[0].Series, series)
+
+	derived, err := SelectSeries(doc, 0, 0)
+	require.NoError(t, err)
+	assert.Same(t, &doc.Component[0].Series.Derivation[0].DerivedSeries, derived)
+
+	_, err = SelectSeries(doc, 1, -1)
+	assert.ErrorIs(t, err, ErrNoSeries)
+	_, err = SelectSeries(doc, 0, 1)
+	assert.ErrorIs(t, err, ErrNoSeries)
+}
+
+// TestWriteCSV tests the time column and the per-lead µV columns
+func TestWriteCSV(t *testing.T) {
+	doc := newTestDoc(t)
+	var buf bytes.Buffer
+	require.NoError(t, WriteCSV(&buf, &doc.Component[0].Series))
+
+	rows, err := csv.NewReader(&buf).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 5)
+	assert.Equal(t, "time_s", rows[0][0])
+	assert.ElementsMatch(t, []string{"MDC_ECG_LEAD_I", "MDC_ECG_LEAD_II"}, rows[0][1:])
+	assert.Equal(t, "0.004", rows[3][0])
+
+	col := 1
+	if rows[0][1] != "MDC_ECG_LEAD_I" {
+		col = 2
+	}
+	assert.Equal(t, "1000", rows[3][col], "200 digits at 5 µV")
+
+	assert.ErrorIs(t, WriteCSV(&buf, &types.Series{}), types.ErrMissingTimeSequence)
+}
+
+// TestWriteJSON tests the summary and waveforms of the JSON export
+func TestWriteJSON(t *testing.T) {
+	doc := newTestDoc(t)
+	var buf bytes.Buffer
+	require.NoError(t, WriteJSON(&buf, doc))
+
+	var decoded Document
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+	assert.Equal(t, "93000", decoded.Summary.Code)
+	require.Len(t, decoded.Series, 1)
+	assert.Equal(t, 500.0, decoded.Series[0].SampleRate)
+	assert.Equal(t, types.UNIT_MICROVOLT, decoded.Series[0].Unit)
+	assert.Equal(t, []float64{0, -500, -1000, -500}, decoded.Series[0].Leads[types.MDC_ECG_LEAD_II])
+	require.Len(t, decoded.Series[0].Derived, 1)
+	assert.Equal(t, []float64{5, 10}, decoded.Series[0].Derived[0].Leads[types.MDC_ECG_LEAD_I])
+}
+
+// TestWriteSVG tests the paper size and the traces
+func TestWriteSVG(t *testing.T) {
+	doc := newTestDoc(t)
+	var buf bytes.Buffer
+	require.NoError(t, WriteSVG(&buf, &doc.Component[0].Series, SVGOptions{}))
+
+	var svg struct {
+		Width     string `xml:"width,attr"`
+		Height    string `xml:"height,attr"`
+		Polylines []struct {
+			Points string `xml:"points,attr"`
+		} `xml:"polyline"`
+		Texts []string `xml:"text"`
+	}
+	require.NoError(t, xml.Unmarshal(buf.Bytes(), &svg))
+
+	// 4 samples at 500 Hz and 25 mm/s: 0.2 mm after the 10 mm margin
+	assert.Equal(t, "10.2mm", svg.Width)
+	assert.Equal(t, "60mm", svg.Height)
+	assert.Len(t, svg.Polylines, 2)
+	assert.ElementsMatch(t, []string{"I", "II"}, svg.Texts)
+
+	// Lead I in the first or second row: 1000 µV at 10 mm/mV is 10 mm above the baseline
+	points := svg.Polylines[0].Points + " " + svg.Polylines[1].Points
+	assert.Contains(t, points, "10.15,5")
+}
+
+// TestSVGOptions_Duration tests that the strip is cut to the requested duration
+func TestSVGOptions_Duration(t *testing.T) {
+	doc := newTestDoc(t)
+	var buf bytes.Buffer
+	opts := DefaultSVGOptions()
+	opts.Duration = 0.004
+	require.NoError(t, WriteSVG(&buf, &doc.Component[0].Series, opts))
+	assert.Contains(t, buf.String(), `width="10.1mm"`)
+	assert.Contains(t, buf.String(), `points="10,15 10.05,10"`, "2 samples of lead I")
+}
+
+// TestWriteSVG_Limits tests that out-of-range options and oversized strips
+// are rejected before anything is written
+func TestWriteSVG_Limits(t *testing.T) {
+	doc := newTestDoc(t)
+	tests := []struct {
+		name string
+		opts SVGOptions
+	}{
+		{"speed above the limit", SVGOptions{Speed: 250000}},
+		{"infinite speed", SVGOptions{Speed: math.Inf(1)}},
+		{"NaN gain", SVGOptions{Gain: math.NaN()}},
+		{"negative duration", SVGOptions{Duration: -1}},
+		{"duration beyond the recording", SVGOptions{Duration: 0.01}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			assert.ErrorIs(t, WriteSVG(&buf, &doc.Component[0].Series, tt.opts), ErrInvalidSVGOptions)
+			assert.Zero(t, buf.Len())
+		})
+	}
+
+	// At 100 mm/s, a minute is 6 m of paper and ten minutes exceed the limit
+	for _, tt := range []struct {
+		seconds int
+		wantErr bool
+	}{{60, false}, {600, true}} {
+		h := hl7aecg.NewHl7xml("").AddRhythmSeries("20240315101500.000", "", nil, nil, 500,
+			map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: make([]int, tt.seconds*500)}, 0, 5)
+		err := WriteSVG(io.Discard, &h.HL7AEcg.Component[0].Series, SVGOptions{Speed: MaxSVGSpeed})
+		if tt.wantErr {
+			assert.ErrorIs(t, err, ErrInvalidSVGOptions, "%d s", tt.seconds)
+		} else {
+			assert.NoError(t, err, "%d s", tt.seconds)
+		}
+	}
+}
@@ -0,0 +1,193 @@
+package export
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// SVG
+// =============================================================================
+
+// ErrInvalidSVGOptions is returned when SVG options are out of range or the
+// strip they describe is too large.
+var ErrInvalidSVGOptions = errors.New("export: invalid SVG options")
+
+// Limits of SVGOptions, which bound the size of the image.
+const (
+	MaxSVGSpeed     = 100.0   // mm/s
+	MaxSVGGain      = 100.0   // mm/mV
+	MaxSVGRowHeight = 100.0   // mm
+	MaxSVGWidth     = 20000.0 // mm, 800 s at 25 mm/s
+)
+
+// SVGOptions configures the ECG strip written by WriteSVG. Sizes are in
+// millimetres of paper.
+type SVGOptions struct {
+	// Speed is the paper speed in mm/s (25 by default, MaxSVGSpeed at most).
+	Speed float64
+
+	// Gain is the amplitude scale in mm/mV (10 by default, MaxSVGGain at
+	// most).
+	Gain float64
+
+	// RowHeight is the height of the row of each lead (30 by default,
+	// MaxSVGRowHeight at most).
+	RowHeight float64
+
+	// Duration limits the strip to the first seconds of the series, at most
+	// the length of the recording; zero draws the whole series.
+	Duration float64
+}
+
+// DefaultSVGOptions returns the standard paper settings: 25 mm/s, 10 mm/mV
+// and 30 mm per lead.
+func DefaultSVGOptions() SVGOptions {
+	return SVGOptions{Speed: 25, Gain: 10, RowHeight: 30}
+}
+
+// Validate checks that the options are finite, not negative and within the
+// limits. Zero fields stand for the defaults.
+func (o SVGOptions) Validate() error {
+	for _, f := range []struct {
+		name  string
+		value float64
+		max   float64
+	}{
+		{"speed", o.Speed, MaxSVGSpeed},
+		{"gain", o.Gain, MaxSVGGain},
+		{"row height", o.RowHeight, MaxSVGRowHeight},
+		{"duration", o.Duration, math.MaxFloat64},
+	} {
+		if math.IsNaN(f.value) || f.value < 0 || f.value > f.max {
+			return fmt.Errorf("%w: %s %v is not between 0 and %v", ErrInvalidSVGOptions, f.name, f.value, f.max)
+		}
+	}
+	return nil
+}
+
+// withDefaults fills the zero fields with the defaults.
+func (o SVGOptions) withDefaults() SVGOptions {
+	d := DefaultSVGOptions()
+	if o.Speed <= 0 {
+		o.Speed = d.Speed
+	}
+	if o.Gain <= 0 {
+		o.Gain = d.Gain
+	}
+	if o.RowHeight <= 0 {
+		o.RowHeight = d.RowHeight
+	}
+	return o
+}
+
+const (
+	svgMargin    = 10.0 // Left margin holding the lead labels, in mm
+	svgGridMinor = "#f7c6c6"
+	svgGridMajor = "#e88f8f"
+)
+
+// WriteSVG draws the waveforms of a series on ECG paper, one lead per row.
+//
+// The image is sized in millimetres, so that it prints at the configured
+// paper speed and gain: a 1 mm minor grid and a 5 mm major grid, the lead
+// label at the left of each row and the baseline at its middle.
+//
+// Returns ErrInvalidSVGOptions if the options are out of range, the duration
+// exceeds the recording, or the strip is wider than MaxSVGWidth.
+func WriteSVG(w io.Writer, series *types.Series, opts SVGOptions) error {
+	if err := opts.Validate(); err != nil {
+		return err
+	}
+	wf, err := decode(series)
+	if err != nil {
+		return err
+	}
+	opts = opts.withDefaults()
+
+	samples := wf.samples
+	if length := float64(samples) / wf.rate; opts.Duration > length {
+		return fmt.Errorf("%w: duration %v s exceeds the %v s recording", ErrInvalidSVGOptions, opts.Duration, length)
+	}
+	if opts.Duration > 0 {
+		samples = min(samples, int(opts.Duration*wf.rate))
+	}
+	width := svgMargin + float64(samples)/wf.rate*opts.Speed
+	height := float64(len(wf.leads)) * opts.RowHeight
+	if width > MaxSVGWidth {
+		return fmt.Errorf("%w: %s mm strip wider than %v mm, set a shorter duration",
+			ErrInvalidSVGOptions, formatMM(width), MaxSVGWidth)
+	}
+
+	bw := bufio.NewWriter(w)
+	fmt.Fprintf(bw, `<svg xmlns="http://www.w3.org/2000/svg" width="%smm" height="%smm" viewBox="0 0 %s %s">`+"\n",
+		formatMM(width), formatMM(height), formatMM(width), formatMM(height))
+	fmt.Fprintf(bw, `<rect width="%s" height="%s" fill="#ffffff"/>`+"\n", formatMM(width), formatMM(height))
+	writeGrid(bw, width, height)
+
+	mmPerSample := opts.Speed / wf.rate
+	mmPerMicrovolt := opts.Gain / 1000
+	for i, lead := range wf.leads {
+		baseline := (float64(i) + 0.5) * opts.RowHeight
+		fmt.Fprintf(bw, `<text x="1" y="%s" font-family="sans-serif" font-size="3.5">%s</text>`+"\n",
+			formatMM(baseline-opts.RowHeight/4), leadLabel(lead))
+
+		values := wf.values[i][:min(samples, len(wf.values[i]))]
+		if len(values) == 0 {
+			continue
+		}
+		bw.WriteString(`<polyline fill="none" stroke="#000000" stroke-width="0.25" stroke-linejoin="round" points="`)
+		for j, v := range values {
+			if j > 0 {
+				bw.WriteByte(' ')
+			}
+			bw.WriteString(formatMM(svgMargin + float64(j)*mmPerSample))
+			bw.WriteByte(',')
+			bw.WriteString(formatMM(baseline - v*mmPerMicrovolt))
+		}
+		bw.WriteString("\"/>\n")
+	}
+
+	bw.WriteString("</svg>\n")
+	return bw.Flush()
+}
+
+// writeGrid draws the 1 mm and 5 mm grid lines.
+func writeGrid(w *bufio.Writer, width, height float64) {
+	for _, major := range []bool{false, true} {
+		color, stroke := svgGridMinor, "0.1"
+		if major {
+			color, stroke = svgGridMajor, "0.2"
+		}
+		var path strings.Builder
+		for x := 0; float64(x) <= width; x++ {
+			if (x%5 == 0) == major {
+				fmt.Fprintf(&path, "M%d 0V%s", x, formatMM(height))
+			}
+		}
+		for y := 0; float64(y) <= height; y++ {
+			if (y%5 == 0) == major {
+				fmt.Fprintf(&path, "M0 %dH%s", y, formatMM(width))
+			}
+		}
+		fmt.Fprintf(w, `<path d="%s" stroke="%s" stroke-width="%s"/>`+"\n", path.String(), color, stroke)
+	}
+}
+
+// leadLabel returns the short name of a lead (e.g. "V2" for MDC_ECG_LEAD_V2).
+func leadLabel(lead types.LeadCode) string {
+	return strings.TrimPrefix(string(lead), "MDC_ECG_LEAD_")
+}
+
+// formatMM formats a length in mm to a hundredth of a millimetre.
+func formatMM(mm float64) string {
+	s := fmt.Sprintf("%.2f", mm)
+	s = strings.TrimRight(s, "0")
+	return strings.TrimSuffix(s, ".")
+}
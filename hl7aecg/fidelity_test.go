@@ -0,0 +1,206 @@
+package hl7aecg
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// fidelityNode is an element of a document in canonical form.
+type fidelityNode struct {
+	name     string
+	attrs    []string
+	text     string
+	children []*fidelityNode
+}
+
+// empty reports whether the element holds nothing.
+func (n *fidelityNode) empty() bool {
+	return len(n.attrs) == 0 && n.text == "" && len(n.children) == 0
+}
+
+// write appends the element to b, one line per element, attribute and text.
+func (n *fidelityNode) write(b *strings.Builder, indent string) {
+	b.WriteString(indent + n.name + "\n")
+	for _, attr := range n.attrs {
+		b.WriteString(indent + "  @" + attr + "\n")
+	}
+	if n.text != "" {
+		b.WriteString(indent + "  " + n.text + "\n")
+	}
+	for _, c := range n.children {
+		c.write(b, indent+"  ")
+	}
+}
+
+// omittedDefaults are elements written only when they differ from their
+// default value, mapped to that value.
+var omittedDefaults = map[string]string{
+	"{urn:hl7-org:v3}Paced": "false",
+}
+
+// canonicalXML returns the element tree of a document in a canonical form,
+// one line per element, attribute and text, in which:
+//   - names are namespace-resolved, and namespace declarations dropped
+//   - attributes are sorted, and attributes with an empty value dropped
+//   - whitespace in text is collapsed
+//   - empty elements and elements set to their default value (see
+//     omittedDefaults) are dropped
+//   - children are sorted, as unmodeled elements are written after the
+//     modeled ones
+//
+// Comments, processing instructions and directives are ignored.
+func canonicalXML(t *testing.T, data []byte) string {
+	t.Helper()
+	name := func(n xml.Name) string {
+		if n.Space == "" {
+			return n.Local
+		}
+		return "{" + n.Space + "}" + n.Local
+	}
+	var root *fidelityNode
+	var stack []*fidelityNode
+	var text []string
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("canonicalXML() error = %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			n := &fidelityNode{name: name(tok.Name)}
+			for _, attr := range tok.Attr {
+				if attr.Name.Space == "xmlns" || (attr.Name.Space == "" && attr.Name.Local == "xmlns") || attr.Value == "" {
+					continue
+				}
+				n.attrs = append(n.attrs, name(attr.Name)+"="+attr.Value)
+			}
+			slices.Sort(n.attrs)
+			stack = append(stack, n)
+			text = append(text, "")
+		case xml.CharData:
+			if len(text) > 0 {
+				text[len(text)-1] += string(tok)
+			}
+		case xml.EndElement:
+			n := stack[len(stack)-1]
+			n.text = strings.Join(strings.Fields(text[len(text)-1]), " ")
+			stack, text = stack[:len(stack)-1], text[:len(text)-1]
+			slices.SortFunc(n.children, func(a, b *fidelityNode) int {
+				var sa, sb strings.Builder
+				a.write(&sa, "")
+				b.write(&sb, "")
+				return strings.Compare(sa.String(), sb.String())
+			})
+			switch {
+			case len(stack) == 0:
+				root = n
+			case !n.empty() && !(len(n.attrs) == 0 && len(n.children) == 0 && omittedDefaults[n.name] == n.text):
+				parent := stack[len(stack)-1]
+				parent.children = append(parent.children, n)
+			}
+		}
+	}
+	var b strings.Builder
+	root.write(&b, "")
+	return b.String()
+}
+
+// extensionPositions returns, for each element of a document outside the
+// HL7 namespace, its path and the name of the sibling it follows ("" for
+// the first child), in document order.
+func extensionPositions(t *testing.T, data []byte) []string {
+	t.Helper()
+	type level struct{ path, last string }
+	var positions []string
+	stack := []level{{}}
+	d := xml.NewDecoder(bytes.NewReader(data))
+	for {
+		tok, err := d.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("extensionPositions() error = %v", err)
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			parent := &stack[len(stack)-1]
+			if len(stack) > 1 && tok.Name.Space != types.NAMESPACE_HL7 {
+				positions = append(positions, parent.path+"/"+tok.Name.Local+" after "+parent.last)
+			}
+			parent.last = tok.Name.Local
+			stack = append(stack, level{path: parent.path + "/" + tok.Name.Local})
+		case xml.EndElement:
+			stack = stack[:len(stack)-1]
+		}
+	}
+	return positions
+}
+
+// firstDifference returns the first line differing between two texts.
+func firstDifference(want, got string) string {
+	wl, gl := strings.Split(want, "\n"), strings.Split(got, "\n")
+	for i := 0; i < min(len(wl), len(gl)); i++ {
+		if wl[i] != gl[i] {
+			return "line " + wl[i] + " became " + gl[i]
+		}
+	}
+	return "lengths differ"
+}
+
+// TestFidelity_RoundTrip tests that documents read and written again keep
+// their content, including the elements and attributes the types do not model
+func TestFidelity_RoundTrip(t *testing.T) {
+	files, err := filepath.Glob("testdata/fidelity/*.xml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	files = append(files, "../exemple/hl7aecg_example.xml")
+
+	for _, file := range files {
+		t.Run(filepath.Base(file), func(t *testing.T) {
+			data, err := os.ReadFile(file)
+			if err != nil {
+				t.Fatal(err)
+			}
+			h := NewHl7xml("").SetComplianceProfile(types.COMPLIANCE_LEGACY)
+			if err := h.Unmarshal(data); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			out, err := h.Marshal()
+			if err != nil {
+				t.Fatalf("Marshal() error = %v", err)
+			}
+
+			want, got := canonicalXML(t, data), canonicalXML(t, out)
+			if want != got {
+				t.Errorf("round-trip changed the document: %s\n%s", firstDifference(want, got), out)
+			}
+
+			wantPos, gotPos := extensionPositions(t, data), extensionPositions(t, out)
+			if !slices.Equal(wantPos, gotPos) {
+				t.Errorf("round-trip moved unmodeled elements: got %q, want %q", gotPos, wantPos)
+			}
+
+			back := NewHl7xml("")
+			if err := back.Unmarshal(out); err != nil {
+				t.Fatalf("Unmarshal(Marshal()) error = %v", err)
+			}
+			if !back.HL7AEcg.Equal(&h.HL7AEcg) {
+				t.Errorf("Unmarshal(Marshal()) is not Equal to the document read")
+			}
+		})
+	}
+}
@@ -0,0 +1,391 @@
+// Package ingest watches a drop folder for aECG documents, validates them and
+// hands the valid ones to a Sink.
+//
+// The folder is polled; a file is processed once its size and modification
+// time are unchanged between two polls, so that files still being copied
+// (typically by an ECG cart onto a network share) are left alone. Each file
+// is then moved to the accepted or rejected folder with a sidecar report
+// (<file>.report.json).
+//
+// Processing is idempotent: the IDs (root^extension) of delivered documents
+// are recorded in a ledger, and a document whose ID was already delivered is
+// moved to the accepted folder with the "duplicate" status, without calling
+// the sink again. A document is recorded only after the sink succeeded; when
+// the sink fails, the file is left in place and retried at the next poll.
+//
+// Example:
+//
+//	w, err := ingest.New("/mnt/share/ecg", ingest.Options{
+//	    Sink: ingest.WebhookSink{URL: "https://example.org/ecg"},
+//	})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	err = w.Run(ctx) // until ctx is done
+package ingest
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// Defaults of Options.
+const (
+	DefaultInterval = 5 * time.Second
+	DefaultLedger   = ".ingest-ledger.jsonl"
+)
+
+// =============================================================================
+// Options
+// =============================================================================
+
+// Options configures a Watcher.
+type Options struct {
+	// Sink receives the valid documents. Required.
+	Sink Sink
+
+	// Interval is the polling period (DefaultInterval by default).
+	Interval time.Duration
+
+	// Extensions are the file extensions processed, case-insensitive
+	// (".xml" by default). Hidden files are always skipped.
+	Extensions []string
+
+	// Convert, if set, converts the content of each file to HL7 aECG XML
+	// before it is parsed, for carts that export a vendor format (add its
+	// file extension to Extensions). The package reads HL7 aECG XML only.
+	// A conversion error rejects the file, with a ConvertErrorField issue.
+	Convert func(name string, data []byte) ([]byte, error)
+
+	// AcceptedDir and RejectedDir receive the processed files ("accepted"
+	// and "rejected" under the watched folder by default).
+	AcceptedDir string
+	RejectedDir string
+
+	// Ledger is the file recording the delivered IDs (DefaultLedger under
+	// the watched folder by default).
+	Ledger string
+
+	// Registry is passed to hl7aecg.NewReader for every file.
+	Registry *types.TrialRegistry
+
+	// OnEvent, if set, is called with the outcome of each file, for logging.
+	OnEvent func(Event)
+}
+
+// withDefaults fills the zero fields with the defaults for folder dir.
+func (o Options) withDefaults(dir string) Options {
+	if o.Interval <= 0 {
+		o.Interval = DefaultInterval
+	}
+	if len(o.Extensions) == 0 {
+		o.Extensions = []string{".xml"}
+	}
+	exts := make([]string, len(o.Extensions))
+	for i, ext := range o.Extensions {
+		exts[i] = strings.ToLower(ext)
+	}
+	o.Extensions = exts
+	if o.AcceptedDir == "" {
+		o.AcceptedDir = filepath.Join(dir, "accepted")
+	}
+	if o.RejectedDir == "" {
+		o.RejectedDir = filepath.Join(dir, "rejected")
+	}
+	if o.Ledger == "" {
+		o.Ledger = filepath.Join(dir, DefaultLedger)
+	}
+	return o
+}
+
+// =============================================================================
+// Events
+// =============================================================================
+
+// Status is the outcome of a file.
+type Status string
+
+const (
+	// StatusAccepted: valid, delivered to the sink and moved to accepted.
+	StatusAccepted Status = "accepted"
+
+	// StatusDuplicate: valid, already delivered; moved to accepted.
+	StatusDuplicate Status = "duplicate"
+
+	// StatusRejected: invalid, not aECG XML or not converted; moved to
+	// rejected.
+	StatusRejected Status = "rejected"
+
+	// StatusFailed: I/O or sink error; the file is left in place and
+	// retried at the next poll.
+	StatusFailed Status = "failed"
+)
+
+// Event reports the outcome of a file.
+type Event struct {
+	// Path is the path of the file in the watched folder, or of the folder
+	// itself when it could not be listed.
+	Path   string
+	ID     string
+	Status Status
+
+	// Dest is the path the file was moved to (empty if StatusFailed).
+	Dest   string
+	Report *types.ValidationReport
+	Err    error
+}
+
+// sidecar is the content of a <file>.report.json sidecar.
+type sidecar struct {
+	File     string                  `json:"file"`
+	ID       string                  `json:"id"`
+	Status   Status                  `json:"status"`
+	Received time.Time               `json:"received"`
+	Report   *types.ValidationReport `json:"report"`
+}
+
+// =============================================================================
+// Watcher
+// =============================================================================
+
+// ErrNoSink is returned by New when Options.Sink is nil.
+var ErrNoSink = errors.New("ingest: no sink")
+
+// ConvertErrorField is the field of the validation issue reporting a file
+// that Options.Convert could not convert.
+const ConvertErrorField = "convert"
+
+// stamp identifies a version of a file, to detect files still being written.
+type stamp struct {
+	size    int64
+	modTime time.Time
+}
+
+// Watcher processes the documents dropped in a folder. It is not safe for
+// concurrent use.
+type Watcher struct {
+	dir    string
+	opts   Options
+	ledger *ledger
+
+	// seen holds the files of the last poll that are not processed yet
+	seen map[string]stamp
+}
+
+// New returns a watcher of folder dir. It creates the accepted and rejected
+// folders and loads the ledger.
+func New(dir string, opts Options) (*Watcher, error) {
+	if opts.Sink == nil {
+		return nil, ErrNoSink
+	}
+	opts = opts.withDefaults(dir)
+	for _, d := range []string{opts.AcceptedDir, opts.RejectedDir} {
+		if err := os.MkdirAll(d, 0o755); err != nil {
+			return nil, err
+		}
+	}
+	l, err := openLedger(opts.Ledger)
+	if err != nil {
+		return nil, err
+	}
+	return &Watcher{dir: dir, opts: opts, ledger: l, seen: map[string]stamp{}}, nil
+}
+
+// Run polls the folder every Options.Interval until ctx is done, and
+// returns the context error. Errors listing the folder are reported as
+// StatusFailed events, and polling goes on.
+func (w *Watcher) Run(ctx context.Context) error {
+	ticker := time.NewTicker(w.opts.Interval)
+	defer ticker.Stop()
+	for {
+		if err := w.Poll(ctx); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			w.emit(Event{Path: w.dir, Status: StatusFailed, Err: err})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists the folder once and processes the files unchanged since the
+// previous poll. It returns an error if the folder cannot be listed or ctx
+// is done; the outcome of each file is reported through Options.OnEvent.
+func (w *Watcher) Poll(ctx context.Context) error {
+	entries, err := os.ReadDir(w.dir)
+	if err != nil {
+		return err
+	}
+
+	seen := make(map[string]stamp, len(w.seen))
+	for _, entry := range entries {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		name := entry.Name()
+		if !entry.Type().IsRegular() || strings.HasPrefix(name, ".") ||
+			!slices.Contains(w.opts.Extensions, strings.ToLower(filepath.Ext(name))) {
+			continue
+		}
+		info, err := entry.Info()
+		if err != nil {
+			continue // Removed since listed
+		}
+
+		path := filepath.Join(w.dir, name)
+		cur := stamp{size: info.Size(), modTime: info.ModTime()}
+		if prev, ok := w.seen[path]; !ok || prev != cur {
+			seen[path] = cur // New or still being written
+			continue
+		}
+		if err := w.process(ctx, path); err != nil {
+			return err
+		}
+	}
+	w.seen = seen
+	return nil
+}
+
+// process validates, delivers and moves one file. It returns an error only
+// if ctx is done.
+func (w *Watcher) process(ctx context.Context, path string) error {
+	received := time.Now().UTC()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if !errors.Is(err, fs.ErrNotExist) {
+			w.emit(Event{Path: path, Status: StatusFailed, Err: err})
+		}
+		return nil
+	}
+
+	h := hl7aecg.NewReader(ctx, w.opts.Registry)
+	var report *types.ValidationReport
+	if w.opts.Convert != nil {
+		if data, err = w.opts.Convert(filepath.Base(path), data); err != nil {
+			report = &types.ValidationReport{
+				Errors:   []types.ValidationIssue{{Field: ConvertErrorField, Message: err.Error()}},
+				Warnings: []string{},
+			}
+		}
+	}
+	if report == nil {
+		if report, err = h.UnmarshalAndReport(data); err != nil {
+			return err
+		}
+	}
+
+	doc := &Document{
+		ID:       documentID(&h.HL7AEcg, data),
+		File:     filepath.Base(path),
+		Received: received,
+		Data:     data,
+		AECG:     &h.HL7AEcg,
+		Report:   report,
+	}
+	event := Event{Path: path, ID: doc.ID, Report: report}
+
+	switch {
+	case !report.Valid:
+		event.Status = StatusRejected
+	case w.ledger.has(doc.ID):
+		event.Status = StatusDuplicate
+	default:
+		doc.Summary = h.HL7AEcg.Summary()
+		if err := w.opts.Sink.Deliver(ctx, doc); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			event.Status, event.Err = StatusFailed, fmt.Errorf("deliver: %w", err)
+			w.emit(event)
+			return nil
+		}
+		if err := w.ledger.record(doc.ID, doc.File, received); err != nil {
+			// Delivered, but a later copy would be delivered again
+			event.Status, event.Err = StatusFailed, fmt.Errorf("ledger: %w", err)
+			w.emit(event)
+			return nil
+		}
+		event.Status = StatusAccepted
+	}
+
+	dir := w.opts.AcceptedDir
+	if event.Status == StatusRejected {
+		dir = w.opts.RejectedDir
+	}
+	event.Dest, event.Err = moveWithSidecar(path, dir, sidecar{
+		File:     doc.File,
+		ID:       doc.ID,
+		Status:   event.Status,
+		Received: received,
+		Report:   report,
+	})
+	if event.Err != nil {
+		// Left in place: a delivered document comes back as a duplicate
+		event.Status = StatusFailed
+	}
+	w.emit(event)
+	return nil
+}
+
+// emit reports an event to Options.OnEvent.
+func (w *Watcher) emit(e Event) {
+	if w.opts.OnEvent != nil {
+		w.opts.OnEvent(e)
+	}
+}
+
+// documentID returns the ID of a document (root^extension), or the SHA-256
+// of its content when it has none (e.g. it could not be parsed).
+func documentID(doc *types.HL7AEcg, data []byte) string {
+	if doc.ID != nil && !doc.ID.IsEmpty() {
+		return doc.ID.String()
+	}
+	sum := sha256.Sum256(data)
+	return "sha256:" + hex.EncodeToString(sum[:])
+}
+
+// moveWithSidecar moves the file at path to dir, renaming it if a file of
+// the same name exists, and writes its sidecar report next to it. It returns
+// the new path.
+func moveWithSidecar(path, dir string, report sidecar) (string, error) {
+	name := filepath.Base(path)
+	ext := filepath.Ext(name)
+	dest := filepath.Join(dir, name)
+	for i := 1; ; i++ {
+		if _, err := os.Lstat(dest); errors.Is(err, fs.ErrNotExist) {
+			break
+		}
+		dest = filepath.Join(dir, fmt.Sprintf("%s-%d%s", strings.TrimSuffix(name, ext), i, ext))
+	}
+
+	// The sidecar first: a file moved without one would look unprocessed
+	data, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	if err := os.WriteFile(dest+".report.json", append(data, '\n'), 0o644); err != nil {
+		return "", err
+	}
+	if err := os.Rename(path, dest); err != nil {
+		os.Remove(dest + ".report.json")
+		return "", err
+	}
+	return dest, nil
+}
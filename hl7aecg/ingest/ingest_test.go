@@ -0,0 +1,303 @@
+package ingest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+)
+
+// recorder is a sink recording the delivered IDs, failing while err is set.
+type recorder struct {
+	ids []string
+	err error
+}
+
+func (r *recorder) Deliver(ctx context.Context, doc *Document) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.ids = append(r.ids, doc.ID)
+	return nil
+}
+
+// newWatcher returns a watcher of a new folder delivering to sink, and the
+// events it reports.
+func newWatcher(t *testing.T, sink Sink) (string, *Watcher, *[]Event) {
+	t.Helper()
+	dir := t.TempDir()
+	var events []Event
+	w, err := New(dir, Options{Sink: sink, OnEvent: func(e Event) { events = append(events, e) }})
+	require.NoError(t, err)
+	return dir, w, &events
+}
+
+// poll polls twice, so that the files dropped before are processed.
+func poll(t *testing.T, w *Watcher) {
+	t.Helper()
+	require.NoError(t, w.Poll(context.Background()))
+	require.NoError(t, w.Poll(context.Background()))
+}
+
+// readSidecar returns the sidecar of a moved file.
+func readSidecar(t *testing.T, path string) sidecar {
+	t.Helper()
+	data, err := os.ReadFile(path + ".report.json")
+	require.NoError(t, err)
+	var s sidecar
+	require.NoError(t, json.Unmarshal(data, &s))
+	return s
+}
+
+// TestWatcher_Poll tests the accepted, rejected and skipped files
+func TestWatcher_Poll(t *testing.T) {
+	sink := &recorder{}
+	dir, w, events := newWatcher(t, sink)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"}), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.XML"), testdoc.Marshal(t, testdoc.Doc{ID: "ECG_B", Invalid: true}), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "c.xml"), []byte("<AnnotatedECG"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.txt"), []byte("not an ECG"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, ".partial.xml"), []byte("<"), 0o644))
+
+	require.NoError(t, w.Poll(context.Background()))
+	assert.Empty(t, *events, "files are processed once unchanged between two polls")
+
+	require.NoError(t, w.Poll(context.Background()))
+	require.Len(t, *events, 3)
+	assert.Equal(t, []string{testdoc.Root + "^ECG_A"}, sink.ids)
+
+	accepted := filepath.Join(dir, "accepted", "a.xml")
+	assert.FileExists(t, accepted)
+	s := readSidecar(t, accepted)
+	assert.Equal(t, StatusAccepted, s.Status)
+	assert.Equal(t, testdoc.Root+"^ECG_A", s.ID)
+	assert.True(t, s.Report.Valid)
+
+	s = readSidecar(t, filepath.Join(dir, "rejected", "b.XML"))
+	assert.Equal(t, StatusRejected, s.Status)
+	assert.Contains(t, s.Report.Rules(), "Time")
+
+	s = readSidecar(t, filepath.Join(dir, "rejected", "c.xml"))
+	assert.Equal(t, "xml", s.Report.Errors[0].Field)
+	assert.Contains(t, s.ID, "sha256:")
+
+	assert.FileExists(t, filepath.Join(dir, "notes.txt"))
+	assert.FileExists(t, filepath.Join(dir, ".partial.xml"))
+}
+
+// TestWatcher_Growing tests that a file still being written is not processed
+func TestWatcher_Growing(t *testing.T) {
+	sink := &recorder{}
+	dir, w, _ := newWatcher(t, sink)
+	data := testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"})
+	path := filepath.Join(dir, "a.xml")
+
+	require.NoError(t, os.WriteFile(path, data[:100], 0o644))
+	require.NoError(t, w.Poll(context.Background()))
+	require.NoError(t, os.WriteFile(path, data, 0o644))
+	require.NoError(t, w.Poll(context.Background()))
+	assert.Empty(t, sink.ids)
+	assert.FileExists(t, path)
+
+	require.NoError(t, w.Poll(context.Background()))
+	assert.Len(t, sink.ids, 1)
+}
+
+// TestWatcher_Idempotent tests duplicates, within a run and across restarts
+func TestWatcher_Idempotent(t *testing.T) {
+	sink := &recorder{}
+	dir, w, events := newWatcher(t, sink)
+	data := testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"})
+
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), data, 0o644))
+	poll(t, w)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), data, 0o644))
+	poll(t, w)
+	assert.Len(t, sink.ids, 1)
+	assert.Equal(t, StatusDuplicate, (*events)[1].Status)
+	assert.Equal(t, filepath.Join(dir, "accepted", "a-1.xml"), (*events)[1].Dest)
+
+	// A new watcher loads the ledger
+	w, err := New(dir, Options{Sink: sink})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "copy.xml"), data, 0o644))
+	poll(t, w)
+	assert.Len(t, sink.ids, 1)
+	assert.Equal(t, StatusDuplicate, readSidecar(t, filepath.Join(dir, "accepted", "copy.xml")).Status)
+}
+
+// TestWatcher_SinkError tests that a failed delivery is retried
+func TestWatcher_SinkError(t *testing.T) {
+	sink := &recorder{err: errors.New("database down")}
+	dir, w, events := newWatcher(t, sink)
+	path := filepath.Join(dir, "a.xml")
+	require.NoError(t, os.WriteFile(path, testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"}), 0o644))
+
+	poll(t, w)
+	require.Len(t, *events, 1)
+	assert.Equal(t, StatusFailed, (*events)[0].Status)
+	assert.ErrorContains(t, (*events)[0].Err, "database down")
+	assert.FileExists(t, path)
+
+	sink.err = nil
+	poll(t, w)
+	assert.Equal(t, []string{testdoc.Root + "^ECG_A"}, sink.ids)
+	assert.NoFileExists(t, path)
+}
+
+// TestWatcher_Run tests that Run polls until the context is done
+func TestWatcher_Run(t *testing.T) {
+	dir := t.TempDir()
+	delivered := make(chan string, 1)
+	w, err := New(dir, Options{
+		Interval: 10 * time.Millisecond,
+		Sink: SinkFunc(func(ctx context.Context, doc *Document) error {
+			delivered <- doc.ID
+			return nil
+		}),
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"}), 0o644))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error)
+	go func() { done <- w.Run(ctx) }()
+	select {
+	case id := <-delivered:
+		assert.Equal(t, testdoc.Root+"^ECG_A", id)
+	case <-time.After(5 * time.Second):
+		t.Fatal("document not delivered")
+	}
+	cancel()
+	assert.ErrorIs(t, <-done, context.Canceled)
+}
+
+// TestNew_Errors tests the missing sink and a corrupt ledger
+func TestNew_Errors(t *testing.T) {
+	_, err := New(t.TempDir(), Options{})
+	assert.ErrorIs(t, err, ErrNoSink)
+
+	dir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(dir, DefaultLedger), []byte("{\n"), 0o644))
+	_, err = New(dir, Options{Sink: &recorder{}})
+	assert.ErrorContains(t, err, "ledger")
+}
+
+// TestSinks tests the folder, index and webhook sinks
+func TestSinks(t *testing.T) {
+	var notification Notification
+	var auth string
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		auth = r.Header.Get("Authorization")
+		json.NewDecoder(r.Body).Decode(&notification)
+	}))
+	defer webhook.Close()
+
+	out := t.TempDir()
+	index := filepath.Join(out, "index.jsonl")
+	sink := MultiSink{
+		DirSink{Dir: out},
+		IndexSink{Path: index},
+		WebhookSink{URL: webhook.URL, Header: http.Header{"Authorization": {"Bearer secret"}}},
+	}
+	dir, w, _ := newWatcher(t, sink)
+	data := testdoc.Marshal(t, testdoc.Doc{ID: "ECG_A"})
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.xml"), data, 0o644))
+	poll(t, w)
+
+	copied, err := os.ReadFile(filepath.Join(out, fileName(testdoc.Root+"^ECG_A")))
+	require.NoError(t, err)
+	assert.Equal(t, data, copied)
+
+	line, err := os.ReadFile(index)
+	require.NoError(t, err)
+	var record Notification
+	require.NoError(t, json.Unmarshal(line, &record))
+	assert.Equal(t, testdoc.Root+"^ECG_A", record.ID)
+	assert.Len(t, record.Summary.Series, 1)
+
+	assert.Equal(t, "Bearer secret", auth)
+	assert.Equal(t, "a.xml", notification.File)
+	assert.True(t, notification.Report.Valid)
+	assert.Empty(t, notification.Document)
+}
+
+// TestWebhookSink_Status tests that non-2xx answers are errors
+func TestWebhookSink_Status(t *testing.T) {
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "nope", http.StatusBadGateway)
+	}))
+	defer webhook.Close()
+
+	err := WebhookSink{URL: webhook.URL}.Deliver(context.Background(), &Document{ID: "x"})
+	assert.ErrorIs(t, err, ErrWebhookStatus)
+}
+
+// TestWebhookSink_Timeout tests that an endpoint that does not answer fails
+// the delivery
+func TestWebhookSink_Timeout(t *testing.T) {
+	release := make(chan struct{})
+	webhook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+	}))
+	defer webhook.Close()
+	defer close(release)
+
+	err := WebhookSink{URL: webhook.URL, Timeout: 50 * time.Millisecond}.Deliver(context.Background(), &Document{ID: "x"})
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+// TestDirSink_Names tests that IDs differing only by unsafe characters or
+// case get different files
+func TestDirSink_Names(t *testing.T) {
+	out := t.TempDir()
+	sink := DirSink{Dir: out}
+	ids := []string{"1.2^a/b", "1.2^a_b", "1.2^A_B"}
+	for _, id := range ids {
+		require.NoError(t, sink.Deliver(context.Background(), &Document{ID: id, Data: []byte(id)}))
+	}
+	assert.Regexp(t, `^1\.2_a_b\.[0-9a-f]{12}\.xml$`, fileName(ids[0]))
+	for _, id := range ids {
+		data, err := os.ReadFile(filepath.Join(out, fileName(id)))
+		require.NoError(t, err)
+		assert.Equal(t, id, string(data))
+	}
+}
+
+// TestWatcher_Convert tests that converted files are parsed, and that files
+// failing conversion are rejected
+func TestWatcher_Convert(t *testing.T) {
+	sink := &recorder{}
+	dir := t.TempDir()
+	w, err := New(dir, Options{
+		Sink:       sink,
+		Extensions: []string{".ecg"},
+		Convert: func(name string, data []byte) ([]byte, error) {
+			if string(data) != "vendor" {
+				return nil, errors.New("unknown format")
+			}
+			return testdoc.Marshal(t, testdoc.Doc{ID: "ECG_" + name}), nil
+		},
+	})
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "a.ecg"), []byte("vendor"), 0o644))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "b.ecg"), []byte("other"), 0o644))
+	poll(t, w)
+
+	assert.Equal(t, []string{testdoc.Root + "^ECG_a.ecg"}, sink.ids)
+	assert.Equal(t, StatusAccepted, readSidecar(t, filepath.Join(dir, "accepted", "a.ecg")).Status)
+	s := readSidecar(t, filepath.Join(dir, "rejected", "b.ecg"))
+	assert.Equal(t, StatusRejected, s.Status)
+	require.Len(t, s.Report.Errors, 1)
+	assert.Equal(t, ConvertErrorField, s.Report.Errors[0].Field)
+}
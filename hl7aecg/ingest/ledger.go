@@ -0,0 +1,85 @@
+package ingest
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+)
+
+// =============================================================================
+// Ledger
+// =============================================================================
+
+// ledgerEntry is one line of the ledger.
+type ledgerEntry struct {
+	ID       string    `json:"id"`
+	File     string    `json:"file"`
+	Received time.Time `json:"received"`
+}
+
+// ledger records the IDs of the delivered documents in a JSON-lines file,
+// so that they are delivered once across restarts.
+type ledger struct {
+	path string
+	ids  map[string]bool
+}
+
+// openLedger loads the ledger at path, or starts an empty one if the file
+// does not exist.
+func openLedger(path string) (*ledger, error) {
+	l := &ledger{path: path, ids: map[string]bool{}}
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return l, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry ledgerEntry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("ingest: ledger %s:%d: %w", path, line, err)
+		}
+		l.ids[entry.ID] = true
+	}
+	return l, scanner.Err()
+}
+
+// has reports whether id was delivered.
+func (l *ledger) has(id string) bool {
+	return l.ids[id]
+}
+
+// record appends id to the ledger and syncs it to disk.
+func (l *ledger) record(id, file string, received time.Time) error {
+	data, err := json.Marshal(ledgerEntry{ID: id, File: file, Received: received})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(l.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if err == nil {
+		err = f.Sync()
+	}
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+	l.ids[id] = true
+	return nil
+}
@@ -0,0 +1,227 @@
+package ingest
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Sinks
+// =============================================================================
+
+// Document is a valid document handed to a Sink.
+type Document struct {
+	// ID is the document ID (root^extension), the key of idempotency.
+	ID string
+
+	// File is the name of the file in the watched folder.
+	File     string
+	Received time.Time
+
+	// Data is the XML as dropped, or as converted by Options.Convert.
+	Data []byte
+
+	AECG    *types.HL7AEcg
+	Report  *types.ValidationReport
+	Summary *types.Summary
+}
+
+// Sink receives the valid documents. Deliver must not keep Document after
+// returning; an error leaves the file in place to be retried.
+type Sink interface {
+	Deliver(ctx context.Context, doc *Document) error
+}
+
+// SinkFunc adapts a function to the Sink interface.
+type SinkFunc func(ctx context.Context, doc *Document) error
+
+// Deliver calls f.
+func (f SinkFunc) Deliver(ctx context.Context, doc *Document) error {
+	return f(ctx, doc)
+}
+
+// MultiSink delivers to each sink in turn, stopping at the first error.
+// Sinks before the failing one will see the document again on retry.
+type MultiSink []Sink
+
+// Deliver delivers doc to each sink.
+func (m MultiSink) Deliver(ctx context.Context, doc *Document) error {
+	for _, sink := range m {
+		if err := sink.Deliver(ctx, doc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+// Filesystem
+// -----------------------------------------------------------------------------
+
+// DirSink copies the documents to a folder, named after their ID and a
+// hash of it (e.g. 2.16.840.1.113883.3.1_ECG_001.5c1e3f0a9b2d.xml), so that
+// IDs differing only by characters unsafe in file names, or by case, get
+// different files. A redelivered document overwrites its copy.
+type DirSink struct {
+	Dir string
+}
+
+// Deliver writes doc to the folder, through a temporary file so that
+// readers of the folder never see a partial document.
+func (s DirSink) Deliver(ctx context.Context, doc *Document) error {
+	tmp, err := os.CreateTemp(s.Dir, ".ingest-*")
+	if err != nil {
+		return err
+	}
+	_, err = tmp.Write(doc.Data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err == nil {
+		err = os.Rename(tmp.Name(), filepath.Join(s.Dir, fileName(doc.ID)))
+	}
+	if err != nil {
+		os.Remove(tmp.Name())
+	}
+	return err
+}
+
+// fileName returns the file name of the document id: id with the characters
+// unsafe in file names replaced by "_", then the first 12 hexadecimal digits
+// of the SHA-256 of id, which tell apart the IDs the replacement merges.
+func fileName(id string) string {
+	safe := strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z', r >= '0' && r <= '9', r == '.', r == '-', r == '_':
+			return r
+		}
+		return '_'
+	}, id)
+	sum := sha256.Sum256([]byte(id))
+	return safe + "." + hex.EncodeToString(sum[:6]) + ".xml"
+}
+
+// -----------------------------------------------------------------------------
+// Local database
+// -----------------------------------------------------------------------------
+
+// IndexSink appends one JSON line per document (see Notification, without
+// the report) to a file, a minimal local database of the received ECGs
+// that other tools can load.
+type IndexSink struct {
+	Path string
+}
+
+// Deliver appends the record of doc.
+func (s IndexSink) Deliver(ctx context.Context, doc *Document) error {
+	data, err := json.Marshal(Notification{ID: doc.ID, File: doc.File, Received: doc.Received, Summary: doc.Summary})
+	if err != nil {
+		return err
+	}
+	f, err := os.OpenFile(s.Path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0o644)
+	if err != nil {
+		return err
+	}
+	_, err = f.Write(append(data, '\n'))
+	if cerr := f.Close(); err == nil {
+		err = cerr
+	}
+	return err
+}
+
+// -----------------------------------------------------------------------------
+// HTTP webhook
+// -----------------------------------------------------------------------------
+
+// Notification is the JSON body posted by WebhookSink.
+type Notification struct {
+	ID       string                  `json:"id"`
+	File     string                  `json:"file"`
+	Received time.Time               `json:"received"`
+	Summary  *types.Summary          `json:"summary"`
+	Report   *types.ValidationReport `json:"report,omitempty"`
+
+	// Document is the XML, if WebhookSink.IncludeDocument is set.
+	Document string `json:"document,omitempty"`
+}
+
+// DefaultWebhookTimeout is the time limit of a webhook request when
+// WebhookSink.Timeout is not set.
+const DefaultWebhookTimeout = 30 * time.Second
+
+// ErrWebhookStatus is returned by WebhookSink when the endpoint does not
+// answer with a 2xx status.
+var ErrWebhookStatus = errors.New("ingest: webhook status")
+
+// WebhookSink posts a Notification per document to URL.
+type WebhookSink struct {
+	URL string
+
+	// Header is added to each request (e.g. Authorization).
+	Header http.Header
+
+	// IncludeDocument adds the XML to the notification.
+	IncludeDocument bool
+
+	// Client sends the requests (http.DefaultClient by default).
+	Client *http.Client
+
+	// Timeout limits each request, response included
+	// (DefaultWebhookTimeout by default), so that an endpoint that hangs
+	// fails the delivery instead of stopping the ingestion.
+	Timeout time.Duration
+}
+
+// Deliver posts the notification of doc.
+func (s WebhookSink) Deliver(ctx context.Context, doc *Document) error {
+	n := Notification{ID: doc.ID, File: doc.File, Received: doc.Received, Summary: doc.Summary, Report: doc.Report}
+	if s.IncludeDocument {
+		n.Document = string(doc.Data)
+	}
+	body, err := json.Marshal(n)
+	if err != nil {
+		return err
+	}
+	timeout := s.Timeout
+	if timeout <= 0 {
+		timeout = DefaultWebhookTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, s.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	for key, values := range s.Header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	client := s.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 1<<20))
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("%w: %s", ErrWebhookStatus, resp.Status)
+	}
+	return nil
+}
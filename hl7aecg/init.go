@@ -2,10 +2,9 @@ package hl7aecg
 
 import (
 	"context"
-	stdxml "encoding/xml"
+	"encoding/xml"
 	"os"
 
-	"github.com/ECUST-XX/xml"
 	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
 )
 
@@ -14,14 +13,17 @@ type Hl7xml struct {
 	ctx       context.Context
 	outputDir string
 	vctx      *types.ValidationContext
+	profile   types.ComplianceProfile
+	study     *StudySettings
+	encoding  SeriesEncoding
 }
 
 func NewHl7xml(outputDir string) *Hl7xml {
 	return &Hl7xml{
 		HL7AEcg: types.HL7AEcg{
-			XmlnsXsi:            "http://www.w3.org/2001/XMLSchema-instance",
-			XmlnsVoc:            "urn:hl7-org:v3/voc",
-			Xmlns:               "urn:hl7-org:v3",
+			XmlnsXsi:            types.NAMESPACE_XSI,
+			XmlnsVoc:            types.NAMESPACE_VOC,
+			Xmlns:               types.NAMESPACE_HL7,
 			ID:                  &types.ID{},
 			Code:                &types.Code[types.CPT_CODE, types.CodeSystemOID]{},
 			ConfidentialityCode: &types.Code[types.ConfidentialityCode, string]{},
@@ -30,9 +32,50 @@ func NewHl7xml(outputDir string) *Hl7xml {
 		ctx:       context.Background(),
 		outputDir: outputDir,
 		vctx:      types.NewValidationContext(true),
+		profile:   types.COMPLIANCE_STRICT,
 	}
 }
 
+// NewReader returns an empty document for parsing and validating received
+// files, as the batch validator, the HTTP server and the ingestion watcher
+// do: validation runs under ctx, and, if registry is not nil, cross-checks
+// the protocol and trial IDs against the registered ones, so that a file
+// from an unknown or mismatched study is reported (see SetTrialRegistry).
+//
+// Example:
+//
+//	h := hl7aecg.NewReader(ctx, registry)
+//	report, err := h.UnmarshalAndReport(data)
+func NewReader(ctx context.Context, registry *types.TrialRegistry) *Hl7xml {
+	return NewHl7xml("").SetContext(ctx).SetTrialRegistry(registry)
+}
+
+// SetContext sets the context checked by Validate, for cancellation and
+// deadlines. The default is context.Background().
+func (h *Hl7xml) SetContext(ctx context.Context) *Hl7xml {
+	h.ctx = ctx
+	return h
+}
+
+// SetComplianceProfile selects how vendor extensions on the subject
+// demographics (PatientID, Age, Medications, ...) are encoded.
+//
+// The default, types.COMPLIANCE_STRICT, maps them to standard constructs
+// or drops them so that the output passes strict schema validation.
+// types.COMPLIANCE_LEGACY writes them as-is.
+//
+// Decoding is not affected: legacy files are always read in full.
+func (h *Hl7xml) SetComplianceProfile(profile types.ComplianceProfile) *Hl7xml {
+	h.profile = profile
+	return h
+}
+
+// encodable returns the document to marshal under the compliance profile.
+func (h *Hl7xml) encodable() *types.HL7AEcg {
+	doc, _ := h.HL7AEcg.Compliant(h.profile)
+	return doc
+}
+
 // Initialize sets up the HL7 aECG document with the provided CPT code and code system OID.
 func (h *Hl7xml) Initialize(code types.CPT_CODE, codeSystem types.CodeSystemOID, codeSystemName, displayName string) *Hl7xml {
 	h.HL7AEcg.Code.SetCode(code, codeSystem, codeSystemName, displayName)
@@ -44,22 +87,34 @@ func (h *Hl7xml) Initialize(code types.CPT_CODE, codeSystem types.CodeSystemOID,
 	return h
 }
 
+// String returns the document as indented XML, encoded under the compliance
+// profile. It is the output of Marshal without the XML declaration.
 func (h *Hl7xml) String() (string, error) {
-	data, err := xml.MarshalIndentShortForm(h.HL7AEcg, "", "  ")
+	data, err := encode(h.encodable())
 	if err != nil {
 		return "", err
 	}
 	return string(data), nil
 }
 
+// Marshal returns the document as indented XML with an XML declaration,
+// encoded under the compliance profile.
+//
+// Elements are written in the urn:hl7-org:v3 default namespace declared by
+// the root, with the xsi prefix bound for xsi:type, and empty elements are
+// closed in short form. The output can be read back with Unmarshal.
+func (h *Hl7xml) Marshal() ([]byte, error) {
+	data, err := encode(h.encodable())
+	if err != nil {
+		return nil, err
+	}
+	return append([]byte(xml.Header), append(data, '\n')...), nil
+}
+
 func (h *Hl7xml) Test() (*Hl7xml, error) {
 	dir := "/tmp/hl7aecg_example.xml"
 
-	// DEBUG: Try standard Go XML marshalling to see if MarshalXML is called
-	// Use standard encoding/xml package instead of github.com/ECUST-XX/xml
-	data, err := stdxml.MarshalIndent(h.HL7AEcg, "", "  ")
-
-	//data, err := xml.MarshalIndentShortForm(h.HL7AEcg, "", "  ")
+	data, err := encode(h.encodable())
 	if err != nil {
 		return h, err
 	}
@@ -387,6 +387,18 @@ func TestContextCancellation(t *testing.T) {
 	}
 }
 
+// TestHl7xml_SetContext tests that Hl7xml.Validate returns the error of a cancelled context
+func TestHl7xml_SetContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	h := NewHl7xml("").SetContext(ctx)
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "")
+	if err := h.Validate(); err != context.Canceled {
+		t.Errorf("Validate() error = %v, want %v", err, context.Canceled)
+	}
+}
+
 // TestRootAttributes tests that Type and SchemaLocation attributes appear in XML output
 func TestRootAttributes(t *testing.T) {
 	tmpDir := t.TempDir()
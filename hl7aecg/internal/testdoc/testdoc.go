@@ -0,0 +1,93 @@
+// Package testdoc builds the aECG documents shared by the tests of the
+// packages built on hl7aecg (batch, server, ingest, deid, diff, export, qtc).
+// Tests change the fields they check on top of the document it builds.
+//
+// The tests of hl7aecg and types cannot use it, since it imports them.
+package testdoc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// Root is the root OID of the document, subject and site IDs.
+const Root = "2.16.840.1.113883.3.1"
+
+// Doc describes a test document.
+type Doc struct {
+	// ID is the document ID extension. Defaults to "ECG_1".
+	ID string
+
+	// Subject is the subject ID extension. Defaults to "S1".
+	Subject string
+
+	// Site is the site ID extension. The document has no location when empty.
+	Site string
+
+	// Low is the start of the effective time. Defaults to
+	// "20240315101500".
+	Low string
+
+	// Invalid gives the document a malformed effective time, so that it
+	// parses but fails validation.
+	Invalid bool
+
+	// Leads are the digits of the rhythm series, at 500 Hz and 5 µV per
+	// digit. Defaults to leads I and II with three samples each.
+	Leads map[types.LeadCode][]int
+}
+
+// New returns the document, with one rhythm series.
+func New(t testing.TB, d Doc) *hl7aecg.Hl7xml {
+	t.Helper()
+	if d.ID == "" {
+		d.ID = "ECG_1"
+	}
+	if d.Subject == "" {
+		d.Subject = "S1"
+	}
+	low := d.Low
+	if low == "" {
+		low = "20240315101500"
+	}
+	if d.Invalid {
+		low = "yesterday"
+	}
+	if d.Leads == nil {
+		d.Leads = map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {1, 2, 3}, types.MDC_ECG_LEAD_II: {4, 5, 6}}
+	}
+
+	h := hl7aecg.NewHl7xml("")
+	h.HL7AEcg.SetRootID(Root, "")
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "").
+		SetEffectiveTime(low, "20240315101510", nil, nil).
+		SetSubject(Root, d.Subject, types.SUBJECT_ROLE_ENROLLED)
+	if d.Site != "" {
+		h.SetLocation(d.Site, Root, "Clinic", "Nantes", "", "FRA")
+	}
+	h.AddRhythmSeries("20240315101500.000", "20240315101510.000", nil, nil, 500, d.Leads, 0, 5)
+	h.HL7AEcg.SetID(Root, d.ID)
+	h.AddConfidentialityCode(types.CONFIDENTIALITY_SPONSOR_BLINDED).AddReasonCode(types.REASON_PER_PROTOCOL)
+	return h
+}
+
+// Marshal returns the XML of the document built by New.
+func Marshal(t testing.TB, d Doc) []byte {
+	t.Helper()
+	data, err := New(t, d).Marshal()
+	require.NoError(t, err)
+	return data
+}
+
+// Write writes the XML of the document to path, creating its directory.
+func Write(t testing.TB, path string, d Doc) {
+	t.Helper()
+	require.NoError(t, os.MkdirAll(filepath.Dir(path), 0o755))
+	require.NoError(t, os.WriteFile(path, Marshal(t, d), 0o644))
+}
@@ -0,0 +1,80 @@
+package measure
+
+import (
+	"math"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Annotation Output
+// =============================================================================
+
+// MeasurementMatrixCode is the code of the lead annotations holding per-lead amplitudes.
+const MeasurementMatrixCode = "MEASUREMENT_MATRIX"
+
+// Annotate writes the measurements into an annotation set.
+//
+// The following annotations are added, in order:
+//   - Wave annotations (MDC_ECG_WAVC) for the P, QRS and T waves with their
+//     onset and offset in milliseconds
+//   - Global intervals: MDC_ECG_TIME_PD_PR, MDC_ECG_TIME_PD_QRS, MDC_ECG_TIME_PD_QT
+//   - Frontal axes: MDC_ECG_ANGLE_P_FRONT, MDC_ECG_ANGLE_QRS_FRONT, MDC_ECG_ANGLE_T_FRONT
+//   - One MEASUREMENT_MATRIX lead annotation per lead with nested
+//     MDC_ECG_AMPL_P, MDC_ECG_AMPL_QRS, MDC_ECG_AMPL_ST and MDC_ECG_AMPL_T values in µV
+//
+// Measurements depending on an absent wave are skipped. Values are rounded
+// to the nearest millisecond, degree and microvolt.
+func (r *Result) Annotate(as *types.AnnotationSet, opts Options) {
+	if r == nil || as == nil {
+		return
+	}
+	opts = opts.withDefaults()
+
+	// Fiducial points
+	if r.HasP {
+		as.AddWaveAnnotation(types.MDC_ECG_WAVC_PWAVE, math.Round(r.POnset), math.Round(r.POffset), types.UNIT_MILLISECOND)
+	}
+	as.AddWaveAnnotation(types.MDC_ECG_WAVC_QRSWAVE, math.Round(r.QRSOnset), math.Round(r.QRSOffset), types.UNIT_MILLISECOND)
+	if r.HasT {
+		as.AddWaveAnnotation(types.MDC_ECG_WAVC_TWAVE, math.Round(r.TOnset), math.Round(r.TOffset), types.UNIT_MILLISECOND)
+	}
+
+	// Global intervals
+	if r.HasP {
+		as.AddPRInterval(math.Round(r.PR))
+	}
+	as.AddQRSDuration(math.Round(r.QRS))
+	if r.HasT {
+		as.AddQTInterval(math.Round(r.QT))
+	}
+
+	// Frontal axes
+	if !math.IsNaN(r.PAxis) {
+		as.AddPAxis(math.Round(r.PAxis))
+	}
+	if !math.IsNaN(r.QRSAxis) {
+		as.AddQRSAxis(math.Round(r.QRSAxis))
+	}
+	if !math.IsNaN(r.TAxis) {
+		as.AddTAxis(math.Round(r.TAxis))
+	}
+
+	// Per-lead amplitudes
+	for _, lead := range r.Leads {
+		amp := r.Amplitudes[lead]
+		idx := as.AddLeadAnnotation(string(lead), MeasurementMatrixCode, "", opts.MatrixCodeSystemName)
+		ann := as.GetAnnotation(idx)
+		if ann == nil {
+			continue
+		}
+		if r.HasP {
+			ann.AddNestedAnnotation(string(types.MDC_ECG_AMPL_P), string(types.MDC_OID), math.Round(amp.P), types.UNIT_MICROVOLT)
+		}
+		ann.AddNestedAnnotation(string(types.MDC_ECG_AMPL_QRS), string(types.MDC_OID), math.Round(amp.QRS), types.UNIT_MICROVOLT)
+		ann.AddNestedAnnotation(string(types.MDC_ECG_AMPL_ST), string(types.MDC_OID), math.Round(amp.ST), types.UNIT_MICROVOLT)
+		if r.HasT {
+			ann.AddNestedAnnotation(string(types.MDC_ECG_AMPL_T), string(types.MDC_OID), math.Round(amp.T), types.UNIT_MICROVOLT)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package measure
+
+import (
+	"math"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Delineation
+// =============================================================================
+
+// Search windows, in milliseconds, relative to the QRS boundaries.
+const (
+	pSearchBefore  = 300 // earliest P wave search position before QRS onset
+	pSearchGap     = 20  // latest P wave search position before QRS onset
+	tSearchGap     = 40  // earliest T wave search position after QRS offset
+	tSearchAfter   = 700 // latest T wave search position after QRS onset
+	qrsSearchWidth = 150 // maximum distance from the QRS peak to its boundaries
+	baselineWidth  = 10  // width of the isoelectric window before QRS onset
+	smoothingWidth = 8   // moving average width of the velocity signal
+)
+
+// minBeatDuration is the shortest beat, in milliseconds, that holds the QRS
+// search window on both sides of its peak.
+const minBeatDuration = 2 * qrsSearchWidth
+
+// delineator holds the state of a single measurement run.
+type delineator struct {
+	leads map[types.LeadCode][]float64
+	order []types.LeadCode
+	n     int
+	rate  float64
+	opts  Options
+
+	baseline map[types.LeadCode]float64
+}
+
+// samples converts a duration in milliseconds to a number of samples.
+func (d *delineator) samples(ms float64) int {
+	return int(math.Round(ms * d.rate / 1000))
+}
+
+// ms converts a sample index to milliseconds from the start of the beat.
+func (d *delineator) ms(i int) float64 {
+	return float64(i) * 1000 / d.rate
+}
+
+// run delineates the beat and fills a Result.
+func (d *delineator) run() (*Result, error) {
+	res := &Result{
+		SampleRate: d.rate,
+		PAxis:      math.NaN(),
+		QRSAxis:    math.NaN(),
+		TAxis:      math.NaN(),
+		Leads:      d.order,
+		Amplitudes: make(map[types.LeadCode]Amplitudes, len(d.order)),
+	}
+
+	quiet := max(1, d.samples(d.opts.MinQuietDuration))
+
+	// QRS: the steepest part of the beat across all leads.
+	velocity := d.spatialVelocity()
+	peak := argmax(velocity, 0, d.n-1)
+	if velocity[peak] <= 0 {
+		return nil, ErrNoQRS
+	}
+	threshold := d.opts.QRSThreshold * velocity[peak]
+	width := d.samples(qrsSearchWidth)
+	qrsOn := boundaryLeft(velocity, peak, max(0, peak-width), threshold, quiet)
+	qrsOff := boundaryRight(velocity, peak, min(d.n-1, peak+width), threshold, quiet)
+	res.QRSOnset, res.QRSOffset = d.ms(qrsOn), d.ms(qrsOff)
+	res.QRS = res.QRSOffset - res.QRSOnset
+
+	// Isoelectric level per lead, taken from the PR segment just before QRS onset.
+	d.baseline = make(map[types.LeadCode]float64, len(d.order))
+	from := max(0, qrsOn-d.samples(baselineWidth))
+	for _, lead := range d.order {
+		d.baseline[lead] = mean(d.leads[lead][from : qrsOn+1])
+	}
+	amplitude := d.spatialAmplitude()
+
+	// P wave: largest spatial deflection in the window preceding the QRS.
+	pOn, pOff := -1, -1
+	if lo, hi := max(0, qrsOn-d.samples(pSearchBefore)), qrsOn-d.samples(pSearchGap); hi > lo {
+		p := argmax(amplitude, lo, hi)
+		if amplitude[p] >= d.opts.MinPAmplitude {
+			th := d.opts.WaveThreshold * amplitude[p]
+			pOn = boundaryLeft(amplitude, p, 0, th, quiet)
+			pOff = boundaryRight(amplitude, p, qrsOn, th, quiet)
+			res.HasP = true
+			res.POnset, res.POffset = d.ms(pOn), d.ms(pOff)
+			res.PR = res.QRSOnset - res.POnset
+		}
+	}
+
+	// T wave: largest spatial deflection after the QRS.
+	tOn, tOff := -1, -1
+	if lo, hi := qrsOff+d.samples(tSearchGap), min(d.n-1, qrsOn+d.samples(tSearchAfter)); hi > lo {
+		t := argmax(amplitude, lo, hi)
+		if amplitude[t] >= d.opts.MinTAmplitude {
+			th := d.opts.WaveThreshold * amplitude[t]
+			tOn = boundaryLeft(amplitude, t, qrsOff, th, quiet)
+			tOff = boundaryRight(amplitude, t, d.n-1, th, quiet)
+			res.HasT = true
+			res.TOnset, res.TOffset = d.ms(tOn), d.ms(tOff)
+			res.QT = res.TOffset - res.QRSOnset
+		}
+	}
+
+	// Frontal axes from the net area of each wave.
+	res.QRSAxis = d.frontalAxis(qrsOn, qrsOff)
+	if res.HasP {
+		res.PAxis = d.frontalAxis(pOn, pOff)
+	}
+	if res.HasT {
+		res.TAxis = d.frontalAxis(tOn, tOff)
+	}
+
+	// Per-lead amplitudes.
+	st := qrsOff + d.samples(d.opts.STOffset)
+	for _, lead := range d.order {
+		x, b := d.leads[lead], d.baseline[lead]
+		var amp Amplitudes
+		lo, hi := extrema(x, qrsOn, qrsOff)
+		amp.QRS = hi - lo
+		if res.HasP {
+			amp.P = signedPeak(x, pOn, pOff, b)
+		}
+		if res.HasT {
+			amp.T = signedPeak(x, tOn, tOff, b)
+		}
+		if st < d.n {
+			amp.ST = x[st] - b
+		}
+		res.Amplitudes[lead] = amp
+	}
+
+	return res, nil
+}
+
+// spatialVelocity returns the smoothed magnitude of the first derivative
+// across all leads.
+func (d *delineator) spatialVelocity() []float64 {
+	v := make([]float64, d.n)
+	for i := 1; i < d.n-1; i++ {
+		var sum float64
+		for _, lead := range d.order {
+			x := d.leads[lead]
+			dx := (x[i+1] - x[i-1]) / 2
+			sum += dx * dx
+		}
+		v[i] = math.Sqrt(sum)
+	}
+	return smooth(v, max(1, d.samples(smoothingWidth)))
+}
+
+// spatialAmplitude returns the magnitude of the deviation from the
+// isoelectric level across all leads.
+func (d *delineator) spatialAmplitude() []float64 {
+	a := make([]float64, d.n)
+	for i := range a {
+		var sum float64
+		for _, lead := range d.order {
+			dx := d.leads[lead][i] - d.baseline[lead]
+			sum += dx * dx
+		}
+		a[i] = math.Sqrt(sum)
+	}
+	return a
+}
+
+// frontalAxis returns the frontal plane axis in degrees of the wave spanning
+// samples lo to hi, or NaN when leads I and aVF cannot be obtained.
+//
+// Missing limb leads are derived with Einthoven's and Goldberger's equations:
+// I = II - III and aVF = (II + III) / 2.
+func (d *delineator) frontalAxis(lo, hi int) float64 {
+	area := func(lead types.LeadCode) (float64, bool) {
+		x, ok := d.leads[lead]
+		if !ok {
+			return 0, false
+		}
+		var sum float64
+		for i := lo; i <= hi; i++ {
+			sum += x[i] - d.baseline[lead]
+		}
+		return sum, true
+	}
+
+	leadI, okI := area(types.MDC_ECG_LEAD_I)
+	leadII, okII := area(types.MDC_ECG_LEAD_II)
+	leadIII, okIII := area(types.MDC_ECG_LEAD_III)
+	leadAVF, okAVF := area(types.MDC_ECG_LEAD_AVF)
+
+	if !okI && okII && okIII {
+		leadI, okI = leadII-leadIII, true
+	}
+	if !okAVF {
+		switch {
+		case okII && okIII:
+			leadAVF, okAVF = (leadII+leadIII)/2, true
+		case okI && okII:
+			leadAVF, okAVF = leadII-leadI/2, true
+		}
+	}
+	if !okI || !okAVF || (leadI == 0 && leadAVF == 0) {
+		return math.NaN()
+	}
+
+	// aVF projects the frontal vector with a sqrt(3)/2 gain relative to lead I.
+	return math.Atan2(2*leadAVF/math.Sqrt(3), leadI) * 180 / math.Pi
+}
+
+// =============================================================================
+// Signal Helpers
+// =============================================================================
+
+// boundaryLeft walks left from start and returns the first sample of the
+// first run of quiet samples below threshold, or limit if none is found.
+func boundaryLeft(sig []float64, start, limit int, threshold float64, quiet int) int {
+	run := 0
+	for i := start; i >= limit; i-- {
+		if sig[i] >= threshold {
+			run = 0
+			continue
+		}
+		run++
+		if run >= quiet {
+			return i + quiet - 1
+		}
+	}
+	return limit
+}
+
+// boundaryRight walks right from start and returns the first sample of the
+// first run of quiet samples below threshold, or limit if none is found.
+func boundaryRight(sig []float64, start, limit int, threshold float64, quiet int) int {
+	run := 0
+	for i := start; i <= limit; i++ {
+		if sig[i] >= threshold {
+			run = 0
+			continue
+		}
+		run++
+		if run >= quiet {
+			return i - quiet + 1
+		}
+	}
+	return limit
+}
+
+// argmax returns the index of the largest value in sig[lo:hi+1].
+func argmax(sig []float64, lo, hi int) int {
+	best := lo
+	for i := lo + 1; i <= hi; i++ {
+		if sig[i] > sig[best] {
+			best = i
+		}
+	}
+	return best
+}
+
+// extrema returns the minimum and maximum of x[lo:hi+1].
+func extrema(x []float64, lo, hi int) (float64, float64) {
+	low, high := x[lo], x[lo]
+	for _, v := range x[lo+1 : hi+1] {
+		low = min(low, v)
+		high = max(high, v)
+	}
+	return low, high
+}
+
+// signedPeak returns the deviation from baseline with the largest magnitude
+// in x[lo:hi+1], keeping its sign.
+func signedPeak(x []float64, lo, hi int, baseline float64) float64 {
+	var peak float64
+	for _, v := range x[lo : hi+1] {
+		if dv := v - baseline; math.Abs(dv) > math.Abs(peak) {
+			peak = dv
+		}
+	}
+	return peak
+}
+
+// mean returns the arithmetic mean of x.
+func mean(x []float64) float64 {
+	if len(x) == 0 {
+		return 0
+	}
+	var sum float64
+	for _, v := range x {
+		sum += v
+	}
+	return sum / float64(len(x))
+}
+
+// smooth applies a centered moving average of the given width.
+func smooth(x []float64, width int) []float64 {
+	if width <= 1 {
+		return x
+	}
+	out := make([]float64, len(x))
+	half := width / 2
+	for i := range x {
+		lo, hi := max(0, i-half), min(len(x)-1, i+half)
+		out[i] = mean(x[lo : hi+1])
+	}
+	return out
+}
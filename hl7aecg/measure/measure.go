@@ -0,0 +1,263 @@
+// Package measure computes global ECG measurements from a representative
+// (median) beat.
+//
+// The engine delineates the P, QRS and T waves across all available leads,
+// then derives the PR interval, QRS duration, QT interval, the P/QRS/T
+// frontal axes and per-lead wave amplitudes. Results can be written into an
+// AnnotationSet together with the wave annotations (fiducial points) that
+// support them.
+//
+// Example:
+//
+//	beat := &h.HL7AEcg.Component[0].Series.Derivation[0].DerivedSeries
+//	res, err := measure.AnnotateSeries(beat, "20250923103600", measure.DefaultOptions())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("PR=%.0f QRS=%.0f QT=%.0f\n", res.PR, res.QRS, res.QT)
+package measure
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Options
+// =============================================================================
+
+// DefaultMatrixCodeSystemName is the code system name used for the
+// MEASUREMENT_MATRIX lead annotations written by Annotate.
+const DefaultMatrixCodeSystemName = "HL7V3AECG"
+
+// Options tunes the delineation thresholds.
+//
+// All durations are in milliseconds and all fractions are relative to the
+// peak of the corresponding detection signal.
+type Options struct {
+	// QRSThreshold is the fraction of the peak spatial velocity below which
+	// the signal is considered outside of the QRS complex.
+	QRSThreshold float64
+
+	// WaveThreshold is the fraction of the P or T peak spatial amplitude below
+	// which the signal is considered outside of the wave.
+	WaveThreshold float64
+
+	// MinQuietDuration is how long the detection signal must stay below the
+	// threshold for a boundary to be accepted.
+	MinQuietDuration float64
+
+	// MinPAmplitude and MinTAmplitude are the minimum spatial amplitudes (µV)
+	// for a P or T wave to be reported as present.
+	MinPAmplitude float64
+	MinTAmplitude float64
+
+	// STOffset is the delay after the J point at which the ST amplitude is read.
+	STOffset float64
+
+	// MatrixCodeSystemName is the code system name of the MEASUREMENT_MATRIX
+	// lead annotations. Defaults to DefaultMatrixCodeSystemName.
+	MatrixCodeSystemName string
+}
+
+// DefaultOptions returns the thresholds used when none are provided.
+func DefaultOptions() Options {
+	return Options{
+		QRSThreshold:         0.08,
+		WaveThreshold:        0.1,
+		MinQuietDuration:     16,
+		MinPAmplitude:        20,
+		MinTAmplitude:        40,
+		STOffset:             60,
+		MatrixCodeSystemName: DefaultMatrixCodeSystemName,
+	}
+}
+
+// =============================================================================
+// Result
+// =============================================================================
+
+// Amplitudes holds the wave amplitudes of a single lead in µV.
+//
+// P, ST and T are signed deviations from the isoelectric level (PR segment).
+// QRS is the peak-to-peak amplitude of the complex.
+type Amplitudes struct {
+	P   float64
+	QRS float64
+	ST  float64
+	T   float64
+}
+
+// Result holds the measurements of a representative beat.
+//
+// Fiducial points are expressed in milliseconds from the start of the beat.
+// Axes are in degrees in the range (-180, 180]; they are NaN when the wave
+// is absent or the frontal leads are unavailable.
+type Result struct {
+	SampleRate float64
+
+	HasP bool
+	HasT bool
+
+	POnset    float64
+	POffset   float64
+	QRSOnset  float64
+	QRSOffset float64
+	TOnset    float64
+	TOffset   float64
+
+	PR  float64
+	QRS float64
+	QT  float64
+
+	PAxis   float64
+	QRSAxis float64
+	TAxis   float64
+
+	// Leads lists the measured leads in output order.
+	Leads      []types.LeadCode
+	Amplitudes map[types.LeadCode]Amplitudes
+}
+
+// =============================================================================
+// Errors
+// =============================================================================
+
+var (
+	// ErrNoLeads is returned when no lead waveform is provided.
+	ErrNoLeads = errors.New("measure: no lead waveforms")
+
+	// ErrInvalidSampleRate is returned when the sample rate is not positive.
+	ErrInvalidSampleRate = errors.New("measure: sample rate must be positive")
+
+	// ErrLengthMismatch is returned when leads have different lengths.
+	ErrLengthMismatch = errors.New("measure: leads have different lengths")
+
+	// ErrTooShort is returned when the beat is too short to hold a QRS
+	// complex and its search windows.
+	ErrTooShort = errors.New("measure: beat is too short")
+
+	// ErrNoQRS is returned when no QRS complex can be located.
+	ErrNoQRS = errors.New("measure: no QRS complex detected")
+)
+
+// =============================================================================
+// Entry Points
+// =============================================================================
+
+// MeasureSeries measures the representative beat held in a series.
+func MeasureSeries(s *types.Series, opts Options) (*Result, error) {
+	rate, err := s.GetSampleRate()
+	if err != nil {
+		return nil, fmt.Errorf("measure: sample rate: %w", err)
+	}
+	leads, err := s.GetLeadValuesMap()
+	if err != nil {
+		return nil, fmt.Errorf("measure: leads: %w", err)
+	}
+	return Measure(leads, rate, opts)
+}
+
+// SoftwareName identifies the measurement engine as the author of the
+// annotation sets written by AnnotateSeries.
+const SoftwareName = "hl7v3-aecg measure"
+
+// AnnotateSeries measures the representative beat held in a series and writes
+// the results into a new annotation set authored by the measurement engine
+// (function code ALGORITHM), leaving other reads of the series untouched.
+func AnnotateSeries(s *types.Series, activityTime string, opts Options) (*Result, error) {
+	res, err := MeasureSeries(s, opts)
+	if err != nil {
+		return nil, err
+	}
+	as := s.AddAnnotationSet(activityTime).
+		AddDeviceAuthor(types.AUTHOR_FUNCTION_ALGORITHM, "", "", "", SoftwareName)
+	res.Annotate(as, opts)
+	return res, nil
+}
+
+// Measure delineates a representative beat and computes global measurements.
+//
+// Parameters:
+//   - leads: Lead waveforms in µV, all of the same length
+//   - sampleRate: Sampling frequency in Hz
+//   - opts: Delineation thresholds (zero values fall back to DefaultOptions)
+func Measure(leads map[types.LeadCode][]float64, sampleRate float64, opts Options) (*Result, error) {
+	if len(leads) == 0 {
+		return nil, ErrNoLeads
+	}
+	if sampleRate <= 0 || math.IsNaN(sampleRate) || math.IsInf(sampleRate, 0) {
+		return nil, ErrInvalidSampleRate
+	}
+	opts = opts.withDefaults()
+
+	order := orderLeads(leads)
+	n := len(leads[order[0]])
+	for _, lead := range order {
+		if len(leads[lead]) != n {
+			return nil, ErrLengthMismatch
+		}
+	}
+	if float64(n)*1000/sampleRate < minBeatDuration {
+		return nil, ErrTooShort
+	}
+
+	d := &delineator{
+		leads: leads,
+		order: order,
+		n:     n,
+		rate:  sampleRate,
+		opts:  opts,
+	}
+	return d.run()
+}
+
+// withDefaults replaces zero-valued options with their defaults.
+func (o Options) withDefaults() Options {
+	def := DefaultOptions()
+	if o.QRSThreshold <= 0 {
+		o.QRSThreshold = def.QRSThreshold
+	}
+	if o.WaveThreshold <= 0 {
+		o.WaveThreshold = def.WaveThreshold
+	}
+	if o.MinQuietDuration <= 0 {
+		o.MinQuietDuration = def.MinQuietDuration
+	}
+	if o.MinPAmplitude <= 0 {
+		o.MinPAmplitude = def.MinPAmplitude
+	}
+	if o.MinTAmplitude <= 0 {
+		o.MinTAmplitude = def.MinTAmplitude
+	}
+	if o.STOffset <= 0 {
+		o.STOffset = def.STOffset
+	}
+	if o.MatrixCodeSystemName == "" {
+		o.MatrixCodeSystemName = def.MatrixCodeSystemName
+	}
+	return o
+}
+
+// orderLeads returns the standard leads first, then any extra lead in
+// lexical order, so that output is deterministic.
+func orderLeads(leads map[types.LeadCode][]float64) []types.LeadCode {
+	order := make([]types.LeadCode, 0, len(leads))
+	for _, lead := range types.GetStandardLeads() {
+		if _, ok := leads[lead]; ok {
+			order = append(order, lead)
+		}
+	}
+	var extra []types.LeadCode
+	for lead := range leads {
+		if !types.IsStandardLead(lead) {
+			extra = append(extra, lead)
+		}
+	}
+	slices.Sort(extra)
+	return append(order, extra...)
+}
@@ -0,0 +1,340 @@
+package measure
+
+import (
+	"encoding/xml"
+	"errors"
+	"math"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// wave describes a gaussian deflection of the synthetic beat.
+type wave struct {
+	center    float64 // ms
+	sigma     float64 // ms
+	amplitude float64 // µV
+	axis      float64 // degrees in the frontal plane
+}
+
+// syntheticBeat builds a 12-lead beat from gaussian waves projected on the
+// hexaxial reference system. Precordial leads reuse the lead II morphology
+// with different gains.
+func syntheticBeat(rate, duration float64, waves []wave) map[types.LeadCode][]float64 {
+	n := int(duration * rate / 1000)
+	project := func(angle float64) []float64 {
+		x := make([]float64, n)
+		for i := range x {
+			t := float64(i) * 1000 / rate
+			for _, w := range waves {
+				g := math.Exp(-(t - w.center) * (t - w.center) / (2 * w.sigma * w.sigma))
+				x[i] += w.amplitude * g * math.Cos((w.axis-angle)*math.Pi/180)
+			}
+		}
+		return x
+	}
+
+	leadI := project(0)
+	leadII := project(60)
+	leads := map[types.LeadCode][]float64{
+		types.MDC_ECG_LEAD_I:  leadI,
+		types.MDC_ECG_LEAD_II: leadII,
+	}
+	combine := func(a, b float64) []float64 {
+		x := make([]float64, n)
+		for i := range x {
+			x[i] = a*leadI[i] + b*leadII[i]
+		}
+		return x
+	}
+	leads[types.MDC_ECG_LEAD_III] = combine(-1, 1)
+	leads[types.MDC_ECG_LEAD_AVR] = combine(-0.5, -0.5)
+	leads[types.MDC_ECG_LEAD_AVL] = combine(1, -0.5)
+	leads[types.MDC_ECG_LEAD_AVF] = combine(-0.5, 1)
+	for i, lead := range []types.LeadCode{
+		types.MDC_ECG_LEAD_V1, types.MDC_ECG_LEAD_V2, types.MDC_ECG_LEAD_V3,
+		types.MDC_ECG_LEAD_V4, types.MDC_ECG_LEAD_V5, types.MDC_ECG_LEAD_V6,
+	} {
+		leads[lead] = combine(0, 0.4+0.2*float64(i))
+	}
+	return leads
+}
+
+// normalBeat returns a beat with a P wave, a QRS complex and a T wave.
+func normalBeat(rate float64) map[types.LeadCode][]float64 {
+	return syntheticBeat(rate, 1000, []wave{
+		{center: 200, sigma: 15, amplitude: 150, axis: 60},
+		{center: 350, sigma: 10, amplitude: 1200, axis: 45},
+		{center: 600, sigma: 45, amplitude: 350, axis: 30},
+	})
+}
+
+func assertNear(t *testing.T, name string, got, want, tolerance float64) {
+	t.Helper()
+	if math.IsNaN(got) || math.Abs(got-want) > tolerance {
+		t.Errorf("%s = %.1f, want %.1f ± %.1f", name, got, want, tolerance)
+	}
+}
+
+// TestMeasure_NormalBeat checks intervals and axes on a synthetic beat.
+func TestMeasure_NormalBeat(t *testing.T) {
+	for _, rate := range []float64{500, 1000} {
+		t.Run(strconv.FormatFloat(rate, 'f', -1, 64)+"Hz", func(t *testing.T) {
+			res, err := Measure(normalBeat(rate), rate, DefaultOptions())
+			if err != nil {
+				t.Fatalf("Measure() error = %v", err)
+			}
+			if !res.HasP || !res.HasT {
+				t.Fatalf("Measure() HasP = %v, HasT = %v, want both", res.HasP, res.HasT)
+			}
+
+			// Gaussian boundaries sit roughly 2 to 3 sigma away from the wave center.
+			assertNear(t, "QRSOnset", res.QRSOnset, 325, 8)
+			assertNear(t, "QRSOffset", res.QRSOffset, 375, 8)
+			assertNear(t, "POnset", res.POnset, 165, 10)
+			assertNear(t, "TOffset", res.TOffset, 700, 15)
+			assertNear(t, "PR", res.PR, res.QRSOnset-res.POnset, 1e-9)
+			assertNear(t, "QRS", res.QRS, 50, 15)
+			assertNear(t, "QT", res.QT, 375, 20)
+
+			assertNear(t, "PAxis", res.PAxis, 60, 3)
+			assertNear(t, "QRSAxis", res.QRSAxis, 45, 3)
+			assertNear(t, "TAxis", res.TAxis, 30, 3)
+
+			amp := res.Amplitudes[types.MDC_ECG_LEAD_I]
+			assertNear(t, "P amplitude lead I", amp.P, 150*math.Cos(60*math.Pi/180), 10)
+			assertNear(t, "T amplitude lead I", amp.T, 350*math.Cos(30*math.Pi/180), 10)
+			assertNear(t, "QRS amplitude lead I", amp.QRS, 1200*math.Cos(45*math.Pi/180), 20)
+		})
+	}
+}
+
+// TestMeasure_NoPWave checks that a missing P wave is reported as absent.
+func TestMeasure_NoPWave(t *testing.T) {
+	leads := syntheticBeat(500, 1000, []wave{
+		{center: 350, sigma: 10, amplitude: 1200, axis: 45},
+		{center: 600, sigma: 45, amplitude: 350, axis: 30},
+	})
+
+	res, err := Measure(leads, 500, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	if res.HasP {
+		t.Errorf("Measure() HasP = true, want false")
+	}
+	if !math.IsNaN(res.PAxis) {
+		t.Errorf("Measure() PAxis = %v, want NaN", res.PAxis)
+	}
+	if !res.HasT {
+		t.Errorf("Measure() HasT = false, want true")
+	}
+}
+
+// TestMeasure_DerivedFrontalLeads checks that axes are computed from leads II and III alone.
+func TestMeasure_DerivedFrontalLeads(t *testing.T) {
+	full := normalBeat(500)
+	leads := map[types.LeadCode][]float64{
+		types.MDC_ECG_LEAD_II:  full[types.MDC_ECG_LEAD_II],
+		types.MDC_ECG_LEAD_III: full[types.MDC_ECG_LEAD_III],
+	}
+
+	res, err := Measure(leads, 500, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+	assertNear(t, "QRSAxis", res.QRSAxis, 45, 3)
+}
+
+// TestMeasure_Errors checks input validation.
+func TestMeasure_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		leads   map[types.LeadCode][]float64
+		rate    float64
+		wantErr error
+	}{
+		{
+			name:    "No leads",
+			leads:   map[types.LeadCode][]float64{},
+			rate:    500,
+			wantErr: ErrNoLeads,
+		},
+		{
+			name:    "Zero sample rate",
+			leads:   map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: {0, 1, 0}},
+			rate:    0,
+			wantErr: ErrInvalidSampleRate,
+		},
+		{
+			name: "Length mismatch",
+			leads: map[types.LeadCode][]float64{
+				types.MDC_ECG_LEAD_I:  {0, 1, 0},
+				types.MDC_ECG_LEAD_II: {0, 1},
+			},
+			rate:    500,
+			wantErr: ErrLengthMismatch,
+		},
+		{
+			name:    "Empty leads",
+			leads:   map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: {}, types.MDC_ECG_LEAD_II: {}},
+			rate:    500,
+			wantErr: ErrTooShort,
+		},
+		{
+			name:    "Shorter than a QRS search window",
+			leads:   map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: make([]float64, 149)},
+			rate:    500,
+			wantErr: ErrTooShort,
+		},
+		{
+			name:    "Flat line",
+			leads:   map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: make([]float64, 500)},
+			rate:    500,
+			wantErr: ErrNoQRS,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Measure(tt.leads, tt.rate, DefaultOptions())
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Measure() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestResult_Annotate checks the annotations written for a normal beat.
+func TestResult_Annotate(t *testing.T) {
+	res, err := Measure(normalBeat(500), 500, DefaultOptions())
+	if err != nil {
+		t.Fatalf("Measure() error = %v", err)
+	}
+
+	as := &types.AnnotationSet{}
+	res.Annotate(as, Options{})
+
+	for _, code := range []string{
+		string(types.MDC_ECG_TIME_PD_PR),
+		string(types.MDC_ECG_TIME_PD_QRS),
+		string(types.MDC_ECG_TIME_PD_QT),
+		string(types.MDC_ECG_ANGLE_P_FRONT),
+		string(types.MDC_ECG_ANGLE_QRS_FRONT),
+		string(types.MDC_ECG_ANGLE_T_FRONT),
+	} {
+		if as.GetAnnotationByCode(code) == nil {
+			t.Errorf("Annotate() missing %s", code)
+		}
+	}
+
+	qrs := as.GetWaveAnnotations(types.MDC_ECG_WAVC_QRSWAVE)
+	if len(qrs) != 1 {
+		t.Fatalf("Annotate() QRS wave annotations = %d, want 1", len(qrs))
+	}
+	onset, offset, unit, ok := qrs[0].GetTimeBoundary()
+	if !ok || onset != math.Round(res.QRSOnset) || offset != math.Round(res.QRSOffset) || unit != "ms" {
+		t.Errorf("QRS boundary = (%v, %v, %q, %v), want (%v, %v, \"ms\", true)",
+			onset, offset, unit, ok, math.Round(res.QRSOnset), math.Round(res.QRSOffset))
+	}
+
+	leadII := as.GetLeadAnnotations(string(types.MDC_ECG_LEAD_II))
+	if leadII == nil {
+		t.Fatalf("Annotate() missing lead II measurement matrix")
+	}
+	if leadII.Code.CodeSystemName != DefaultMatrixCodeSystemName {
+		t.Errorf("matrix codeSystemName = %q, want %q", leadII.Code.CodeSystemName, DefaultMatrixCodeSystemName)
+	}
+	for _, code := range []types.MeasurementCode{
+		types.MDC_ECG_AMPL_P, types.MDC_ECG_AMPL_QRS, types.MDC_ECG_AMPL_ST, types.MDC_ECG_AMPL_T,
+	} {
+		nested := leadII.GetNestedAnnotationByCode(string(code))
+		if nested == nil {
+			t.Errorf("lead II missing %s", code)
+			continue
+		}
+		if nested.GetValueUnit() != "uV" {
+			t.Errorf("lead II %s unit = %q, want uV", code, nested.GetValueUnit())
+		}
+	}
+}
+
+// buildSeries wraps lead waveforms into a representative beat series.
+func buildSeries(leads map[types.LeadCode][]float64, rate float64) *types.Series {
+	set := types.SequenceSet{}
+	timeSeq := types.SequenceComponent{}
+	timeSeq.Sequence.Code.Time = &types.Code[types.TimeSequenceCode, types.CodeSystemOID]{
+		Code:       types.TIME_RELATIVE_CODE,
+		CodeSystem: types.HL7_ActCode_OID,
+	}
+	timeSeq.Sequence.Value = &types.SequenceValue{
+		XsiType: "GLIST_PQ",
+		Typed: &types.GLIST_PQ{
+			Head:      types.PhysicalQuantity{Value: "0", Unit: "ms"},
+			Increment: types.PhysicalQuantity{Value: strconv.FormatFloat(1000/rate, 'f', -1, 64), Unit: "ms"},
+		},
+	}
+	set.Component = append(set.Component, timeSeq)
+
+	for _, lead := range orderLeads(leads) {
+		digits := make([]string, len(leads[lead]))
+		for i, v := range leads[lead] {
+			digits[i] = strconv.Itoa(int(math.Round(v / 5)))
+		}
+		seq := types.SequenceComponent{}
+		seq.Sequence.Code.Lead = &types.Code[types.LeadCode, types.CodeSystemOID]{Code: lead, CodeSystem: types.MDC_OID}
+		seq.Sequence.Value = &types.SequenceValue{
+			XsiType: "SLIST_PQ",
+			Typed: &types.SLIST_PQ{
+				Origin: types.PhysicalQuantity{Value: "0", Unit: "mV"},
+				Scale:  types.PhysicalQuantity{Value: "0.005", Unit: "mV"},
+				Digits: strings.Join(digits, " "),
+			},
+		}
+		set.Component = append(set.Component, seq)
+	}
+
+	return &types.Series{Component: []types.SeriesComponent{{SequenceSet: set}}}
+}
+
+// TestAnnotateSeries checks measurement from a series and XML round-trip of the results.
+func TestAnnotateSeries(t *testing.T) {
+	series := buildSeries(normalBeat(500), 500)
+
+	res, err := AnnotateSeries(series, "20250923103600", DefaultOptions())
+	if err != nil {
+		t.Fatalf("AnnotateSeries() error = %v", err)
+	}
+	assertNear(t, "QRSAxis", res.QRSAxis, 45, 3)
+
+	data, err := xml.Marshal(series)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+	var decoded types.Series
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+
+	sets := decoded.GetAnnotationSetsByAuthorFunction(types.AUTHOR_FUNCTION_ALGORITHM)
+	if len(sets) != 1 || !sets[0].IsDeviceAuthored() {
+		t.Fatalf("decoded algorithm annotation sets = %d, want 1 device-authored set", len(sets))
+	}
+	as := sets[0]
+	waves := as.GetWaveAnnotations(types.MDC_ECG_WAVC_TWAVE)
+	if len(waves) != 1 {
+		t.Fatalf("decoded T wave annotations = %d, want 1", len(waves))
+	}
+	if bv := waves[0].Support.SupportingROI.Component[0].Boundary.Value; bv.XsiType != "IVL_PQ" {
+		t.Errorf("decoded boundary xsi:type = %q, want IVL_PQ", bv.XsiType)
+	}
+	_, offset, _, ok := waves[0].GetTimeBoundary()
+	if !ok || offset != math.Round(res.TOffset) {
+		t.Errorf("decoded T offset = %v (ok=%v), want %v", offset, ok, math.Round(res.TOffset))
+	}
+	if qt, ok := as.GetAnnotationByCode(string(types.MDC_ECG_TIME_PD_QT)).GetValueFloat(); !ok || qt != math.Round(res.QT) {
+		t.Errorf("decoded QT = %v (ok=%v), want %v", qt, ok, math.Round(res.QT))
+	}
+}
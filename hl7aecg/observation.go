@@ -0,0 +1,86 @@
+package hl7aecg
+
+import (
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// AddRelatedObservation adds a numeric observation about the subject, coded in LOINC.
+//
+// Related observations are stored in the subject assignment
+// (ComponentOf > TimepointEvent > SubjectAssignment > SubjectOf). Invalid
+// values (NaN, Inf) are ignored.
+//
+// Parameters:
+//   - code: LOINC observation code (e.g., types.OBS_WEIGHT)
+//   - value: Numeric value
+//   - unit: UCUM unit (e.g., "kg")
+//
+// Example:
+//
+//	h.AddRelatedObservation(types.OBS_WEIGHT, 72.5, types.UNIT_KILOGRAM)
+func (h *Hl7xml) AddRelatedObservation(code types.ObservationCode, value float64, unit string) *Hl7xml {
+	h.subjectAssignment().AddRelatedObservation(code, types.LOINC_OID, value, unit)
+	return h
+}
+
+// AddCodedObservation adds a coded observation about the subject, coded in LOINC.
+//
+// Example:
+//
+//	h.AddCodedObservation("72166-2", types.CodedValue{
+//	    Code: "LA18978-9", CodeSystem: string(types.LOINC_OID), DisplayName: "Never smoker",
+//	})
+func (h *Hl7xml) AddCodedObservation(code types.ObservationCode, value types.CodedValue) *Hl7xml {
+	h.subjectAssignment().AddCodedObservation(code, types.LOINC_OID, value)
+	return h
+}
+
+// AddSubjectAge adds the subject's age in years (LOINC 21612-7).
+func (h *Hl7xml) AddSubjectAge(ageYears float64) *Hl7xml {
+	return h.AddRelatedObservation(types.OBS_AGE, ageYears, types.UNIT_YEAR)
+}
+
+// AddSubjectHeight adds the subject's height in centimeters (LOINC 8302-2).
+func (h *Hl7xml) AddSubjectHeight(heightCm float64) *Hl7xml {
+	return h.AddRelatedObservation(types.OBS_HEIGHT, heightCm, types.UNIT_CENTIMETER)
+}
+
+// AddSubjectWeight adds the subject's weight in kilograms (LOINC 29463-7).
+func (h *Hl7xml) AddSubjectWeight(weightKg float64) *Hl7xml {
+	return h.AddRelatedObservation(types.OBS_WEIGHT, weightKg, types.UNIT_KILOGRAM)
+}
+
+// AddSubjectHeartRate adds a heart rate measured outside the ECG, e.g. at a
+// vital signs check (LOINC 8867-4).
+func (h *Hl7xml) AddSubjectHeartRate(bpm float64) *Hl7xml {
+	return h.AddRelatedObservation(types.OBS_HEART_RATE, bpm, types.UNIT_PER_MINUTE)
+}
+
+// AddBloodPressure adds systolic (LOINC 8480-6) and diastolic (LOINC 8462-4)
+// blood pressure in mm[Hg].
+//
+// Example:
+//
+//	h.AddBloodPressure(120, 80)
+func (h *Hl7xml) AddBloodPressure(systolic, diastolic float64) *Hl7xml {
+	h.AddRelatedObservation(types.OBS_BP_SYSTOLIC, systolic, types.UNIT_MMHG)
+	return h.AddRelatedObservation(types.OBS_BP_DIASTOLIC, diastolic, types.UNIT_MMHG)
+}
+
+// GetRelatedObservation returns the first related observation with the given
+// code, or nil if absent.
+func (h *Hl7xml) GetRelatedObservation(code types.ObservationCode) *types.RelatedObservation {
+	if h.HL7AEcg.ComponentOf == nil {
+		return nil
+	}
+	return h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.GetRelatedObservationByCode(code)
+}
+
+// subjectAssignment returns the subject assignment, initializing the
+// ComponentOf structure if needed.
+func (h *Hl7xml) subjectAssignment() *types.SubjectAssignment {
+	if h.HL7AEcg.ComponentOf == nil {
+		h.SetSubject("", "", "") // Initialize structure
+	}
+	return &h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+}
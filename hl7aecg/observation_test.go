@@ -0,0 +1,161 @@
+package hl7aecg
+
+import (
+	"encoding/xml"
+	"math"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// TestHl7xml_RelatedObservations tests the LOINC observation helpers and their XML round-trip
+func TestHl7xml_RelatedObservations(t *testing.T) {
+	h := NewHl7xml("/tmp")
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "")
+	h.SetSubject("2.16.840.1.113883.3.5", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+		AddSubjectAge(34).
+		AddSubjectHeight(178).
+		AddSubjectWeight(72.5).
+		AddSubjectHeartRate(64).
+		AddBloodPressure(120, 80).
+		AddSubjectWeight(math.NaN())
+
+	sa := &h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	if len(sa.SubjectOf) != 6 {
+		t.Fatalf("len(SubjectOf) = %d, want 6", len(sa.SubjectOf))
+	}
+	sa.GetRelatedObservation(0).SetPersonAuthor("20021122091000", "2.16.840.1.113883.3.5", "NURSE-12", "Julie Tech")
+
+	data, err := xml.Marshal(&h.HL7AEcg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	decoded := NewHl7xml("")
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	tests := []struct {
+		code  types.ObservationCode
+		value float64
+		unit  string
+	}{
+		{types.OBS_AGE, 34, "a"},
+		{types.OBS_HEIGHT, 178, "cm"},
+		{types.OBS_WEIGHT, 72.5, "kg"},
+		{types.OBS_HEART_RATE, 64, "/min"},
+		{types.OBS_BP_SYSTOLIC, 120, "mm[Hg]"},
+		{types.OBS_BP_DIASTOLIC, 80, "mm[Hg]"},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.code), func(t *testing.T) {
+			obs := decoded.GetRelatedObservation(tt.code)
+			if obs == nil {
+				t.Fatalf("observation %s not found", tt.code)
+			}
+			if obs.Code.CodeSystem != types.LOINC_OID {
+				t.Errorf("CodeSystem = %v, want %v", obs.Code.CodeSystem, types.LOINC_OID)
+			}
+			if v, ok := obs.GetValueFloat(); !ok || v != tt.value {
+				t.Errorf("value = %v (%v), want %v", v, ok, tt.value)
+			}
+			if obs.GetValueUnit() != tt.unit {
+				t.Errorf("unit = %q, want %q", obs.GetValueUnit(), tt.unit)
+			}
+		})
+	}
+
+	age := decoded.GetRelatedObservation(types.OBS_AGE)
+	if age.Author == nil || age.Author.Time.Value != "20021122091000" {
+		t.Fatalf("age author time not preserved: %+v", age.Author)
+	}
+	if p := age.Author.AssignedEntity.AssignedPerson; p == nil || *p.Name != "Julie Tech" {
+		t.Errorf("age author person not preserved: %+v", p)
+	}
+	if age.Code.DisplayName != "Age" {
+		t.Errorf("DisplayName = %q, want Age", age.Code.DisplayName)
+	}
+}
+
+// TestHl7xml_UnmarshalSponsorRelatedObservations tests parsing related
+// observations as written by sponsor systems (namespaced xsi:type, INT and CD values)
+func TestHl7xml_UnmarshalSponsorRelatedObservations(t *testing.T) {
+	data := `<?xml version="1.0" encoding="UTF-8"?>
+<AnnotatedECG xmlns="urn:hl7-org:v3" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+	<id root="728989ec-b8bc-49cd-9a5a-30be5ade1db5"/>
+	<code code="93000" codeSystem="2.16.840.1.113883.6.12"/>
+	<componentOf>
+		<timepointEvent>
+			<componentOf>
+				<subjectAssignment>
+					<subject>
+						<trialSubject>
+							<id root="2.16.840.1.113883.3.5" extension="SUBJ_1"/>
+						</trialSubject>
+					</subject>
+					<subjectOf>
+						<relatedObservation>
+							<code code="21612-7" codeSystem="2.16.840.1.113883.6.1" displayName="Age"/>
+							<value xsi:type="INT" value="61"/>
+						</relatedObservation>
+					</subjectOf>
+					<subjectOf>
+						<relatedObservation>
+							<code code="8480-6" codeSystem="2.16.840.1.113883.6.1"/>
+							<value xsi:type="PQ" value="132" unit="mm[Hg]"/>
+							<author>
+								<time value="20210315083000"/>
+								<assignedEntity>
+									<assignedDevice>
+										<manufacturerModelName>BP-200</manufacturerModelName>
+									</assignedDevice>
+								</assignedEntity>
+							</author>
+						</relatedObservation>
+					</subjectOf>
+					<subjectOf>
+						<relatedObservation>
+							<code code="72166-2" codeSystem="2.16.840.1.113883.6.1" displayName="Tobacco smoking status"/>
+							<value xsi:type="CD" code="LA18978-9" codeSystem="2.16.840.1.113883.6.1" displayName="Never smoker"/>
+						</relatedObservation>
+					</subjectOf>
+					<componentOf>
+						<clinicalTrial>
+							<id root="trial-001"/>
+						</clinicalTrial>
+					</componentOf>
+				</subjectAssignment>
+			</componentOf>
+		</timepointEvent>
+	</componentOf>
+</AnnotatedECG>`
+
+	h := NewHl7xml("")
+	if err := h.Unmarshal([]byte(data)); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	if age, ok := h.GetRelatedObservation(types.OBS_AGE).GetValueFloat(); !ok || age != 61 {
+		t.Errorf("age = %v (%v), want 61", age, ok)
+	}
+
+	bp := h.GetRelatedObservation(types.OBS_BP_SYSTOLIC)
+	if v, _ := bp.GetValueFloat(); v != 132 || bp.GetValueUnit() != "mm[Hg]" {
+		t.Errorf("systolic = %v %s, want 132 mm[Hg]", v, bp.GetValueUnit())
+	}
+	if bp.Author == nil || bp.Author.AssignedEntity.AssignedDevice == nil {
+		t.Fatal("systolic author device not parsed")
+	}
+	if name := bp.Author.AssignedEntity.AssignedDevice.ManufacturerModelName; name == nil || *name != "BP-200" {
+		t.Errorf("device model = %v, want BP-200", name)
+	}
+
+	smoking := h.GetRelatedObservation("72166-2")
+	if smoking == nil {
+		t.Fatal("smoking status not parsed")
+	}
+	ce, ok := smoking.Value.GetCoded()
+	if !ok || ce.Code != "LA18978-9" || ce.XsiType != "CD" {
+		t.Errorf("coded value = %+v, want LA18978-9 (CD)", ce)
+	}
+}
@@ -0,0 +1,202 @@
+package qtc
+
+import (
+	"errors"
+	"math"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Annotation Codes
+// =============================================================================
+
+const (
+	// CodeSystemName is the code system name of the provenance annotations.
+	CodeSystemName = "HL7V3AECG"
+
+	// MethodAnnotationCode is the code of the nested annotation whose coded
+	// value identifies the correction method.
+	MethodAnnotationCode = "QTC_CORRECTION_METHOD"
+
+	// ExponentAnnotationCode is the code of the nested annotation holding the
+	// fitted RR exponent of the QTcP and QTcI methods.
+	ExponentAnnotationCode = "QTC_CORRECTION_EXPONENT"
+)
+
+var (
+	// ErrMissingQT is returned when the annotation set has no usable QT annotation.
+	ErrMissingQT = errors.New("qtc: annotation set has no QT interval")
+
+	// ErrMissingRR is returned when the annotation set has neither an RR
+	// interval nor a heart rate annotation.
+	ErrMissingRR = errors.New("qtc: annotation set has no RR interval or heart rate")
+)
+
+// =============================================================================
+// Reading Intervals
+// =============================================================================
+
+// Intervals returns the QT and RR intervals, in milliseconds, recorded in an
+// annotation set.
+//
+// The RR interval is read from MDC_ECG_TIME_PD_RR, or derived from
+// MDC_ECG_HEART_RATE (RR = 60000 / HR) when absent. Values expressed in
+// seconds are converted to milliseconds.
+func Intervals(as *types.AnnotationSet) (qt, rr float64, err error) {
+	qt, ok := durationMs(as.GetAnnotationByCode(string(types.MDC_ECG_TIME_PD_QT)))
+	if !ok {
+		return 0, 0, ErrMissingQT
+	}
+
+	if rr, ok = durationMs(as.GetAnnotationByCode(string(types.MDC_ECG_TIME_PD_RR))); ok {
+		return qt, rr, nil
+	}
+	if hr, ok := as.GetAnnotationByCode(string(types.MDC_ECG_HEART_RATE)).GetValueFloat(); ok && isPositive(hr) {
+		return qt, 60000 / hr, nil
+	}
+	return 0, 0, ErrMissingRR
+}
+
+// durationMs returns the annotation value in milliseconds.
+func durationMs(ann *types.Annotation) (float64, bool) {
+	v, ok := ann.GetValueFloat()
+	if !ok || !isPositive(v) {
+		return 0, false
+	}
+	switch ann.GetValueUnit() {
+	case types.UNIT_MILLISECOND, "":
+		return v, true
+	case types.UNIT_SECOND:
+		return v * 1000, true
+	}
+	return 0, false
+}
+
+// =============================================================================
+// Writing Corrections
+// =============================================================================
+
+// Annotate computes QTc from the QT and RR intervals of the annotation set
+// and appends it as an MDC_ECG_TIME_PD_QTc annotation.
+//
+// The annotation carries a nested QTC_CORRECTION_METHOD coded annotation and,
+// for fitted methods, a nested QTC_CORRECTION_EXPONENT annotation:
+//
+//	<annotation>
+//	  <code code="MDC_ECG_TIME_PD_QTc" codeSystem="2.16.840.1.113883.6.24"/>
+//	  <value xsi:type="PQ" value="412.3" unit="ms"/>
+//	  <component>
+//	    <annotation>
+//	      <code code="QTC_CORRECTION_METHOD" codeSystem="" codeSystemName="HL7V3AECG"/>
+//	      <value xsi:type="CE" code="QTcF" codeSystemName="HL7V3AECG" displayName="Fridericia"/>
+//	    </annotation>
+//	  </component>
+//	</annotation>
+//
+// Returns:
+//   - float64: QTc in milliseconds, rounded to 0.1 ms
+//   - int: Index of the QTc annotation in the set
+//   - error: Missing intervals or invalid formula
+func (f Formula) Annotate(as *types.AnnotationSet) (float64, int, error) {
+	qt, rr, err := Intervals(as)
+	if err != nil {
+		return 0, -1, err
+	}
+	value, err := f.Correct(qt, rr)
+	if err != nil {
+		return 0, -1, err
+	}
+	value = math.Round(value*10) / 10
+
+	idx := as.AddQTcInterval(value)
+	ann := as.GetAnnotation(idx)
+	if ann == nil {
+		return 0, -1, ErrInvalidInterval
+	}
+	f.annotateMethod(ann)
+	return value, idx, nil
+}
+
+// annotateMethod appends the provenance annotations to a QTc annotation.
+func (f Formula) annotateMethod(ann *types.Annotation) {
+	idx := ann.AddNestedCodedAnnotation(MethodAnnotationCode, "", types.CodedValue{
+		Code:           string(f.Method),
+		CodeSystemName: CodeSystemName,
+		DisplayName:    f.Method.DisplayName(),
+	})
+	if nested := ann.GetNestedAnnotation(idx); nested != nil {
+		nested.Code.CodeSystemName = CodeSystemName
+	}
+	if f.Method.IsFitted() {
+		ann.AddNestedAnnotationWithCodeSystemName(ExponentAnnotationCode, CodeSystemName, f.Exponent, "1")
+	}
+}
+
+// =============================================================================
+// Reading Corrections
+// =============================================================================
+
+// Correction is a QTc value read back from an annotation set.
+type Correction struct {
+	// Formula is the recorded correction method. Its Method is empty when the
+	// annotation carries no provenance (e.g., a vendor QTc).
+	Formula Formula
+
+	// Value is the QTc in milliseconds.
+	Value float64
+
+	// Annotation is the underlying MDC_ECG_TIME_PD_QTc annotation.
+	Annotation *types.Annotation
+}
+
+// Corrections returns every QTc annotation of the set with its recorded method.
+func Corrections(as *types.AnnotationSet) []Correction {
+	corrections := []Correction{}
+	if as == nil {
+		return corrections
+	}
+	for i := range as.Component {
+		ann := &as.Component[i].Annotation
+		if ann.Code == nil || (ann.Code.Code != string(types.MDC_ECG_TIME_PD_QTc) &&
+			ann.Code.Code != string(types.MDC_ECG_TIME_PD_QTC)) {
+			continue
+		}
+		value, ok := durationMs(ann)
+		if !ok {
+			continue
+		}
+		formula, _ := FormulaOf(ann)
+		corrections = append(corrections, Correction{Formula: formula, Value: value, Annotation: ann})
+	}
+	return corrections
+}
+
+// Find returns the first QTc annotation of the set computed with the given method.
+func Find(as *types.AnnotationSet, method Method) (Correction, bool) {
+	for _, c := range Corrections(as) {
+		if c.Formula.Method == method {
+			return c, true
+		}
+	}
+	return Correction{}, false
+}
+
+// FormulaOf returns the correction formula recorded on a QTc annotation.
+// Returns false if the annotation has no method annotation.
+func FormulaOf(ann *types.Annotation) (Formula, bool) {
+	nested := ann.GetNestedAnnotationByCode(MethodAnnotationCode)
+	if nested == nil || nested.Value == nil {
+		return Formula{}, false
+	}
+	ce, ok := nested.Value.GetCoded()
+	if !ok {
+		return Formula{}, false
+	}
+
+	formula := Formula{Method: Method(ce.Code)}
+	if exp, ok := ann.GetNestedAnnotationByCode(ExponentAnnotationCode).GetValueFloat(); ok {
+		formula.Exponent = exp
+	}
+	return formula, true
+}
@@ -0,0 +1,131 @@
+package qtc
+
+import (
+	"encoding/xml"
+	"errors"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// TestIntervals tests reading QT and RR from annotations
+func TestIntervals(t *testing.T) {
+	tests := []struct {
+		name    string
+		build   func(as *types.AnnotationSet)
+		wantQT  float64
+		wantRR  float64
+		wantErr error
+	}{
+		{
+			name: "QT and RR",
+			build: func(as *types.AnnotationSet) {
+				as.AddQTInterval(400)
+				as.AddRRInterval(800)
+			},
+			wantQT: 400, wantRR: 800,
+		},
+		{
+			name: "RR from heart rate",
+			build: func(as *types.AnnotationSet) {
+				as.AddQTInterval(400)
+				as.AddHeartRate(75)
+			},
+			wantQT: 400, wantRR: 800,
+		},
+		{
+			name: "Values in seconds",
+			build: func(as *types.AnnotationSet) {
+				as.AddAnnotation(string(types.MDC_ECG_TIME_PD_QT), string(types.MDC_OID), 0.4, "s")
+				as.AddAnnotation(string(types.MDC_ECG_TIME_PD_RR), string(types.MDC_OID), 0.8, "s")
+			},
+			wantQT: 400, wantRR: 800,
+		},
+		{
+			name:    "Missing QT",
+			build:   func(as *types.AnnotationSet) { as.AddRRInterval(800) },
+			wantErr: ErrMissingQT,
+		},
+		{
+			name:    "Missing RR",
+			build:   func(as *types.AnnotationSet) { as.AddQTInterval(400) },
+			wantErr: ErrMissingRR,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &types.AnnotationSet{}
+			tt.build(as)
+			qt, rr, err := Intervals(as)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Intervals() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Intervals() error = %v", err)
+			}
+			if qt != tt.wantQT || rr != tt.wantRR {
+				t.Errorf("Intervals() = (%v, %v), want (%v, %v)", qt, rr, tt.wantQT, tt.wantRR)
+			}
+		})
+	}
+}
+
+// TestFormula_Annotate_RoundTrip tests that correction methods survive marshal and unmarshal
+func TestFormula_Annotate_RoundTrip(t *testing.T) {
+	as := &types.AnnotationSet{}
+	as.AddQTInterval(380)
+	as.AddRRInterval(800)
+
+	valueF, _, err := Fridericia.Annotate(as)
+	if err != nil {
+		t.Fatalf("Fridericia.Annotate() error = %v", err)
+	}
+	valueI, _, err := Individual(0.3215).Annotate(as)
+	if err != nil {
+		t.Fatalf("Individual.Annotate() error = %v", err)
+	}
+
+	data, err := xml.Marshal(as)
+	if err != nil {
+		t.Fatalf("xml.Marshal() error = %v", err)
+	}
+	var decoded types.AnnotationSet
+	if err := xml.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("xml.Unmarshal() error = %v", err)
+	}
+
+	corrections := Corrections(&decoded)
+	if len(corrections) != 2 {
+		t.Fatalf("Corrections() = %d, want 2", len(corrections))
+	}
+
+	f, ok := Find(&decoded, MethodFridericia)
+	if !ok || f.Value != valueF {
+		t.Errorf("Find(QTcF) = %+v, %v; want value %v", f, ok, valueF)
+	}
+	i, ok := Find(&decoded, MethodIndividual)
+	if !ok || i.Value != valueI || i.Formula.Exponent != 0.3215 {
+		t.Errorf("Find(QTcI) = %+v, %v; want value %v with exponent 0.3215", i, ok, valueI)
+	}
+	if _, ok := Find(&decoded, MethodBazett); ok {
+		t.Errorf("Find(QTcB) found a correction that was not recorded")
+	}
+}
+
+// TestCorrections_WithoutProvenance tests QTc annotations recorded without a method
+func TestCorrections_WithoutProvenance(t *testing.T) {
+	as := &types.AnnotationSet{}
+	as.AddQTcInterval(410)
+
+	corrections := Corrections(as)
+	if len(corrections) != 1 {
+		t.Fatalf("Corrections() = %d, want 1", len(corrections))
+	}
+	if corrections[0].Formula.Method != "" || corrections[0].Value != 410 {
+		t.Errorf("Corrections()[0] = %+v, want value 410 without method", corrections[0])
+	}
+}
@@ -0,0 +1,182 @@
+package qtc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Exponent Fitting
+// =============================================================================
+
+// MinObservations is the minimum number of QT/RR pairs required to fit an exponent.
+const MinObservations = 3
+
+var (
+	// ErrNotEnoughObservations is returned when fewer than MinObservations pairs are available.
+	ErrNotEnoughObservations = fmt.Errorf("qtc: at least %d QT/RR observations are required", MinObservations)
+
+	// ErrNoRRVariation is returned when all RR intervals are identical.
+	ErrNoRRVariation = errors.New("qtc: RR intervals do not vary")
+
+	// ErrMixedSubjects is returned when individual fitting is given documents
+	// from more than one subject.
+	ErrMixedSubjects = errors.New("qtc: documents belong to different subjects")
+)
+
+// Observation is a QT/RR pair in milliseconds.
+type Observation struct {
+	QT float64
+	RR float64
+}
+
+// Fit is the result of a log-linear regression ln(QT) = ln(β) + α·ln(RR).
+//
+// RR is expressed in seconds, so that β is the QT predicted at 60 bpm.
+type Fit struct {
+	// Exponent is the fitted RR exponent α.
+	Exponent float64
+
+	// Intercept is β, the predicted QT in milliseconds at RR = 1 s.
+	Intercept float64
+
+	// N is the number of observations used.
+	N int
+
+	// R2 is the coefficient of determination of the log-linear fit.
+	R2 float64
+}
+
+// Population returns the QTcP formula using the fitted exponent.
+func (f Fit) Population() Formula {
+	return Population(f.Exponent)
+}
+
+// Individual returns the QTcI formula using the fitted exponent.
+func (f Fit) Individual() Formula {
+	return Individual(f.Exponent)
+}
+
+// FitExponent fits the RR exponent on a set of observations by ordinary
+// least squares on the log-transformed intervals.
+//
+// Non-positive observations are ignored.
+func FitExponent(obs []Observation) (Fit, error) {
+	var xs, ys []float64
+	for _, o := range obs {
+		if isPositive(o.QT) && isPositive(o.RR) {
+			xs = append(xs, math.Log(o.RR/1000))
+			ys = append(ys, math.Log(o.QT))
+		}
+	}
+	n := len(xs)
+	if n < MinObservations {
+		return Fit{}, ErrNotEnoughObservations
+	}
+
+	var meanX, meanY float64
+	for i := range xs {
+		meanX += xs[i]
+		meanY += ys[i]
+	}
+	meanX /= float64(n)
+	meanY /= float64(n)
+
+	var sxx, sxy, syy float64
+	for i := range xs {
+		dx, dy := xs[i]-meanX, ys[i]-meanY
+		sxx += dx * dx
+		sxy += dx * dy
+		syy += dy * dy
+	}
+	if sxx < 1e-12 {
+		return Fit{}, ErrNoRRVariation
+	}
+
+	alpha := sxy / sxx
+	fit := Fit{
+		Exponent:  alpha,
+		Intercept: math.Exp(meanY - alpha*meanX),
+		N:         n,
+		R2:        1,
+	}
+	if syy > 0 {
+		fit.R2 = sxy * sxy / (sxx * syy)
+	}
+	return fit, nil
+}
+
+// =============================================================================
+// Document Helpers
+// =============================================================================
+
+// Observations collects one QT/RR pair per series of the document, covering
+// every series and derived series, so that an ECG weighs the same in a fit
+// whatever the number of readers who annotated it.
+//
+// A series annotated by several readers (one annotation set each) gives the
+// mean QT and mean RR of its sets. Annotation sets lacking QT or RR (or heart
+// rate) are skipped, and series without a complete set give no pair.
+func Observations(doc *types.HL7AEcg) []Observation {
+	var obs []Observation
+	if doc == nil {
+		return obs
+	}
+
+	var visit func(s *types.Series)
+	visit = func(s *types.Series) {
+		var sum Observation
+		n := 0
+		for _, set := range s.GetAnnotationSets() {
+			if qt, rr, err := Intervals(set); err == nil {
+				sum.QT += qt
+				sum.RR += rr
+				n++
+			}
+		}
+		if n > 0 {
+			obs = append(obs, Observation{QT: sum.QT / float64(n), RR: sum.RR / float64(n)})
+		}
+		for i := range s.Derivation {
+			visit(&s.Derivation[i].DerivedSeries)
+		}
+	}
+	for i := range doc.Component {
+		visit(&doc.Component[i].Series)
+	}
+	return obs
+}
+
+// FitPopulation fits a population exponent on the pooled observations of
+// documents from any number of subjects (typically drug-free baseline ECGs).
+func FitPopulation(docs []*types.HL7AEcg) (Fit, error) {
+	var obs []Observation
+	for _, doc := range docs {
+		obs = append(obs, Observations(doc)...)
+	}
+	return FitExponent(obs)
+}
+
+// FitIndividual fits an individual exponent on documents of a single subject.
+//
+// Returns ErrMixedSubjects if the documents carry different trial subject IDs.
+// Documents without a subject ID are accepted.
+func FitIndividual(docs []*types.HL7AEcg) (Fit, error) {
+	var subject *types.ID
+	var obs []Observation
+	for _, doc := range docs {
+		if ts := doc.GetTrialSubject(); ts != nil && ts.ID != nil {
+			if subject == nil {
+				subject = ts.ID
+			} else if !subject.Same(*ts.ID) {
+				return Fit{}, fmt.Errorf("%w: %s/%s and %s/%s", ErrMixedSubjects,
+					subject.Root, subject.Extension, ts.ID.Root, ts.ID.Extension)
+			}
+		}
+		obs = append(obs, Observations(doc)...)
+	}
+	return FitExponent(obs)
+}
@@ -0,0 +1,109 @@
+package qtc
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// observationsFor generates exact QT/RR pairs following QT = beta * RR^alpha.
+func observationsFor(alpha, beta float64, rrs ...float64) []Observation {
+	obs := make([]Observation, len(rrs))
+	for i, rr := range rrs {
+		obs[i] = Observation{QT: beta * math.Pow(rr/1000, alpha), RR: rr}
+	}
+	return obs
+}
+
+// TestFitExponent tests the log-linear regression
+func TestFitExponent(t *testing.T) {
+	fit, err := FitExponent(observationsFor(0.35, 400, 700, 850, 1000, 1150, 1300))
+	if err != nil {
+		t.Fatalf("FitExponent() error = %v", err)
+	}
+	if math.Abs(fit.Exponent-0.35) > 1e-9 || math.Abs(fit.Intercept-400) > 1e-6 {
+		t.Errorf("FitExponent() = %+v, want exponent 0.35 and intercept 400", fit)
+	}
+	if fit.N != 5 || math.Abs(fit.R2-1) > 1e-9 {
+		t.Errorf("FitExponent() N = %d, R2 = %v", fit.N, fit.R2)
+	}
+
+	if _, err := FitExponent(observationsFor(0.35, 400, 800, 900)); !errors.Is(err, ErrNotEnoughObservations) {
+		t.Errorf("FitExponent() with 2 observations error = %v", err)
+	}
+	if _, err := FitExponent(observationsFor(0.35, 400, 800, 800, 800)); !errors.Is(err, ErrNoRRVariation) {
+		t.Errorf("FitExponent() with constant RR error = %v", err)
+	}
+}
+
+// newDocument builds a document for a subject with one annotated series per
+// observation, all holding the waveforms of the testdoc rhythm series.
+func newDocument(t *testing.T, subject string, obs ...Observation) *types.HL7AEcg {
+	t.Helper()
+	doc := &testdoc.New(t, testdoc.Doc{Subject: subject}).HL7AEcg
+	rhythm := doc.Component[0].Series
+	doc.Component = nil
+	for _, o := range obs {
+		series := rhythm
+		as := series.InitAnnotationSet("20250923103600")
+		as.AddQTInterval(o.QT)
+		as.AddRRInterval(o.RR)
+		doc.Component = append(doc.Component, types.Component{Series: series})
+	}
+	return doc
+}
+
+// TestFitIndividual tests fitting across documents of one subject
+func TestFitIndividual(t *testing.T) {
+	obs := observationsFor(0.3, 410, 750, 900, 1050, 1200)
+	docs := []*types.HL7AEcg{
+		newDocument(t, "SUBJ-001", obs[:2]...),
+		newDocument(t, "SUBJ-001", obs[2:]...),
+	}
+
+	fit, err := FitIndividual(docs)
+	if err != nil {
+		t.Fatalf("FitIndividual() error = %v", err)
+	}
+	if math.Abs(fit.Exponent-0.3) > 1e-9 || fit.N != 4 {
+		t.Errorf("FitIndividual() = %+v, want exponent 0.3 on 4 observations", fit)
+	}
+	if fit.Individual().Method != MethodIndividual {
+		t.Errorf("Fit.Individual() method = %q", fit.Individual().Method)
+	}
+
+	docs = append(docs, newDocument(t, "SUBJ-002", obs...))
+	if _, err := FitIndividual(docs); !errors.Is(err, ErrMixedSubjects) {
+		t.Errorf("FitIndividual() with two subjects error = %v, want ErrMixedSubjects", err)
+	}
+
+	fit, err = FitPopulation(docs)
+	if err != nil || fit.N != 8 {
+		t.Errorf("FitPopulation() = %+v, %v; want 8 observations", fit, err)
+	}
+}
+
+// TestObservations_Readers tests that a series read by several readers gives
+// one observation, the mean of their intervals
+func TestObservations_Readers(t *testing.T) {
+	doc := newDocument(t, "SUBJ-001", Observation{QT: 380, RR: 750})
+	series := types.Series{}
+	primary := series.AddAnnotationSet("20250924090000")
+	primary.AddPersonAuthor(types.AUTHOR_FUNCTION_PRIMARY_READER, "2.16.840.1.113883.3.5", "READER-01", "")
+	primary.AddQTInterval(400)
+	primary.AddRRInterval(800)
+	secondary := series.AddAnnotationSet("20250925090000")
+	secondary.AddPersonAuthor(types.AUTHOR_FUNCTION_SECONDARY_READER, "2.16.840.1.113883.3.5", "READER-02", "")
+	secondary.AddQTInterval(420)
+	secondary.AddRRInterval(1000)
+	doc.Component = append(doc.Component, types.Component{Series: series})
+
+	obs := Observations(doc)
+	want := []Observation{{QT: 380, RR: 750}, {QT: 410, RR: 900}}
+	if len(obs) != len(want) || obs[0] != want[0] || obs[1] != want[1] {
+		t.Errorf("Observations() = %v, want %v", obs, want)
+	}
+}
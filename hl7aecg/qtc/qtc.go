@@ -0,0 +1,179 @@
+// Package qtc implements QT interval correction formulas.
+//
+// Supported methods:
+//   - Bazett:     QTc = QT / RR^(1/2)
+//   - Fridericia: QTc = QT / RR^(1/3)
+//   - Framingham: QTc = QT + 154 × (1 − RR)
+//   - Hodges:     QTc = QT + 1.75 × (HR − 60)
+//   - Population (QTcP) and Individual (QTcI): QTc = QT / RR^α, with α fitted
+//     on drug-free ECGs of the study population or of a single subject
+//
+// QT and QTc are expressed in milliseconds; RR is converted to seconds
+// inside the formulas as required by their original definitions.
+//
+// Corrections are written to an AnnotationSet as MDC_ECG_TIME_PD_QTc
+// annotations carrying the correction method (and exponent when fitted) as
+// nested coded annotations, so that the provenance of each value survives an
+// XML round-trip.
+//
+// Example:
+//
+//	annSet := series.GetOrCreateAnnotationSet("20250923103600")
+//	qtcF, _, err := qtc.Fridericia.Annotate(annSet)
+//
+//	fit, err := qtc.FitIndividual(baselineDocs)
+//	qtcI, _, err := fit.Individual().Annotate(annSet)
+package qtc
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// =============================================================================
+// Methods
+// =============================================================================
+
+// Method identifies a QT correction formula.
+//
+// The values are used as codes of the coded method annotation.
+type Method string
+
+const (
+	MethodBazett     Method = "QTcB"  // Bazett (1920)
+	MethodFridericia Method = "QTcF"  // Fridericia (1920)
+	MethodFramingham Method = "QTcFm" // Framingham linear regression (Sagie 1992)
+	MethodHodges     Method = "QTcH"  // Hodges (1983)
+	MethodPopulation Method = "QTcP"  // Study-specific population correction
+	MethodIndividual Method = "QTcI"  // Subject-specific individual correction
+)
+
+// displayNames holds the human-readable name of each method.
+var displayNames = map[Method]string{
+	MethodBazett:     "Bazett",
+	MethodFridericia: "Fridericia",
+	MethodFramingham: "Framingham",
+	MethodHodges:     "Hodges",
+	MethodPopulation: "Population-specific",
+	MethodIndividual: "Individual-specific",
+}
+
+// DisplayName returns the human-readable name of the method.
+func (m Method) DisplayName() string {
+	return displayNames[m]
+}
+
+// IsValid reports whether m is a supported method.
+func (m Method) IsValid() bool {
+	_, ok := displayNames[m]
+	return ok
+}
+
+// IsFitted reports whether the method requires a fitted exponent.
+func (m Method) IsFitted() bool {
+	return m == MethodPopulation || m == MethodIndividual
+}
+
+// =============================================================================
+// Errors
+// =============================================================================
+
+var (
+	// ErrUnknownMethod is returned for an unsupported correction method.
+	ErrUnknownMethod = errors.New("qtc: unknown correction method")
+
+	// ErrInvalidInterval is returned when QT or RR is not a positive number.
+	ErrInvalidInterval = errors.New("qtc: QT and RR must be positive numbers")
+
+	// ErrInvalidExponent is returned when a fitted method has no positive exponent.
+	ErrInvalidExponent = errors.New("qtc: fitted methods require a positive exponent")
+)
+
+// =============================================================================
+// Formulas
+// =============================================================================
+
+// Formula is a QT correction method together with its parameters.
+type Formula struct {
+	// Method identifies the correction formula.
+	Method Method
+
+	// Exponent is the RR exponent α of the QTcP and QTcI methods.
+	// It is ignored by the fixed formulas.
+	Exponent float64
+}
+
+// Fixed correction formulas.
+var (
+	Bazett     = Formula{Method: MethodBazett}
+	Fridericia = Formula{Method: MethodFridericia}
+	Framingham = Formula{Method: MethodFramingham}
+	Hodges     = Formula{Method: MethodHodges}
+)
+
+// Population returns the study-specific population formula QT / RR^exponent.
+func Population(exponent float64) Formula {
+	return Formula{Method: MethodPopulation, Exponent: exponent}
+}
+
+// Individual returns the subject-specific formula QT / RR^exponent.
+func Individual(exponent float64) Formula {
+	return Formula{Method: MethodIndividual, Exponent: exponent}
+}
+
+// Validate checks the method and, for fitted methods, the exponent.
+func (f Formula) Validate() error {
+	if !f.Method.IsValid() {
+		return fmt.Errorf("%w: %q", ErrUnknownMethod, f.Method)
+	}
+	if f.Method.IsFitted() && (f.Exponent <= 0 || math.IsNaN(f.Exponent) || math.IsInf(f.Exponent, 0)) {
+		return ErrInvalidExponent
+	}
+	return nil
+}
+
+// String returns the method code, with the exponent for fitted methods.
+func (f Formula) String() string {
+	if f.Method.IsFitted() {
+		return fmt.Sprintf("%s(α=%.4g)", f.Method, f.Exponent)
+	}
+	return string(f.Method)
+}
+
+// Correct returns the corrected QT interval.
+//
+// Parameters:
+//   - qt: QT interval in milliseconds
+//   - rr: RR interval in milliseconds
+//
+// Returns:
+//   - float64: QTc in milliseconds
+//   - error: ErrInvalidInterval, ErrUnknownMethod or ErrInvalidExponent
+func (f Formula) Correct(qt, rr float64) (float64, error) {
+	if err := f.Validate(); err != nil {
+		return 0, err
+	}
+	if !isPositive(qt) || !isPositive(rr) {
+		return 0, ErrInvalidInterval
+	}
+
+	rrSec := rr / 1000
+	switch f.Method {
+	case MethodBazett:
+		return qt / math.Sqrt(rrSec), nil
+	case MethodFridericia:
+		return qt / math.Cbrt(rrSec), nil
+	case MethodFramingham:
+		return qt + 154*(1-rrSec), nil
+	case MethodHodges:
+		return qt + 1.75*(60/rrSec-60), nil
+	default:
+		return qt / math.Pow(rrSec, f.Exponent), nil
+	}
+}
+
+// isPositive reports whether v is a finite, strictly positive number.
+func isPositive(v float64) bool {
+	return v > 0 && !math.IsInf(v, 0) && !math.IsNaN(v)
+}
@@ -0,0 +1,64 @@
+package qtc
+
+import (
+	"errors"
+	"math"
+	"testing"
+)
+
+// TestFormula_Correct tests each correction formula against reference values
+func TestFormula_Correct(t *testing.T) {
+	tests := []struct {
+		name    string
+		formula Formula
+		qt, rr  float64
+		want    float64
+		wantErr error
+	}{
+		{name: "Bazett at 60 bpm", formula: Bazett, qt: 400, rr: 1000, want: 400},
+		{name: "Bazett at 75 bpm", formula: Bazett, qt: 380, rr: 800, want: 380 / math.Sqrt(0.8)},
+		{name: "Fridericia at 75 bpm", formula: Fridericia, qt: 380, rr: 800, want: 380 / math.Cbrt(0.8)},
+		{name: "Framingham at 75 bpm", formula: Framingham, qt: 380, rr: 800, want: 380 + 154*0.2},
+		{name: "Hodges at 75 bpm", formula: Hodges, qt: 380, rr: 800, want: 380 + 1.75*15},
+		{name: "Population exponent", formula: Population(0.4), qt: 380, rr: 800, want: 380 / math.Pow(0.8, 0.4)},
+		{name: "Individual exponent", formula: Individual(0.3), qt: 380, rr: 1200, want: 380 / math.Pow(1.2, 0.3)},
+		{name: "Zero RR", formula: Bazett, qt: 380, rr: 0, wantErr: ErrInvalidInterval},
+		{name: "NaN QT", formula: Fridericia, qt: math.NaN(), rr: 800, wantErr: ErrInvalidInterval},
+		{name: "Unknown method", formula: Formula{Method: "QTcX"}, qt: 380, rr: 800, wantErr: ErrUnknownMethod},
+		{name: "Missing exponent", formula: Individual(0), qt: 380, rr: 800, wantErr: ErrInvalidExponent},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.formula.Correct(tt.qt, tt.rr)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Correct() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Correct() error = %v", err)
+			}
+			if math.Abs(got-tt.want) > 1e-9 {
+				t.Errorf("Correct() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestMethod tests method metadata
+func TestMethod(t *testing.T) {
+	if MethodFridericia.DisplayName() != "Fridericia" {
+		t.Errorf("DisplayName() = %q, want Fridericia", MethodFridericia.DisplayName())
+	}
+	if !MethodIndividual.IsFitted() || MethodBazett.IsFitted() {
+		t.Errorf("IsFitted() mismatch")
+	}
+	if Method("QTcX").IsValid() {
+		t.Errorf("IsValid() = true for unknown method")
+	}
+	if got := Population(0.35).String(); got != "QTcP(α=0.35)" {
+		t.Errorf("String() = %q", got)
+	}
+}
@@ -0,0 +1,350 @@
+package hl7aecg
+
+import (
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+var (
+	// ErrInvalidQuantization is returned when quantization settings or samples cannot be used.
+	ErrInvalidQuantization = errors.New("hl7aecg: invalid quantization")
+
+	// ErrQuantizationRange is returned when samples do not fit the digit range at the requested resolution.
+	ErrQuantizationRange = errors.New("hl7aecg: samples exceed the digit range")
+
+	// ErrNoParentSeries is returned when a derived series is added before any series.
+	ErrNoParentSeries = errors.New("hl7aecg: no parent series")
+)
+
+// =============================================================================
+// Quantization
+// =============================================================================
+
+// DefaultQuantizationBits is the bit depth of the digits when none is set.
+const DefaultQuantizationBits = 16
+
+// Quantization selects how floating-point millivolt samples are converted to
+// digits by the *Millivolts series builders.
+//
+// The origin and scale are written in the series encoding unit (see
+// SetSeriesEncoding). SLIST_INT leads round them to integer µV, which can
+// make the scale coarser than requested.
+type Quantization struct {
+	// Resolution is the voltage per digit in mV (e.g. 0.005 for 5 µV).
+	// Zero picks the finest resolution whose digits fit Bits.
+	Resolution float64
+
+	// Bits is the bit depth of the digits: they are guaranteed to be in
+	// [-2^(Bits-1), 2^(Bits-1)-1]. From 2 to 32, defaults to
+	// DefaultQuantizationBits.
+	Bits int
+
+	// PerLead picks an origin and scale for each lead. By default every lead
+	// of the series shares one origin and scale, fitted to all of them.
+	PerLead bool
+}
+
+// LeadQuantization is the encoding chosen for one lead and the error it introduces.
+type LeadQuantization struct {
+	// Origin and Scale are the values written, in Unit.
+	Origin float64
+	Scale  float64
+	Unit   string
+
+	// MinDigit and MaxDigit are the extreme digits written.
+	MinDigit int32
+	MaxDigit int32
+
+	// MaxError and RMSError are the maximum and root mean square differences
+	// between the samples and the decoded digits, in mV.
+	MaxError float64
+	RMSError float64
+}
+
+// QuantizationReport is the quantization of every lead of a series.
+type QuantizationReport map[types.LeadCode]LeadQuantization
+
+// MaxError returns the largest quantization error of the leads, in mV.
+func (r QuantizationReport) MaxError() float64 {
+	maxErr := 0.0
+	for _, lead := range r {
+		maxErr = max(maxErr, lead.MaxError)
+	}
+	return maxErr
+}
+
+// digitRange returns the digit bounds of the bit depth.
+func (q Quantization) digitRange() (lo, hi int64) {
+	bits := q.Bits
+	if bits == 0 {
+		bits = DefaultQuantizationBits
+	}
+	return -1 << (bits - 1), 1<<(bits-1) - 1
+}
+
+// validate checks the resolution and bit depth.
+func (q Quantization) validate() error {
+	if q.Bits != 0 && (q.Bits < 2 || q.Bits > 32) {
+		return fmt.Errorf("%w: bit depth %d is not between 2 and 32", ErrInvalidQuantization, q.Bits)
+	}
+	if q.Resolution < 0 || math.IsNaN(q.Resolution) || math.IsInf(q.Resolution, 0) {
+		return fmt.Errorf("%w: resolution %v mV", ErrInvalidQuantization, q.Resolution)
+	}
+	return nil
+}
+
+// quantizeLeads converts millivolt samples to digits with the origin and
+// scale in unit, rounded to integers if integer is set.
+func (q Quantization) quantizeLeads(
+	leads map[types.LeadCode][]float64,
+	unit string,
+	integer bool,
+) (map[types.LeadCode]leadData, QuantizationReport, error) {
+	if err := q.validate(); err != nil {
+		return nil, nil, err
+	}
+	uvPerUnit, ok := types.UCUMVoltageUnitMicrovolts(unit)
+	if !ok {
+		return nil, nil, fmt.Errorf("%w: %q is not a voltage unit", ErrInvalidQuantization, unit)
+	}
+	mvPerUnit := uvPerUnit / 1e3 // The sample values are divided by it
+
+	// Sample bounds in unit, per lead and over the series. Leads without
+	// samples are fitted as if they were 0.
+	type bounds struct{ lo, hi float64 }
+	leadBounds := make(map[types.LeadCode]bounds, len(leads))
+	var all *bounds
+	for leadCode, samples := range leads {
+		b := bounds{}
+		for i, v := range samples {
+			if math.IsNaN(v) || math.IsInf(v, 0) {
+				return nil, nil, fmt.Errorf("%w: lead %s sample %d is %v", ErrInvalidQuantization, leadCode, i, v)
+			}
+			if i == 0 {
+				b = bounds{v / mvPerUnit, v / mvPerUnit}
+			}
+			b.lo, b.hi = min(b.lo, v/mvPerUnit), max(b.hi, v/mvPerUnit)
+		}
+		leadBounds[leadCode] = b
+		if len(samples) == 0 {
+			continue
+		}
+		if all == nil {
+			all = &b
+		}
+		all.lo, all.hi = min(all.lo, b.lo), max(all.hi, b.hi)
+	}
+	if all == nil {
+		all = &bounds{}
+	}
+
+	data := make(map[types.LeadCode]leadData, len(leads))
+	report := make(QuantizationReport, len(leads))
+	var origin, scale float64
+	var err error
+	if !q.PerLead {
+		if origin, scale, err = q.fit(all.lo, all.hi, mvPerUnit, integer); err != nil {
+			return nil, nil, err
+		}
+	}
+	for leadCode, samples := range leads {
+		if q.PerLead {
+			b := leadBounds[leadCode]
+			if origin, scale, err = q.fit(b.lo, b.hi, mvPerUnit, integer); err != nil {
+				return nil, nil, fmt.Errorf("lead %s: %w", leadCode, err)
+			}
+		}
+
+		digits := make([]int32, len(samples))
+		lq := LeadQuantization{Origin: origin, Scale: scale, Unit: unit}
+		sumSq := 0.0
+		for i, v := range samples {
+			d := int32(math.Round((v/mvPerUnit - origin) / scale))
+			digits[i] = d
+			if i == 0 || d < lq.MinDigit {
+				lq.MinDigit = d
+			}
+			if i == 0 || d > lq.MaxDigit {
+				lq.MaxDigit = d
+			}
+			e := math.Abs((origin+float64(d)*scale)*mvPerUnit - v)
+			lq.MaxError = max(lq.MaxError, e)
+			sumSq += e * e
+		}
+		if len(samples) > 0 {
+			lq.RMSError = math.Sqrt(sumSq / float64(len(samples)))
+		}
+		data[leadCode] = leadData{digits: digits, origin: origin, scale: scale}
+		report[leadCode] = lq
+	}
+	return data, report, nil
+}
+
+// fit returns the origin and scale, in the unit of mvPerUnit, with which the
+// samples in [lo, hi] fit the digit range.
+//
+// The origin is 0 when the samples fit around it, so that digits read as
+// voltages, else the middle of the samples, else the origin mapping lo to the
+// lowest digit.
+func (q Quantization) fit(lo, hi, mvPerUnit float64, integer bool) (origin, scale float64, err error) {
+	dlo, dhi := q.digitRange()
+	fits := func(origin, scale float64) bool {
+		return math.Round((lo-origin)/scale) >= float64(dlo) && math.Round((hi-origin)/scale) <= float64(dhi)
+	}
+
+	explicit := q.Resolution > 0
+	switch {
+	case explicit:
+		scale = q.Resolution / mvPerUnit
+	case hi > lo:
+		scale = (hi - lo) / float64(dhi-dlo)
+	default:
+		// Constant samples: any scale is exact with an origin on the value
+		scale = 1e-3 / mvPerUnit
+	}
+	if integer {
+		scale = max(1, math.Ceil(scale))
+	}
+
+	// The automatic scale fits up to rounding: widen it until it does
+	for range 8 {
+		for _, origin := range []float64{0, (lo + hi) / 2, lo - float64(dlo)*scale} {
+			if integer {
+				origin = math.Round(origin)
+			}
+			if fits(origin, scale) {
+				return origin, scale, nil
+			}
+		}
+		if explicit {
+			return 0, 0, fmt.Errorf("%w: %v to %v mV span more than %d digits of %v mV",
+				ErrQuantizationRange, lo*mvPerUnit, hi*mvPerUnit, dhi-dlo+1, q.Resolution)
+		}
+		if integer {
+			scale++
+		} else {
+			scale = math.Nextafter(scale*(1+1e-12), math.Inf(1))
+		}
+	}
+	return 0, 0, fmt.Errorf("%w: no scale found for %v to %v mV", ErrQuantizationRange, lo*mvPerUnit, hi*mvPerUnit)
+}
+
+// quantizeSeries quantizes millivolt samples for the series encoding.
+func (h *Hl7xml) quantizeSeries(
+	leads map[types.LeadCode][]float64,
+	q Quantization,
+) (map[types.LeadCode]leadData, QuantizationReport, error) {
+	enc := h.SeriesEncoding()
+	return q.quantizeLeads(leads, enc.Unit, enc.Values == VALUE_SLIST_INT)
+}
+
+// =============================================================================
+// Builder Methods for Millivolt Samples
+// =============================================================================
+
+// AddRhythmSeriesMillivolts adds a rhythm series from floating-point samples
+// in mV, choosing the origin and scale with q. See AddRhythmSeries.
+//
+// Returns the quantization of every lead, or ErrInvalidQuantization or
+// ErrQuantizationRange, in which case no series is added.
+//
+// Example:
+//
+//	report, err := h.AddRhythmSeriesMillivolts(start, end, nil, nil, 500, leads,
+//	    hl7aecg.Quantization{Resolution: 0.005}) // 5 µV per digit
+//	...
+//	fmt.Printf("max error %.4f mV\n", report.MaxError())
+func (h *Hl7xml) AddRhythmSeriesMillivolts(
+	startTime, endTime string,
+	inclusive_low, inclusive_high *bool,
+	sampleRate float64,
+	leads map[types.LeadCode][]float64,
+	q Quantization,
+) (QuantizationReport, error) {
+	data, report, err := h.quantizeSeries(leads, q)
+	if err != nil {
+		return nil, err
+	}
+	series := h.buildSeries(
+		types.RHYTHM_CODE,
+		startTime,
+		endTime,
+		buildTimeSequence(h.SeriesEncoding().Time, startTime, sampleRate, leadLength(data)),
+		data,
+	)
+	if inclusive_low != nil {
+		series.EffectiveTime.Low.Inclusive = inclusive_low
+	}
+	if inclusive_high != nil {
+		series.EffectiveTime.High.Inclusive = inclusive_high
+	}
+
+	h.HL7AEcg.Component = append(h.HL7AEcg.Component, types.Component{Series: *series})
+	h.applyStudySeries()
+	return report, nil
+}
+
+// AddRepresentativeBeatSeriesMillivolts adds a representative beat series
+// from floating-point samples in mV. See AddRhythmSeriesMillivolts.
+func (h *Hl7xml) AddRepresentativeBeatSeriesMillivolts(
+	startTime, endTime string,
+	sampleRate float64,
+	leads map[types.LeadCode][]float64,
+	q Quantization,
+) (QuantizationReport, error) {
+	data, report, err := h.quantizeSeries(leads, q)
+	if err != nil {
+		return nil, err
+	}
+	series := h.buildSeries(
+		types.REPRESENTATIVE_BEAT_CODE,
+		startTime,
+		endTime,
+		buildTimeSequence(h.SeriesEncoding().Time, startTime, sampleRate, leadLength(data)),
+		data,
+	)
+
+	h.HL7AEcg.Component = append(h.HL7AEcg.Component, types.Component{Series: *series})
+	h.applyStudySeries()
+	return report, nil
+}
+
+// AddDerivedSeriesMillivolts adds a derived series from floating-point
+// samples in mV to the most recently added series. See AddDerivedSeries and
+// AddRhythmSeriesMillivolts.
+//
+// Returns ErrNoParentSeries if no series was added before.
+func (h *Hl7xml) AddDerivedSeriesMillivolts(
+	seriesCode types.SeriesTypeCode,
+	startTime, endTime string,
+	startTimeLowInclusive, endTimeHighInclusive *bool,
+	sampleRate float64,
+	leads map[types.LeadCode][]float64,
+	q Quantization,
+) (QuantizationReport, error) {
+	if len(h.HL7AEcg.Component) == 0 {
+		return nil, ErrNoParentSeries
+	}
+	data, report, err := h.quantizeSeries(leads, q)
+	if err != nil {
+		return nil, err
+	}
+	derivedSeries := h.buildDerivedSeries(
+		seriesCode,
+		startTime,
+		endTime,
+		startTimeLowInclusive,
+		endTimeHighInclusive,
+		sampleRate,
+		data,
+	)
+
+	lastComponent := &h.HL7AEcg.Component[len(h.HL7AEcg.Component)-1]
+	lastComponent.Series.Derivation = append(lastComponent.Series.Derivation, types.Derivation{
+		DerivedSeries: *derivedSeries,
+	})
+	return report, nil
+}
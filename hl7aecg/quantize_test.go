@@ -0,0 +1,215 @@
+package hl7aecg
+
+import (
+	"errors"
+	"math"
+	"math/rand"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// millivoltLeads are the leads of the quantization tests, in mV.
+var millivoltLeads = map[types.LeadCode][]float64{
+	types.MDC_ECG_LEAD_I:  {-0.1234, 0, 0.0507, 0.25},
+	types.MDC_ECG_LEAD_II: {-3, 0.5, 1.0001, 5},
+}
+
+// TestAddRhythmSeriesMillivolts tests the origin, scale and digits chosen for
+// a resolution or a bit depth, and the error reported
+func TestAddRhythmSeriesMillivolts(t *testing.T) {
+	tests := []struct {
+		name       string
+		enc        SeriesEncoding
+		q          Quantization
+		wantOrigin float64
+		wantScale  float64
+		wantUnit   string
+	}{
+		{
+			name:      "5 µV resolution",
+			q:         Quantization{Resolution: 0.005},
+			wantScale: 5,
+			wantUnit:  types.UNIT_MICROVOLT,
+		},
+		{
+			name:      "resolution in mV",
+			enc:       SeriesEncoding{Unit: types.UNIT_MILLIVOLT},
+			q:         Quantization{Resolution: 0.001},
+			wantScale: 0.001,
+			wantUnit:  types.UNIT_MILLIVOLT,
+		},
+		{
+			// 8 mV over 255 steps fits neither around 0 nor around the middle
+			// (5 mV rounds to digit 128): -3 mV maps to digit -128
+			name:       "8 bits",
+			q:          Quantization{Bits: 8},
+			wantOrigin: -3000 + 128*8000.0/255,
+			wantScale:  8000.0 / 255,
+			wantUnit:   types.UNIT_MICROVOLT,
+		},
+		{
+			// Rounded up to 32 µV, the digits fit around the middle
+			name:       "8 bits SLIST_INT",
+			enc:        SeriesEncoding{Values: VALUE_SLIST_INT},
+			q:          Quantization{Bits: 8},
+			wantOrigin: 1000,
+			wantScale:  32,
+			wantUnit:   types.UNIT_MICROVOLT,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("")
+			if err := h.SetSeriesEncoding(tt.enc); err != nil {
+				t.Fatalf("SetSeriesEncoding() error = %v", err)
+			}
+			report, err := h.AddRhythmSeriesMillivolts("20240315101500.000", "20240315101500.008",
+				nil, nil, 500, millivoltLeads, tt.q)
+			if err != nil {
+				t.Fatalf("AddRhythmSeriesMillivolts() error = %v", err)
+			}
+
+			dlo, dhi := tt.q.digitRange()
+			for lead, lq := range report {
+				if math.Abs(lq.Origin-tt.wantOrigin) > 1e-9 || math.Abs(lq.Scale-tt.wantScale) > 1e-9 || lq.Unit != tt.wantUnit {
+					t.Errorf("lead %s origin, scale = %v, %v %s, want %v, %v %s",
+						lead, lq.Origin, lq.Scale, lq.Unit, tt.wantOrigin, tt.wantScale, tt.wantUnit)
+				}
+				if int64(lq.MinDigit) < dlo || int64(lq.MaxDigit) > dhi {
+					t.Errorf("lead %s digits %d to %d outside %d to %d", lead, lq.MinDigit, lq.MaxDigit, dlo, dhi)
+				}
+				uvPerUnit, _ := types.UCUMVoltageUnitMicrovolts(lq.Unit)
+				halfStep := lq.Scale * uvPerUnit / 1e3 / 2
+				if lq.MaxError > halfStep*(1+1e-9) || lq.RMSError > lq.MaxError {
+					t.Errorf("lead %s error max %v, RMS %v, want at most %v", lead, lq.MaxError, lq.RMSError, halfStep)
+				}
+			}
+
+			out, err := h.String()
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+			back := NewHl7xml("")
+			if err := back.Unmarshal([]byte(out)); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			for lead, samples := range millivoltLeads {
+				values, err := back.HL7AEcg.Component[0].Series.GetLeadValues(lead)
+				if err != nil {
+					t.Fatalf("GetLeadValues(%s) error = %v", lead, err)
+				}
+				for i, v := range samples {
+					if got := math.Abs(values[i]/1000 - v); got > report[lead].MaxError+1e-9 {
+						t.Errorf("lead %s sample %d decodes %v µV from %v mV, more than the reported error", lead, i, values[i], v)
+					}
+				}
+			}
+		})
+	}
+}
+
+// TestAddRhythmSeriesMillivolts_PerLead tests that each lead gets its own
+// scale, and the series one scale fitted to every lead
+func TestAddRhythmSeriesMillivolts_PerLead(t *testing.T) {
+	h := NewHl7xml("")
+	perSet, err := h.AddRhythmSeriesMillivolts("", "", nil, nil, 500, millivoltLeads, Quantization{Bits: 12})
+	if err != nil {
+		t.Fatalf("AddRhythmSeriesMillivolts() error = %v", err)
+	}
+	perLead, err := h.AddRhythmSeriesMillivolts("", "", nil, nil, 500, millivoltLeads, Quantization{Bits: 12, PerLead: true})
+	if err != nil {
+		t.Fatalf("AddRhythmSeriesMillivolts() error = %v", err)
+	}
+
+	if perSet[types.MDC_ECG_LEAD_I].Scale != perSet[types.MDC_ECG_LEAD_II].Scale {
+		t.Errorf("per set scales differ: %v, %v", perSet[types.MDC_ECG_LEAD_I].Scale, perSet[types.MDC_ECG_LEAD_II].Scale)
+	}
+	if perLead[types.MDC_ECG_LEAD_I].Scale >= perSet[types.MDC_ECG_LEAD_I].Scale {
+		t.Errorf("per lead scale %v is not finer than the per set one %v", perLead[types.MDC_ECG_LEAD_I].Scale, perSet[types.MDC_ECG_LEAD_I].Scale)
+	}
+	if perLead[types.MDC_ECG_LEAD_I].MaxError >= perSet[types.MDC_ECG_LEAD_I].MaxError {
+		t.Errorf("per lead error %v is not lower than the per set one %v", perLead[types.MDC_ECG_LEAD_I].MaxError, perSet[types.MDC_ECG_LEAD_I].MaxError)
+	}
+	if perLead.MaxError() != perLead[types.MDC_ECG_LEAD_II].MaxError {
+		t.Errorf("MaxError() = %v, want lead II error %v", perLead.MaxError(), perLead[types.MDC_ECG_LEAD_II].MaxError)
+	}
+}
+
+// TestAddRhythmSeriesMillivolts_Errors tests that invalid settings and
+// samples, or samples exceeding the digit range, add no series
+func TestAddRhythmSeriesMillivolts_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		leads   map[types.LeadCode][]float64
+		q       Quantization
+		wantErr error
+	}{
+		{
+			name:    "1 µV over 8 bits",
+			leads:   millivoltLeads,
+			q:       Quantization{Resolution: 0.001, Bits: 8},
+			wantErr: ErrQuantizationRange,
+		},
+		{name: "bit depth", leads: millivoltLeads, q: Quantization{Bits: 33}, wantErr: ErrInvalidQuantization},
+		{name: "negative resolution", leads: millivoltLeads, q: Quantization{Resolution: -1}, wantErr: ErrInvalidQuantization},
+		{
+			name:    "NaN sample",
+			leads:   map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: {0, math.NaN()}},
+			wantErr: ErrInvalidQuantization,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("")
+			_, err := h.AddRhythmSeriesMillivolts("", "", nil, nil, 500, tt.leads, tt.q)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("AddRhythmSeriesMillivolts() error = %v, want %v", err, tt.wantErr)
+			}
+			if len(h.HL7AEcg.Component) != 0 {
+				t.Errorf("series count = %d, want 0", len(h.HL7AEcg.Component))
+			}
+		})
+	}
+
+	h := NewHl7xml("")
+	_, err := h.AddDerivedSeriesMillivolts(types.REPRESENTATIVE_BEAT_CODE, "", "", nil, nil, 500, millivoltLeads, Quantization{})
+	if !errors.Is(err, ErrNoParentSeries) {
+		t.Errorf("AddDerivedSeriesMillivolts() error = %v, want ErrNoParentSeries", err)
+	}
+}
+
+// TestQuantization_DigitRange tests that the digits of random signals always
+// fit the bit depth, in every unit
+func TestQuantization_DigitRange(t *testing.T) {
+	r := rand.New(rand.NewSource(1))
+	for _, unit := range []string{types.UNIT_MICROVOLT, types.UNIT_MILLIVOLT, types.UNIT_VOLT} {
+		for bits := 2; bits <= 32; bits++ {
+			for _, integer := range []bool{false, true} {
+				amplitude := math.Pow(10, r.Float64()*6-3)
+				offset := (r.Float64() - 0.5) * 20 * amplitude
+				samples := make([]float64, 200)
+				for i := range samples {
+					samples[i] = offset + (r.Float64()-0.5)*amplitude
+				}
+
+				q := Quantization{Bits: bits}
+				_, report, err := q.quantizeLeads(map[types.LeadCode][]float64{types.MDC_ECG_LEAD_I: samples}, unit, integer)
+				if err != nil {
+					t.Fatalf("%s, %d bits, integer %v: error = %v", unit, bits, integer, err)
+				}
+				lq := report[types.MDC_ECG_LEAD_I]
+				dlo, dhi := q.digitRange()
+				if int64(lq.MinDigit) < dlo || int64(lq.MaxDigit) > dhi {
+					t.Errorf("%s, %d bits, integer %v: digits %d to %d outside %d to %d",
+						unit, bits, integer, lq.MinDigit, lq.MaxDigit, dlo, dhi)
+				}
+				if integer && (lq.Origin != math.Trunc(lq.Origin) || lq.Scale != math.Trunc(lq.Scale)) {
+					t.Errorf("%s, %d bits: origin %v and scale %v are not integers", unit, bits, lq.Origin, lq.Scale)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package resample
+
+import (
+	"math"
+)
+
+// =============================================================================
+// Interpolation Kernel
+// =============================================================================
+
+// kernel is a Kaiser-windowed sinc low-pass filter, in units of input samples.
+type kernel struct {
+	// cutoff is the cutoff frequency as a fraction of the input Nyquist
+	// frequency: the lower of the two rates times Options.Cutoff.
+	cutoff float64
+
+	// halfWidth is the number of input samples on each side of the output
+	// sample that contribute to it.
+	halfWidth float64
+
+	beta, i0Beta float64
+}
+
+// newKernel returns the anti-aliasing kernel for a rate ratio (to / from).
+func newKernel(ratio float64, opts Options) kernel {
+	cutoff := min(1, ratio) * opts.Cutoff
+	return kernel{
+		cutoff:    cutoff,
+		halfWidth: float64(opts.Zeros) / cutoff,
+		beta:      opts.Beta,
+		i0Beta:    besselI0(opts.Beta),
+	}
+}
+
+// at returns the kernel value u input samples away from its center.
+func (k kernel) at(u float64) float64 {
+	if math.Abs(u) >= k.halfWidth {
+		return 0
+	}
+	r := u / k.halfWidth
+	window := besselI0(k.beta*math.Sqrt(1-r*r)) / k.i0Beta
+	return k.cutoff * sinc(k.cutoff*u) * window
+}
+
+// taps returns the weights of the input samples first, first+1, ... for an
+// output sample at position t (in input samples), normalized so that a
+// constant signal is preserved.
+func (k kernel) taps(t float64) (first int, weights []float64) {
+	first = int(math.Ceil(t - k.halfWidth))
+	last := int(math.Floor(t + k.halfWidth))
+	weights = make([]float64, last-first+1)
+	sum := 0.0
+	for i := range weights {
+		weights[i] = k.at(t - float64(first+i))
+		sum += weights[i]
+	}
+	for i := range weights {
+		weights[i] /= sum
+	}
+	return first, weights
+}
+
+// apply returns the weighted sum of the samples from first, holding the edge
+// samples beyond the ends of the recording.
+func apply(samples []float64, first int, weights []float64) float64 {
+	y := 0.0
+	last := len(samples) - 1
+	for i, w := range weights {
+		y += w * samples[min(max(first+i, 0), last)]
+	}
+	return y
+}
+
+// sinc returns the normalized sinc function sin(πx)/(πx).
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// besselI0 returns the modified Bessel function of the first kind of order 0,
+// by its power series.
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	q := x * x / 4
+	for k := 1; k < 500; k++ {
+		term *= q / float64(k*k)
+		sum += term
+		if term < sum*1e-17 {
+			break
+		}
+	}
+	return sum
+}
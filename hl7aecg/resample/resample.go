@@ -0,0 +1,477 @@
+// Package resample converts the waveforms of a series to another sample rate.
+//
+// Samples are interpolated with a Kaiser-windowed sinc low-pass filter whose
+// cutoff is below the lower of the two Nyquist frequencies, so that
+// downsampling does not alias. When the ratio of the rates is a fraction with
+// a small denominator (250 → 500, 1000 → 500, 1024 → 500 Hz), the filter
+// weights are computed once per phase (polyphase resampling); other ratios
+// compute them for every output sample.
+//
+// ResampleSeries rewrites a parsed series in place: the increment of the
+// time sequence, the digits of every lead requantized with the lead origin
+// and scale, and a RESAMPLING control variable recording the original and
+// new rates.
+//
+// Example:
+//
+//	series := &h.HL7AEcg.Component[0].Series
+//	res, err := resample.ResampleSeries(series, 500, resample.DefaultOptions())
+//	if err != nil {
+//		log.Fatal(err)
+//	}
+//	fmt.Printf("%g Hz → %g Hz (%s)\n", res.From, res.To, res.Method)
+package resample
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Options
+// =============================================================================
+
+// Method identifies how the filter weights are computed.
+type Method string
+
+const (
+	// MethodPolyphase computes the weights once for each of the L phases of
+	// a rational L/M rate ratio.
+	MethodPolyphase Method = "polyphase"
+
+	// MethodSinc computes the weights for every output sample, for any ratio.
+	MethodSinc Method = "sinc"
+)
+
+// MaxPhases is the largest denominator L of the rate ratio L/M resampled
+// with MethodPolyphase when no method is set.
+const MaxPhases = 4096
+
+// Options tunes the anti-aliasing filter.
+type Options struct {
+	// Method selects polyphase or per-sample weights. Empty picks
+	// MethodPolyphase when the rate ratio is a fraction with at most
+	// MaxPhases phases, else MethodSinc.
+	Method Method
+
+	// Zeros is the number of sinc zero crossings on each side of the
+	// kernel. More zeros give a sharper transition band.
+	Zeros int
+
+	// Beta is the Kaiser window parameter: higher values attenuate the
+	// stopband more but widen the transition band.
+	Beta float64
+
+	// Cutoff is the filter cutoff as a fraction of the lower Nyquist
+	// frequency, in (0, 1].
+	Cutoff float64
+}
+
+// DefaultOptions returns the filter used when none is provided: 16 zero
+// crossings, β = 8 (about 80 dB stopband attenuation) and a cutoff at 90 % of
+// the lower Nyquist frequency (225 Hz when resampling to 500 Hz).
+func DefaultOptions() Options {
+	return Options{
+		Zeros:  16,
+		Beta:   8,
+		Cutoff: 0.9,
+	}
+}
+
+// withDefaults returns the options with their zero fields set to the defaults.
+func (o Options) withDefaults() Options {
+	d := DefaultOptions()
+	if o.Zeros == 0 {
+		o.Zeros = d.Zeros
+	}
+	if o.Beta == 0 {
+		o.Beta = d.Beta
+	}
+	if o.Cutoff == 0 {
+		o.Cutoff = d.Cutoff
+	}
+	return o
+}
+
+// =============================================================================
+// Errors
+// =============================================================================
+
+var (
+	// ErrInvalidSampleRate is returned when a sample rate is not positive.
+	ErrInvalidSampleRate = errors.New("resample: sample rate must be positive")
+
+	// ErrInvalidOptions is returned when the filter options cannot be used.
+	ErrInvalidOptions = errors.New("resample: invalid options")
+
+	// ErrUnsupportedSequence is returned for a time or lead sequence type
+	// that cannot be resampled.
+	ErrUnsupportedSequence = errors.New("resample: unsupported sequence")
+
+	// ErrDigitRange is returned when a resampled digit exceeds the int32 range.
+	ErrDigitRange = errors.New("resample: resampled digits exceed the int32 range")
+)
+
+// =============================================================================
+// Resampling
+// =============================================================================
+
+// Resample converts samples taken at from Hz to the rate to Hz.
+//
+// The output has ceil(len(samples) × to / from) samples, the first at the time
+// of the first input sample. Samples beyond the ends of the recording are
+// taken equal to the first and last samples, so that a baseline offset does
+// not ring at the edges.
+func Resample(samples []float64, from, to float64, opts Options) ([]float64, error) {
+	out, _, err := resample(samples, from, to, opts)
+	return out, err
+}
+
+// resample converts samples and returns the method used.
+func resample(samples []float64, from, to float64, opts Options) ([]float64, Method, error) {
+	if !(from > 0) || !(to > 0) || math.IsInf(from, 0) || math.IsInf(to, 0) {
+		return nil, "", fmt.Errorf("%w: %v Hz to %v Hz", ErrInvalidSampleRate, from, to)
+	}
+	opts = opts.withDefaults()
+	if opts.Zeros < 1 || opts.Beta < 0 || !(opts.Cutoff > 0 && opts.Cutoff <= 1) {
+		return nil, "", fmt.Errorf("%w: %+v", ErrInvalidOptions, opts)
+	}
+
+	ratio := to / from
+	l, m, rational := fraction(ratio, MaxPhases)
+	method := opts.Method
+	switch method {
+	case "":
+		method = MethodSinc
+		if rational {
+			method = MethodPolyphase
+		}
+	case MethodPolyphase:
+		if !rational {
+			return nil, "", fmt.Errorf("%w: %v Hz to %v Hz is not a ratio of at most %d phases", ErrInvalidOptions, from, to, MaxPhases)
+		}
+	case MethodSinc:
+	default:
+		return nil, "", fmt.Errorf("%w: unknown method %q", ErrInvalidOptions, method)
+	}
+
+	n := int(math.Ceil(float64(len(samples))*ratio - 1e-9))
+	out := make([]float64, n)
+	if len(samples) == 0 {
+		return out, method, nil
+	}
+	k := newKernel(ratio, opts)
+
+	if method == MethodPolyphase {
+		// Output sample j is at input position j·M/L: its integer part
+		// advances by M/L and its fraction cycles through the L phases
+		phases := make([][]float64, l)
+		firsts := make([]int, l)
+		for j := range out {
+			pos := int64(j) * int64(m)
+			base, phase := int(pos/int64(l)), int(pos%int64(l))
+			if phases[phase] == nil {
+				firsts[phase], phases[phase] = k.taps(float64(phase) / float64(l))
+			}
+			out[j] = apply(samples, base+firsts[phase], phases[phase])
+		}
+		return out, method, nil
+	}
+
+	for j := range out {
+		first, weights := k.taps(float64(j) / ratio)
+		out[j] = apply(samples, first, weights)
+	}
+	return out, method, nil
+}
+
+// fraction returns l/m equal to ratio with m at most maxDen, the smallest such
+// denominator, and reports whether one was found.
+func fraction(ratio float64, maxDen int) (l, m int, ok bool) {
+	for m = 1; m <= maxDen; m++ {
+		l = int(math.Round(ratio * float64(m)))
+		if l > 0 && l <= maxDen && math.Abs(float64(l)/float64(m)-ratio) <= 1e-12*ratio {
+			// The phases are the numerator: the ratio reduced is L/M
+			return l, m, true
+		}
+	}
+	return 0, 0, false
+}
+
+// =============================================================================
+// Series
+// =============================================================================
+
+// ControlVariableCode is the code of the control variable recording a resampling.
+const ControlVariableCode = "RESAMPLING"
+
+// CodeSystemName is the code system name of the resampling control variable
+// and of its components.
+const CodeSystemName = "HL7V3AECG"
+
+// Result describes a series resampling.
+type Result struct {
+	// From and To are the original and new sample rates in Hz.
+	From float64
+	To   float64
+
+	// Method is the method used.
+	Method Method
+}
+
+// ResampleSeries resamples every sequence set of the series to rate Hz.
+//
+// The time sequences are GLIST_TS, GLIST_PQ or evenly spaced SLIST_PQ;
+// their increment is replaced, in the unit it is written in. The lead
+// sequences are SLIST_PQ or SLIST_INT, requantized with their own origin and
+// scale. The series is changed only if every sequence set can be resampled.
+//
+// A RESAMPLING control variable is added to the series with the original
+// and new sample rates and the method. The derived series of s are not
+// resampled: call ResampleSeries on each of them.
+//
+// Returns the original rate and the method. A series already at rate is
+// left unchanged.
+func ResampleSeries(s *types.Series, rate float64, opts Options) (*Result, error) {
+	if s == nil || len(s.Component) == 0 {
+		return nil, fmt.Errorf("resample: %w", types.ErrMissingTimeSequence)
+	}
+	if !(rate > 0) || math.IsInf(rate, 0) {
+		return nil, fmt.Errorf("%w: %v Hz", ErrInvalidSampleRate, rate)
+	}
+
+	var res *Result
+	sets := make([]types.SequenceSet, len(s.Component))
+	for i := range s.Component {
+		from, err := (&types.Series{Component: []types.SeriesComponent{s.Component[i]}}).GetSampleRate()
+		if err != nil {
+			return nil, fmt.Errorf("resample: sequence set %d: %w", i, err)
+		}
+		if res == nil {
+			res = &Result{From: from, To: rate}
+		} else if from != res.From {
+			return nil, fmt.Errorf("%w: sequence set %d is sampled at %v Hz, not %v Hz", ErrUnsupportedSequence, i, from, res.From)
+		}
+		if from == rate {
+			continue
+		}
+		set, method, err := resampleSet(&s.Component[i].SequenceSet, from, rate, opts)
+		if err != nil {
+			return nil, fmt.Errorf("sequence set %d: %w", i, err)
+		}
+		sets[i], res.Method = set, method
+	}
+	if res.From == rate {
+		return res, nil
+	}
+
+	for i := range s.Component {
+		s.Component[i].SequenceSet = sets[i]
+	}
+	s.ControlVariable = append(s.ControlVariable, *controlVariable(res))
+	return res, nil
+}
+
+// resampleSet returns a resampled copy of a sequence set.
+func resampleSet(set *types.SequenceSet, from, to float64, opts Options) (types.SequenceSet, Method, error) {
+	out := *set
+	out.Component = make([]types.SequenceComponent, len(set.Component))
+	copy(out.Component, set.Component)
+
+	var method Method
+	length := 0
+	timeIndex := -1
+	for i, comp := range set.Component {
+		seq := comp.Sequence
+		if seq.Value == nil {
+			continue
+		}
+		if seq.Code.Time != nil {
+			if timeIndex < 0 {
+				timeIndex = i
+			}
+			continue
+		}
+
+		value, m, err := resampleLead(seq.Value, from, to, opts)
+		if err != nil {
+			return types.SequenceSet{}, "", fmt.Errorf("sequence %d: %w", i, err)
+		}
+		method = m
+		length = max(length, leadLength(value))
+		out.Component[i].Sequence.Value = value
+	}
+
+	for i, comp := range set.Component {
+		if comp.Sequence.Code.Time == nil || comp.Sequence.Value == nil {
+			continue
+		}
+		value, err := retime(comp.Sequence.Value, to, length)
+		if err != nil {
+			return types.SequenceSet{}, "", fmt.Errorf("sequence %d: %w", i, err)
+		}
+		out.Component[i].Sequence.Value = value
+	}
+	return out, method, nil
+}
+
+// leadLength returns the number of digits of a lead sequence value.
+func leadLength(v *types.SequenceValue) int {
+	switch typed := v.Typed.(type) {
+	case *types.SLIST_PQ:
+		return typed.GetLength()
+	case *types.SLIST_INT:
+		return typed.GetLength()
+	}
+	return 0
+}
+
+// resampleLead returns the lead sequence value resampled, with the same
+// origin and scale.
+func resampleLead(v *types.SequenceValue, from, to float64, opts Options) (*types.SequenceValue, Method, error) {
+	var origin, scale float64
+	var values []float64
+	switch typed := v.Typed.(type) {
+	case *types.SLIST_PQ:
+		var ok bool
+		if origin, ok = typed.Origin.GetValueFloat(); !ok {
+			origin = 0
+		}
+		if scale, ok = typed.Scale.GetValueFloat(); !ok || scale == 0 {
+			return nil, "", types.ErrInvalidScale
+		}
+		var err error
+		if values, err = typed.GetActualValues(); err != nil {
+			return nil, "", err
+		}
+	case *types.SLIST_INT:
+		if typed.Scale == 0 {
+			return nil, "", types.ErrInvalidScale
+		}
+		origin, scale = float64(typed.Origin), float64(typed.Scale)
+		ints, err := typed.GetActualValues()
+		if err != nil {
+			return nil, "", err
+		}
+		values = make([]float64, len(ints))
+		for i, x := range ints {
+			values[i] = float64(x)
+		}
+	default:
+		return nil, "", fmt.Errorf("%w: lead value type %q", ErrUnsupportedSequence, v.XsiType)
+	}
+
+	resampled, method, err := resample(values, from, to, opts)
+	if err != nil {
+		return nil, "", err
+	}
+	digits := make([]int32, len(resampled))
+	for i, x := range resampled {
+		d := math.Round((x - origin) / scale)
+		if d < math.MinInt32 || d > math.MaxInt32 {
+			return nil, "", fmt.Errorf("%w: digit %d", ErrDigitRange, i)
+		}
+		digits[i] = int32(d)
+	}
+
+	out := *v
+	switch typed := v.Typed.(type) {
+	case *types.SLIST_PQ:
+		out.Typed = (&types.SLIST_PQ{Origin: typed.Origin, Scale: typed.Scale, Extensions: typed.Extensions}).SetDigitValues(digits)
+	case *types.SLIST_INT:
+		out.Typed = (&types.SLIST_INT{Origin: typed.Origin, Scale: typed.Scale, Extensions: typed.Extensions}).SetDigitValues(digits)
+	}
+	return &out, method, nil
+}
+
+// retime returns the time sequence value with the increment of rate Hz, and
+// n samples for SLIST_PQ time sequences.
+func retime(v *types.SequenceValue, rate float64, n int) (*types.SequenceValue, error) {
+	out := *v
+	switch typed := v.Typed.(type) {
+	case *types.GLIST_TS:
+		g := *typed
+		increment, err := incrementIn(g.Increment.Unit, rate)
+		if err != nil {
+			return nil, err
+		}
+		g.Increment.Value = increment
+		out.Typed = &g
+	case *types.GLIST_PQ:
+		g := *typed
+		increment, err := incrementIn(g.Increment.Unit, rate)
+		if err != nil {
+			return nil, err
+		}
+		g.Increment.Value = increment
+		out.Typed = &g
+	case *types.SLIST_PQ:
+		// The first time becomes the origin, and the digits count samples
+		origin, _ := typed.Origin.GetValueFloat()
+		scale, _ := typed.Scale.GetValueFloat()
+		times, err := typed.GetDigitValues()
+		if err != nil {
+			return nil, err
+		}
+		if len(times) > 0 {
+			origin += float64(times[0]) * scale
+		}
+		increment, err := incrementIn(typed.Scale.Unit, rate)
+		if err != nil {
+			return nil, err
+		}
+		digits := make([]int32, n)
+		for i := range digits {
+			digits[i] = int32(i)
+		}
+		s := &types.SLIST_PQ{
+			Origin:     types.PhysicalQuantity{Value: strconv.FormatFloat(origin, 'f', -1, 64), Unit: typed.Origin.Unit},
+			Scale:      types.PhysicalQuantity{Value: increment, Unit: typed.Scale.Unit},
+			Extensions: typed.Extensions,
+		}
+		out.Typed = s.SetDigitValues(digits)
+	default:
+		return nil, fmt.Errorf("%w: time value type %q", ErrUnsupportedSequence, v.XsiType)
+	}
+	return &out, nil
+}
+
+// incrementIn returns the sampling increment of rate Hz in a UCUM time unit.
+func incrementIn(unit string, rate float64) (string, error) {
+	seconds, ok := types.UCUMTimeUnitSeconds(unit)
+	if !ok {
+		return "", types.ErrInvalidIncrement
+	}
+	return strconv.FormatFloat(1/(rate*seconds), 'f', -1, 64), nil
+}
+
+// controlVariable returns the control variable recording a resampling.
+//
+// Example:
+//
+//	<controlVariable>
+//	  <controlVariable>
+//	    <code code="RESAMPLING" codeSystemName="HL7V3AECG" displayName="Resampling"/>
+//	    <component>
+//	      <controlVariable>
+//	        <code code="ORIGINAL_SAMPLE_RATE" .../>
+//	        <value xsi:type="PQ" value="1000" unit="Hz"/>
+//	      </controlVariable>
+//	    </component>
+//	    ... SAMPLE_RATE and METHOD
+//	  </controlVariable>
+//	</controlVariable>
+func controlVariable(res *Result) *types.ControlVariable {
+	cv := types.NewControlVariable(ControlVariableCode, "", CodeSystemName, "Resampling")
+	cv.ControlVariable.
+		AddComponent("ORIGINAL_SAMPLE_RATE", "", CodeSystemName, "Original Sample Rate",
+			strconv.FormatFloat(res.From, 'f', -1, 64), types.UNIT_HERTZ).
+		AddComponent("SAMPLE_RATE", "", CodeSystemName, "Sample Rate",
+			strconv.FormatFloat(res.To, 'f', -1, 64), types.UNIT_HERTZ).
+		AddComponentWithText("METHOD", "", CodeSystemName, "Method", string(res.Method))
+	return cv
+}
@@ -0,0 +1,251 @@
+package resample
+
+import (
+	"errors"
+	"math"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// tone returns n samples at rate Hz of a sine of frequency f Hz plus an offset.
+func tone(n int, rate, f, amplitude, offset float64) []float64 {
+	x := make([]float64, n)
+	for i := range x {
+		x[i] = offset + amplitude*math.Sin(2*math.Pi*f*float64(i)/rate)
+	}
+	return x
+}
+
+// maxInteriorError returns the largest difference between got and want,
+// skipping margin samples at both ends.
+func maxInteriorError(got, want []float64, margin int) float64 {
+	maxErr := 0.0
+	for i := margin; i < len(got)-margin; i++ {
+		maxErr = max(maxErr, math.Abs(got[i]-want[i]))
+	}
+	return maxErr
+}
+
+// TestResample tests that an in-band tone is preserved between the rates
+// sites send and the analysis rate
+func TestResample(t *testing.T) {
+	tests := []struct {
+		name       string
+		from, to   float64
+		wantMethod Method
+	}{
+		{name: "250 to 500 Hz", from: 250, to: 500, wantMethod: MethodPolyphase},
+		{name: "1000 to 500 Hz", from: 1000, to: 500, wantMethod: MethodPolyphase},
+		{name: "1024 to 500 Hz", from: 1024, to: 500, wantMethod: MethodPolyphase},
+		{name: "500 to 1000/3 Hz", from: 500, to: 1000.0 / 3, wantMethod: MethodPolyphase},
+		{name: "500 to 333.3 Hz", from: 500, to: 333.3, wantMethod: MethodSinc},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := int(4 * tt.from)
+			samples := tone(n, tt.from, 10, 1000, 200)
+			got, method, err := resample(samples, tt.from, tt.to, DefaultOptions())
+			if err != nil {
+				t.Fatalf("resample() error = %v", err)
+			}
+			if method != tt.wantMethod {
+				t.Errorf("method = %s, want %s", method, tt.wantMethod)
+			}
+			if want := int(math.Ceil(float64(n) * tt.to / tt.from)); len(got) != want {
+				t.Errorf("length = %d, want %d", len(got), want)
+			}
+			want := tone(len(got), tt.to, 10, 1000, 200)
+			if e := maxInteriorError(got, want, int(tt.to/10)); e > 0.5 {
+				t.Errorf("max error = %v, want below 0.5 for an amplitude of 1000", e)
+			}
+		})
+	}
+}
+
+// TestResample_AntiAliasing tests that a tone above the new Nyquist frequency
+// is removed instead of folding back into the band
+func TestResample_AntiAliasing(t *testing.T) {
+	samples := tone(4000, 1000, 400, 1000, 0)
+	got, err := Resample(samples, 1000, 500, Options{})
+	if err != nil {
+		t.Fatalf("Resample() error = %v", err)
+	}
+	if e := maxInteriorError(got, make([]float64, len(got)), 50); e > 1 {
+		t.Errorf("400 Hz tone left with amplitude %v after resampling to 500 Hz", e)
+	}
+}
+
+// TestResample_Constant tests that a baseline offset is kept up to the edges
+func TestResample_Constant(t *testing.T) {
+	for _, to := range []float64{500, 333.3, 2000} {
+		samples := tone(1000, 1000, 0, 0, -125)
+		got, err := Resample(samples, 1000, to, Options{})
+		if err != nil {
+			t.Fatalf("Resample() error = %v", err)
+		}
+		for i, v := range got {
+			if math.Abs(v+125) > 1e-9 {
+				t.Fatalf("%v Hz: sample %d = %v, want -125", to, i, v)
+			}
+		}
+	}
+}
+
+// TestResample_MethodsAgree tests that polyphase and per-sample weights give
+// the same samples for a rational ratio
+func TestResample_MethodsAgree(t *testing.T) {
+	samples := tone(3000, 1024, 17, 500, 0)
+	poly, err := Resample(samples, 1024, 500, Options{Method: MethodPolyphase})
+	if err != nil {
+		t.Fatalf("Resample() error = %v", err)
+	}
+	direct, err := Resample(samples, 1024, 500, Options{Method: MethodSinc})
+	if err != nil {
+		t.Fatalf("Resample() error = %v", err)
+	}
+	if e := maxInteriorError(poly, direct, 0); e > 1e-9 {
+		t.Errorf("methods differ by %v", e)
+	}
+}
+
+// TestResample_Errors tests the rejected rates and options
+func TestResample_Errors(t *testing.T) {
+	tests := []struct {
+		name     string
+		from, to float64
+		opts     Options
+		wantErr  error
+	}{
+		{name: "zero rate", from: 0, to: 500, wantErr: ErrInvalidSampleRate},
+		{name: "NaN rate", from: 500, to: math.NaN(), wantErr: ErrInvalidSampleRate},
+		{name: "cutoff above Nyquist", from: 1000, to: 500, opts: Options{Cutoff: 1.5}, wantErr: ErrInvalidOptions},
+		{name: "unknown method", from: 1000, to: 500, opts: Options{Method: "cubic"}, wantErr: ErrInvalidOptions},
+		{name: "polyphase irrational", from: 500, to: 333.3, opts: Options{Method: MethodPolyphase}, wantErr: ErrInvalidOptions},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Resample([]float64{1, 2, 3}, tt.from, tt.to, tt.opts)
+			if !errors.Is(err, tt.wantErr) {
+				t.Errorf("Resample() error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// TestIncrementIn tests that increments are written in the time units
+// GetSampleRate reads back
+func TestIncrementIn(t *testing.T) {
+	tests := []struct {
+		unit    string
+		want    string
+		wantErr error
+	}{
+		{unit: "s", want: "0.002"},
+		{unit: "ms", want: "2"},
+		{unit: "us", want: "2000"},
+		{unit: "", wantErr: types.ErrInvalidIncrement},
+		{unit: "S", wantErr: types.ErrInvalidIncrement},
+	}
+
+	for _, tt := range tests {
+		got, err := incrementIn(tt.unit, 500)
+		if !errors.Is(err, tt.wantErr) || got != tt.want {
+			t.Errorf("incrementIn(%q) = %q, %v, want %q, %v", tt.unit, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
+
+// newSeries returns a rhythm series of two seconds at rate Hz with a 1 mV
+// 10 Hz tone on lead II, quantized to 5 µV and encoded with enc.
+func newSeries(t *testing.T, rate float64, enc hl7aecg.SeriesEncoding) *types.Series {
+	t.Helper()
+	h := hl7aecg.NewHl7xml("")
+	if err := h.SetSeriesEncoding(enc); err != nil {
+		t.Fatalf("SetSeriesEncoding() error = %v", err)
+	}
+	mv := tone(int(2*rate), rate, 10, 1, 0)
+	if _, err := h.AddRhythmSeriesMillivolts("20240315101500.000", "20240315101502.000", nil, nil, rate,
+		map[types.LeadCode][]float64{types.MDC_ECG_LEAD_II: mv}, hl7aecg.Quantization{Resolution: 0.005}); err != nil {
+		t.Fatalf("AddRhythmSeriesMillivolts() error = %v", err)
+	}
+	return &h.HL7AEcg.Component[0].Series
+}
+
+// TestResampleSeries tests that the time and lead sequences are rewritten and
+// the resampling recorded, for each sequence encoding
+func TestResampleSeries(t *testing.T) {
+	encodings := map[string]hl7aecg.SeriesEncoding{
+		"GLIST_TS and SLIST_PQ": {},
+		"GLIST_PQ in mV":        {Time: hl7aecg.TIME_GLIST_PQ, Unit: types.UNIT_MILLIVOLT},
+		"SLIST_PQ time":         {Time: hl7aecg.TIME_SLIST_PQ},
+		"SLIST_INT":             {Values: hl7aecg.VALUE_SLIST_INT},
+	}
+
+	for name, enc := range encodings {
+		t.Run(name, func(t *testing.T) {
+			series := newSeries(t, 1000, enc)
+			res, err := ResampleSeries(series, 500, Options{})
+			if err != nil {
+				t.Fatalf("ResampleSeries() error = %v", err)
+			}
+			if res.From != 1000 || res.To != 500 || res.Method != MethodPolyphase {
+				t.Errorf("result = %+v, want 1000 Hz to 500 Hz polyphase", res)
+			}
+
+			rate, err := series.GetSampleRate()
+			if err != nil || rate != 500 {
+				t.Errorf("GetSampleRate() = %v, %v, want 500", rate, err)
+			}
+			values, err := series.GetLeadValues(types.MDC_ECG_LEAD_II)
+			if err != nil {
+				t.Fatalf("GetLeadValues() error = %v", err)
+			}
+			if len(values) != 1000 {
+				t.Errorf("lead length = %d, want 1000", len(values))
+			}
+			if e := maxInteriorError(values, tone(1000, 500, 10, 1000, 0), 50); e > 5 {
+				t.Errorf("max error = %v µV, want within the 5 µV scale", e)
+			}
+			timeSeq := series.Component[0].SequenceSet.Component[0].Sequence
+			if s, ok := timeSeq.Value.Typed.(*types.SLIST_PQ); ok && s.GetLength() != 1000 {
+				t.Errorf("time sequence length = %d, want 1000", s.GetLength())
+			}
+
+			if len(series.ControlVariable) != 1 {
+				t.Fatalf("control variables = %d, want 1", len(series.ControlVariable))
+			}
+			cv := series.ControlVariable[0].ControlVariable
+			if cv.Code.Code != ControlVariableCode || len(cv.Component) != 3 {
+				t.Errorf("control variable %s with %d components, want %s with 3", cv.Code.Code, len(cv.Component), ControlVariableCode)
+			}
+		})
+	}
+}
+
+// TestResampleSeries_Unchanged tests that a series at the target rate, or
+// that cannot be resampled, is left as it was
+func TestResampleSeries_Unchanged(t *testing.T) {
+	series := newSeries(t, 500, hl7aecg.SeriesEncoding{})
+	res, err := ResampleSeries(series, 500, Options{})
+	if err != nil {
+		t.Fatalf("ResampleSeries() error = %v", err)
+	}
+	if res.From != 500 || len(series.ControlVariable) != 0 {
+		t.Errorf("series at 500 Hz resampled: %+v, %d control variables", res, len(series.ControlVariable))
+	}
+
+	h := hl7aecg.NewHl7xml("")
+	h.AddIrregularRhythmSeries("20240315101500.000", "20240315101500.008", []int{0, 2, 5, 7}, 0.001,
+		map[types.LeadCode][]int{types.MDC_ECG_LEAD_II: {1, 2, 3, 4}}, 0, 5)
+	irregular := &h.HL7AEcg.Component[0].Series
+	if _, err := ResampleSeries(irregular, 500, Options{}); !errors.Is(err, types.ErrIrregularSampling) {
+		t.Errorf("ResampleSeries() error = %v, want ErrIrregularSampling", err)
+	}
+	if len(irregular.ControlVariable) != 0 {
+		t.Errorf("irregular series changed")
+	}
+}
@@ -0,0 +1,355 @@
+package hl7aecg
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"math"
+	"slices"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// ErrInvalidSeriesEncoding is returned when a series encoding cannot be used.
+var ErrInvalidSeriesEncoding = errors.New("hl7aecg: invalid series encoding")
+
+// =============================================================================
+// Series Encoding
+// =============================================================================
+
+// TimeEncoding is the value type of the time sequence written by the series builders.
+type TimeEncoding string
+
+const (
+	// TIME_GLIST_TS writes a TIME_ABSOLUTE sequence: the start timestamp and
+	// the sampling increment.
+	TIME_GLIST_TS TimeEncoding = "GLIST_TS"
+
+	// TIME_GLIST_PQ writes a TIME_RELATIVE sequence starting at 0 s, with the
+	// sampling increment.
+	TIME_GLIST_PQ TimeEncoding = "GLIST_PQ"
+
+	// TIME_SLIST_PQ writes a TIME_RELATIVE sequence listing the time of every
+	// sample, as required for irregular sampling.
+	TIME_SLIST_PQ TimeEncoding = "SLIST_PQ"
+)
+
+// ValueEncoding is the value type of the lead sequences written by the series builders.
+type ValueEncoding string
+
+const (
+	// VALUE_SLIST_PQ writes origin and scale as physical quantities with a unit.
+	VALUE_SLIST_PQ ValueEncoding = "SLIST_PQ"
+
+	// VALUE_SLIST_INT writes origin and scale as integers, without a unit.
+	VALUE_SLIST_INT ValueEncoding = "SLIST_INT"
+)
+
+// SeriesEncoding selects how the series builders encode the time and lead
+// sequences. The zero value is DefaultSeriesEncoding.
+type SeriesEncoding struct {
+	// Time is the time sequence type of rhythm and representative beat
+	// series. Derived series always use relative time: GLIST_PQ, or SLIST_PQ
+	// if Time is TIME_SLIST_PQ. Defaults to TIME_GLIST_TS.
+	Time TimeEncoding
+
+	// Values is the lead sequence type. Defaults to VALUE_SLIST_PQ.
+	Values ValueEncoding
+
+	// Unit is the unit of the lead origin and scale: types.UNIT_MICROVOLT,
+	// types.UNIT_MILLIVOLT or types.UNIT_VOLT. SLIST_INT sequences carry no
+	// unit and are read as µV, so they only accept types.UNIT_MICROVOLT.
+	// Defaults to types.UNIT_MICROVOLT.
+	Unit string
+}
+
+// DefaultSeriesEncoding returns the encoding used when none is set: absolute
+// GLIST_TS time and SLIST_PQ leads in µV.
+func DefaultSeriesEncoding() SeriesEncoding {
+	return SeriesEncoding{
+		Time:   TIME_GLIST_TS,
+		Values: VALUE_SLIST_PQ,
+		Unit:   types.UNIT_MICROVOLT,
+	}
+}
+
+// withDefaults returns the encoding with its empty fields set to the defaults.
+func (e SeriesEncoding) withDefaults() SeriesEncoding {
+	d := DefaultSeriesEncoding()
+	if e.Time == "" {
+		e.Time = d.Time
+	}
+	if e.Values == "" {
+		e.Values = d.Values
+	}
+	if e.Unit == "" {
+		e.Unit = d.Unit
+	}
+	return e
+}
+
+// Validate checks the encoding types and unit. Empty fields are valid and
+// take their default.
+//
+// Returns ErrInvalidSeriesEncoding describing the first invalid field.
+func (e SeriesEncoding) Validate() error {
+	e = e.withDefaults()
+	switch e.Time {
+	case TIME_GLIST_TS, TIME_GLIST_PQ, TIME_SLIST_PQ:
+	default:
+		return fmt.Errorf("%w: unknown time encoding %q", ErrInvalidSeriesEncoding, e.Time)
+	}
+	switch e.Values {
+	case VALUE_SLIST_PQ, VALUE_SLIST_INT:
+	default:
+		return fmt.Errorf("%w: unknown value encoding %q", ErrInvalidSeriesEncoding, e.Values)
+	}
+	if !types.IsUCUMVoltageUnit(e.Unit) {
+		return fmt.Errorf("%w: %q is not a voltage unit (uV, mV, V)", ErrInvalidSeriesEncoding, e.Unit)
+	}
+	if e.Values == VALUE_SLIST_INT && e.Unit != types.UNIT_MICROVOLT {
+		return fmt.Errorf("%w: SLIST_INT sequences have no unit and are read as uV, not %s", ErrInvalidSeriesEncoding, e.Unit)
+	}
+	return nil
+}
+
+// SetSeriesEncoding selects the encoding of the series added afterwards with
+// AddRhythmSeries, AddRepresentativeBeatSeries, AddIrregularRhythmSeries and
+// AddDerivedSeries. Series already added are not changed.
+//
+// Returns ErrInvalidSeriesEncoding if the encoding is invalid, in which case
+// the current encoding is kept.
+//
+// Example:
+//
+//	err := h.SetSeriesEncoding(hl7aecg.SeriesEncoding{
+//	    Time:   hl7aecg.TIME_GLIST_PQ,
+//	    Values: hl7aecg.VALUE_SLIST_INT,
+//	})
+func (h *Hl7xml) SetSeriesEncoding(enc SeriesEncoding) error {
+	if err := enc.Validate(); err != nil {
+		return err
+	}
+	h.encoding = enc.withDefaults()
+	return nil
+}
+
+// SeriesEncoding returns the encoding of the series added next.
+func (h *Hl7xml) SeriesEncoding() SeriesEncoding {
+	return h.encoding.withDefaults()
+}
+
+// =============================================================================
+// Sequence Builders
+// =============================================================================
+
+// standardLeadOrder is the order of the lead sequences written by the series
+// builders: limb leads I, II, III, augmented leads aVR, aVL, aVF, then
+// precordial leads V1 to V6.
+var standardLeadOrder = []types.LeadCode{
+	types.MDC_ECG_LEAD_I,
+	types.MDC_ECG_LEAD_II,
+	types.MDC_ECG_LEAD_III,
+	types.MDC_ECG_LEAD_AVR,
+	types.MDC_ECG_LEAD_AVL,
+	types.MDC_ECG_LEAD_AVF,
+	types.MDC_ECG_LEAD_V1,
+	types.MDC_ECG_LEAD_V2,
+	types.MDC_ECG_LEAD_V3,
+	types.MDC_ECG_LEAD_V4,
+	types.MDC_ECG_LEAD_V5,
+	types.MDC_ECG_LEAD_V6,
+}
+
+// leadData is a lead to encode: its digits, and the origin and scale in the
+// series encoding unit.
+type leadData struct {
+	digits        []int32
+	origin, scale float64
+}
+
+// scaledLeads returns the leads with one origin and scale. It reports
+// whether every sample fits in a digit, logging a warning if not (see
+// toDigits).
+func scaledLeads(leads map[types.LeadCode][]int, origin, scale float64) (map[types.LeadCode]leadData, bool) {
+	out := make(map[types.LeadCode]leadData, len(leads))
+	for leadCode, samples := range leads {
+		digits, ok := toDigits("lead "+string(leadCode), samples)
+		if !ok {
+			return nil, false
+		}
+		out[leadCode] = leadData{digits: digits, origin: origin, scale: scale}
+	}
+	return out, true
+}
+
+// toDigits converts samples to sequence digits, which are 32-bit integers.
+// It reports whether every sample is in the int32 range, logging a warning
+// naming the first one that is not.
+func toDigits(what string, samples []int) ([]int32, bool) {
+	digits := make([]int32, len(samples))
+	for i, sample := range samples {
+		if sample < math.MinInt32 || sample > math.MaxInt32 {
+			log.Printf("Warning: %s sample %d (%d) is outside the digit range [%d, %d]. Series not added.",
+				what, i, sample, math.MinInt32, math.MaxInt32)
+			return nil, false
+		}
+		digits[i] = int32(sample)
+	}
+	return digits, true
+}
+
+// leadLength returns the number of samples of the longest lead.
+func leadLength(leads map[types.LeadCode]leadData) int {
+	n := 0
+	for _, lead := range leads {
+		n = max(n, len(lead.digits))
+	}
+	return n
+}
+
+// buildLeadSequences creates the lead sequences in the standard medical
+// order, followed by the leads outside the standard 12-lead set.
+func (h *Hl7xml) buildLeadSequences(leads map[types.LeadCode]leadData) []types.SequenceComponent {
+	var sequences []types.SequenceComponent
+
+	// Iterate in standard order, only adding leads that are present in the map
+	for _, leadCode := range standardLeadOrder {
+		if lead, exists := leads[leadCode]; exists {
+			sequences = append(sequences, h.buildLeadSequence(leadCode, lead))
+		}
+	}
+
+	// Add any remaining leads that aren't in the standard 12-lead set,
+	// sorted so that the output does not depend on the map order
+	var others []types.LeadCode
+	for leadCode := range leads {
+		if !slices.Contains(standardLeadOrder, leadCode) {
+			others = append(others, leadCode)
+		}
+	}
+	slices.Sort(others)
+	for _, leadCode := range others {
+		sequences = append(sequences, h.buildLeadSequence(leadCode, leads[leadCode]))
+	}
+	return sequences
+}
+
+// buildLeadSequence creates a sequence for a single lead, encoded as the
+// series encoding Values.
+func (h *Hl7xml) buildLeadSequence(leadCode types.LeadCode, lead leadData) types.SequenceComponent {
+	enc := h.SeriesEncoding()
+
+	// Digits are formatted only when the document is encoded
+	value := &types.SequenceValue{XsiType: string(enc.Values)}
+	switch enc.Values {
+	case VALUE_SLIST_INT:
+		// checkLeadScale has checked that origin and scale are integers
+		value.Typed = (&types.SLIST_INT{
+			Origin: int(lead.origin),
+			Scale:  int(lead.scale),
+		}).SetDigitValues(lead.digits)
+	default:
+		value.Typed = (&types.SLIST_PQ{
+			Origin: types.PhysicalQuantity{
+				Value: formatFloat(lead.origin),
+				Unit:  enc.Unit,
+			},
+			Scale: types.PhysicalQuantity{
+				Value: formatFloat(lead.scale),
+				Unit:  enc.Unit,
+			},
+		}).SetDigitValues(lead.digits)
+	}
+
+	seq := types.SequenceComponent{
+		Sequence: types.Sequence{Value: value},
+	}
+	seq.Sequence.Code.Lead = &types.Code[types.LeadCode, types.CodeSystemOID]{}
+	seq.Sequence.Code.Lead.SetCode(leadCode, types.MDC_OID, "MDC", "")
+	return seq
+}
+
+// checkLeadScale reports whether origin and scale can be written with the
+// series encoding, logging a warning if not: SLIST_INT only holds integers.
+func (h *Hl7xml) checkLeadScale(origin, scale float64) bool {
+	if h.SeriesEncoding().Values != VALUE_SLIST_INT {
+		return true
+	}
+	if !isInt(origin) || !isInt(scale) {
+		log.Printf("Warning: SLIST_INT origin %v and scale %v must be integers. Series not added.", origin, scale)
+		return false
+	}
+	return true
+}
+
+// isInt reports whether f is an integer in the int range.
+func isInt(f float64) bool {
+	return f == math.Trunc(f) && f >= math.MinInt && f <= math.MaxInt
+}
+
+// buildGlistTimeSequence creates a regularly sampled time sequence: a
+// TIME_ABSOLUTE GLIST_TS from startTime, or a TIME_RELATIVE GLIST_PQ from 0 s.
+func buildGlistTimeSequence(encoding TimeEncoding, startTime string, sampleRate float64) types.SequenceComponent {
+	// Calculate increment from sample rate: increment = 1 / sampleRate seconds
+	increment := formatFloat(1.0 / sampleRate)
+
+	timeSeq := types.SequenceComponent{}
+	timeSeq.Sequence.Code.Time = &types.Code[types.TimeSequenceCode, types.CodeSystemOID]{}
+	if encoding == TIME_GLIST_TS {
+		timeSeq.Sequence.Value = &types.SequenceValue{
+			XsiType: "GLIST_TS",
+			Typed: &types.GLIST_TS{
+				Head:      types.HeadTimestamp{Value: startTime, Unit: types.UNIT_SECOND},
+				Increment: types.Increment{Value: increment, Unit: types.UNIT_SECOND},
+			},
+		}
+		timeSeq.Sequence.Code.Time.SetCode(types.TIME_ABSOLUTE_CODE, types.HL7_ActCode_OID, "ActCode", "")
+		return timeSeq
+	}
+
+	// Time starts at 0.000 (relative to the series start)
+	timeSeq.Sequence.Value = &types.SequenceValue{
+		XsiType: "GLIST_PQ",
+		Typed: &types.GLIST_PQ{
+			Head:      types.PhysicalQuantity{Value: "0.000", Unit: types.UNIT_SECOND},
+			Increment: types.PhysicalQuantity{Value: increment, Unit: types.UNIT_SECOND},
+		},
+	}
+	timeSeq.Sequence.Code.Time.SetCode(types.TIME_RELATIVE_CODE, types.HL7_ActCode_OID, "ActCode", "Relative Time")
+	return timeSeq
+}
+
+// buildSlistTimeSequence creates a TIME_RELATIVE SLIST_PQ time sequence: the
+// time of sample i is times[i] × scale seconds from the series start.
+func buildSlistTimeSequence(times []int32, scale float64) types.SequenceComponent {
+	timeSeq := types.SequenceComponent{
+		Sequence: types.Sequence{
+			Value: &types.SequenceValue{
+				XsiType: "SLIST_PQ",
+				Typed: (&types.SLIST_PQ{
+					Origin: types.PhysicalQuantity{Value: "0", Unit: types.UNIT_SECOND},
+					Scale:  types.PhysicalQuantity{Value: formatFloat(scale), Unit: types.UNIT_SECOND},
+				}).SetDigitValues(times),
+			},
+		},
+	}
+	timeSeq.Sequence.Code.Time = &types.Code[types.TimeSequenceCode, types.CodeSystemOID]{}
+	timeSeq.Sequence.Code.Time.SetCode(types.TIME_RELATIVE_CODE, types.HL7_ActCode_OID, "ActCode", "Relative Time")
+	return timeSeq
+}
+
+// buildTimeSequence creates the time sequence of n regularly sampled
+// samples with the given encoding. SLIST_PQ lists the time of every sample,
+// in units of the sampling increment.
+func buildTimeSequence(encoding TimeEncoding, startTime string, sampleRate float64, n int) types.SequenceComponent {
+	if encoding != TIME_SLIST_PQ {
+		return buildGlistTimeSequence(encoding, startTime, sampleRate)
+	}
+
+	times := make([]int32, n)
+	for i := range times {
+		times[i] = int32(i)
+	}
+	return buildSlistTimeSequence(times, 1.0/sampleRate)
+}
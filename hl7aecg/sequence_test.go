@@ -0,0 +1,324 @@
+package hl7aecg
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// encodedLeads are the leads of the series encoding tests.
+var encodedLeads = map[types.LeadCode][]int{
+	types.MDC_ECG_LEAD_I:  {1, 2, 3, 4},
+	types.MDC_ECG_LEAD_II: {-1, 0, 1, 2},
+}
+
+// TestSeriesEncoding_Validate tests the validation of the encoding types and units
+func TestSeriesEncoding_Validate(t *testing.T) {
+	tests := []struct {
+		name    string
+		enc     SeriesEncoding
+		wantErr bool
+	}{
+		{name: "zero value", enc: SeriesEncoding{}},
+		{name: "defaults", enc: DefaultSeriesEncoding()},
+		{name: "relative time in mV", enc: SeriesEncoding{Time: TIME_GLIST_PQ, Unit: types.UNIT_MILLIVOLT}},
+		{name: "timestamps in V", enc: SeriesEncoding{Time: TIME_SLIST_PQ, Unit: types.UNIT_VOLT}},
+		{name: "SLIST_INT", enc: SeriesEncoding{Values: VALUE_SLIST_INT}},
+		{name: "SLIST_INT in uV", enc: SeriesEncoding{Values: VALUE_SLIST_INT, Unit: types.UNIT_MICROVOLT}},
+		{name: "SLIST_INT in mV", enc: SeriesEncoding{Values: VALUE_SLIST_INT, Unit: types.UNIT_MILLIVOLT}, wantErr: true},
+		{name: "unknown time", enc: SeriesEncoding{Time: "SLIST_TS"}, wantErr: true},
+		{name: "unknown values", enc: SeriesEncoding{Values: "GLIST_PQ"}, wantErr: true},
+		{name: "time unit", enc: SeriesEncoding{Unit: types.UNIT_SECOND}, wantErr: true},
+		{name: "case-sensitive unit", enc: SeriesEncoding{Unit: "MV"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.enc.Validate()
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidSeriesEncoding) {
+					t.Errorf("Validate() error = %v, want ErrInvalidSeriesEncoding", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Errorf("Validate() error = %v", err)
+			}
+		})
+	}
+}
+
+// TestSetSeriesEncoding_Invalid tests that an invalid encoding keeps the current one
+func TestSetSeriesEncoding_Invalid(t *testing.T) {
+	h := NewHl7xml("")
+	if got := h.SeriesEncoding(); got != DefaultSeriesEncoding() {
+		t.Errorf("SeriesEncoding() = %+v, want the defaults", got)
+	}
+
+	if err := h.SetSeriesEncoding(SeriesEncoding{Time: TIME_GLIST_PQ}); err != nil {
+		t.Fatalf("SetSeriesEncoding() error = %v", err)
+	}
+	if err := h.SetSeriesEncoding(SeriesEncoding{Unit: "mmHg"}); err == nil {
+		t.Errorf("SetSeriesEncoding() accepted a pressure unit")
+	}
+
+	want := SeriesEncoding{Time: TIME_GLIST_PQ, Values: VALUE_SLIST_PQ, Unit: types.UNIT_MICROVOLT}
+	if got := h.SeriesEncoding(); got != want {
+		t.Errorf("SeriesEncoding() = %+v, want %+v", got, want)
+	}
+}
+
+// TestAddRhythmSeries_Encodings tests the time and lead sequences written with
+// each encoding, and that they read back with the same sample rate and values
+func TestAddRhythmSeries_Encodings(t *testing.T) {
+	tests := []struct {
+		name       string
+		enc        SeriesEncoding
+		scale      float64
+		wantTime   string
+		wantCode   types.TimeSequenceCode
+		wantValues string
+		wantUnit   string
+		wantLeadI  []float64
+	}{
+		{
+			name:       "defaults",
+			enc:        SeriesEncoding{},
+			scale:      5,
+			wantTime:   "GLIST_TS",
+			wantCode:   types.TIME_ABSOLUTE_CODE,
+			wantValues: "SLIST_PQ",
+			wantUnit:   `unit="uV"`,
+			wantLeadI:  []float64{5, 10, 15, 20},
+		},
+		{
+			name:       "relative time in mV",
+			enc:        SeriesEncoding{Time: TIME_GLIST_PQ, Unit: types.UNIT_MILLIVOLT},
+			scale:      0.005,
+			wantTime:   "GLIST_PQ",
+			wantCode:   types.TIME_RELATIVE_CODE,
+			wantValues: "SLIST_PQ",
+			wantUnit:   `unit="mV"`,
+			wantLeadI:  []float64{5, 10, 15, 20},
+		},
+		{
+			name:       "timestamps and SLIST_INT",
+			enc:        SeriesEncoding{Time: TIME_SLIST_PQ, Values: VALUE_SLIST_INT},
+			scale:      5,
+			wantTime:   "SLIST_PQ",
+			wantCode:   types.TIME_RELATIVE_CODE,
+			wantValues: "SLIST_INT",
+			wantLeadI:  []float64{5, 10, 15, 20},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("")
+			if err := h.SetSeriesEncoding(tt.enc); err != nil {
+				t.Fatalf("SetSeriesEncoding() error = %v", err)
+			}
+			h.AddRhythmSeries("20240315101500.000", "20240315101500.008", nil, nil, 500, encodedLeads, 0, tt.scale)
+			if len(h.HL7AEcg.Component) != 1 {
+				t.Fatalf("series count = %d, want 1", len(h.HL7AEcg.Component))
+			}
+
+			seqs := h.HL7AEcg.Component[0].Series.Component[0].SequenceSet.Component
+			if len(seqs) != 3 {
+				t.Fatalf("sequence count = %d, want 3", len(seqs))
+			}
+			if got := seqs[0].Sequence.Value.XsiType; got != tt.wantTime {
+				t.Errorf("time sequence type = %s, want %s", got, tt.wantTime)
+			}
+			if got := seqs[0].Sequence.Code.Time.Code; got != tt.wantCode {
+				t.Errorf("time sequence code = %s, want %s", got, tt.wantCode)
+			}
+			for _, seq := range seqs[1:] {
+				if got := seq.Sequence.Value.XsiType; got != tt.wantValues {
+					t.Errorf("lead %s type = %s, want %s", seq.Sequence.Code.Lead.Code, got, tt.wantValues)
+				}
+			}
+
+			out, err := h.String()
+			if err != nil {
+				t.Fatalf("String() error = %v", err)
+			}
+			if tt.wantUnit != "" && strings.Count(out, tt.wantUnit) != 4 {
+				t.Errorf("output has %d %s, want the origin and scale of 2 leads", strings.Count(out, tt.wantUnit), tt.wantUnit)
+			}
+
+			back := NewHl7xml("")
+			if err := back.Unmarshal([]byte(out)); err != nil {
+				t.Fatalf("Unmarshal() error = %v", err)
+			}
+			series := &back.HL7AEcg.Component[0].Series
+			rate, err := series.GetSampleRate()
+			if err != nil || rate != 500 {
+				t.Errorf("GetSampleRate() = %v, %v, want 500", rate, err)
+			}
+			values, err := series.GetLeadValues(types.MDC_ECG_LEAD_I)
+			if err != nil {
+				t.Fatalf("GetLeadValues() error = %v", err)
+			}
+			for i, want := range tt.wantLeadI {
+				if diff := values[i] - want; diff > 1e-9 || diff < -1e-9 {
+					t.Errorf("lead I value %d = %v µV, want %v", i, values[i], want)
+				}
+			}
+		})
+	}
+}
+
+// TestAddRhythmSeries_SlistIntFractionalScale tests that SLIST_INT series
+// with a fractional scale are not added
+func TestAddRhythmSeries_SlistIntFractionalScale(t *testing.T) {
+	h := NewHl7xml("")
+	if err := h.SetSeriesEncoding(SeriesEncoding{Values: VALUE_SLIST_INT}); err != nil {
+		t.Fatalf("SetSeriesEncoding() error = %v", err)
+	}
+	h.AddRhythmSeries("20240315101500.000", "20240315101500.008", nil, nil, 500, encodedLeads, 0, 2.5)
+
+	if len(h.HL7AEcg.Component) != 0 {
+		t.Errorf("series count = %d, want 0", len(h.HL7AEcg.Component))
+	}
+}
+
+// TestAddSeries_DigitRange tests that samples and times at the int32 bounds
+// are kept, and that series with a value beyond them are not added
+func TestAddSeries_DigitRange(t *testing.T) {
+	inRange := map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {math.MinInt32, 0, math.MaxInt32}}
+	tooHigh := map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {0, math.MaxInt32 + 1, 0}}
+	tooLow := map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {0, math.MinInt32 - 1, 0}}
+	times := []int{0, 1, math.MaxInt32}
+
+	tests := []struct {
+		name string
+		add  func(h *Hl7xml)
+		want int
+	}{
+		{"rhythm at the bounds", func(h *Hl7xml) {
+			h.AddRhythmSeries("20240315101500.000", "20240315101500.006", nil, nil, 500, inRange, 0, 5)
+		}, 1},
+		{"rhythm above MaxInt32", func(h *Hl7xml) {
+			h.AddRhythmSeries("20240315101500.000", "20240315101500.006", nil, nil, 500, tooHigh, 0, 5)
+		}, 0},
+		{"rhythm below MinInt32", func(h *Hl7xml) {
+			h.AddRhythmSeries("20240315101500.000", "20240315101500.006", nil, nil, 500, tooLow, 0, 5)
+		}, 0},
+		{"beat above MaxInt32", func(h *Hl7xml) {
+			h.AddRepresentativeBeatSeries("20240315101500.000", "20240315101500.006", 500, tooHigh, 0, 5)
+		}, 0},
+		{"irregular at the bounds", func(h *Hl7xml) {
+			h.AddIrregularRhythmSeries("20240315101500.000", "20240315101500.006", times, 0.001, inRange, 0, 5)
+		}, 1},
+		{"irregular time above MaxInt32", func(h *Hl7xml) {
+			h.AddIrregularRhythmSeries("20240315101500.000", "20240315101500.006", []int{0, 1, math.MaxInt32 + 1}, 0.001, inRange, 0, 5)
+		}, 0},
+		{"irregular lead above MaxInt32", func(h *Hl7xml) {
+			h.AddIrregularRhythmSeries("20240315101500.000", "20240315101500.006", times, 0.001, tooHigh, 0, 5)
+		}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("")
+			tt.add(h)
+			if len(h.HL7AEcg.Component) != tt.want {
+				t.Fatalf("series count = %d, want %d", len(h.HL7AEcg.Component), tt.want)
+			}
+			if tt.want == 0 {
+				return
+			}
+			values, err := h.HL7AEcg.Component[0].Series.GetLeadValues(types.MDC_ECG_LEAD_I)
+			if err != nil {
+				t.Fatalf("GetLeadValues() error = %v", err)
+			}
+			if want := []float64{math.MinInt32 * 5, 0, math.MaxInt32 * 5}; !slices.Equal(values, want) {
+				t.Errorf("GetLeadValues() = %v, want %v", values, want)
+			}
+		})
+	}
+
+	// Nor are derived series
+	h := NewHl7xml("")
+	h.AddRhythmSeries("20240315101500.000", "20240315101500.006", nil, nil, 500, inRange, 0, 5)
+	h.AddDerivedSeries(types.REPRESENTATIVE_BEAT_CODE, "20240315101500.000", "20240315101500.006", nil, nil, 500, tooHigh, 0, 5)
+	if n := len(h.HL7AEcg.Component[0].Series.Derivation); n != 0 {
+		t.Errorf("derived series count = %d, want 0", n)
+	}
+}
+
+// TestAddIrregularRhythmSeries tests that the time of every sample is listed
+func TestAddIrregularRhythmSeries(t *testing.T) {
+	h := NewHl7xml("")
+	h.AddIrregularRhythmSeries("20240315101500.000", "20240315101500.008",
+		[]int{0, 2, 5, 7}, 0.001, encodedLeads, 0, 5)
+
+	series := &h.HL7AEcg.Component[0].Series
+	timeSeq := series.Component[0].SequenceSet.Component[0].Sequence
+	if timeSeq.Code.Time.Code != types.TIME_RELATIVE_CODE {
+		t.Errorf("time sequence code = %s, want TIME_RELATIVE", timeSeq.Code.Time.Code)
+	}
+	times, ok := timeSeq.Value.Typed.(*types.SLIST_PQ)
+	if !ok {
+		t.Fatalf("time sequence is %T, want *types.SLIST_PQ", timeSeq.Value.Typed)
+	}
+	if times.Scale.Value != "0.001" || times.Scale.Unit != types.UNIT_SECOND {
+		t.Errorf("time scale = %s %s, want 0.001 s", times.Scale.Value, times.Scale.Unit)
+	}
+	actual, err := times.GetActualValues()
+	if err != nil {
+		t.Fatalf("GetActualValues() error = %v", err)
+	}
+	for i, want := range []float64{0, 0.002, 0.005, 0.007} {
+		if diff := actual[i] - want; diff > 1e-12 || diff < -1e-12 {
+			t.Errorf("time %d = %v s, want %v", i, actual[i], want)
+		}
+	}
+
+	if _, err := series.GetSampleRate(); !errors.Is(err, types.ErrIrregularSampling) {
+		t.Errorf("GetSampleRate() error = %v, want ErrIrregularSampling", err)
+	}
+}
+
+// TestAddDerivedSeries_TimeEncoding tests that derived series use relative
+// time whatever the time encoding
+func TestAddDerivedSeries_TimeEncoding(t *testing.T) {
+	tests := []struct {
+		name     string
+		time     TimeEncoding
+		wantType string
+	}{
+		{name: "absolute", time: TIME_GLIST_TS, wantType: "GLIST_PQ"},
+		{name: "relative", time: TIME_GLIST_PQ, wantType: "GLIST_PQ"},
+		{name: "timestamps", time: TIME_SLIST_PQ, wantType: "SLIST_PQ"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("")
+			if err := h.SetSeriesEncoding(SeriesEncoding{Time: tt.time}); err != nil {
+				t.Fatalf("SetSeriesEncoding() error = %v", err)
+			}
+			h.AddRhythmSeries("20240315101500.000", "20240315101500.008", nil, nil, 500, encodedLeads, 0, 5).
+				AddDerivedSeries(types.REPRESENTATIVE_BEAT_CODE, "20240315101500.000", "20240315101500.008",
+					nil, nil, 500, encodedLeads, 0, 5)
+
+			derived := h.HL7AEcg.Component[0].Series.Derivation[0].DerivedSeries
+			timeSeq := derived.Component[0].SequenceSet.Component[0].Sequence
+			if timeSeq.Code.Time.Code != types.TIME_RELATIVE_CODE {
+				t.Errorf("time sequence code = %s, want TIME_RELATIVE", timeSeq.Code.Time.Code)
+			}
+			if timeSeq.Value.XsiType != tt.wantType {
+				t.Errorf("time sequence type = %s, want %s", timeSeq.Value.XsiType, tt.wantType)
+			}
+			if rate, err := derived.GetSampleRate(); err != nil || rate != 500 {
+				t.Errorf("GetSampleRate() = %v, %v, want 500", rate, err)
+			}
+		})
+	}
+}
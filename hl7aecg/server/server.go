@@ -0,0 +1,350 @@
+// Package server exposes validation and conversion of aECG documents over
+// HTTP, for tools written in other languages.
+//
+// Endpoints (see openapi.yaml, also served at GET /openapi.yaml):
+//
+//	POST /v1/validate  structured validation report (types.ValidationReport)
+//	POST /v1/summary   document overview (types.Summary)
+//	POST /v1/convert   CSV, JSON or SVG export (?to=csv|json|svg)
+//	GET  /healthz      liveness probe
+//
+// Documents are uploaded as the raw request body (application/xml) or as
+// the "file" field of a multipart form. Uploads larger than
+// Config.MaxBodyBytes are rejected with 413, and each request is bounded by
+// Config.Timeout, mapped onto the context checked by Validate and by the
+// exports; requests over it get 504. SVG options beyond the limits of
+// export.SVGOptions are rejected with 400.
+//
+// Example:
+//
+//	srv := &http.Server{Addr: ":8080", Handler: server.New(server.Config{})}
+//	log.Fatal(srv.ListenAndServe())
+package server
+
+import (
+	"bytes"
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/export"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// OpenAPI is the OpenAPI 3 description of the service.
+//
+//go:embed openapi.yaml
+var OpenAPI []byte
+
+// Defaults of Config.
+const (
+	DefaultMaxBodyBytes = 64 << 20 // 64 MiB, a 24-hour Holter is about 40 MiB
+	DefaultTimeout      = 30 * time.Second
+)
+
+// =============================================================================
+// Configuration
+// =============================================================================
+
+// Config configures the service.
+type Config struct {
+	// MaxBodyBytes limits the size of an upload (DefaultMaxBodyBytes by
+	// default).
+	MaxBodyBytes int64
+
+	// Timeout bounds the handling of a request (DefaultTimeout by default).
+	// Validation and conversion stop when it expires, and 504 is returned.
+	Timeout time.Duration
+
+	// Registry is passed to hl7aecg.NewReader for every request.
+	Registry *types.TrialRegistry
+}
+
+// withDefaults fills the zero fields with the defaults.
+func (c Config) withDefaults() Config {
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = DefaultTimeout
+	}
+	return c
+}
+
+// =============================================================================
+// Handler
+// =============================================================================
+
+// handler serves the endpoints.
+type handler struct {
+	cfg Config
+}
+
+// New returns the HTTP handler of the service.
+func New(cfg Config) http.Handler {
+	h := &handler{cfg: cfg.withDefaults()}
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/validate", h.validate)
+	mux.HandleFunc("POST /v1/summary", h.summary)
+	mux.HandleFunc("POST /v1/convert", h.convert)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	mux.HandleFunc("GET /openapi.yaml", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/yaml")
+		w.Write(OpenAPI)
+	})
+	return h.withTimeout(mux)
+}
+
+// withTimeout bounds the context of every request by Config.Timeout.
+func (h *handler) withTimeout(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), h.cfg.Timeout)
+		defer cancel()
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// Error is the body of error responses.
+type Error struct {
+	Error string `json:"error"`
+}
+
+// validate returns the validation report of the uploaded document. Invalid
+// documents are reported with 200 and "valid": false; documents that cannot
+// be parsed with 422.
+func (h *handler) validate(w http.ResponseWriter, r *http.Request) {
+	ctx := r.Context()
+	data, ok := h.readDocument(w, r)
+	if !ok {
+		return
+	}
+	doc := hl7aecg.NewReader(ctx, h.cfg.Registry)
+	if err := doc.Unmarshal(data); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	// Rule violations are in the report; only a done context is an error
+	if err := doc.Validate(); err != nil && ctx.Err() != nil && errors.Is(err, ctx.Err()) {
+		writeContextError(w, "validation", err)
+		return
+	}
+	writeJSON(w, http.StatusOK, doc.ValidationReport())
+}
+
+// summary returns the overview of the uploaded document.
+func (h *handler) summary(w http.ResponseWriter, r *http.Request) {
+	doc, ok := h.parse(w, r)
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, doc.HL7AEcg.Summary())
+}
+
+// convert exports the uploaded document.
+//
+// Query parameters: to (csv, json or svg, required); series and derived
+// select the series for csv and svg (see export.SelectSeries); duration,
+// speed and gain configure svg (see export.SVGOptions), and are rejected
+// with 400 when out of range.
+func (h *handler) convert(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	to := q.Get("to")
+	contentType, ok := map[string]string{
+		"csv":  "text/csv; charset=utf-8",
+		"json": "application/json",
+		"svg":  "image/svg+xml",
+	}[to]
+	if !ok {
+		writeError(w, http.StatusBadRequest, fmt.Errorf("unknown output format %q (want csv, json or svg)", to))
+		return
+	}
+	index, err1 := queryInt(q.Get("series"), 0)
+	derived, err2 := queryInt(q.Get("derived"), -1)
+	opts := export.DefaultSVGOptions()
+	var err3, err4, err5 error
+	opts.Duration, err3 = queryFloat(q.Get("duration"), 0)
+	opts.Speed, err4 = queryFloat(q.Get("speed"), opts.Speed)
+	opts.Gain, err5 = queryFloat(q.Get("gain"), opts.Gain)
+	if err := errors.Join(err1, err2, err3, err4, err5, opts.Validate()); err != nil {
+		writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	doc, ok := h.parse(w, r)
+	if !ok {
+		return
+	}
+
+	// Convert to a buffer first, so that errors are still reported as JSON
+	var buf bytes.Buffer
+	out := contextWriter{ctx: r.Context(), w: &buf}
+	var err error
+	if to == "json" {
+		err = export.WriteJSON(out, &doc.HL7AEcg)
+	} else if series, serr := export.SelectSeries(&doc.HL7AEcg, index, derived); serr != nil {
+		err = serr
+	} else if to == "csv" {
+		err = export.WriteCSV(out, series)
+	} else {
+		err = export.WriteSVG(out, series, opts)
+	}
+	switch {
+	case err == nil:
+	case r.Context().Err() != nil:
+		writeContextError(w, "conversion", r.Context().Err())
+		return
+	case errors.Is(err, export.ErrInvalidSVGOptions):
+		writeError(w, http.StatusBadRequest, err)
+		return
+	default:
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return
+	}
+	w.Header().Set("Content-Type", contentType)
+	w.Write(buf.Bytes())
+}
+
+// parse reads and parses the uploaded document. It writes the error
+// response and returns false on failure.
+func (h *handler) parse(w http.ResponseWriter, r *http.Request) (*hl7aecg.Hl7xml, bool) {
+	data, ok := h.readDocument(w, r)
+	if !ok {
+		return nil, false
+	}
+	doc := hl7aecg.NewHl7xml("")
+	if err := doc.Unmarshal(data); err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err)
+		return nil, false
+	}
+	if err := r.Context().Err(); err != nil {
+		writeContextError(w, "parsing", err)
+		return nil, false
+	}
+	return doc, true
+}
+
+// contextWriter fails the writes once ctx is done, so that an export stops
+// when the request times out.
+type contextWriter struct {
+	ctx context.Context
+	w   io.Writer
+}
+
+func (cw contextWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return cw.w.Write(p)
+}
+
+// readDocument reads the uploaded document: the "file" field of a
+// multipart form, or the raw body otherwise. It writes the error response
+// and returns false on failure.
+func (h *handler) readDocument(w http.ResponseWriter, r *http.Request) ([]byte, bool) {
+	r.Body = http.MaxBytesReader(w, r.Body, h.cfg.MaxBodyBytes)
+
+	var body io.Reader = r.Body
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "multipart/form-data" {
+		reader, err := r.MultipartReader()
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return nil, false
+		}
+		for {
+			part, err := reader.NextPart()
+			if err != nil {
+				if errors.Is(err, io.EOF) {
+					err = errors.New(`multipart form has no "file" field`)
+				}
+				writeReadError(w, err)
+				return nil, false
+			}
+			if part.FormName() == "file" {
+				body = part
+				break
+			}
+		}
+	}
+
+	data, err := io.ReadAll(body)
+	if err != nil {
+		writeReadError(w, err)
+		return nil, false
+	}
+	if len(data) == 0 {
+		writeError(w, http.StatusBadRequest, errors.New("empty document"))
+		return nil, false
+	}
+	return data, true
+}
+
+// =============================================================================
+// Responses
+// =============================================================================
+
+// writeJSON writes v as JSON with the status code.
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
+
+// writeError writes an Error with the status code.
+func writeError(w http.ResponseWriter, status int, err error) {
+	writeJSON(w, status, Error{Error: err.Error()})
+}
+
+// writeReadError writes the response of an upload that could not be read:
+// 413 when it exceeds the size limit, 400 otherwise.
+func writeReadError(w http.ResponseWriter, err error) {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		writeError(w, http.StatusRequestEntityTooLarge, fmt.Errorf("document larger than %d bytes", tooLarge.Limit))
+		return
+	}
+	writeError(w, http.StatusBadRequest, err)
+}
+
+// writeContextError writes the response of a request whose context is done
+// during a step: 504 when the timeout expired, 503 when the client went away.
+func writeContextError(w http.ResponseWriter, step string, err error) {
+	if errors.Is(err, context.DeadlineExceeded) {
+		writeError(w, http.StatusGatewayTimeout, fmt.Errorf("%s timed out", step))
+		return
+	}
+	writeError(w, http.StatusServiceUnavailable, err)
+}
+
+// queryInt parses an integer query parameter, or returns def if empty.
+func queryInt(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("invalid integer %q", s)
+	}
+	return v, nil
+}
+
+// queryFloat parses a number query parameter, or returns def if empty.
+func queryFloat(s string, def float64) (float64, error) {
+	if s == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", s)
+	}
+	return v, nil
+}
@@ -0,0 +1,183 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/internal/testdoc"
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// post sends body to the handler and returns the response.
+func post(t *testing.T, handler http.Handler, target, contentType string, body []byte) *httptest.ResponseRecorder {
+	t.Helper()
+	req := httptest.NewRequest(http.MethodPost, target, bytes.NewReader(body))
+	req.Header.Set("Content-Type", contentType)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec
+}
+
+// decodeError returns the message of an error response.
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) string {
+	t.Helper()
+	var e Error
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &e), rec.Body.String())
+	return e.Error
+}
+
+// TestValidate tests the reports of valid, invalid and malformed documents
+func TestValidate(t *testing.T) {
+	handler := New(Config{})
+
+	rec := post(t, handler, "/v1/validate", "application/xml", testdoc.Marshal(t, testdoc.Doc{}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	var report types.ValidationReport
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.True(t, report.Valid, "%+v", report)
+
+	rec = post(t, handler, "/v1/validate", "application/xml", testdoc.Marshal(t, testdoc.Doc{Invalid: true}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+	assert.False(t, report.Valid)
+	assert.Contains(t, report.Rules(), "Time")
+
+	rec = post(t, handler, "/v1/validate", "application/xml", []byte("<AnnotatedECG"))
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+	assert.NotEmpty(t, decodeError(t, rec))
+
+	rec = post(t, handler, "/v1/validate", "application/xml", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "empty document", decodeError(t, rec))
+}
+
+// TestValidate_Multipart tests uploads as a multipart form
+func TestValidate_Multipart(t *testing.T) {
+	handler := New(Config{})
+
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("comment", "ignored"))
+	fw, err := mw.CreateFormFile("file", "ecg.xml")
+	require.NoError(t, err)
+	fw.Write(testdoc.Marshal(t, testdoc.Doc{}))
+	require.NoError(t, mw.Close())
+
+	rec := post(t, handler, "/v1/validate", mw.FormDataContentType(), body.Bytes())
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Contains(t, rec.Body.String(), `"valid":true`)
+
+	body.Reset()
+	mw = multipart.NewWriter(&body)
+	require.NoError(t, mw.WriteField("comment", "no file"))
+	require.NoError(t, mw.Close())
+	rec = post(t, handler, "/v1/validate", mw.FormDataContentType(), body.Bytes())
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Contains(t, decodeError(t, rec), `"file"`)
+}
+
+// TestValidate_Limits tests the size limit and the timeout
+func TestValidate_Limits(t *testing.T) {
+	doc := testdoc.Marshal(t, testdoc.Doc{})
+
+	rec := post(t, New(Config{MaxBodyBytes: 100}), "/v1/validate", "application/xml", doc)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, rec.Code)
+	assert.Equal(t, "document larger than 100 bytes", decodeError(t, rec))
+
+	rec = post(t, New(Config{Timeout: time.Nanosecond}), "/v1/validate", "application/xml", doc)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Equal(t, "validation timed out", decodeError(t, rec))
+}
+
+// TestSummary tests the document overview
+func TestSummary(t *testing.T) {
+	rec := post(t, New(Config{}), "/v1/summary", "application/xml", testdoc.Marshal(t, testdoc.Doc{}))
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+	var summary types.Summary
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &summary))
+	assert.Equal(t, testdoc.Root+"^ECG_1", summary.ID)
+	require.Len(t, summary.Series, 1)
+	assert.Equal(t, 500.0, summary.Series[0].SampleRate)
+}
+
+// TestConvert tests the CSV, JSON and SVG exports and the parameter errors
+func TestConvert(t *testing.T) {
+	handler := New(Config{})
+	doc := testdoc.Marshal(t, testdoc.Doc{})
+
+	rec := post(t, handler, "/v1/convert?to=csv", "application/xml", doc)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "text/csv; charset=utf-8", rec.Header().Get("Content-Type"))
+	lines := strings.Split(strings.TrimSpace(rec.Body.String()), "\n")
+	assert.Len(t, lines, 4, "header and three samples")
+
+	rec = post(t, handler, "/v1/convert?to=json", "application/xml", doc)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.True(t, json.Valid(rec.Body.Bytes()))
+
+	rec = post(t, handler, "/v1/convert?to=svg&speed=50&gain=20", "application/xml", doc)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	assert.Equal(t, "image/svg+xml", rec.Header().Get("Content-Type"))
+	assert.Contains(t, rec.Body.String(), "<svg")
+
+	rec = post(t, handler, "/v1/convert?to=pdf", "application/xml", doc)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = post(t, handler, "/v1/convert?to=csv&series=x", "application/xml", doc)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, `invalid integer "x"`, decodeError(t, rec))
+
+	rec = post(t, handler, "/v1/convert?to=csv&series=3", "application/xml", doc)
+	assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+}
+
+// TestConvert_Limits tests that SVG options beyond the limits are rejected
+// and that the timeout applies to every endpoint
+func TestConvert_Limits(t *testing.T) {
+	handler := New(Config{})
+	doc := testdoc.Marshal(t, testdoc.Doc{})
+
+	for _, query := range []string{"speed=250000", "speed=Inf", "speed=-25", "gain=NaN", "gain=1000", "duration=60"} {
+		rec := post(t, handler, "/v1/convert?to=svg&"+query, "application/xml", doc)
+		assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		assert.Contains(t, decodeError(t, rec), "invalid SVG options", query)
+	}
+
+	slow := New(Config{Timeout: time.Nanosecond})
+	rec := post(t, slow, "/v1/convert?to=svg", "application/xml", doc)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+	assert.Equal(t, "parsing timed out", decodeError(t, rec))
+
+	rec = post(t, slow, "/v1/summary", "application/xml", doc)
+	assert.Equal(t, http.StatusGatewayTimeout, rec.Code)
+}
+
+// TestRoutes tests the health check, the OpenAPI description and methods
+func TestRoutes(t *testing.T) {
+	handler := New(Config{})
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/healthz", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.JSONEq(t, `{"status":"ok"}`, rec.Body.String())
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/openapi.yaml", nil))
+	assert.Equal(t, http.StatusOK, rec.Code)
+	assert.Contains(t, rec.Body.String(), "/v1/validate:")
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/v1/validate", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -142,6 +142,66 @@ func (h *Hl7xml) SetResponsibleParty(investigatorRoot, investigatorID, prefix, g
 	return h
 }
 
+// SetProtocol sets the protocol of the clinical trial.
+//
+// This method uses the ComponentOf structure to access ClinicalTrial.
+//
+// Parameters:
+//   - root: Protocol OID, also used as code system of protocol-defined codes
+//   - extension: Traditional protocol identifier (e.g., "PUK-123-PROT-A")
+//   - title: Protocol title (optional, use "" to skip)
+//
+// Example:
+//
+//	h.SetProtocol("2.16.840.1.113883.3.2", "PUK-123-PROT-A", "Cardiac Safety Protocol for Compound PUK-123")
+func (h *Hl7xml) SetProtocol(root, extension, title string) *Hl7xml {
+	h.clinicalTrial().SetProtocol(root, extension, title)
+	return h
+}
+
+// SetSponsor sets the organization sponsoring the clinical trial.
+//
+// This method uses the ComponentOf structure to access ClinicalTrial.
+//
+// Parameters:
+//   - root: Sponsor organization OID
+//   - extension: Well-known sponsor identifier (optional, use "" to skip)
+//   - name: Organization name (optional, use "" to skip)
+//
+// Example:
+//
+//	h.SetSponsor("2.16.840.1.113883.3", "ABC-PHARMA", "ABC Drug Company")
+func (h *Hl7xml) SetSponsor(root, extension, name string) *Hl7xml {
+	h.clinicalTrial().SetSponsor(root, extension, name)
+	return h
+}
+
+// SetTrialRegistry configures the registered protocols that Validate()
+// cross-checks the document's protocol and trial IDs against.
+//
+// Pass nil to disable the check.
+//
+// Example:
+//
+//	reg := types.NewTrialRegistry().Register(types.TrialRegistryEntry{
+//	    ProtocolID: types.ID{Root: "2.16.840.1.113883.3.2", Extension: "PUK-123-PROT-A"},
+//	    TrialID:    types.ID{Root: "2.16.840.1.113883.3.4", Extension: "PUK-123-TRL-1"},
+//	})
+//	h.SetTrialRegistry(reg)
+func (h *Hl7xml) SetTrialRegistry(registry *types.TrialRegistry) *Hl7xml {
+	h.vctx.TrialRegistry = registry
+	return h
+}
+
+// clinicalTrial returns the clinical trial, initializing the ComponentOf
+// structure if needed.
+func (h *Hl7xml) clinicalTrial() *types.ClinicalTrial {
+	if h.HL7AEcg.ComponentOf == nil {
+		h.SetSubject("", "", "") // Initialize structure
+	}
+	return &h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial
+}
+
 // SetSeriesCode updates the code of the most recently added series with additional attributes.
 //
 // This method automatically finds the last series in the Component array and updates
@@ -1,6 +1,9 @@
 package hl7aecg
 
 import (
+	"context"
+	"encoding/xml"
+	"strings"
 	"testing"
 
 	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
@@ -121,3 +124,85 @@ func TestHl7xml_SetSeriesCode(t *testing.T) {
 		}
 	})
 }
+
+// TestHl7xml_SetProtocolAndSponsor tests the protocol and sponsor setters and their XML output.
+func TestHl7xml_SetProtocolAndSponsor(t *testing.T) {
+	h := NewHl7xml("")
+	h.SetSubject("2.16.840.1.113883.3.5", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+		SetProtocol("2.16.840.1.113883.3.2", "PUK-123-PROT-A", "Cardiac Safety Protocol for Compound PUK-123").
+		SetSponsor("2.16.840.1.113883.3", "ABC-PHARMA", "ABC Drug Company")
+
+	data, err := xml.Marshal(&h.HL7AEcg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	out := string(data)
+
+	wantSponsor := `<author><clinicalTrialSponsor><sponsorOrganization><id root="2.16.840.1.113883.3" extension="ABC-PHARMA"></id><name>ABC Drug Company</name></sponsorOrganization></clinicalTrialSponsor></author>`
+	if !strings.Contains(out, wantSponsor) {
+		t.Errorf("sponsor not encoded as expected:\n%s", out)
+	}
+	wantProtocol := `<definition><clinicalTrialProtocol><id root="2.16.840.1.113883.3.2" extension="PUK-123-PROT-A"></id><title>Cardiac Safety Protocol for Compound PUK-123</title></clinicalTrialProtocol></definition>`
+	if !strings.Contains(out, wantProtocol) {
+		t.Errorf("protocol not encoded as expected:\n%s", out)
+	}
+	if strings.Contains(out, "<extension>") {
+		t.Error("sponsor must not carry a separate <extension> element")
+	}
+
+	decoded := NewHl7xml("")
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	ct := &decoded.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial
+	if p := ct.GetProtocol(); p == nil || p.ID.Extension != "PUK-123-PROT-A" || *p.Title != "Cardiac Safety Protocol for Compound PUK-123" {
+		t.Errorf("GetProtocol() = %+v", p)
+	}
+	if s := ct.GetSponsor(); s == nil || s.ID.Root != "2.16.840.1.113883.3" || *s.Name != "ABC Drug Company" {
+		t.Errorf("GetSponsor() = %+v", s)
+	}
+}
+
+// TestHl7xml_SetTrialRegistry tests the protocol cross-check against a trial registry.
+func TestHl7xml_SetTrialRegistry(t *testing.T) {
+	registry := types.NewTrialRegistry().Register(types.TrialRegistryEntry{
+		ProtocolID: types.ID{Root: "2.16.840.1.113883.3.2", Extension: "PUK-123-PROT-A"},
+		TrialID:    types.ID{Root: "2.16.840.1.113883.3.4", Extension: "PUK-123-TRL-1"},
+	})
+
+	tests := []struct {
+		name      string
+		trialExt  string
+		protocol  string
+		wantField string
+	}{
+		{"registered", "PUK-123-TRL-1", "PUK-123-PROT-A", ""},
+		{"unknown protocol", "PUK-123-TRL-1", "PUK-999-PROT-Z", "clinicalTrialProtocol.id"},
+		{"trial mismatch", "PUK-123-TRL-2", "PUK-123-PROT-A", "clinicalTrial.id"},
+		{"missing protocol", "PUK-123-TRL-1", "", "clinicalTrialProtocol.id"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHl7xml("").SetTrialRegistry(registry)
+			h.clinicalTrial().SetID("2.16.840.1.113883.3.4", tt.trialExt)
+			if tt.protocol != "" {
+				h.SetProtocol("2.16.840.1.113883.3.2", tt.protocol, "")
+			}
+
+			h.HL7AEcg.Validate(context.Background(), h.vctx)
+			var fields []string
+			for _, err := range h.vctx.Errors {
+				if ve, ok := err.(*types.ValidationError); ok && strings.HasPrefix(ve.Field, "clinicalTrial") {
+					fields = append(fields, ve.Field)
+				}
+			}
+			if tt.wantField == "" && len(fields) != 0 {
+				t.Errorf("unexpected registry errors: %v", fields)
+			}
+			if tt.wantField != "" && (len(fields) != 1 || fields[0] != tt.wantField) {
+				t.Errorf("errors = %v, want [%s]", fields, tt.wantField)
+			}
+		})
+	}
+}
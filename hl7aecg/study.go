@@ -0,0 +1,307 @@
+package hl7aecg
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+	"gopkg.in/yaml.v3"
+)
+
+// =============================================================================
+// Study Profile
+// =============================================================================
+
+var (
+	// ErrUnknownSite is returned when a site ID is not listed in the study profile.
+	ErrUnknownSite = errors.New("hl7aecg: unknown study site")
+
+	// ErrInvalidStudyProfile is returned when a study profile cannot be used.
+	ErrInvalidStudyProfile = errors.New("hl7aecg: invalid study profile")
+)
+
+// StudyProfile holds the settings shared by every document of a trial:
+// trial and protocol identifiers, sponsor, sites, investigators, device and
+// filter settings.
+//
+// A profile is loaded once with LoadStudyProfile and stamped onto each new
+// document with NewHl7xml, so that the acquisition code only supplies the
+// subject, the times and the waveforms.
+//
+// Example (YAML):
+//
+//	trial:    {root: 2.16.840.1.113883.3.4, extension: PUK-123-TRL-1, title: Cardiac Safety Study}
+//	protocol: {root: 2.16.840.1.113883.3.2, extension: PUK-123-PROT-A}
+//	sponsor:  {root: 2.16.840.1.113883.3, extension: ABC-PHARMA, name: ABC Drug Company}
+//	siteRoot: 2.16.840.1.113883.3.5
+//	defaults:
+//	  device: {id: 1.2.3.4.5, type: 12LEAD_ELECTROCARDIOGRAPH, model: MAC 5500}
+//	  filters: {lowPass: "35", highPass: "0.56", notch: "50", unit: Hz}
+//	sites:
+//	  - id: SITE_001
+//	    name: 1st Clinic of Milwaukee
+//	    country: USA
+//	    investigator: {id: INV_001, prefix: Dr., given: John, family: Smith}
+//	    filters: {notch: "60"}
+//
+// JSON files use the same keys.
+type StudyProfile struct {
+	Trial    StudyIdentifier `json:"trial" yaml:"trial"`
+	Protocol StudyIdentifier `json:"protocol" yaml:"protocol"`
+	Sponsor  StudySponsor    `json:"sponsor" yaml:"sponsor"`
+
+	// SiteRoot is the OID used for sites that do not set their own root.
+	SiteRoot string `json:"siteRoot,omitempty" yaml:"siteRoot,omitempty"`
+
+	// Defaults apply to every site unless the site overrides them.
+	Defaults StudySettings `json:"defaults" yaml:"defaults"`
+
+	Sites []StudySite `json:"sites" yaml:"sites"`
+}
+
+// StudyIdentifier identifies the trial or the protocol.
+type StudyIdentifier struct {
+	Root      string `json:"root" yaml:"root"`
+	Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Title     string `json:"title,omitempty" yaml:"title,omitempty"`
+}
+
+// StudySponsor describes the sponsor organization.
+type StudySponsor struct {
+	Root      string `json:"root" yaml:"root"`
+	Extension string `json:"extension,omitempty" yaml:"extension,omitempty"`
+	Name      string `json:"name,omitempty" yaml:"name,omitempty"`
+}
+
+// StudySettings groups the settings a site may override.
+//
+// A site investigator or device replaces the default one as a whole.
+// Filters are overridden field by field, so a site only needs to list the
+// frequencies that differ (e.g. a 60 Hz notch).
+type StudySettings struct {
+	Investigator *StudyInvestigator `json:"investigator,omitempty" yaml:"investigator,omitempty"`
+	Device       *StudyDevice       `json:"device,omitempty" yaml:"device,omitempty"`
+	Filters      *StudyFilters      `json:"filters,omitempty" yaml:"filters,omitempty"`
+}
+
+// StudySite describes a trial site and its overrides.
+type StudySite struct {
+	ID      string `json:"id" yaml:"id"`
+	Root    string `json:"root,omitempty" yaml:"root,omitempty"`
+	Name    string `json:"name,omitempty" yaml:"name,omitempty"`
+	City    string `json:"city,omitempty" yaml:"city,omitempty"`
+	State   string `json:"state,omitempty" yaml:"state,omitempty"`
+	Country string `json:"country,omitempty" yaml:"country,omitempty"`
+
+	StudySettings `yaml:",inline"`
+}
+
+// StudyInvestigator is the investigator responsible for a site.
+// See SetResponsibleParty.
+type StudyInvestigator struct {
+	Root   string `json:"root,omitempty" yaml:"root,omitempty"`
+	ID     string `json:"id" yaml:"id"`
+	Prefix string `json:"prefix,omitempty" yaml:"prefix,omitempty"`
+	Given  string `json:"given,omitempty" yaml:"given,omitempty"`
+	Family string `json:"family,omitempty" yaml:"family,omitempty"`
+	Suffix string `json:"suffix,omitempty" yaml:"suffix,omitempty"`
+}
+
+// StudyDevice is the device that authors the series. See SetSeriesAuthor.
+type StudyDevice struct {
+	ID               string               `json:"id" yaml:"id"`
+	Type             types.DeviceTypeCode `json:"type,omitempty" yaml:"type,omitempty"`
+	Model            string               `json:"model,omitempty" yaml:"model,omitempty"`
+	Software         string               `json:"software,omitempty" yaml:"software,omitempty"`
+	ManufacturerID   string               `json:"manufacturerId,omitempty" yaml:"manufacturerId,omitempty"`
+	ManufacturerName string               `json:"manufacturerName,omitempty" yaml:"manufacturerName,omitempty"`
+}
+
+// StudyFilters lists the filter settings written on every series.
+// Empty frequencies are not written. Unit defaults to "Hz".
+type StudyFilters struct {
+	LowPass  string `json:"lowPass,omitempty" yaml:"lowPass,omitempty"`
+	HighPass string `json:"highPass,omitempty" yaml:"highPass,omitempty"`
+	Notch    string `json:"notch,omitempty" yaml:"notch,omitempty"`
+	Unit     string `json:"unit,omitempty" yaml:"unit,omitempty"`
+}
+
+// LoadStudyProfile reads a study profile from a YAML or JSON file.
+func LoadStudyProfile(path string) (*StudyProfile, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read study profile %s: %w", path, err)
+	}
+	p, err := ParseStudyProfile(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return p, nil
+}
+
+// ParseStudyProfile parses a study profile from YAML or JSON data.
+//
+// Unknown keys are rejected so that typos do not silently drop settings.
+func ParseStudyProfile(data []byte) (*StudyProfile, error) {
+	// JSON is valid YAML, so a single decoder handles both formats
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+
+	p := &StudyProfile{}
+	if err := dec.Decode(p); err != nil {
+		return nil, fmt.Errorf("decode study profile: %w", err)
+	}
+	if err := p.check(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// check reports missing or duplicate site IDs.
+func (p *StudyProfile) check() error {
+	seen := make(map[string]bool, len(p.Sites))
+	for i, site := range p.Sites {
+		if site.ID == "" {
+			return fmt.Errorf("%w: sites[%d] has no id", ErrInvalidStudyProfile, i)
+		}
+		if seen[site.ID] {
+			return fmt.Errorf("%w: duplicate site id %q", ErrInvalidStudyProfile, site.ID)
+		}
+		seen[site.ID] = true
+	}
+	return nil
+}
+
+// Site returns the site with the given ID, with the study defaults resolved
+// and SiteRoot applied.
+func (p *StudyProfile) Site(id string) (StudySite, bool) {
+	for _, site := range p.Sites {
+		if site.ID != id {
+			continue
+		}
+		if site.Root == "" {
+			site.Root = p.SiteRoot
+		}
+		site.StudySettings = p.Defaults.merge(site.StudySettings)
+		return site, true
+	}
+	return StudySite{}, false
+}
+
+// NewHl7xml creates a document stamped with the study and site settings.
+//
+// The trial, protocol, sponsor, site and investigator are set immediately.
+// The device and filters are applied to each series added afterwards with
+// AddRhythmSeries or AddRepresentativeBeatSeries.
+//
+// Returns ErrUnknownSite if the site is not listed in the profile.
+//
+// Example:
+//
+//	profile, err := hl7aecg.LoadStudyProfile("study.yaml")
+//	...
+//	h, err := profile.NewHl7xml("/tmp", "SITE_001")
+//	...
+//	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "").
+//	    SetSubject("2.16.840.1.113883.3.6", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+//	    SetEffectiveTime("20240101120000", "20240101120010", nil, nil).
+//	    AddRhythmSeries("20240101120000", "20240101120010", nil, nil, 500, leads, 0, 5)
+func (p *StudyProfile) NewHl7xml(outputDir, siteID string) (*Hl7xml, error) {
+	h := NewHl7xml(outputDir)
+	if err := h.ApplyStudyProfile(p, siteID); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// ApplyStudyProfile stamps the study and site settings onto the document.
+// See StudyProfile.NewHl7xml.
+//
+// Returns ErrUnknownSite if the site is not listed in the profile.
+func (h *Hl7xml) ApplyStudyProfile(p *StudyProfile, siteID string) error {
+	site, ok := p.Site(siteID)
+	if !ok {
+		return fmt.Errorf("%w: %q", ErrUnknownSite, siteID)
+	}
+
+	ct := h.clinicalTrial()
+	if p.Trial.Root != "" || p.Trial.Extension != "" {
+		ct.SetID(p.Trial.Root, p.Trial.Extension)
+	}
+	if p.Trial.Title != "" {
+		ct.SetTitle(p.Trial.Title)
+	}
+	if p.Protocol.Root != "" || p.Protocol.Extension != "" {
+		h.SetProtocol(p.Protocol.Root, p.Protocol.Extension, p.Protocol.Title)
+	}
+	if p.Sponsor.Root != "" || p.Sponsor.Extension != "" || p.Sponsor.Name != "" {
+		h.SetSponsor(p.Sponsor.Root, p.Sponsor.Extension, p.Sponsor.Name)
+	}
+
+	h.SetLocation(site.ID, site.Root, site.Name, site.City, site.State, site.Country)
+	if inv := site.Investigator; inv != nil {
+		h.SetResponsibleParty(inv.Root, inv.ID, inv.Prefix, inv.Given, inv.Family, inv.Suffix)
+	}
+
+	h.study = &site.StudySettings
+	return nil
+}
+
+// applyStudySeries writes the study device and filters on the most recently
+// added series.
+func (h *Hl7xml) applyStudySeries() {
+	if h.study == nil {
+		return
+	}
+	if d := h.study.Device; d != nil {
+		h.SetSeriesAuthor(d.ID, d.Type, d.Model, d.Software, d.ManufacturerID, d.ManufacturerName)
+	}
+	if f := h.study.Filters; f != nil {
+		unit := f.Unit
+		if unit == "" {
+			unit = "Hz"
+		}
+		if f.LowPass != "" {
+			h.AddLowPassFilter(f.LowPass, unit)
+		}
+		if f.HighPass != "" {
+			h.AddHighPassFilter(f.HighPass, unit)
+		}
+		if f.Notch != "" {
+			h.AddNotchFilter(f.Notch, unit)
+		}
+	}
+}
+
+// merge returns the defaults overridden by the site settings.
+func (d StudySettings) merge(site StudySettings) StudySettings {
+	out := d
+	if site.Investigator != nil {
+		out.Investigator = site.Investigator
+	}
+	if site.Device != nil {
+		out.Device = site.Device
+	}
+	if site.Filters != nil {
+		filters := StudyFilters{}
+		if d.Filters != nil {
+			filters = *d.Filters
+		}
+		if site.Filters.LowPass != "" {
+			filters.LowPass = site.Filters.LowPass
+		}
+		if site.Filters.HighPass != "" {
+			filters.HighPass = site.Filters.HighPass
+		}
+		if site.Filters.Notch != "" {
+			filters.Notch = site.Filters.Notch
+		}
+		if site.Filters.Unit != "" {
+			filters.Unit = site.Filters.Unit
+		}
+		out.Filters = &filters
+	}
+	return out
+}
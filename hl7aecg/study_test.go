@@ -0,0 +1,165 @@
+package hl7aecg
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+const studyYAML = `
+trial: {root: 2.16.840.1.113883.3.4, extension: PUK-123-TRL-1, title: Cardiac Safety Study}
+protocol: {root: 2.16.840.1.113883.3.2, extension: PUK-123-PROT-A}
+sponsor: {root: 2.16.840.1.113883.3, extension: ABC-PHARMA, name: ABC Drug Company}
+siteRoot: 2.16.840.1.113883.3.5
+defaults:
+  investigator: {id: INV_000, family: Default}
+  device: {id: 1.2.3.4.5, type: 12LEAD_ELECTROCARDIOGRAPH, model: MAC 5500, manufacturerId: 1.2.3, manufacturerName: Acme}
+  filters: {lowPass: "35", highPass: "0.56", notch: "50"}
+sites:
+  - id: SITE_001
+    name: Hopital Haut-Leveque
+    city: Pessac
+    country: France
+  - id: SITE_002
+    root: 2.16.840.1.113883.3.9
+    name: 1st Clinic of Milwaukee
+    country: USA
+    investigator: {id: INV_002, prefix: Dr., given: John, family: Smith}
+    filters: {notch: "60"}
+`
+
+const studyJSON = `{
+  "trial": {"root": "2.16.840.1.113883.3.4", "extension": "PUK-123-TRL-1"},
+  "protocol": {"root": "2.16.840.1.113883.3.2", "extension": "PUK-123-PROT-A"},
+  "sponsor": {"root": "2.16.840.1.113883.3", "name": "ABC Drug Company"},
+  "sites": [{"id": "SITE_001", "root": "2.16.840.1.113883.3.5"}]
+}`
+
+// TestLoadStudyProfile tests loading YAML and JSON files
+func TestLoadStudyProfile(t *testing.T) {
+	dir := t.TempDir()
+	for name, content := range map[string]string{"study.yaml": studyYAML, "study.json": studyJSON} {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		p, err := LoadStudyProfile(path)
+		if err != nil {
+			t.Fatalf("LoadStudyProfile(%s) error = %v", name, err)
+		}
+		if p.Protocol.Extension != "PUK-123-PROT-A" || len(p.Sites) == 0 {
+			t.Errorf("%s: profile = %+v", name, p)
+		}
+	}
+
+	if _, err := LoadStudyProfile(filepath.Join(dir, "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
+
+// TestParseStudyProfile_Invalid tests that bad profiles are rejected
+func TestParseStudyProfile_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"unknown key", "protocl: {root: 1.2.3}"},
+		{"site without id", "sites: [{name: A}]"},
+		{"duplicate site", "sites: [{id: A}, {id: A}]"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseStudyProfile([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+// TestStudyProfile_Site tests site lookup and per-site overrides
+func TestStudyProfile_Site(t *testing.T) {
+	p, err := ParseStudyProfile([]byte(studyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	site, ok := p.Site("SITE_001")
+	if !ok {
+		t.Fatal("SITE_001 not found")
+	}
+	if site.Root != "2.16.840.1.113883.3.5" {
+		t.Errorf("Root = %q, want siteRoot", site.Root)
+	}
+	if site.Investigator == nil || site.Investigator.ID != "INV_000" {
+		t.Errorf("Investigator = %+v, want default", site.Investigator)
+	}
+	if site.Filters.Notch != "50" {
+		t.Errorf("Notch = %q, want 50", site.Filters.Notch)
+	}
+
+	site, _ = p.Site("SITE_002")
+	if site.Root != "2.16.840.1.113883.3.9" {
+		t.Errorf("Root = %q, want site root", site.Root)
+	}
+	if site.Investigator.ID != "INV_002" {
+		t.Errorf("Investigator = %+v, want site override", site.Investigator)
+	}
+	if site.Filters.Notch != "60" || site.Filters.LowPass != "35" {
+		t.Errorf("Filters = %+v, want notch 60 merged with defaults", site.Filters)
+	}
+	if p.Defaults.Filters.Notch != "50" {
+		t.Error("Site() modified the defaults")
+	}
+
+	if _, ok := p.Site("SITE_404"); ok {
+		t.Error("unknown site was found")
+	}
+}
+
+// TestStudyProfile_NewHl7xml tests that study settings are stamped on the document
+func TestStudyProfile_NewHl7xml(t *testing.T) {
+	p, err := ParseStudyProfile([]byte(studyYAML))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := p.NewHl7xml("", "SITE_404"); !errors.Is(err, ErrUnknownSite) {
+		t.Errorf("error = %v, want ErrUnknownSite", err)
+	}
+
+	h, err := p.NewHl7xml("", "SITE_002")
+	if err != nil {
+		t.Fatalf("NewHl7xml() error = %v", err)
+	}
+	h.AddRhythmSeries("20240101120000", "20240101120010", nil, nil, 500,
+		map[types.LeadCode][]int{types.MDC_ECG_LEAD_I: {1, 2, 3}}, 0, 5)
+
+	ct := h.clinicalTrial()
+	if ct.ID.Extension != "PUK-123-TRL-1" || ct.Title == nil || *ct.Title != "Cardiac Safety Study" {
+		t.Errorf("trial = %+v", ct)
+	}
+	if prot := ct.GetProtocol(); prot == nil || prot.ID.Extension != "PUK-123-PROT-A" {
+		t.Errorf("protocol = %+v", prot)
+	}
+	if sp := ct.GetSponsor(); sp == nil || sp.Name == nil || *sp.Name != "ABC Drug Company" {
+		t.Errorf("sponsor = %+v", sp)
+	}
+	trialSite := ct.Location.TrialSite
+	if trialSite.ID.Extension != "SITE_002" || trialSite.ID.Root != "2.16.840.1.113883.3.9" {
+		t.Errorf("site id = %+v", trialSite.ID)
+	}
+	if trialSite.ResponsibleParty == nil || trialSite.ResponsibleParty.TrialInvestigator.ID.Extension != "INV_002" {
+		t.Errorf("responsible party = %+v", trialSite.ResponsibleParty)
+	}
+
+	series := h.HL7AEcg.Component[0].Series
+	if series.Author == nil || series.Author.SeriesAuthor.ID.Root != "1.2.3.4.5" {
+		t.Errorf("series author = %+v", series.Author)
+	}
+	if len(series.ControlVariable) != 3 {
+		t.Fatalf("control variables = %d, want 3 filters", len(series.ControlVariable))
+	}
+}
@@ -54,7 +54,6 @@ func (h *Hl7xml) SetSubject(id, extension string, code types.CodeRole) *Hl7xml {
 		}
 		trialSubject.Code.SetCode(code, types.HL7_ResearchSubjectRoleBasis_OID, "", "")
 	}
-	trialSubject.SubjectDemographicPerson.SetMedications().SetClinicalClassifications()
 
 	return h
 }
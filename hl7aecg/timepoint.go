@@ -0,0 +1,136 @@
+package hl7aecg
+
+import (
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// =============================================================================
+// Timepoint Event (visit)
+// =============================================================================
+
+// SetTimepointEvent sets the visit during which the ECG was recorded.
+//
+// The timepoint event is stored in ComponentOf > TimepointEvent, which is
+// initialized if needed.
+//
+// Parameters:
+//   - code: Visit code defined by the protocol (e.g., "VISIT_2", "DAY_1")
+//   - codeSystem: OID naming the protocol that defines the code
+//   - reason: types.VISIT_TYPE_SCHEDULED or types.VISIT_TYPE_UNSCHEDULED, empty to omit
+//
+// Example:
+//
+//	h.SetTimepointEvent("VISIT_2", "2.16.840.1.113883.3.1", types.VISIT_TYPE_SCHEDULED)
+func (h *Hl7xml) SetTimepointEvent(code string, codeSystem types.CodeSystemOID, reason types.VisitTypeCode) *Hl7xml {
+	te := h.timepointEvent()
+	te.SetCode(code, codeSystem)
+	if reason != "" {
+		te.SetReasonCode(reason)
+	}
+	return h
+}
+
+// SetTimepointEventTime sets the time range of the visit.
+//
+// Example:
+//
+//	h.SetTimepointEventTime("20020509100700", "20020509134600")
+func (h *Hl7xml) SetTimepointEventTime(low, high string) *Hl7xml {
+	h.timepointEvent().SetEffectiveTime(low, high)
+	return h
+}
+
+// SetTimepointPerformer sets the person responsible for the visit, typically
+// the technician who recorded the ECG.
+//
+// Example:
+//
+//	h.SetTimepointPerformer("2.16.840.1.113883.3.5", "TECH_1", "Julie Tech")
+func (h *Hl7xml) SetTimepointPerformer(root, extension, name string) *Hl7xml {
+	h.timepointEvent().SetPerformer(root, extension, name)
+	return h
+}
+
+// =============================================================================
+// Relative Timepoint (planned timepoint)
+// =============================================================================
+
+// SetRelativeTimepoint sets the planned timepoint relative to a reference
+// event, e.g. "2 hours post dose" in a thorough QT study.
+//
+// The protocol timepoint event defaults to the visit code set with
+// SetTimepointEvent; use SetProtocolTimepointEvent when the ECG was not
+// recorded at the planned visit. Invalid pause values (NaN, Inf) are ignored.
+//
+// Parameters:
+//   - code: Planned timepoint code (e.g., "POST_DOSE_2H")
+//   - codeSystem: OID naming the protocol that defines the code
+//   - pause: Delay after the reference event
+//   - unit: UCUM time unit of the delay (e.g., types.UNIT_HOUR)
+//
+// Example:
+//
+//	h.SetTimepointEvent("VISIT_2", protocolOID, types.VISIT_TYPE_SCHEDULED).
+//	    SetRelativeTimepoint("POST_DOSE_2H", protocolOID, 2, types.UNIT_HOUR).
+//	    SetReferenceEvent("DOSAGE_1", protocolOID)
+func (h *Hl7xml) SetRelativeTimepoint(code string, codeSystem types.CodeSystemOID, pause float64, unit string) *Hl7xml {
+	rt := types.NewRelativeTimepoint(code, codeSystem).SetPauseQuantity(pause, unit)
+	if h.HL7AEcg.Definition != nil {
+		// Keep the protocol timepoint and reference event already set
+		rt.ComponentOf.ProtocolTimepointEvent = h.HL7AEcg.Definition.RelativeTimepoint.ComponentOf.ProtocolTimepointEvent
+	}
+	if rt.ComponentOf.ProtocolTimepointEvent.Code.Code == "" && h.HL7AEcg.ComponentOf != nil {
+		if visit := h.HL7AEcg.ComponentOf.TimepointEvent.Code; visit != nil {
+			rt.SetProtocolTimepointEvent(visit.Code, visit.CodeSystem)
+		}
+	}
+	h.HL7AEcg.Definition = &types.AnnotatedECGDefinition{RelativeTimepoint: *rt}
+	return h
+}
+
+// SetProtocolTimepointEvent sets the visit code as planned by the protocol.
+//
+// Example:
+//
+//	h.SetProtocolTimepointEvent("VISIT_2", "2.16.840.1.113883.3.1")
+func (h *Hl7xml) SetProtocolTimepointEvent(code string, codeSystem types.CodeSystemOID) *Hl7xml {
+	h.relativeTimepoint().SetProtocolTimepointEvent(code, codeSystem)
+	return h
+}
+
+// SetReferenceEvent sets the benchmark event of the planned timepoint
+// (e.g., the dose the timepoint is relative to).
+//
+// Example:
+//
+//	h.SetReferenceEvent("DOSAGE_1", "2.16.840.1.113883.3.1")
+func (h *Hl7xml) SetReferenceEvent(code string, codeSystem types.CodeSystemOID) *Hl7xml {
+	h.relativeTimepoint().SetReferenceEvent(code, codeSystem)
+	return h
+}
+
+// GetRelativeTimepoint returns the planned relative timepoint, or nil if not set.
+func (h *Hl7xml) GetRelativeTimepoint() *types.RelativeTimepoint {
+	if h.HL7AEcg.Definition == nil {
+		return nil
+	}
+	return &h.HL7AEcg.Definition.RelativeTimepoint
+}
+
+// timepointEvent returns the timepoint event, initializing the ComponentOf
+// structure if needed.
+func (h *Hl7xml) timepointEvent() *types.TimepointEvent {
+	if h.HL7AEcg.ComponentOf == nil {
+		h.SetSubject("", "", "") // Initialize structure
+	}
+	return &h.HL7AEcg.ComponentOf.TimepointEvent
+}
+
+// relativeTimepoint returns the relative timepoint, initializing the
+// Definition structure if needed.
+func (h *Hl7xml) relativeTimepoint() *types.RelativeTimepoint {
+	if h.HL7AEcg.Definition == nil {
+		h.HL7AEcg.Definition = &types.AnnotatedECGDefinition{}
+	}
+	return &h.HL7AEcg.Definition.RelativeTimepoint
+}
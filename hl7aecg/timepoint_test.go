@@ -0,0 +1,91 @@
+package hl7aecg
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+const protocolOID types.CodeSystemOID = "2.16.840.1.113883.3.1"
+
+// TestHl7xml_TimepointBuilders tests the visit and relative timepoint builders and their XML round-trip
+func TestHl7xml_TimepointBuilders(t *testing.T) {
+	h := NewHl7xml("/tmp")
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "")
+	h.SetTimepointEvent("VISIT_2", protocolOID, types.VISIT_TYPE_SCHEDULED).
+		SetTimepointEventTime("20020509100700", "20020509134600").
+		SetTimepointPerformer("2.16.840.1.113883.3.5", "TECH_1", "Julie Tech").
+		SetRelativeTimepoint("POST_DOSE_2H", protocolOID, 2, types.UNIT_HOUR).
+		SetReferenceEvent("DOSAGE_1", protocolOID)
+
+	data, err := xml.Marshal(&h.HL7AEcg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	out := string(data)
+	if !strings.Contains(out, `<pauseQuantity value="2" unit="h"></pauseQuantity>`) {
+		t.Errorf("pauseQuantity not encoded: %s", out)
+	}
+	if strings.Index(out, "<definition>") > strings.Index(out, "<componentOf>") {
+		t.Error("definition must precede componentOf")
+	}
+
+	decoded := NewHl7xml("")
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+
+	te := decoded.HL7AEcg.ComponentOf.TimepointEvent
+	if te.Code == nil || te.Code.Code != "VISIT_2" || te.Code.CodeSystem != protocolOID {
+		t.Errorf("timepointEvent code = %+v", te.Code)
+	}
+	if te.ReasonCode == nil || te.ReasonCode.Code != types.VISIT_TYPE_SCHEDULED {
+		t.Errorf("timepointEvent reasonCode = %+v", te.ReasonCode)
+	}
+	if te.EffectiveTime == nil || te.EffectiveTime.High.Value != "20020509134600" {
+		t.Errorf("timepointEvent effectiveTime = %+v", te.EffectiveTime)
+	}
+	if te.Performer == nil || *te.Performer.StudyEventPerformer.AssignedPerson.Name != "Julie Tech" ||
+		te.Performer.StudyEventPerformer.ID.Extension != "TECH_1" {
+		t.Errorf("timepointEvent performer = %+v", te.Performer)
+	}
+
+	rt := decoded.GetRelativeTimepoint()
+	if rt == nil {
+		t.Fatal("GetRelativeTimepoint() = nil")
+	}
+	if rt.Code.Code != "POST_DOSE_2H" {
+		t.Errorf("relativeTimepoint code = %q", rt.Code.Code)
+	}
+	if s, ok := rt.ComponentOf.PauseQuantity.Seconds(); !ok || s != 7200 {
+		t.Errorf("pause = %v (%v), want 7200 s", s, ok)
+	}
+	if rt.ComponentOf.ProtocolTimepointEvent.Code.Code != "VISIT_2" {
+		t.Errorf("protocol timepoint = %q, want visit code", rt.ComponentOf.ProtocolTimepointEvent.Code.Code)
+	}
+	if ref := rt.GetReferenceEvent(); ref == nil || ref.Code.Code != "DOSAGE_1" {
+		t.Errorf("reference event = %+v", ref)
+	}
+}
+
+// TestHl7xml_RelativeTimepointKeepsProtocol tests that resetting the relative timepoint keeps its protocol parts
+func TestHl7xml_RelativeTimepointKeepsProtocol(t *testing.T) {
+	h := NewHl7xml("")
+	h.SetReferenceEvent("DOSAGE_1", protocolOID).
+		SetProtocolTimepointEvent("VISIT_3", protocolOID).
+		SetTimepointEvent("VISIT_2", protocolOID, "").
+		SetRelativeTimepoint("PRE_DOSE", protocolOID, 30, types.UNIT_MINUTE)
+
+	rt := h.GetRelativeTimepoint()
+	if rt.ComponentOf.ProtocolTimepointEvent.Code.Code != "VISIT_3" {
+		t.Errorf("protocol timepoint = %q, want VISIT_3", rt.ComponentOf.ProtocolTimepointEvent.Code.Code)
+	}
+	if ref := rt.GetReferenceEvent(); ref == nil || ref.Code.Code != "DOSAGE_1" {
+		t.Errorf("reference event = %+v", ref)
+	}
+	if h.HL7AEcg.ComponentOf.TimepointEvent.ReasonCode != nil {
+		t.Error("empty reason must not set a reasonCode")
+	}
+}
@@ -0,0 +1,90 @@
+package hl7aecg
+
+import (
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// SetTreatmentGroup assigns the subject to a sponsor-defined treatment arm
+// (unblinded).
+//
+// The assignment is stored in ComponentOf > TimepointEvent > SubjectAssignment >
+// Definition, which is initialized if needed.
+//
+// Parameters:
+//   - code: Arm code defined by the sponsor (e.g., "PLACEBO", "MOXI_400")
+//   - codeSystem: OID of the protocol or trial defining the arm
+//   - displayName: Human-readable arm name, empty to omit
+//
+// Example:
+//
+//	h.SetTreatmentGroup("MOXI_400", "2.16.840.1.113883.3.1", "Moxifloxacin 400 mg")
+func (h *Hl7xml) SetTreatmentGroup(code types.TreatmentGroupCode, codeSystem types.CodeSystemOID, displayName string) *Hl7xml {
+	h.subjectAssignment().SetTreatmentGroup(code, codeSystem, displayName)
+	return h
+}
+
+// SetBlindedTreatmentGroup masks the subject's treatment arm and sets the
+// document's confidentiality code accordingly.
+//
+// Parameters:
+//   - codeSystem: OID of the protocol or trial defining the arms
+//   - confidentiality: Who the document is blinded to
+//     (types.CONFIDENTIALITY_SPONSOR_BLINDED, CONFIDENTIALITY_INVESTIGATOR_BLINDED
+//     or CONFIDENTIALITY_BOTH)
+//
+// Example:
+//
+//	h.SetBlindedTreatmentGroup("2.16.840.1.113883.3.1", types.CONFIDENTIALITY_BOTH)
+func (h *Hl7xml) SetBlindedTreatmentGroup(codeSystem types.CodeSystemOID, confidentiality types.ConfidentialityCode) *Hl7xml {
+	h.subjectAssignment().SetBlindedTreatmentGroup(codeSystem)
+	return h.AddConfidentialityCode(confidentiality)
+}
+
+// AssignTreatmentGroup assigns the subject to a treatment arm, choosing the
+// blinded or unblinded variant from the document's confidentiality code.
+//
+// If the confidentiality code blinds the sponsor or the investigator
+// (S, I or B), the masked group is written; otherwise the actual arm is.
+// Set the confidentiality code first (AddConfidentialityCode).
+//
+// Example:
+//
+//	h.AddConfidentialityCode(types.CONFIDENTIALITY_BOTH).
+//	    AssignTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg") // writes BLINDED
+func (h *Hl7xml) AssignTreatmentGroup(code types.TreatmentGroupCode, codeSystem types.CodeSystemOID, displayName string) *Hl7xml {
+	if cc := h.HL7AEcg.ConfidentialityCode; cc != nil && types.IsBlinding(cc.Code) {
+		h.subjectAssignment().SetBlindedTreatmentGroup(codeSystem)
+		return h
+	}
+	return h.SetTreatmentGroup(code, codeSystem, displayName)
+}
+
+// GetTreatmentGroup returns the subject's treatment group code, or nil if not assigned.
+func (h *Hl7xml) GetTreatmentGroup() *types.Code[types.TreatmentGroupCode, types.CodeSystemOID] {
+	if h.HL7AEcg.ComponentOf == nil {
+		return nil
+	}
+	return h.HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.GetTreatmentGroup()
+}
+
+// ViewForRole returns a copy of the document that is safe to send to the
+// recipient, with the treatment group, timepoint information and annotation
+// codes blinded for its role masked or removed (see types.HL7AEcg.ViewForRole).
+//
+// The document held by the receiver is not modified, so a master document may
+// carry the actual arm together with a blinding confidentiality code; validate
+// the view rather than the master in that case. A nil policy means
+// types.DefaultBlindingPolicy().
+//
+// Example:
+//
+//	h.SetTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg").
+//	    AddConfidentialityCode(types.CONFIDENTIALITY_BOTH)
+//	out, err := h.ViewForRole(types.ROLE_READER, nil).String()
+func (h *Hl7xml) ViewForRole(role types.RecipientRole, policy *types.BlindingPolicy) *Hl7xml {
+	view := *h
+	view.HL7AEcg = *h.HL7AEcg.ViewForRole(role, policy)
+	view.vctx = types.NewValidationContext(true)
+	view.vctx.TrialRegistry = h.vctx.TrialRegistry
+	return &view
+}
@@ -0,0 +1,148 @@
+package hl7aecg
+
+import (
+	"context"
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
+)
+
+// treatmentErrors validates the document and returns the treatment group errors and warnings
+func treatmentErrors(h *Hl7xml) (errs []string, warnings []string) {
+	vctx := types.NewValidationContext(false)
+	h.HL7AEcg.Validate(context.Background(), vctx)
+	for _, err := range vctx.Errors {
+		if ve, ok := err.(*types.ValidationError); ok && ve.Field == "treatmentGroupAssignment.code" {
+			errs = append(errs, ve.Message)
+		}
+	}
+	for _, w := range vctx.Warnings {
+		if strings.HasPrefix(w, "treatmentGroupAssignment") {
+			warnings = append(warnings, w)
+		}
+	}
+	return errs, warnings
+}
+
+// TestHl7xml_SetTreatmentGroup tests assigning a sponsor-defined arm and its XML round-trip
+func TestHl7xml_SetTreatmentGroup(t *testing.T) {
+	h := NewHl7xml("")
+	h.SetSubject("2.16.840.1.113883.3.5", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+		SetTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg")
+
+	data, err := xml.Marshal(&h.HL7AEcg)
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.Contains(string(data), `<treatmentGroupAssignment><code code="MOXI_400" codeSystem="2.16.840.1.113883.3.1" displayName="Moxifloxacin 400 mg"></code></treatmentGroupAssignment>`) {
+		t.Errorf("treatment group not encoded: %s", data)
+	}
+
+	decoded := NewHl7xml("")
+	if err := decoded.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	group := decoded.GetTreatmentGroup()
+	if group == nil || group.Code != "MOXI_400" || group.CodeSystem != protocolOID || group.DisplayName != "Moxifloxacin 400 mg" {
+		t.Errorf("GetTreatmentGroup() = %+v", group)
+	}
+
+	if errs, _ := treatmentErrors(h); len(errs) != 0 {
+		t.Errorf("unexpected errors for an unblinded document: %v", errs)
+	}
+}
+
+// TestHl7xml_TreatmentGroupBlinding tests that the treatment group variant follows the confidentiality code
+func TestHl7xml_TreatmentGroupBlinding(t *testing.T) {
+	t.Run("blinded builder sets confidentiality", func(t *testing.T) {
+		h := NewHl7xml("").SetBlindedTreatmentGroup(protocolOID, types.CONFIDENTIALITY_BOTH)
+		if h.HL7AEcg.ConfidentialityCode.Code != types.CONFIDENTIALITY_BOTH {
+			t.Errorf("ConfidentialityCode = %q, want B", h.HL7AEcg.ConfidentialityCode.Code)
+		}
+		if h.GetTreatmentGroup().Code != types.TREATMENT_GROUP_BLINDED {
+			t.Errorf("group = %q, want BLINDED", h.GetTreatmentGroup().Code)
+		}
+		if errs, warnings := treatmentErrors(h); len(errs) != 0 || len(warnings) != 0 {
+			t.Errorf("errors = %v, warnings = %v", errs, warnings)
+		}
+	})
+
+	t.Run("assign follows confidentiality", func(t *testing.T) {
+		blinded := NewHl7xml("").AddConfidentialityCode(types.CONFIDENTIALITY_SPONSOR_BLINDED).
+			AssignTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg")
+		if blinded.GetTreatmentGroup().Code != types.TREATMENT_GROUP_BLINDED {
+			t.Errorf("blinded group = %q, want BLINDED", blinded.GetTreatmentGroup().Code)
+		}
+
+		custom := NewHl7xml("").AddConfidentialityCode(types.CONFIDENTIALITY_CUSTOM).
+			AssignTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg")
+		if custom.GetTreatmentGroup().Code != "MOXI_400" {
+			t.Errorf("custom group = %q, want MOXI_400", custom.GetTreatmentGroup().Code)
+		}
+	})
+
+	t.Run("unblinded arm in blinded document", func(t *testing.T) {
+		h := NewHl7xml("").SetTreatmentGroup("MOXI_400", protocolOID, "").
+			AddConfidentialityCode(types.CONFIDENTIALITY_INVESTIGATOR_BLINDED)
+		if errs, _ := treatmentErrors(h); len(errs) != 1 {
+			t.Errorf("errors = %v, want 1", errs)
+		}
+	})
+
+	t.Run("masked arm without confidentiality", func(t *testing.T) {
+		h := NewHl7xml("")
+		h.SetSubject("", "", "").HL7AEcg.ComponentOf.TimepointEvent.ComponentOf.
+			SubjectAssignment.SetBlindedTreatmentGroup(protocolOID)
+		if _, warnings := treatmentErrors(h); len(warnings) != 1 {
+			t.Errorf("warnings = %v, want 1", warnings)
+		}
+	})
+
+	t.Run("missing code", func(t *testing.T) {
+		h := NewHl7xml("").SetTreatmentGroup("", protocolOID, "")
+		if errs, _ := treatmentErrors(h); len(errs) != 1 {
+			t.Errorf("errors = %v, want 1", errs)
+		}
+	})
+}
+
+// TestHl7xml_ViewForRole tests that views mask the arm for blinded roles only
+func TestHl7xml_ViewForRole(t *testing.T) {
+	h := NewHl7xml("")
+	h.SetSubject("2.16.840.1.113883.3.5", "SUBJ_1", types.SUBJECT_ROLE_ENROLLED).
+		SetTimepointEvent("VISIT_2", protocolOID, types.VISIT_TYPE_SCHEDULED).
+		SetRelativeTimepoint("POST_DOSE_2H", protocolOID, 2, types.UNIT_HOUR).
+		SetTreatmentGroup("MOXI_400", protocolOID, "Moxifloxacin 400 mg").
+		AddConfidentialityCode(types.CONFIDENTIALITY_BOTH)
+
+	reader := h.ViewForRole(types.ROLE_READER, nil)
+	out, err := reader.String()
+	if err != nil {
+		t.Fatalf("String() error = %v", err)
+	}
+	for _, leak := range []string{"MOXI_400", "Moxifloxacin", "VISIT_2", "POST_DOSE_2H"} {
+		if strings.Contains(out, leak) {
+			t.Errorf("reader view contains %q", leak)
+		}
+	}
+	if errs, _ := treatmentErrors(reader); len(errs) != 0 {
+		t.Errorf("reader view errors = %v", errs)
+	}
+
+	investigator := h.ViewForRole(types.ROLE_INVESTIGATOR, nil)
+	if investigator.GetTreatmentGroup().Code != types.TREATMENT_GROUP_BLINDED {
+		t.Errorf("investigator group = %q, want BLINDED", investigator.GetTreatmentGroup().Code)
+	}
+	if investigator.GetRelativeTimepoint() == nil {
+		t.Error("investigator view lost the timepoint")
+	}
+
+	if unblinded := h.ViewForRole(types.ROLE_UNBLINDED, nil); unblinded.GetTreatmentGroup().Code != "MOXI_400" {
+		t.Errorf("unblinded group = %q, want MOXI_400", unblinded.GetTreatmentGroup().Code)
+	}
+	if h.GetTreatmentGroup().Code != "MOXI_400" || h.GetRelativeTimepoint() == nil {
+		t.Error("ViewForRole() modified the master document")
+	}
+}
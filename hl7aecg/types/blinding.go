@@ -0,0 +1,152 @@
+package types
+
+import "slices"
+
+// =============================================================================
+// Blinding
+// =============================================================================
+
+// RecipientRole is the party a document is sent to.
+type RecipientRole string
+
+const (
+	ROLE_SPONSOR      RecipientRole = "sponsor"      // Sponsor staff
+	ROLE_INVESTIGATOR RecipientRole = "investigator" // Site investigator
+	ROLE_READER       RecipientRole = "reader"       // Core lab reader (sponsor side)
+	ROLE_UNBLINDED    RecipientRole = "unblinded"    // Unblinded statistician or safety board
+)
+
+// BlindingPolicy tells what is hidden from a blinded recipient beyond the
+// treatment group, which is always masked.
+//
+// Example:
+//
+//	policy := types.DefaultBlindingPolicy()
+//	policy.AnnotationCodes[types.ROLE_SPONSOR] = []string{"DRUG_CONCENTRATION"}
+type BlindingPolicy struct {
+	// Timepoints lists the roles from which timepoint information (visit
+	// code, visit type and planned relative timepoint) is hidden.
+	Timepoints []RecipientRole
+
+	// AnnotationCodes lists, per role, the annotation codes removed from
+	// every annotation set.
+	AnnotationCodes map[RecipientRole][]string
+
+	// Custom lists the roles blinded by CONFIDENTIALITY_CUSTOM.
+	Custom []RecipientRole
+}
+
+// DefaultBlindingPolicy returns a policy hiding timepoints from core lab
+// readers, as usual in thorough QT studies, and no annotation codes.
+func DefaultBlindingPolicy() *BlindingPolicy {
+	return &BlindingPolicy{
+		Timepoints:      []RecipientRole{ROLE_READER},
+		AnnotationCodes: map[RecipientRole][]string{},
+	}
+}
+
+// Blinds reports whether a document with the given confidentiality code is
+// blinded for the role:
+//   - S blinds the sponsor and the core lab readers
+//   - I blinds the investigator
+//   - B blinds the sponsor, the investigator and the core lab readers
+//   - C blinds the roles listed in Custom
+//
+// ROLE_UNBLINDED is never blinded.
+func (p *BlindingPolicy) Blinds(code ConfidentialityCode, role RecipientRole) bool {
+	switch code {
+	case CONFIDENTIALITY_SPONSOR_BLINDED:
+		return role == ROLE_SPONSOR || role == ROLE_READER
+	case CONFIDENTIALITY_INVESTIGATOR_BLINDED:
+		return role == ROLE_INVESTIGATOR
+	case CONFIDENTIALITY_BOTH:
+		return role == ROLE_SPONSOR || role == ROLE_INVESTIGATOR || role == ROLE_READER
+	case CONFIDENTIALITY_CUSTOM:
+		return p != nil && role != ROLE_UNBLINDED && slices.Contains(p.Custom, role)
+	default:
+		return false
+	}
+}
+
+// ViewForRole returns a copy of the document as it may be sent to a
+// recipient (see Clone).
+//
+// When the confidentiality code blinds the role (see BlindingPolicy.Blinds),
+// in the copy:
+//   - the treatment group is replaced by TREATMENT_GROUP_BLINDED, and the
+//     extensions of the treatment group assignment are dropped
+//   - the timepoint event code and reason code and the relative timepoint
+//     definition are removed, if the policy hides timepoints from the role
+//   - annotations whose code the policy lists for the role are removed,
+//     nested annotations included, in every series and derived series
+//
+// A nil policy means DefaultBlindingPolicy(). The receiver is not modified.
+func (h *HL7AEcg) ViewForRole(role RecipientRole, policy *BlindingPolicy) *HL7AEcg {
+	if policy == nil {
+		policy = DefaultBlindingPolicy()
+	}
+	out := h.Clone()
+	if h.ConfidentialityCode == nil || !policy.Blinds(h.ConfidentialityCode.Code, role) {
+		return out
+	}
+
+	hideTimepoint := slices.Contains(policy.Timepoints, role)
+	if hideTimepoint {
+		out.Definition = nil
+	}
+
+	if out.ComponentOf != nil {
+		te := &out.ComponentOf.TimepointEvent
+		if hideTimepoint {
+			te.Code = nil
+			te.ReasonCode = nil
+		}
+		if def := te.ComponentOf.SubjectAssignment.Definition; def != nil {
+			tga := &def.TreatmentGroupAssignment
+			*tga = TreatmentGroupAssignment{
+				Code: Code[TreatmentGroupCode, CodeSystemOID]{
+					Code:       TREATMENT_GROUP_BLINDED,
+					CodeSystem: tga.Code.CodeSystem,
+				},
+			}
+		}
+	}
+
+	if codes := policy.AnnotationCodes[role]; len(codes) > 0 {
+		for i := range out.Component {
+			removeAnnotations(&out.Component[i].Series, codes)
+		}
+	}
+
+	return out
+}
+
+// removeAnnotations removes the annotations with the given codes from the
+// series and its derived series.
+func removeAnnotations(s *Series, codes []string) {
+	for _, so := range s.SubjectOf {
+		if so.AnnotationSet != nil {
+			so.AnnotationSet.Component = annotationsWithout(so.AnnotationSet.Component, codes)
+		}
+	}
+	for i := range s.Derivation {
+		removeAnnotations(&s.Derivation[i].DerivedSeries, codes)
+	}
+}
+
+// annotationsWithout returns the components without the
+// annotations with the given codes, recursing into nested annotations.
+func annotationsWithout(components []AnnotationComponent, codes []string) []AnnotationComponent {
+	if components == nil {
+		return nil
+	}
+	out := make([]AnnotationComponent, 0, len(components))
+	for _, c := range components {
+		if c.Annotation.Code != nil && slices.Contains(codes, c.Annotation.Code.Code) {
+			continue
+		}
+		c.Annotation.Component = annotationsWithout(c.Annotation.Component, codes)
+		out = append(out, c)
+	}
+	return out
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newBlindedDoc builds a double-blind document with an arm, a timepoint and
+// a drug concentration annotation nested under a lead.
+func newBlindedDoc(code ConfidentialityCode) *HL7AEcg {
+	doc := &HL7AEcg{
+		ConfidentialityCode: NewCode[ConfidentialityCode, string](code, "", "", ""),
+		ComponentOf:         &ComponentOfTimepointEvent{},
+	}
+	te := &doc.ComponentOf.TimepointEvent
+	te.SetCode("VISIT_2", "2.16.840.1.113883.3.1").SetReasonCode(VISIT_TYPE_SCHEDULED)
+	te.ComponentOf.SubjectAssignment.SetTreatmentGroup("MOXI_400", "2.16.840.1.113883.3.1", "Moxifloxacin 400 mg")
+	doc.Definition = &AnnotatedECGDefinition{RelativeTimepoint: *NewRelativeTimepoint("POST_DOSE_2H", "2.16.840.1.113883.3.1")}
+
+	series := Series{}
+	as := series.AddAnnotationSet("20250923103600")
+	as.AddQTInterval(398)
+	as.AddAnnotation("DRUG_CONC", string(MDC_OID), 12, "ng/mL")
+	idx := as.AddLeadAnnotation("MDC_ECG_LEAD_V2", "MEASUREMENT_MATRIX", "", "HL7V3AECG")
+	as.GetAnnotation(idx).AddNestedAnnotation("DRUG_CONC", "", 11, "ng/mL")
+	derived := Series{}
+	derived.AddAnnotationSet("20250923103600").AddAnnotation("DRUG_CONC", string(MDC_OID), 12, "ng/mL")
+	series.Derivation = []Derivation{{DerivedSeries: derived}}
+	doc.Component = []Component{{Series: series}}
+	return doc
+}
+
+// TestBlindingPolicy_Blinds tests which roles each confidentiality code blinds
+func TestBlindingPolicy_Blinds(t *testing.T) {
+	p := DefaultBlindingPolicy()
+	p.Custom = []RecipientRole{ROLE_INVESTIGATOR, ROLE_UNBLINDED}
+
+	tests := []struct {
+		code ConfidentialityCode
+		role RecipientRole
+		want bool
+	}{
+		{CONFIDENTIALITY_SPONSOR_BLINDED, ROLE_SPONSOR, true},
+		{CONFIDENTIALITY_SPONSOR_BLINDED, ROLE_READER, true},
+		{CONFIDENTIALITY_SPONSOR_BLINDED, ROLE_INVESTIGATOR, false},
+		{CONFIDENTIALITY_INVESTIGATOR_BLINDED, ROLE_INVESTIGATOR, true},
+		{CONFIDENTIALITY_INVESTIGATOR_BLINDED, ROLE_SPONSOR, false},
+		{CONFIDENTIALITY_BOTH, ROLE_READER, true},
+		{CONFIDENTIALITY_BOTH, ROLE_UNBLINDED, false},
+		{CONFIDENTIALITY_CUSTOM, ROLE_INVESTIGATOR, true},
+		{CONFIDENTIALITY_CUSTOM, ROLE_SPONSOR, false},
+		{CONFIDENTIALITY_CUSTOM, ROLE_UNBLINDED, false},
+		{"", ROLE_SPONSOR, false},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, p.Blinds(tt.code, tt.role), "%s/%s", tt.code, tt.role)
+	}
+}
+
+// TestHL7AEcg_ViewForRole tests masking of the arm, timepoints and annotation codes
+func TestHL7AEcg_ViewForRole(t *testing.T) {
+	policy := DefaultBlindingPolicy()
+	policy.AnnotationCodes[ROLE_SPONSOR] = []string{"DRUG_CONC"}
+
+	t.Run("unblinded role gets a copy", func(t *testing.T) {
+		doc := newBlindedDoc(CONFIDENTIALITY_BOTH)
+		view := doc.ViewForRole(ROLE_UNBLINDED, policy)
+		require.NotSame(t, doc, view)
+		assert.True(t, view.Equal(doc))
+
+		view.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.SetTreatmentGroup("PLACEBO", "2.16.840.1.113883.3.1", "")
+		master := doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.GetTreatmentGroup()
+		assert.Equal(t, TreatmentGroupCode("MOXI_400"), master.Code)
+	})
+
+	t.Run("blinded arm loses its extensions", func(t *testing.T) {
+		doc := newBlindedDoc(CONFIDENTIALITY_BOTH)
+		tga := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.Definition.TreatmentGroupAssignment
+		tga.ExtraElements = []ExtraElement{{XMLName: xml.Name{Space: "urn:acme", Local: "armLabel"}, InnerXML: []byte("Moxifloxacin")}}
+		tga.ExtraAttrs = []xml.Attr{{Name: xml.Name{Space: "urn:acme", Local: "dose"}, Value: "400 mg"}}
+
+		view := doc.ViewForRole(ROLE_INVESTIGATOR, policy)
+		blinded := view.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.Definition.TreatmentGroupAssignment
+		assert.Equal(t, TREATMENT_GROUP_BLINDED, blinded.Code.Code)
+		assert.Empty(t, blinded.ExtraElements)
+		assert.Empty(t, blinded.ExtraAttrs)
+		assert.Len(t, tga.ExtraElements, 1, "master keeps its extensions")
+	})
+
+	t.Run("sponsor loses arm and annotation codes", func(t *testing.T) {
+		doc := newBlindedDoc(CONFIDENTIALITY_SPONSOR_BLINDED)
+		view := doc.ViewForRole(ROLE_SPONSOR, policy)
+		require.NotSame(t, doc, view)
+
+		sa := &view.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+		assert.True(t, sa.IsTreatmentGroupBlinded())
+		assert.Equal(t, CodeSystemOID("2.16.840.1.113883.3.1"), sa.GetTreatmentGroup().CodeSystem)
+		assert.Empty(t, sa.GetTreatmentGroup().DisplayName)
+		assert.NotNil(t, view.ComponentOf.TimepointEvent.Code, "timepoints are only hidden from readers")
+
+		as := view.Component[0].Series.SubjectOf[0].AnnotationSet
+		assert.Nil(t, as.GetAnnotationByCode("DRUG_CONC"))
+		assert.NotNil(t, as.GetAnnotationByCode(string(MDC_ECG_TIME_PD_QT)))
+		assert.Empty(t, as.GetLeadAnnotations("MDC_ECG_LEAD_V2").Component)
+		derived := view.Component[0].Series.Derivation[0].DerivedSeries.SubjectOf[0].AnnotationSet
+		assert.Nil(t, derived.GetAnnotationByCode("DRUG_CONC"))
+
+		// The master document is left untouched
+		master := doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.GetTreatmentGroup()
+		assert.Equal(t, TreatmentGroupCode("MOXI_400"), master.Code)
+		masterSet := doc.Component[0].Series.SubjectOf[0].AnnotationSet
+		assert.NotNil(t, masterSet.GetAnnotationByCode("DRUG_CONC"))
+		assert.Len(t, masterSet.GetLeadAnnotations("MDC_ECG_LEAD_V2").Component, 1)
+		assert.NotNil(t, doc.Component[0].Series.Derivation[0].DerivedSeries.SubjectOf[0].AnnotationSet.GetAnnotationByCode("DRUG_CONC"))
+	})
+
+	t.Run("reader loses timepoints", func(t *testing.T) {
+		doc := newBlindedDoc(CONFIDENTIALITY_BOTH)
+		view := doc.ViewForRole(ROLE_READER, nil)
+		assert.Nil(t, view.Definition)
+		assert.Nil(t, view.ComponentOf.TimepointEvent.Code)
+		assert.Nil(t, view.ComponentOf.TimepointEvent.ReasonCode)
+		assert.NotNil(t, doc.Definition)
+		assert.NotNil(t, doc.ComponentOf.TimepointEvent.Code)
+	})
+}
@@ -0,0 +1,251 @@
+package types
+
+import (
+	"encoding/xml"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// =============================================================================
+// Copy, Equality and Canonical Form
+// =============================================================================
+
+// Clone returns a deep copy of the document: no pointer, slice or typed
+// value is shared with the receiver, so either can be modified freely.
+func (h *HL7AEcg) Clone() *HL7AEcg {
+	if h == nil {
+		return nil
+	}
+	return deepCopy(reflect.ValueOf(h)).Interface().(*HL7AEcg)
+}
+
+// Equal reports whether two documents are semantically equal, i.e. equal
+// once both are canonicalized (see Canonicalize). Neither is modified.
+func (h *HL7AEcg) Equal(other *HL7AEcg) bool {
+	if h == nil || other == nil {
+		return h == other
+	}
+	return reflect.DeepEqual(h.Clone().Canonicalize(), other.Clone().Canonicalize())
+}
+
+// Canonicalize normalizes the document in place, so that documents with
+// the same content have the same representation:
+//   - empty optional elements are removed: pointers to empty structs and
+//     strings become nil, empty slices nil (pointers to numbers and
+//     booleans are kept, their zero value being meaningful)
+//   - numeric values of physical quantities and increments are formatted
+//     as by the setters ("400.0" and "4e2" become "400")
+//   - digits are separated by single spaces, and formatted from the values
+//     of sequences built with SetDigitValues
+//   - the raw XML kept by decoding is dropped from typed values, and the
+//     decoded element names (XMLName), namespace declarations, schema
+//     location and positions of unmodeled elements (ExtraElement.After),
+//     which are details of the serialization, are cleared
+//   - unmodeled attributes and elements (see Extensions) are kept as
+//     written
+//   - sequences are ordered: time sequences first, then the 12 standard
+//     leads in the usual order (see GetStandardLeads), then the other leads
+//     and the other sequences by code
+//
+// It returns the receiver.
+func (h *HL7AEcg) Canonicalize() *HL7AEcg {
+	if h == nil {
+		return nil
+	}
+	canonicalize(reflect.ValueOf(h).Elem())
+	return h
+}
+
+// deepCopy returns a copy of v sharing no memory with it.
+func deepCopy(v reflect.Value) reflect.Value {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(deepCopy(v.Elem()))
+		return out
+
+	case reflect.Interface:
+		out := reflect.New(v.Type()).Elem()
+		if !v.IsNil() {
+			out.Set(deepCopy(v.Elem()))
+		}
+		return out
+
+	case reflect.Struct:
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < v.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(deepCopy(v.Field(i)))
+			}
+		}
+		// Unexported fields are copied as is
+		if h, ok := out.Addr().Interface().(digitHolder); ok {
+			h.cache().detach()
+		}
+		return out
+
+	case reflect.Slice:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+
+	case reflect.Array:
+		out := reflect.New(v.Type()).Elem()
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(deepCopy(v.Index(i)))
+		}
+		return out
+
+	case reflect.Map:
+		if v.IsNil() {
+			return reflect.Zero(v.Type())
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(deepCopy(iter.Key()), deepCopy(iter.Value()))
+		}
+		return out
+	}
+	return v
+}
+
+var xmlNameType = reflect.TypeOf(xml.Name{})
+
+// canonicalize normalizes the addressable value v in place.
+func canonicalize(v reflect.Value) {
+	switch v.Kind() {
+	case reflect.Pointer:
+		if v.IsNil() {
+			return
+		}
+		canonicalize(v.Elem())
+		switch v.Elem().Kind() {
+		case reflect.Struct, reflect.String:
+			if v.Elem().IsZero() {
+				v.SetZero()
+			}
+		}
+
+	case reflect.Interface:
+		// Typed values are held by pointer, hence addressable
+		if !v.IsNil() && v.Elem().Kind() == reflect.Pointer && !v.Elem().IsNil() {
+			canonicalize(v.Elem().Elem())
+		}
+
+	case reflect.Slice:
+		if v.Len() == 0 {
+			v.SetZero()
+			return
+		}
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			canonicalize(v.Index(i))
+		}
+
+	case reflect.Struct:
+		switch v.Type() {
+		case xmlNameType:
+			v.SetZero()
+			return
+		case extraElementType:
+			// Unmodeled content is compared as written, <a/> being <a></a>
+			el := v.Addr().Interface().(*ExtraElement)
+			if len(el.InnerXML) == 0 {
+				el.InnerXML = nil
+			}
+			el.After, el.offset = nil, 0
+			return
+		case xmlAttrType:
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				canonicalize(v.Field(i))
+			}
+		}
+		canonicalizeStruct(v.Addr().Interface())
+	}
+}
+
+// canonicalizeStruct applies the type-specific rules of Canonicalize, after
+// the fields of the struct were canonicalized.
+func canonicalizeStruct(s any) {
+	switch s := s.(type) {
+	case *HL7AEcg:
+		s.Xmlns, s.XmlnsVoc, s.XmlnsXsi, s.SchemaLocation = "", "", "", ""
+	case *Extensions:
+		s.ExtraAttrs = slices.DeleteFunc(s.ExtraAttrs, func(attr xml.Attr) bool {
+			_, ok := declaredPrefix(attr.Name)
+			return ok
+		})
+		if len(s.ExtraAttrs) == 0 {
+			s.ExtraAttrs = nil
+		}
+	case *PhysicalQuantity:
+		s.Value = canonicalNumber(s.Value)
+	case *Increment:
+		s.Value = canonicalNumber(s.Value)
+	case *SLIST_PQ:
+		s.Digits = s.digits.canonical(s.Digits)
+	case *SLIST_INT:
+		s.Digits = s.digits.canonical(s.Digits)
+	case *SequenceValue:
+		if s.Typed != nil {
+			s.RawXML = nil
+		}
+	case *AnnotationValue:
+		if s.Typed != nil {
+			s.RawXML = nil
+		}
+	case *SequenceSet:
+		slices.SortStableFunc(s.Component, func(a, b SequenceComponent) int {
+			ra, ca := sequenceRank(&a.Sequence.Code)
+			rb, cb := sequenceRank(&b.Sequence.Code)
+			if ra != rb {
+				return ra - rb
+			}
+			return strings.Compare(ca, cb)
+		})
+	}
+}
+
+// canonicalNumber formats a number as formatFloat does, or returns s
+// unchanged if it is not a number.
+func canonicalNumber(s string) string {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil {
+		return s
+	}
+	return formatFloat(f)
+}
+
+// sequenceRank returns the sort rank of a sequence code, and the code to
+// order sequences of the same rank by: time sequences first (in document
+// order), standard leads in order, other leads by code, then other
+// sequences by code.
+func sequenceRank(code *SequenceCode) (int, string) {
+	switch {
+	case code.Lead != nil:
+		if i := slices.Index(GetStandardLeads(), code.Lead.Code); i >= 0 {
+			return i, ""
+		}
+		return len(GetStandardLeads()), string(code.Lead.Code)
+	case code.Other != nil:
+		return len(GetStandardLeads()) + 1, code.Other.Code
+	}
+	return -1, ""
+}
@@ -0,0 +1,128 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newCanonicalDoc returns a document with annotations and two leads, I and
+// II, in the given order.
+func newCanonicalDoc(order ...LeadCode) *HL7AEcg {
+	doc := newBlindedDoc(CONFIDENTIALITY_SPONSOR_BLINDED)
+	doc.ID = &ID{Root: "2.16.840.1.113883.3.1", Extension: "ECG_1"}
+	waveforms := newWaveformSeries(
+		&SequenceValue{XsiType: "GLIST_TS", Typed: &GLIST_TS{Increment: Increment{Value: "0.002", Unit: "s"}}},
+		map[LeadCode]*SequenceValue{
+			MDC_ECG_LEAD_I:  {XsiType: "SLIST_PQ", Typed: &SLIST_PQ{Origin: PhysicalQuantity{Value: "0", Unit: "uV"}, Scale: PhysicalQuantity{Value: "5", Unit: "uV"}, Digits: "1 2 3"}},
+			MDC_ECG_LEAD_II: {XsiType: "SLIST_PQ", Typed: &SLIST_PQ{Origin: PhysicalQuantity{Value: "0", Unit: "uV"}, Scale: PhysicalQuantity{Value: "5", Unit: "uV"}, Digits: "4 5 6"}},
+		},
+		order...,
+	)
+	doc.Component[0].Series.Component = waveforms.Component
+	return doc
+}
+
+// TestHL7AEcg_Clone tests that a clone shares no memory with the original
+func TestHL7AEcg_Clone(t *testing.T) {
+	doc := newCanonicalDoc(MDC_ECG_LEAD_I, MDC_ECG_LEAD_II)
+	clone := doc.Clone()
+	require.True(t, doc.Equal(clone))
+	assert.Nil(t, (*HL7AEcg)(nil).Clone())
+
+	clone.ID.Extension = "ECG_2"
+	clone.ConfidentialityCode.Code = CONFIDENTIALITY_BOTH
+	as := clone.Component[0].Series.GetAnnotationSets()[0]
+	as.Component[0].Annotation.Value.Typed.(*PhysicalQuantity).Value = "420"
+	seq := &clone.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence
+	seq.Value.Typed.(*SLIST_PQ).Digits = "0 0 0"
+	clone.Component[0].Series.Derivation[0].DerivedSeries.SubjectOf = nil
+
+	assert.Equal(t, "ECG_1", doc.ID.Extension)
+	assert.Equal(t, CONFIDENTIALITY_SPONSOR_BLINDED, doc.ConfidentialityCode.Code)
+	qt := doc.Component[0].Series.GetAnnotationSets()[0].Component[0].Annotation.Value
+	assert.Equal(t, "398", qt.Typed.(*PhysicalQuantity).Value)
+	values, err := doc.Component[0].Series.GetLeadValues(MDC_ECG_LEAD_I)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5, 10, 15}, values)
+	assert.NotNil(t, doc.Component[0].Series.Derivation[0].DerivedSeries.SubjectOf)
+	assert.False(t, doc.Equal(clone))
+}
+
+// TestHL7AEcg_Equal tests the differences ignored by semantic equality
+func TestHL7AEcg_Equal(t *testing.T) {
+	tests := []struct {
+		name   string
+		modify func(doc *HL7AEcg)
+		equal  bool
+	}{
+		{"empty code vs absent", func(doc *HL7AEcg) { doc.ReasonCode = &Code[ReasonCode, string]{} }, true},
+		{"empty string vs absent", func(doc *HL7AEcg) { doc.Component[0].Series.Author = &Author{} }, true},
+		{"empty slice vs absent", func(doc *HL7AEcg) { doc.Component[0].Series.ControlVariable = []ControlVariable{} }, true},
+		{"number formatting", func(doc *HL7AEcg) {
+			qt := doc.Component[0].Series.GetAnnotationSets()[0].Component[0].Annotation.Value
+			qt.Typed.(*PhysicalQuantity).Value = "398.0"
+		}, true},
+		{"digit spacing", func(doc *HL7AEcg) {
+			seq := &doc.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence
+			seq.Value.Typed.(*SLIST_PQ).Digits = " 1  2\n3 "
+		}, true},
+		{"raw XML", func(doc *HL7AEcg) {
+			doc.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence.Value.RawXML = []byte("<digits>1 2 3</digits>")
+		}, true},
+		{"namespace declarations", func(doc *HL7AEcg) { doc.XmlnsVoc, doc.SchemaLocation = "", "" }, true},
+		{"lead order", func(doc *HL7AEcg) {
+			c := doc.Component[0].Series.Component[0].SequenceSet.Component
+			c[1], c[2] = c[2], c[1]
+		}, true},
+		{"value", func(doc *HL7AEcg) {
+			qt := doc.Component[0].Series.GetAnnotationSets()[0].Component[0].Annotation.Value
+			qt.Typed.(*PhysicalQuantity).Value = "399"
+		}, false},
+		{"digits", func(doc *HL7AEcg) {
+			seq := &doc.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence
+			seq.Value.Typed.(*SLIST_PQ).Digits = "1 2 4"
+		}, false},
+		{"inclusive false vs absent", func(doc *HL7AEcg) {
+			inclusive := false
+			doc.Component[0].Series.EffectiveTime.Low.Inclusive = &inclusive
+		}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			doc := newCanonicalDoc(MDC_ECG_LEAD_I, MDC_ECG_LEAD_II)
+			other := doc.Clone()
+			tt.modify(other)
+			assert.Equal(t, tt.equal, doc.Equal(other))
+			assert.Equal(t, tt.equal, other.Equal(doc))
+		})
+	}
+
+	assert.True(t, (*HL7AEcg)(nil).Equal(nil))
+	assert.False(t, newCanonicalDoc().Equal(nil))
+}
+
+// TestHL7AEcg_Canonicalize tests the canonical form
+func TestHL7AEcg_Canonicalize(t *testing.T) {
+	doc := newCanonicalDoc(MDC_ECG_LEAD_II, "MDC_ECG_LEAD_ES", MDC_ECG_LEAD_I)
+	doc.ReasonCode = &Code[ReasonCode, string]{}
+	inc := &doc.Component[0].Series.Component[0].SequenceSet.Component[0].Sequence.Value.Typed.(*GLIST_TS).Increment
+	inc.Value = "2.000e-3"
+
+	require.Same(t, doc, doc.Canonicalize())
+	assert.Nil(t, doc.ReasonCode)
+	assert.Equal(t, "0.002", inc.Value)
+
+	var codes []string
+	for _, c := range doc.Component[0].Series.Component[0].SequenceSet.Component {
+		if c.Sequence.Code.Lead != nil {
+			codes = append(codes, string(c.Sequence.Code.Lead.Code))
+		} else {
+			codes = append(codes, "time")
+		}
+	}
+	assert.Equal(t, []string{"time", "MDC_ECG_LEAD_I", "MDC_ECG_LEAD_II", "MDC_ECG_LEAD_ES"}, codes)
+
+	assert.Nil(t, (*HL7AEcg)(nil).Canonicalize())
+}
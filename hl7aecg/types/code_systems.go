@@ -79,6 +79,28 @@ const (
 	MDC_ECG_LEAD_V4 LeadCode = "MDC_ECG_LEAD_V4" // Lead V4
 	MDC_ECG_LEAD_V5 LeadCode = "MDC_ECG_LEAD_V5" // Lead V5
 	MDC_ECG_LEAD_V6 LeadCode = "MDC_ECG_LEAD_V6" // Lead V6
+
+	// Extended Precordial Leads (15-lead and right-sided ECG)
+	MDC_ECG_LEAD_V7  LeadCode = "MDC_ECG_LEAD_V7"  // Lead V7 (posterior axillary line)
+	MDC_ECG_LEAD_V8  LeadCode = "MDC_ECG_LEAD_V8"  // Lead V8 (posterior scapular line)
+	MDC_ECG_LEAD_V9  LeadCode = "MDC_ECG_LEAD_V9"  // Lead V9 (paraspinal)
+	MDC_ECG_LEAD_V3R LeadCode = "MDC_ECG_LEAD_V3R" // Lead V3R (right-sided)
+	MDC_ECG_LEAD_V4R LeadCode = "MDC_ECG_LEAD_V4R" // Lead V4R (right-sided)
+
+	// Frank Orthogonal Leads (vectorcardiography)
+	MDC_ECG_LEAD_X LeadCode = "MDC_ECG_LEAD_X" // Frank lead X
+	MDC_ECG_LEAD_Y LeadCode = "MDC_ECG_LEAD_Y" // Frank lead Y
+	MDC_ECG_LEAD_Z LeadCode = "MDC_ECG_LEAD_Z" // Frank lead Z
+
+	// EASI Leads
+	MDC_ECG_LEAD_ES LeadCode = "MDC_ECG_LEAD_ES" // EASI lead E-S
+	MDC_ECG_LEAD_AS LeadCode = "MDC_ECG_LEAD_AS" // EASI lead A-S
+	MDC_ECG_LEAD_AI LeadCode = "MDC_ECG_LEAD_AI" // EASI lead A-I
+
+	// Holter Bipolar Leads
+	MDC_ECG_LEAD_CC5  LeadCode = "MDC_ECG_LEAD_CC5"  // Holter lead CC5
+	MDC_ECG_LEAD_CM5  LeadCode = "MDC_ECG_LEAD_CM5"  // Holter lead CM5
+	MDC_ECG_LEAD_MLII LeadCode = "MDC_ECG_LEAD_MLII" // Modified limb lead II
 )
 
 // =============================================================================
@@ -94,10 +116,24 @@ type AnnotationCode string
 
 const (
 	// Waveform Components
+	MDC_ECG_WAVC         WaveformAnnotationCode = "MDC_ECG_WAVC"         // Wave component (annotation code for wave annotations)
 	MDC_ECG_WAVC_PWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_PWAVE"   // P wave
 	MDC_ECG_WAVC_QRSWAVE WaveformAnnotationCode = "MDC_ECG_WAVC_QRSWAVE" // QRS complex
 	MDC_ECG_WAVC_TWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_TWAVE"   // T wave
 	MDC_ECG_WAVC_UWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_UWAVE"   // U wave
+	MDC_ECG_WAVC_PPWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_PPWAVE"  // P' wave (second deflection of P)
+	MDC_ECG_WAVC_QWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_QWAVE"   // Q wave
+	MDC_ECG_WAVC_QSWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_QSWAVE"  // QS wave
+	MDC_ECG_WAVC_RWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_RWAVE"   // R wave
+	MDC_ECG_WAVC_RRWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_RRWAVE"  // R' wave
+	MDC_ECG_WAVC_SWAVE   WaveformAnnotationCode = "MDC_ECG_WAVC_SWAVE"   // S wave
+	MDC_ECG_WAVC_SSWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_SSWAVE"  // S' wave
+	MDC_ECG_WAVC_TTWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_TTWAVE"  // T' wave (second deflection of T)
+	MDC_ECG_WAVC_DELTA   WaveformAnnotationCode = "MDC_ECG_WAVC_DELTA"   // Delta wave (pre-excitation)
+	MDC_ECG_WAVC_JPOINT  WaveformAnnotationCode = "MDC_ECG_WAVC_JPOINT"  // J point
+	MDC_ECG_WAVC_STJ     WaveformAnnotationCode = "MDC_ECG_WAVC_STJ"     // ST-J point
+	MDC_ECG_WAVC_STWAVE  WaveformAnnotationCode = "MDC_ECG_WAVC_STWAVE"  // ST segment
+	MDC_ECG_WAVC_PACE    WaveformAnnotationCode = "MDC_ECG_WAVC_PACE"    // Pacemaker spike
 
 	// Time Intervals (MDC codes used in annotationSet)
 	MDC_ECG_TIME_PD_QT            IntervalCode = "MDC_ECG_TIME_PD_QT"  // QT interval (ms)
@@ -124,6 +160,43 @@ const (
 	MDC_ECG_ANGLE_ST_FRONT  AnnotationCode = "MDC_ECG_ANGLE_ST_FRONT"
 )
 
+// =============================================================================
+// LOINC Observation Codes (Related Observations)
+// =============================================================================
+
+type ObservationCode string
+
+const (
+	// Demographic observations
+	OBS_AGE    ObservationCode = "21612-7" // Age
+	OBS_HEIGHT ObservationCode = "8302-2"  // Body height
+	OBS_WEIGHT ObservationCode = "29463-7" // Body weight
+
+	// Clinical observations
+	OBS_HEART_RATE   ObservationCode = "8867-4" // Heart rate
+	OBS_BP_SYSTOLIC  ObservationCode = "8480-6" // Systolic blood pressure
+	OBS_BP_DIASTOLIC ObservationCode = "8462-4" // Diastolic blood pressure
+
+	// Narrative observations
+	OBS_MEDICATION ObservationCode = "10160-0" // History of medication use
+	OBS_PROBLEM    ObservationCode = "11450-4" // Problem list
+)
+
+// GetObservationDisplayName returns the LOINC display name of an observation code.
+func GetObservationDisplayName(code ObservationCode) string {
+	names := map[ObservationCode]string{
+		OBS_AGE:          "Age",
+		OBS_HEIGHT:       "Body height",
+		OBS_WEIGHT:       "Body weight",
+		OBS_HEART_RATE:   "Heart rate",
+		OBS_BP_SYSTOLIC:  "Systolic blood pressure",
+		OBS_BP_DIASTOLIC: "Diastolic blood pressure",
+		OBS_MEDICATION:   "History of medication use",
+		OBS_PROBLEM:      "Problem list",
+	}
+	return names[code]
+}
+
 // =============================================================================
 // HL7 AdministrativeGender Codes
 // =============================================================================
@@ -181,6 +254,17 @@ const (
 	PERFORMER_ECG_TECHNICIAN PerformerFunctionCode = "ELECTROCARDIOGRAPH_TECH"
 )
 
+// Annotation Author Functions (suggested, no formal vocabulary)
+
+type AuthorFunctionCode string
+
+const (
+	AUTHOR_FUNCTION_ALGORITHM        AuthorFunctionCode = "ALGORITHM"        // Automated machine read
+	AUTHOR_FUNCTION_PRIMARY_READER   AuthorFunctionCode = "PRIMARY_READER"   // First human read
+	AUTHOR_FUNCTION_SECONDARY_READER AuthorFunctionCode = "SECONDARY_READER" // Second human read
+	AUTHOR_FUNCTION_ADJUDICATOR      AuthorFunctionCode = "ADJUDICATOR"      // Adjudication of discordant reads
+)
+
 type RegionOfInterestType string
 
 const (
@@ -226,6 +310,8 @@ const (
 	REASON_WRONG_EVENT     ReasonCode = "IN_PROTOCOL_WRONG_EVENT" // Wrong timepoint
 )
 
+// Treatment group codes are sponsor-defined; any code may be used with the
+// protocol or trial OID as code system. GRP_001..GRP_004 are placeholders.
 type TreatmentGroupCode string
 
 const (
@@ -233,8 +319,23 @@ const (
 	GRP_002 TreatmentGroupCode = "GRP_002" // Treatment Group 2
 	GRP_003 TreatmentGroupCode = "GRP_003" // Treatment Group 3
 	GRP_004 TreatmentGroupCode = "GRP_004" // Treatment Group 4
+
+	// TREATMENT_GROUP_BLINDED masks the actual arm in blinded documents
+	TREATMENT_GROUP_BLINDED TreatmentGroupCode = "BLINDED"
 )
 
+// IsBlinding reports whether a confidentiality code blinds the document to
+// the sponsor, the investigator or both (S, I, B). Custom blinding (C) and
+// unknown codes are not considered blinding.
+func IsBlinding(code ConfidentialityCode) bool {
+	switch code {
+	case CONFIDENTIALITY_SPONSOR_BLINDED, CONFIDENTIALITY_INVESTIGATOR_BLINDED, CONFIDENTIALITY_BOTH:
+		return true
+	default:
+		return false
+	}
+}
+
 // =============================================================================
 // UCUM Units
 // =============================================================================
@@ -245,6 +346,8 @@ const (
 	UNIT_MILLISECOND = "ms"  // Millisecond
 	UNIT_MINUTE      = "min" // Minute
 	UNIT_HOUR        = "h"   // Hour
+	UNIT_DAY         = "d"   // Day
+	UNIT_WEEK        = "wk"  // Week
 
 	// Voltage Units
 	UNIT_MICROVOLT = "uV" // Microvolt (most common for ECG)
@@ -256,12 +359,69 @@ const (
 
 	// Heart Rate
 	UNIT_BPM = "bpm" // Beats per minute
+
+	// Related Observations
+	UNIT_YEAR       = "a"      // Year (age)
+	UNIT_CENTIMETER = "cm"     // Centimeter (height)
+	UNIT_KILOGRAM   = "kg"     // Kilogram (weight)
+	UNIT_PER_MINUTE = "/min"   // Per minute (heart rate)
+	UNIT_MMHG       = "mm[Hg]" // Millimeter of mercury (blood pressure)
 )
 
 // =============================================================================
 // Helper Functions
 // =============================================================================
 
+// ucumTimeUnits maps the UCUM time units to their length in seconds. It is
+// the table of every time quantity: durations (e.g. a relative timepoint
+// pause quantity) and sampling increments alike.
+var ucumTimeUnits = map[string]float64{
+	"us":             1e-6,
+	UNIT_MILLISECOND: 1e-3,
+	UNIT_SECOND:      1,
+	UNIT_MINUTE:      60,
+	UNIT_HOUR:        3600,
+	UNIT_DAY:         86400,
+	UNIT_WEEK:        604800,
+}
+
+// IsUCUMTimeUnit checks if a unit is a UCUM time unit (us, ms, s, min, h, d, wk).
+//
+// UCUM units are case-sensitive: "S" or "H" are not time units.
+func IsUCUMTimeUnit(unit string) bool {
+	_, ok := ucumTimeUnits[unit]
+	return ok
+}
+
+// ucumVoltageUnits maps the UCUM voltage units accepted for lead sequences
+// to their value in microvolts.
+var ucumVoltageUnits = map[string]float64{
+	UNIT_MICROVOLT: 1,
+	UNIT_MILLIVOLT: 1e3,
+	UNIT_VOLT:      1e6,
+}
+
+// IsUCUMVoltageUnit checks if a unit is a UCUM voltage unit accepted for
+// lead sequences (uV, mV, V).
+func IsUCUMVoltageUnit(unit string) bool {
+	_, ok := ucumVoltageUnits[unit]
+	return ok
+}
+
+// UCUMVoltageUnitMicrovolts returns the value of a UCUM voltage unit in
+// microvolts. Returns (0, false) if the unit is not a voltage unit.
+func UCUMVoltageUnitMicrovolts(unit string) (float64, bool) {
+	microvolts, ok := ucumVoltageUnits[unit]
+	return microvolts, ok
+}
+
+// UCUMTimeUnitSeconds returns the length of a UCUM time unit in seconds.
+// Returns (0, false) if the unit is not a time unit.
+func UCUMTimeUnitSeconds(unit string) (float64, bool) {
+	seconds, ok := ucumTimeUnits[unit]
+	return seconds, ok
+}
+
 // IsStandardLead checks if a lead code is part of standard 12-lead ECG.
 func IsStandardLead(leadCode LeadCode) bool {
 	standardLeads := []LeadCode{
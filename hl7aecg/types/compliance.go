@@ -0,0 +1,181 @@
+package types
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// =============================================================================
+// Compliance Profiles
+// =============================================================================
+
+// ComplianceProfile controls how vendor extensions found on
+// SubjectDemographicPerson (PatientID, Age, Paced, Medications, Bed, ...)
+// are written.
+//
+// These elements are not part of the aECG schema. They are always read so
+// that legacy files still load, but a strict encoder must not write them.
+type ComplianceProfile string
+
+const (
+	// COMPLIANCE_STRICT maps vendor extensions to standard constructs and
+	// drops the ones that have no standard equivalent, so that the output
+	// passes strict schema validation. This is the default.
+	COMPLIANCE_STRICT ComplianceProfile = "strict"
+
+	// COMPLIANCE_LEGACY writes vendor extensions as-is, for consumers that
+	// still expect them.
+	COMPLIANCE_LEGACY ComplianceProfile = "legacy"
+)
+
+// Control variable written on every series for a paced subject under
+// COMPLIANCE_STRICT: the SNOMED CT finding "Cardiac pacemaker in situ".
+const (
+	PacedControlVariableCode           = "441509002"
+	PacedControlVariableCodeSystem     = SNOMED_CT_OID
+	PacedControlVariableCodeSystemName = "SNOMED CT"
+)
+
+// HasVendorExtensions reports whether any vendor extension is set.
+func (s *SubjectDemographicPerson) HasVendorExtensions() bool {
+	if s == nil {
+		return false
+	}
+	return s.PatientID != "" || s.SecondPatientID != "" || s.Age != "" || s.Paced ||
+		s.Medications != nil || s.ClinicalClassifications != nil ||
+		s.Bed != "" || s.Room != "" || s.PointOfCare != ""
+}
+
+// Compliant returns the document as it should be encoded under the given
+// profile, and warnings for the vendor values lost in the mapping.
+//
+// Under COMPLIANCE_LEGACY (or when no vendor extension is set) the receiver is
+// returned unchanged. Under COMPLIANCE_STRICT a copy is returned in which:
+//   - PatientID becomes the trialSubject id extension when that is empty
+//   - a numeric Age becomes an OBS_AGE related observation (unit "a"),
+//     unless one is already present; a non-numeric Age is dropped
+//   - each Medication becomes an OBS_MEDICATION text observation
+//   - each ClinicalClassification becomes an OBS_PROBLEM text observation
+//   - Paced becomes a "Cardiac pacemaker in situ" (SNOMED CT 441509002)
+//     control variable on every series
+//   - SecondPatientID, Bed, Room and PointOfCare are dropped
+//
+// When the subject is carried by the direct subject element rather than a
+// subject assignment, only the PatientID mapping applies and the other
+// extensions are dropped.
+//
+// The warnings report the dropped Age, Paced, Medications and
+// ClinicalClassifications values, which carry clinical information; the
+// other dropped elements are administrative and are not reported.
+//
+// The receiver is not modified, but the copy shares the structures that
+// are not mapped with it, and the receiver itself is returned when nothing
+// is mapped: use Clone before modifying the result.
+func (h *HL7AEcg) Compliant(profile ComplianceProfile) (*HL7AEcg, []string) {
+	if profile == COMPLIANCE_LEGACY {
+		return h, nil
+	}
+	ts := h.GetTrialSubject()
+	if ts == nil || !ts.SubjectDemographicPerson.HasVendorExtensions() {
+		return h, nil
+	}
+	demo := ts.SubjectDemographicPerson
+	var warnings []string
+
+	out := *h
+	if h.ComponentOf == nil {
+		// Direct subject layout: there is no subject assignment to carry
+		// related observations, so only the id mapping applies.
+		trial := *h.Subject
+		out.Subject = &trial
+		trial.SubjectDemographicPerson = demo.withoutVendorExtensions()
+		trial.ID = mapPatientID(trial.ID, demo.PatientID)
+		return &out, demo.unmappedWarnings()
+	}
+
+	co := *h.ComponentOf
+	out.ComponentOf = &co
+	sa := &co.TimepointEvent.ComponentOf.SubjectAssignment
+	sa.SubjectOf = append([]SubjectAssignmentSubjectOf(nil), sa.SubjectOf...)
+	trial := &sa.Subject.TrialSubject
+	trial.SubjectDemographicPerson = demo.withoutVendorExtensions()
+	trial.ID = mapPatientID(trial.ID, demo.PatientID)
+
+	if demo.Age != "" {
+		if age, err := strconv.ParseFloat(demo.Age, 64); err != nil {
+			warnings = append(warnings, fmt.Sprintf("compliance: Age %q is not numeric and is dropped", demo.Age))
+		} else if sa.GetRelatedObservationByCode(OBS_AGE) == nil {
+			sa.AddRelatedObservation(OBS_AGE, "", age, UNIT_YEAR)
+		}
+	}
+
+	if demo.Medications != nil {
+		for _, m := range demo.Medications.Medication {
+			sa.AddTextObservation(OBS_MEDICATION, "", m)
+		}
+	}
+	if demo.ClinicalClassifications != nil {
+		for _, c := range demo.ClinicalClassifications.ClinicalClassification {
+			sa.AddTextObservation(OBS_PROBLEM, "", c)
+		}
+	}
+
+	if demo.Paced {
+		out.Component = append([]Component(nil), h.Component...)
+		for i := range out.Component {
+			series := &out.Component[i].Series
+			cv := NewControlVariable(PacedControlVariableCode, PacedControlVariableCodeSystem,
+				PacedControlVariableCodeSystemName, "Cardiac pacemaker in situ")
+			cv.ControlVariable.SetText("true")
+			series.ControlVariable = append(append([]ControlVariable(nil), series.ControlVariable...), *cv)
+		}
+	}
+
+	return &out, warnings
+}
+
+// unmappedWarnings returns the warnings for the clinical vendor extensions
+// dropped in the direct subject layout.
+func (s *SubjectDemographicPerson) unmappedWarnings() []string {
+	var warnings []string
+	drop := func(name string) {
+		warnings = append(warnings, fmt.Sprintf("compliance: %s has no place in the direct subject layout and is dropped", name))
+	}
+	if s.Age != "" {
+		drop("Age")
+	}
+	if s.Paced {
+		drop("Paced")
+	}
+	if s.Medications != nil && len(s.Medications.Medication) > 0 {
+		drop("Medications")
+	}
+	if s.ClinicalClassifications != nil && len(s.ClinicalClassifications.ClinicalClassification) > 0 {
+		drop("ClinicalClassifications")
+	}
+	return warnings
+}
+
+// withoutVendorExtensions returns a copy holding only the standard demographics.
+func (s *SubjectDemographicPerson) withoutVendorExtensions() *SubjectDemographicPerson {
+	return &SubjectDemographicPerson{
+		Name:                     s.Name,
+		AdministrativeGenderCode: s.AdministrativeGenderCode,
+		BirthTime:                s.BirthTime,
+		RaceCode:                 s.RaceCode,
+	}
+}
+
+// mapPatientID returns the subject id with the patient ID as extension when
+// the id has none; otherwise the id is returned unchanged.
+func mapPatientID(id *ID, patientID string) *ID {
+	if patientID == "" || (id != nil && id.Extension != "") {
+		return id
+	}
+	mapped := ID{}
+	if id != nil {
+		mapped = *id
+	}
+	mapped.Extension = patientID
+	return &mapped
+}
@@ -0,0 +1,80 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHL7AEcg_Compliant tests vendor extension handling for each profile and subject layout
+func TestHL7AEcg_Compliant(t *testing.T) {
+	t.Run("no vendor extensions returns the receiver", func(t *testing.T) {
+		doc := &HL7AEcg{Subject: &TrialSubject{SubjectDemographicPerson: &SubjectDemographicPerson{}}}
+		out, warnings := doc.Compliant(COMPLIANCE_STRICT)
+		assert.Same(t, doc, out)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("legacy returns the receiver", func(t *testing.T) {
+		doc := &HL7AEcg{Subject: &TrialSubject{SubjectDemographicPerson: &SubjectDemographicPerson{PatientID: "P1"}}}
+		out, warnings := doc.Compliant(COMPLIANCE_LEGACY)
+		assert.Same(t, doc, out)
+		assert.Empty(t, warnings)
+	})
+
+	t.Run("direct subject keeps an existing extension", func(t *testing.T) {
+		demo := (&SubjectDemographicPerson{}).SetName("JDO").SetPatientID("P1").SetAge("64").SetBed("12A")
+		doc := &HL7AEcg{Subject: &TrialSubject{
+			ID:                       &ID{Root: "1.2.3", Extension: "SUBJ_1"},
+			SubjectDemographicPerson: demo,
+		}}
+
+		out, warnings := doc.Compliant(COMPLIANCE_STRICT)
+		require.NotSame(t, doc, out)
+		assert.Equal(t, []string{"compliance: Age has no place in the direct subject layout and is dropped"}, warnings)
+		assert.Equal(t, "SUBJ_1", out.Subject.ID.Extension)
+		assert.False(t, out.Subject.SubjectDemographicPerson.HasVendorExtensions())
+		assert.Equal(t, "JDO", *out.Subject.SubjectDemographicPerson.Name)
+		assert.Equal(t, "P1", doc.Subject.SubjectDemographicPerson.PatientID)
+	})
+
+	t.Run("direct subject maps PatientID to an empty extension", func(t *testing.T) {
+		doc := &HL7AEcg{Subject: &TrialSubject{
+			ID:                       &ID{Root: "1.2.3"},
+			SubjectDemographicPerson: &SubjectDemographicPerson{PatientID: "P1"},
+		}}
+
+		out, warnings := doc.Compliant(COMPLIANCE_STRICT)
+		assert.Empty(t, warnings)
+		assert.Equal(t, "1.2.3", out.Subject.ID.Root)
+		assert.Equal(t, "P1", out.Subject.ID.Extension)
+		assert.Empty(t, doc.Subject.ID.Extension)
+	})
+
+	t.Run("non-numeric Age is reported", func(t *testing.T) {
+		doc := &HL7AEcg{ComponentOf: &ComponentOfTimepointEvent{}}
+		sa := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+		sa.Subject.TrialSubject.SubjectDemographicPerson = (&SubjectDemographicPerson{}).SetAge("64 years")
+
+		out, warnings := doc.Compliant(COMPLIANCE_STRICT)
+		assert.Equal(t, []string{`compliance: Age "64 years" is not numeric and is dropped`}, warnings)
+		outSA := &out.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+		assert.Nil(t, outSA.GetRelatedObservationByCode(OBS_AGE))
+		assert.False(t, outSA.Subject.TrialSubject.SubjectDemographicPerson.HasVendorExtensions())
+	})
+}
+
+// TestSubjectDemographicPerson_MedicationsNoPlaceholder tests that list setters no longer add empty entries
+func TestSubjectDemographicPerson_MedicationsNoPlaceholder(t *testing.T) {
+	demo := &SubjectDemographicPerson{}
+	assert.False(t, demo.HasVendorExtensions())
+
+	demo.AddMedication("Aspirin")
+	require.NotNil(t, demo.Medications)
+	assert.Equal(t, []string{"Aspirin"}, demo.Medications.Medication)
+
+	demo.SetClinicalClassifications()
+	assert.Empty(t, demo.ClinicalClassifications.ClinicalClassification)
+	assert.True(t, demo.HasVendorExtensions())
+}
@@ -171,6 +171,8 @@ func ParseHL7DateTime(s string) (time.Time, error) {
 // NormalizeLeadCode normalizes lead names to MDC codes
 //
 // Accepts various lead name formats and returns the appropriate MDC_ECG_LEAD_* code.
+// Besides the 12 standard leads, any lead of the MDC nomenclature is recognized
+// by its short name (e.g., "V4R", "X", "ES", "CM5") or its reference ID in any case.
 func NormalizeLeadCode(leadName string) LeadCode {
 	normalized := strings.ToUpper(strings.TrimSpace(leadName))
 
@@ -200,6 +202,12 @@ func NormalizeLeadCode(leadName string) LeadCode {
 	case "V6", "LEAD_V6":
 		return MDC_ECG_LEAD_V6
 	default:
+		name := strings.TrimPrefix(normalized, "LEAD_")
+		for _, refID := range []string{normalized, "MDC_ECG_LEAD_" + name} {
+			if term, ok := LookupMDC(refID); ok && term.Category == MDC_CATEGORY_LEAD {
+				return LeadCode(term.RefID)
+			}
+		}
 		return LeadCode(normalized) // Return as-is if not recognized
 	}
 }
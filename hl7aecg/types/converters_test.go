@@ -395,6 +395,14 @@ func TestNormalizeLeadCode(t *testing.T) {
 		{name: "Lead I with spaces", leadName: "  I  ", want: MDC_ECG_LEAD_I},
 		{name: "aVR with spaces", leadName: "  aVR  ", want: MDC_ECG_LEAD_AVR},
 
+		// Extended leads from the MDC nomenclature
+		{name: "Right-sided V4R", leadName: "V4R", want: MDC_ECG_LEAD_V4R},
+		{name: "Frank X", leadName: "x", want: MDC_ECG_LEAD_X},
+		{name: "EASI ES", leadName: "ES", want: MDC_ECG_LEAD_ES},
+		{name: "Holter CM5 with prefix", leadName: "LEAD_CM5", want: MDC_ECG_LEAD_CM5},
+		{name: "Modified limb MLII", leadName: "MLII", want: MDC_ECG_LEAD_MLII},
+		{name: "Reference ID in lowercase", leadName: "mdc_ecg_lead_avrneg", want: LeadCode("MDC_ECG_LEAD_AVRneg")},
+
 		// Unrecognized (returns as-is)
 		{name: "Unrecognized lead", leadName: "CUSTOM_LEAD", want: LeadCode("CUSTOM_LEAD")},
 		{name: "Empty string", leadName: "", want: LeadCode("")},
@@ -0,0 +1,218 @@
+package types
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// Sequence Digits
+// =============================================================================
+
+// digitCache holds the digits of a scaled sequence (SLIST_PQ, SLIST_INT) as
+// integers, so that the Digits text is parsed once.
+//
+// Decoding records the text, parsed on first access; SetDigitValues records
+// values, which have no text: Digits is left empty and the values are
+// formatted when the sequence is encoded. Reading never writes to the
+// sequence, so a sequence can be read from concurrent goroutines. A Digits
+// replaced by assignment is parsed on every access.
+type digitCache struct {
+	// d is the digits of a text, nil if none was recorded.
+	d *digitData
+}
+
+// digitData holds the digits of one text. Only values and err are set once
+// shared, by a single parse guarded by once.
+type digitData struct {
+	// text is the Digits the values are parsed from, "" for values set with
+	// SetDigitValues.
+	text string
+
+	once sync.Once
+
+	// values are the digits, shared with the callers of GetDigitValues.
+	values []int32
+
+	// err is the error parsing text.
+	err error
+}
+
+// load returns the digits, parsing the text on first call.
+func (d *digitData) load() ([]int32, error) {
+	d.once.Do(func() {
+		d.values, d.err = parseDigits(d.text)
+	})
+	return d.values, d.err
+}
+
+// digitHolder is implemented by the sequences holding a digitCache.
+type digitHolder interface {
+	cache() *digitCache
+}
+
+// get returns the digits of text, from the cache if it holds them.
+func (c *digitCache) get(text string) ([]int32, error) {
+	// Unless Digits was replaced, the texts compared share their bytes and
+	// the comparison is a pointer comparison
+	if c.d != nil && c.d.text == text {
+		return c.d.load()
+	}
+	return parseDigits(text)
+}
+
+// setText records text, to be parsed on first access.
+func (c *digitCache) setText(text string) {
+	c.d = &digitData{text: text}
+}
+
+// set replaces the digits by values, which have no text.
+func (c *digitCache) set(values []int32) {
+	d := &digitData{}
+	d.once.Do(func() { d.values = values })
+	c.d = d
+}
+
+// length returns the number of digits of text.
+func (c *digitCache) length(text string) int {
+	if values, err := c.get(text); err == nil {
+		return len(values)
+	}
+	return countDigits(text)
+}
+
+// canonical returns the digits of text separated by single spaces, and
+// drops the cache so that canonical sequences compare equal.
+func (c *digitCache) canonical(text string) string {
+	values, err := c.get(text)
+	c.d = nil
+	if err != nil {
+		return strings.Join(strings.Fields(text), " ")
+	}
+	return string(appendDigits(nil, values))
+}
+
+// detach gives the cache its own digits, so that a cloned sequence shares
+// no memory with the original. A text is parsed again on first access.
+func (c *digitCache) detach() {
+	switch {
+	case c.d == nil:
+	case c.d.text != "":
+		c.setText(c.d.text)
+	default:
+		values, _ := c.d.load()
+		c.set(slices.Clone(values))
+	}
+}
+
+// digitsXML is the <digits> element of a scaled sequence: Digits as written,
+// or else the values formatted.
+type digitsXML struct {
+	text   string
+	values []int32
+}
+
+// MarshalText returns the content of the element.
+func (d digitsXML) MarshalText() ([]byte, error) {
+	if d.text != "" {
+		return []byte(d.text), nil
+	}
+	return appendDigits(nil, d.values), nil
+}
+
+// UnmarshalText keeps the content of the element as text.
+func (d *digitsXML) UnmarshalText(text []byte) error {
+	*d = digitsXML{text: string(text)}
+	return nil
+}
+
+// digitSpace holds the bytes separating digits (XML whitespace).
+var digitSpace = [256]bool{' ': true, '\n': true, '\t': true, '\r': true}
+
+// isDigitSpace reports whether c separates digits.
+func isDigitSpace(c byte) bool {
+	return digitSpace[c]
+}
+
+// countDigits returns the number of whitespace-separated fields of text.
+func countDigits(text string) int {
+	n := 0
+	inField := false
+	for i := 0; i < len(text); i++ {
+		space := isDigitSpace(text[i])
+		if !space && !inField {
+			n++
+		}
+		inField = !space
+	}
+	return n
+}
+
+// parseDigits parses whitespace-separated integers with an optional sign, in
+// the int32 range. Errors are *strconv.NumError, wrapped with the index of
+// the digit.
+func parseDigits(text string) ([]int32, error) {
+	// Digits take 4 to 5 bytes with their separator in usual recordings
+	values := make([]int32, 0, len(text)/4+1)
+	for i := 0; i < len(text); {
+		if isDigitSpace(text[i]) {
+			i++
+			continue
+		}
+
+		start := i
+		neg := false
+		if text[i] == '-' || text[i] == '+' {
+			neg = text[i] == '-'
+			i++
+		}
+		first := i
+		var v int64
+		for ; i < len(text); i++ {
+			d := text[i] - '0'
+			if d > 9 {
+				break
+			}
+			// Past the int32 range, the value is only checked for syntax
+			if v <= math.MaxInt32+1 {
+				v = v*10 + int64(d)
+			}
+		}
+
+		if i == first || (i < len(text) && !isDigitSpace(text[i])) {
+			for i < len(text) && !isDigitSpace(text[i]) {
+				i++
+			}
+			return nil, digitError(len(values), text[start:i], strconv.ErrSyntax)
+		}
+		if neg {
+			v = -v
+		}
+		if v > math.MaxInt32 || v < math.MinInt32 {
+			return nil, digitError(len(values), text[start:i], strconv.ErrRange)
+		}
+		values = append(values, int32(v))
+	}
+	return values, nil
+}
+
+// digitError returns the error of the digit at index.
+func digitError(index int, digit string, err error) error {
+	return fmt.Errorf("digit %d: %w", index, &strconv.NumError{Func: "ParseInt", Num: digit, Err: err})
+}
+
+// appendDigits appends the values separated by single spaces to b.
+func appendDigits(b []byte, values []int32) []byte {
+	b = slices.Grow(b, len(values)*5)
+	for i, v := range values {
+		if i > 0 {
+			b = append(b, ' ')
+		}
+		b = strconv.AppendInt(b, int64(v), 10)
+	}
+	return b
+}
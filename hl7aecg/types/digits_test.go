@@ -0,0 +1,233 @@
+package types
+
+import (
+	"encoding/xml"
+	"math/rand"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestParseDigits tests the digit scanner
+func TestParseDigits(t *testing.T) {
+	tests := []struct {
+		name    string
+		text    string
+		want    []int32
+		wantErr error
+	}{
+		{name: "empty", text: "", want: []int32{}},
+		{name: "blank", text: " \n\t ", want: []int32{}},
+		{name: "single spaces", text: "1 -2 3", want: []int32{1, -2, 3}},
+		{name: "XML whitespace", text: "\n  10\t-20\r\n+30  ", want: []int32{10, -20, 30}},
+		{name: "leading zeros", text: "007 -0", want: []int32{7, 0}},
+		{name: "int32 bounds", text: "2147483647 -2147483648", want: []int32{2147483647, -2147483648}},
+		{name: "above int32", text: "1 2147483648", wantErr: strconv.ErrRange},
+		{name: "below int32", text: "-2147483649", wantErr: strconv.ErrRange},
+		{name: "many digits", text: "99999999999999999999999", wantErr: strconv.ErrRange},
+		{name: "letters", text: "1 abc 3", wantErr: strconv.ErrSyntax},
+		{name: "trailing letter", text: "1 2a", wantErr: strconv.ErrSyntax},
+		{name: "sign only", text: "1 - 2", wantErr: strconv.ErrSyntax},
+		{name: "decimal", text: "1.5", wantErr: strconv.ErrSyntax},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseDigits(tt.text)
+			if tt.wantErr != nil {
+				assert.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+			assert.Equal(t, len(tt.want), countDigits(tt.text))
+		})
+	}
+}
+
+// TestSLIST_PQ_GetDigitValues tests that decoded digits are parsed once,
+// and again once Digits is replaced
+func TestSLIST_PQ_GetDigitValues(t *testing.T) {
+	s := &SLIST_PQ{}
+	require.NoError(t, xml.Unmarshal([]byte(`<SLIST_PQ><digits>1 2 3</digits></SLIST_PQ>`), s))
+
+	first, err := s.GetDigitValues()
+	require.NoError(t, err)
+	assert.Equal(t, []int32{1, 2, 3}, first)
+	again, err := s.GetDigitValues()
+	require.NoError(t, err)
+	assert.Same(t, &first[0], &again[0], "not parsed again")
+	assert.Equal(t, 3, s.GetLength())
+
+	s.Digits = "4 5"
+	replaced, err := s.GetDigitValues()
+	require.NoError(t, err)
+	assert.Equal(t, []int32{4, 5}, replaced)
+	assert.Equal(t, 2, s.GetLength())
+
+	s.Digits = "4 x"
+	_, err = s.GetDigitValues()
+	assert.ErrorIs(t, err, strconv.ErrSyntax)
+	assert.Equal(t, 2, s.GetLength(), "fields of invalid digits")
+}
+
+// TestSLIST_PQ_ConcurrentReads tests that a sequence can be read and
+// encoded from concurrent goroutines (run with -race)
+func TestSLIST_PQ_ConcurrentReads(t *testing.T) {
+	var value SequenceValue
+	data := `<value xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xsi:type="SLIST_PQ">` +
+		`<origin value="0" unit="uV"/><scale value="5" unit="uV"/><digits>1 2 3 4</digits></value>`
+	require.NoError(t, xml.Unmarshal([]byte(data), &value))
+	s := value.Typed.(*SLIST_PQ)
+	built := (&SLIST_INT{Scale: 1}).SetDigitValues([]int32{1, 2, 3, 4})
+
+	var wg sync.WaitGroup
+	for range 8 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			digits, err := s.GetDigits()
+			assert.NoError(t, err)
+			assert.Equal(t, []int{1, 2, 3, 4}, digits)
+			assert.Equal(t, 4, s.GetLength())
+			assert.Equal(t, 4, built.GetLength())
+			_, err = xml.Marshal(s)
+			assert.NoError(t, err)
+			clone := deepCopy(reflect.ValueOf(s)).Interface().(*SLIST_PQ)
+			assert.Equal(t, 4, clone.GetLength())
+		}()
+	}
+	wg.Wait()
+}
+
+// TestSLIST_PQ_SetDigitValues tests that digits set as values are formatted on encoding
+func TestSLIST_PQ_SetDigitValues(t *testing.T) {
+	s := &SLIST_PQ{
+		Origin: PhysicalQuantity{Value: "0", Unit: "uV"},
+		Scale:  PhysicalQuantity{Value: "5", Unit: "uV"},
+	}
+	s.SetDigitValues([]int32{-1, 0, 12})
+
+	assert.Empty(t, s.Digits)
+	assert.Equal(t, 3, s.GetLength())
+	actual, err := s.GetActualValues()
+	require.NoError(t, err)
+	assert.Equal(t, []float64{-5, 0, 60}, actual)
+
+	data, err := xml.Marshal(&SequenceValue{XsiType: "SLIST_PQ", Typed: s})
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<digits>-1 0 12</digits>")
+
+	var back SequenceValue
+	require.NoError(t, xml.Unmarshal(data, &back))
+	digits, err := back.Typed.(*SLIST_PQ).GetDigits()
+	require.NoError(t, err)
+	assert.Equal(t, []int{-1, 0, 12}, digits)
+
+	// Digits set afterwards take precedence
+	s.Digits = "7"
+	data, err = xml.Marshal(s)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "<digits>7</digits>")
+}
+
+// TestSLIST_INT_SetDigitValues tests that SLIST_INT digits set as values are formatted on encoding
+func TestSLIST_INT_SetDigitValues(t *testing.T) {
+	s := (&SLIST_INT{Origin: 1, Scale: 2}).SetDigitValues([]int32{0, 1, 2})
+
+	values, err := s.GetActualValues()
+	require.NoError(t, err)
+	assert.Equal(t, []int{1, 3, 5}, values)
+
+	data, err := xml.Marshal(s)
+	require.NoError(t, err)
+	assert.Equal(t, `<SLIST_INT><origin value="1"></origin><scale value="2"></scale><digits>0 1 2</digits></SLIST_INT>`, string(data))
+}
+
+// TestHL7AEcg_CloneDigits tests that clones share no digits, and that digits
+// set as values equal the same digits as text
+func TestHL7AEcg_CloneDigits(t *testing.T) {
+	doc := newCanonicalDoc(MDC_ECG_LEAD_I, MDC_ECG_LEAD_II)
+	slist := doc.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence.Value.Typed.(*SLIST_PQ)
+	values, err := slist.GetDigitValues()
+	require.NoError(t, err)
+
+	clone := doc.Clone()
+	cloned, err := clone.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence.Value.Typed.(*SLIST_PQ).GetDigitValues()
+	require.NoError(t, err)
+	assert.Equal(t, values, cloned)
+	assert.NotSame(t, &values[0], &cloned[0])
+
+	built := doc.Clone()
+	builtSlist := built.Component[0].Series.Component[0].SequenceSet.Component[1].Sequence.Value.Typed.(*SLIST_PQ)
+	builtSlist.SetDigitValues([]int32{1, 2, 3})
+	assert.True(t, built.Equal(doc))
+	builtSlist.SetDigitValues([]int32{1, 2, 4})
+	assert.False(t, built.Equal(doc))
+}
+
+// benchmarkDigits returns one minute of a lead sampled at 500 Hz.
+func benchmarkDigits() string {
+	r := rand.New(rand.NewSource(1))
+	fields := make([]string, 500*60)
+	for i := range fields {
+		fields[i] = strconv.Itoa(r.Intn(4000) - 2000)
+	}
+	return strings.Join(fields, " ")
+}
+
+// BenchmarkSLIST_PQ_Digits compares the digit scanner with strings.Fields
+// and strconv.Atoi, and the cached accessors with parsing on every call.
+func BenchmarkSLIST_PQ_Digits(b *testing.B) {
+	text := benchmarkDigits()
+
+	b.Run("strings.Fields", func(b *testing.B) {
+		b.SetBytes(int64(len(text)))
+		for b.Loop() {
+			fields := strings.Fields(text)
+			digits := make([]int, len(fields))
+			for i, f := range fields {
+				digits[i], _ = strconv.Atoi(f)
+			}
+		}
+	})
+	b.Run("parseDigits", func(b *testing.B) {
+		b.SetBytes(int64(len(text)))
+		for b.Loop() {
+			parseDigits(text)
+		}
+	})
+	b.Run("GetDigitValues", func(b *testing.B) {
+		s := &SLIST_PQ{Digits: text}
+		for b.Loop() {
+			s.GetDigitValues()
+		}
+	})
+	b.Run("GetLength", func(b *testing.B) {
+		s := &SLIST_PQ{Digits: text}
+		for b.Loop() {
+			s.GetLength()
+		}
+	})
+	b.Run("GetActualValues", func(b *testing.B) {
+		s := &SLIST_PQ{Origin: PhysicalQuantity{Value: "0"}, Scale: PhysicalQuantity{Value: "5"}, Digits: text}
+		for b.Loop() {
+			s.GetActualValues()
+		}
+	})
+}
+
+// BenchmarkSLIST_PQ_Marshal measures the encoding of digits set as values.
+func BenchmarkSLIST_PQ_Marshal(b *testing.B) {
+	values, err := parseDigits(benchmarkDigits())
+	require.NoError(b, err)
+	s := (&SLIST_PQ{}).SetDigitValues(values)
+	for b.Loop() {
+		xml.Marshal(s)
+	}
+}
@@ -99,6 +99,9 @@ var (
 	// ErrInvalidIncrement indicates increment value is invalid
 	ErrInvalidIncrement = NewValidationError("Increment", "Increment value must be a positive number")
 
+	// ErrIrregularSampling indicates the samples of a time sequence are not evenly spaced
+	ErrIrregularSampling = NewValidationError("Sequence", "Time sequence samples must be evenly spaced to have a sample rate")
+
 	// ErrInvalidScale indicates scale value is invalid
 	ErrInvalidScale = NewValidationError("Scale", "Scale value must be a non-zero number")
 
@@ -118,9 +121,10 @@ var (
 
 // ValidationContext provides context for validation operations.
 type ValidationContext struct {
-	StrictMode bool     // If true, apply stricter validation rules
-	Warnings   []string // Non-fatal warnings collected during validation
-	Errors     []error  // Errors collected during validation
+	StrictMode    bool           // If true, apply stricter validation rules
+	Warnings      []string       // Non-fatal warnings collected during validation
+	Errors        []error        // Errors collected during validation
+	TrialRegistry *TrialRegistry // Optional: registered protocols to cross-check against
 }
 
 // NewValidationContext creates a new validation context.
@@ -0,0 +1,695 @@
+package types
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/xml"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// =============================================================================
+// Unmodeled Content
+// =============================================================================
+
+// Extensions holds the attributes and child elements of an element that the
+// structs do not model: vendor extensions, elements of other schema versions,
+// nullFlavor attributes, ...
+//
+// It is embedded in every element struct; the typed values of an
+// AnnotationValue (PhysicalQuantity, StringValue, CodedValue) keep theirs in
+// the AnnotationValue. Decoding fills it and records where each unmodeled
+// element stood among its siblings (see ExtraElement.After), and encoding
+// the document writes them back there, so that a document read and written
+// again keeps them in place. An element encoded on its own writes them after
+// its modeled content.
+//
+// Namespaced names are kept with their prefix (e.g. "vnd:device"), and the
+// namespace declarations of the document are kept with them, so that the
+// prefixes used by the raw inner XML stay bound. Names in NAMESPACE_HL7 and
+// NAMESPACE_XSI are the exception: they are written with the default
+// namespace and the "xsi" prefix the root element declares (see
+// HL7AEcg.MarshalXML), whatever prefix the document used.
+//
+// Comments, processing instructions and the attributes of elements modeled as
+// plain strings are not kept.
+type Extensions struct {
+	// ExtraAttrs are the attributes not mapped to a field, in document order.
+	ExtraAttrs []xml.Attr `xml:",any,attr"`
+
+	// ExtraElements are the child elements not mapped to a field, in
+	// document order.
+	ExtraElements []ExtraElement `xml:",any"`
+}
+
+// ExtraElement is an unmodeled element, kept verbatim.
+type ExtraElement struct {
+	XMLName xml.Name
+
+	// Attrs are the attributes of the element.
+	Attrs []xml.Attr `xml:",any,attr"`
+
+	// InnerXML is the raw content of the element.
+	InnerXML []byte `xml:",innerxml"`
+
+	// After is the modeled sibling the element follows, set when the
+	// element is decoded with its document. Encoding the document writes
+	// the element right after it, or after the modeled content when After
+	// is nil or the sibling is not written.
+	After *Sibling `xml:"-"`
+
+	// offset is the input offset of the end of the start tag when decoded,
+	// which locates the element in the document.
+	offset int64
+}
+
+// Sibling identifies a child element among the children of its parent: the
+// Index-th element (from 0) named Name. An empty Name stands for the start
+// of the content, before the first child.
+type Sibling struct {
+	Name  string
+	Index int
+}
+
+// UnmarshalXML decodes the element and records its input offset.
+func (e *ExtraElement) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias ExtraElement
+	e.offset = d.InputOffset()
+	return d.DecodeElement((*alias)(e), &start)
+}
+
+// HasExtensions reports whether any unmodeled attribute or element is kept.
+func (e *Extensions) HasExtensions() bool {
+	return len(e.ExtraAttrs) > 0 || len(e.ExtraElements) > 0
+}
+
+// UnmarshalXML decodes the document, locates the unmodeled elements among
+// their siblings, then resolves the names of the unmodeled attributes and
+// elements against the namespace declarations in scope (see Extensions).
+func (h *HL7AEcg) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	// Exported, for the decoder to fill the embedded fields
+	type Alias HL7AEcg
+	doc := struct {
+		*Alias
+		InnerXML []byte `xml:",innerxml"`
+	}{Alias: (*Alias)(h)}
+	offset := d.InputOffset()
+	if err := d.DecodeElement(&doc, &start); err != nil {
+		return err
+	}
+	locateExtraElements(reflect.ValueOf(h).Elem(), doc.InnerXML, offset)
+	resolveNames(reflect.ValueOf(h).Elem(), newNamespaceScope(start.Attr))
+	return nil
+}
+
+// StripExtensions removes every unmodeled attribute and element from the
+// document, and returns the number removed.
+func (h *HL7AEcg) StripExtensions() int {
+	if h == nil {
+		return 0
+	}
+	n := 0
+	walkExtensions(reflect.ValueOf(h).Elem(), func(e *Extensions) {
+		n += len(e.ExtraAttrs) + len(e.ExtraElements)
+		*e = Extensions{}
+	})
+	return n
+}
+
+// =============================================================================
+// Positions
+// =============================================================================
+
+// locateExtraElements sets the After sibling of the unmodeled elements found
+// in v, from the content of the document: inner, read from input offset base.
+//
+// Elements decoded apart from the document (e.g. the content of a sequence
+// value) have offsets in another input: they are left without a position,
+// unless the element at their offset has their name.
+func locateExtraElements(v reflect.Value, inner []byte, base int64) {
+	byOffset := make(map[int64]*ExtraElement)
+	walkExtensions(v, func(e *Extensions) {
+		for i := range e.ExtraElements {
+			if el := &e.ExtraElements[i]; el.offset > base {
+				byOffset[el.offset-base] = el
+			}
+		}
+	})
+	if len(byOffset) == 0 {
+		return
+	}
+
+	// The last modeled sibling and the count of each name, per open element
+	type level struct {
+		after  Sibling
+		counts map[string]int
+	}
+	stack := []*level{{}}
+	d := xml.NewDecoder(bytes.NewReader(inner))
+	d.Strict = false
+	for {
+		tok, err := d.RawToken()
+		if err != nil {
+			return
+		}
+		switch tok := tok.(type) {
+		case xml.StartElement:
+			lv := stack[len(stack)-1]
+			if el, ok := byOffset[d.InputOffset()]; ok && el.XMLName.Local == tok.Name.Local {
+				after := lv.after
+				el.After = &after
+			} else {
+				if lv.counts == nil {
+					lv.counts = make(map[string]int)
+				}
+				lv.after = Sibling{Name: tok.Name.Local, Index: lv.counts[tok.Name.Local]}
+				lv.counts[tok.Name.Local]++
+			}
+			stack = append(stack, &level{})
+		case xml.EndElement:
+			if len(stack) > 1 {
+				stack = stack[:len(stack)-1]
+			}
+		}
+	}
+}
+
+// =============================================================================
+// Encoding
+// =============================================================================
+
+// xmlFieldKind is the way a struct field is encoded.
+type xmlFieldKind int
+
+const (
+	fieldElement xmlFieldKind = iota
+	fieldAttr
+	fieldCharData
+	fieldExtensions
+)
+
+// xmlField is a struct field as encoded by marshalElement.
+type xmlField struct {
+	index     []int
+	name      string
+	kind      xmlFieldKind
+	omitEmpty bool
+}
+
+// xmlFieldsCache maps struct types to their []xmlField, or to nil for the
+// types marshalElement leaves to the standard encoder.
+var xmlFieldsCache sync.Map
+
+// xmlFieldsOf returns the fields of a struct type holding Extensions, in
+// encoding order, or nil if the type does not hold Extensions or uses tags
+// marshalElement does not handle (innerxml, any, parent>child paths).
+func xmlFieldsOf(t reflect.Type) []xmlField {
+	if fields, ok := xmlFieldsCache.Load(t); ok {
+		return fields.([]xmlField)
+	}
+	fields, ok := appendXMLFields(nil, t, nil)
+	if !ok || !slices.ContainsFunc(fields, func(f xmlField) bool { return f.kind == fieldExtensions }) {
+		fields = nil
+	}
+	xmlFieldsCache.Store(t, fields)
+	return fields
+}
+
+// appendXMLFields appends the fields of struct type t, at index in the
+// outer struct, to fields. It reports false for unhandled tags.
+func appendXMLFields(fields []xmlField, t reflect.Type, index []int) ([]xmlField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		tag := sf.Tag.Get("xml")
+		if tag == "-" || sf.Type == xmlNameType || (!sf.IsExported() && !sf.Anonymous) {
+			continue
+		}
+		fieldIndex := append(slices.Clip(index), i)
+		if sf.Anonymous && tag == "" {
+			if sf.Type == extensionsType {
+				fields = append(fields, xmlField{index: fieldIndex, kind: fieldExtensions})
+				continue
+			}
+			if sf.Type.Kind() != reflect.Struct {
+				return nil, false
+			}
+			var ok bool
+			if fields, ok = appendXMLFields(fields, sf.Type, fieldIndex); !ok {
+				return nil, false
+			}
+			continue
+		}
+		if !sf.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(tag, ",")
+		f := xmlField{index: fieldIndex, name: name}
+		if f.name == "" {
+			f.name = sf.Name
+		}
+		for _, opt := range strings.Split(opts, ",") {
+			switch opt {
+			case "":
+			case "attr":
+				f.kind = fieldAttr
+			case "chardata":
+				f.kind = fieldCharData
+			case "omitempty":
+				f.omitEmpty = true
+			default:
+				return nil, false
+			}
+		}
+		if strings.ContainsAny(name, "> ") {
+			return nil, false
+		}
+		fields = append(fields, f)
+	}
+	return fields, true
+}
+
+// marshalElement encodes the addressable struct v as the element start, as
+// the standard encoder does, but for the unmodeled elements: each is written
+// after the sibling it followed when decoded (see ExtraElement.After), and
+// the others after the modeled content.
+func marshalElement(e *xml.Encoder, v reflect.Value, start xml.StartElement) error {
+	fields := xmlFieldsOf(v.Type())
+	if fields == nil {
+		return e.EncodeElement(v.Addr().Interface(), start)
+	}
+
+	var extras extraPlacer
+	for _, f := range fields {
+		fv := v.FieldByIndex(f.index)
+		switch f.kind {
+		case fieldAttr:
+			if attr, ok := marshalAttr(f, fv); ok {
+				start.Attr = append(start.Attr, attr)
+			}
+		case fieldExtensions:
+			ext := fv.Addr().Interface().(*Extensions)
+			start.Attr = append(start.Attr, ext.ExtraAttrs...)
+			extras.elements = ext.ExtraElements
+		}
+	}
+	if err := e.EncodeToken(start); err != nil {
+		return err
+	}
+
+	if err := extras.writeAfter(e, Sibling{}); err != nil {
+		return err
+	}
+	child := func(v reflect.Value, name string) error {
+		if written, err := marshalChild(e, v, name); err != nil || !written {
+			return err
+		}
+		return extras.writeAfterNext(e, name)
+	}
+	for _, f := range fields {
+		fv := v.FieldByIndex(f.index)
+		switch {
+		case f.kind == fieldCharData:
+			if err := e.EncodeToken(xml.CharData(fv.String())); err != nil {
+				return err
+			}
+		case f.kind != fieldElement, f.omitEmpty && isEmptyValue(fv):
+		case fv.Kind() == reflect.Slice && fv.Type().Elem().Kind() != reflect.Uint8:
+			for i := 0; i < fv.Len(); i++ {
+				if err := child(fv.Index(i), f.name); err != nil {
+					return err
+				}
+			}
+		default:
+			if err := child(fv, f.name); err != nil {
+				return err
+			}
+		}
+	}
+	if err := extras.writeRest(e); err != nil {
+		return err
+	}
+	return e.EncodeToken(start.End())
+}
+
+var (
+	marshalerType   = reflect.TypeOf((*xml.Marshaler)(nil)).Elem()
+	textMarshalType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// marshalChild encodes v as the child element name, and reports whether an
+// element was written (nil pointers and interfaces are not).
+func marshalChild(e *xml.Encoder, v reflect.Value, name string) (bool, error) {
+	for v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface {
+		if v.IsNil() {
+			return false, nil
+		}
+		v = v.Elem()
+	}
+	start := xml.StartElement{Name: xml.Name{Local: name}}
+	t := reflect.PointerTo(v.Type())
+	if !v.CanAddr() || t.Implements(marshalerType) || t.Implements(textMarshalType) || v.Kind() != reflect.Struct {
+		if v.CanAddr() {
+			return true, e.EncodeElement(v.Addr().Interface(), start)
+		}
+		return true, e.EncodeElement(v.Interface(), start)
+	}
+	return true, marshalElement(e, v, start)
+}
+
+// marshalAttr returns the attribute of field f holding fv, as the standard
+// encoder writes it: nil pointers and, with omitempty, empty values are
+// omitted.
+func marshalAttr(f xmlField, fv reflect.Value) (xml.Attr, bool) {
+	if f.omitEmpty && isEmptyValue(fv) {
+		return xml.Attr{}, false
+	}
+	if fv.Kind() == reflect.Pointer {
+		if fv.IsNil() {
+			return xml.Attr{}, false
+		}
+		fv = fv.Elem()
+	}
+	attr := xml.Attr{Name: xml.Name{Local: f.name}}
+	switch fv.Kind() {
+	case reflect.String:
+		attr.Value = fv.String()
+	case reflect.Bool:
+		attr.Value = strconv.FormatBool(fv.Bool())
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		attr.Value = strconv.FormatInt(fv.Int(), 10)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		attr.Value = strconv.FormatUint(fv.Uint(), 10)
+	case reflect.Float32, reflect.Float64:
+		attr.Value = strconv.FormatFloat(fv.Float(), 'g', -1, fv.Type().Bits())
+	default:
+		return xml.Attr{}, false
+	}
+	return attr, true
+}
+
+// isEmptyValue reports whether v is empty in the sense of omitempty.
+func isEmptyValue(v reflect.Value) bool {
+	switch v.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return v.Len() == 0
+	case reflect.Bool,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return v.IsZero()
+	}
+	return false
+}
+
+// extraPlacer writes the unmodeled elements of an element among its
+// modeled children, as they are written.
+type extraPlacer struct {
+	elements []ExtraElement
+	written  []bool
+	counts   map[string]int
+}
+
+// writeAfterNext counts a child named name, and writes the elements that
+// followed it.
+func (p *extraPlacer) writeAfterNext(e *xml.Encoder, name string) error {
+	if len(p.elements) == 0 {
+		return nil
+	}
+	if p.counts == nil {
+		p.counts = make(map[string]int)
+	}
+	index := p.counts[name]
+	p.counts[name]++
+	return p.writeAfter(e, Sibling{Name: name, Index: index})
+}
+
+// writeAfter writes the elements that followed sibling, in document order.
+func (p *extraPlacer) writeAfter(e *xml.Encoder, sibling Sibling) error {
+	for i := range p.elements {
+		if el := &p.elements[i]; el.After != nil && *el.After == sibling {
+			if err := p.write(e, i); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeRest writes the elements not written yet, in document order.
+func (p *extraPlacer) writeRest(e *xml.Encoder) error {
+	for i := range p.elements {
+		if err := p.write(e, i); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// write writes the element at index i, unless written already.
+func (p *extraPlacer) write(e *xml.Encoder, i int) error {
+	if p.written == nil {
+		p.written = make([]bool, len(p.elements))
+	}
+	if p.written[i] {
+		return nil
+	}
+	p.written[i] = true
+	return e.Encode(&p.elements[i])
+}
+
+// =============================================================================
+// Namespace Resolution
+// =============================================================================
+
+var (
+	extensionsType   = reflect.TypeOf(Extensions{})
+	extraElementType = reflect.TypeOf(ExtraElement{})
+	xmlAttrType      = reflect.TypeOf(xml.Attr{})
+)
+
+// xmlNamespace is the namespace bound to the reserved "xml" prefix.
+const xmlNamespace = "http://www.w3.org/XML/1998/namespace"
+
+// namespaceScope holds the namespace declarations in scope of an element.
+type namespaceScope struct {
+	// defaultSpace is the default namespace.
+	defaultSpace string
+
+	// prefixes maps namespaces to their prefix.
+	prefixes map[string]string
+}
+
+// newNamespaceScope returns the scope declared by the attributes of the
+// root element.
+func newNamespaceScope(attrs []xml.Attr) *namespaceScope {
+	return (&namespaceScope{prefixes: map[string]string{xmlNamespace: "xml"}}).with(attrs)
+}
+
+// with returns the scope of an element with the given attributes: s, with
+// the declarations of the element added.
+func (s *namespaceScope) with(attrs []xml.Attr) *namespaceScope {
+	var out *namespaceScope
+	for _, attr := range attrs {
+		prefix, ok := declaredPrefix(attr.Name)
+		if !ok {
+			continue
+		}
+		if out == nil {
+			out = &namespaceScope{defaultSpace: s.defaultSpace, prefixes: make(map[string]string, len(s.prefixes)+1)}
+			for space, p := range s.prefixes {
+				out.prefixes[space] = p
+			}
+		}
+		if prefix == "" {
+			out.defaultSpace = attr.Value
+		} else {
+			out.prefixes[attr.Value] = prefix
+		}
+	}
+	if out == nil {
+		return s
+	}
+	return out
+}
+
+// declaredPrefix returns the prefix declared by a namespace declaration
+// ("" for the default namespace), and whether the name is one.
+func declaredPrefix(name xml.Name) (string, bool) {
+	switch {
+	case name.Space == "xmlns":
+		return name.Local, true
+	case name.Space == "" && name.Local == "xmlns":
+		return "", true
+	case name.Space == "" && strings.HasPrefix(name.Local, "xmlns:"):
+		return strings.TrimPrefix(name.Local, "xmlns:"), true
+	}
+	return "", false
+}
+
+// prefixed returns the name as written with its prefix, or false if the
+// namespace has none in scope.
+//
+// Elements decoded apart from the document (e.g. the content of a sequence
+// value) have no declarations in scope: the decoder then leaves the prefix
+// itself as the namespace, which is kept as is.
+func (s *namespaceScope) prefixed(name xml.Name) (xml.Name, bool) {
+	if prefix, ok := s.prefixes[name.Space]; ok {
+		return xml.Name{Local: prefix + ":" + name.Local}, true
+	}
+	if !strings.ContainsAny(name.Space, ":/") {
+		return xml.Name{Local: name.Space + ":" + name.Local}, true
+	}
+	return name, false
+}
+
+// attrName returns the name an attribute is written with.
+func (s *namespaceScope) attrName(name xml.Name) xml.Name {
+	if name.Space == "" {
+		return name
+	}
+	switch name.Space {
+	case "xmlns":
+		return xml.Name{Local: "xmlns:" + name.Local}
+	case NAMESPACE_XSI:
+		return xml.Name{Local: "xsi:" + name.Local}
+	}
+	// An unbound namespace is left to the encoder, which declares it
+	name, _ = s.prefixed(name)
+	return name
+}
+
+// elementName returns the name an element is written with, s being the
+// scope of the element and parent the scope it appears in.
+func (s *namespaceScope) elementName(name xml.Name, parent *namespaceScope) xml.Name {
+	if name.Space == "" || name.Space == s.defaultSpace {
+		return xml.Name{Local: name.Local}
+	}
+	// The element is written in the default namespace of its parent, which
+	// is NAMESPACE_HL7 once encoded, unless it declares its own
+	if name.Space == NAMESPACE_HL7 && s.defaultSpace == parent.defaultSpace {
+		return xml.Name{Local: name.Local}
+	}
+	name, _ = s.prefixed(name)
+	return name
+}
+
+// resolveNames rewrites the names of the unmodeled attributes and elements
+// found in v as written, and moves the prefixed attributes the decoder could
+// not match (e.g. xsi:schemaLocation) to their field.
+func resolveNames(v reflect.Value, s *namespaceScope) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			resolveNames(v.Elem(), s)
+		}
+
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			resolveNames(v.Index(i), s)
+		}
+
+	case reflect.Struct:
+		if v.Type() == extensionsType || v.Type() == extraElementType || v.Type() == xmlAttrType {
+			return
+		}
+		if ext := extensionsOf(v); ext != nil {
+			s = s.with(ext.ExtraAttrs)
+			for i := range ext.ExtraAttrs {
+				ext.ExtraAttrs[i].Name = s.attrName(ext.ExtraAttrs[i].Name)
+			}
+			assignPrefixedAttrs(v, ext)
+			for i := range ext.ExtraElements {
+				el := &ext.ExtraElements[i]
+				es := s.with(el.Attrs)
+				el.XMLName = es.elementName(el.XMLName, s)
+				for j := range el.Attrs {
+					el.Attrs[j].Name = es.attrName(el.Attrs[j].Name)
+				}
+			}
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				resolveNames(v.Field(i), s)
+			}
+		}
+	}
+}
+
+// extensionsOf returns the Extensions embedded in the struct v, or nil.
+func extensionsOf(v reflect.Value) *Extensions {
+	f, ok := v.Type().FieldByName("Extensions")
+	if !ok || !f.Anonymous || f.Type != extensionsType || len(f.Index) != 1 {
+		return nil
+	}
+	return v.Field(f.Index[0]).Addr().Interface().(*Extensions)
+}
+
+// assignPrefixedAttrs moves the attributes matching the prefixed name of a
+// string attribute field of v (e.g. `xml:"xsi:schemaLocation,attr"`) to the
+// field, unless it is already set. Matched attributes are removed either way,
+// so that they are not written twice.
+func assignPrefixedAttrs(v reflect.Value, ext *Extensions) {
+	t := v.Type()
+	kept := ext.ExtraAttrs[:0]
+	for _, attr := range ext.ExtraAttrs {
+		matched := false
+		for i := 0; i < t.NumField(); i++ {
+			f := t.Field(i)
+			name, flags, _ := strings.Cut(f.Tag.Get("xml"), ",")
+			if f.Type.Kind() != reflect.String || name != attr.Name.Local ||
+				!strings.Contains(name, ":") || !strings.Contains(flags, "attr") {
+				continue
+			}
+			if field := v.Field(i); field.String() == "" {
+				field.SetString(attr.Value)
+			}
+			matched = true
+			break
+		}
+		if !matched {
+			kept = append(kept, attr)
+		}
+	}
+	if len(kept) == 0 {
+		kept = nil
+	}
+	ext.ExtraAttrs = kept
+}
+
+// walkExtensions calls fn on every Extensions found in v.
+func walkExtensions(v reflect.Value, fn func(*Extensions)) {
+	switch v.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		if !v.IsNil() {
+			walkExtensions(v.Elem(), fn)
+		}
+	case reflect.Slice:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return
+		}
+		for i := 0; i < v.Len(); i++ {
+			walkExtensions(v.Index(i), fn)
+		}
+	case reflect.Struct:
+		if v.Type() == extensionsType {
+			fn(v.Addr().Interface().(*Extensions))
+			return
+		}
+		if v.Type() == extraElementType || v.Type() == xmlAttrType {
+			return
+		}
+		for i := 0; i < v.NumField(); i++ {
+			if v.Field(i).CanSet() {
+				walkExtensions(v.Field(i), fn)
+			}
+		}
+	}
+}
@@ -0,0 +1,179 @@
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const extensionsDoc = `<AnnotatedECG xmlns="urn:hl7-org:v3" xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"
+    xmlns:acme="urn:acme" xsi:schemaLocation="urn:hl7-org:v3 PORT_MT020001.xsd" classCode="OBS">
+  <id root="2.16.840.1.113883.3.1"/>
+  <code code="93000" codeSystem="2.16.840.1.113883.6.12" acme:source="cart"/>
+  <acme:station id="ST-1">Ward 3</acme:station>
+  <languageCode code="fr-FR"/>
+  <component>
+    <series>
+      <code code="RHYTHM" codeSystem="2.16.840.1.113883.5.4"/>
+      <subjectOf>
+        <annotationSet>
+          <component>
+            <annotation>
+              <code code="QT_RANGE"/>
+              <value xsi:type="IVL_PQ" acme:method="auto"><low value="390" unit="ms"/></value>
+            </annotation>
+          </component>
+          <component>
+            <annotation>
+              <code code="MDC_ECG_TIME_PD_QT"/>
+              <value xsi:type="PQ" value="398" unit="ms" nullFlavor="OTH"/>
+            </annotation>
+          </component>
+        </annotationSet>
+      </subjectOf>
+      <algorithm xmlns="urn:beat"><template>median</template></algorithm>
+    </series>
+  </component>
+</AnnotatedECG>`
+
+// TestHL7AEcg_UnmarshalExtensions tests that unmodeled attributes and
+// elements are kept with the names they are written with
+func TestHL7AEcg_UnmarshalExtensions(t *testing.T) {
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(extensionsDoc), &doc))
+
+	assert.Equal(t, "urn:hl7-org:v3 PORT_MT020001.xsd", doc.SchemaLocation, "prefixed attribute moved to its field")
+	assert.Equal(t, "http://www.w3.org/2001/XMLSchema-instance", doc.XmlnsXsi)
+	assert.Equal(t, []xml.Attr{
+		{Name: xml.Name{Local: "xmlns:acme"}, Value: "urn:acme"},
+		{Name: xml.Name{Local: "classCode"}, Value: "OBS"},
+	}, doc.ExtraAttrs)
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "acme:source"}, Value: "cart"}}, doc.Code.ExtraAttrs)
+
+	require.Len(t, doc.ExtraElements, 2)
+	assert.Equal(t, xml.Name{Local: "acme:station"}, doc.ExtraElements[0].XMLName)
+	assert.Equal(t, "Ward 3", string(doc.ExtraElements[0].InnerXML))
+	assert.Equal(t, xml.Name{Local: "languageCode"}, doc.ExtraElements[1].XMLName, "default namespace")
+
+	series := doc.Component[0].Series
+	require.Len(t, series.ExtraElements, 1)
+	algorithm := series.ExtraElements[0]
+	assert.Equal(t, xml.Name{Local: "algorithm"}, algorithm.XMLName, "own default namespace")
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "xmlns"}, Value: "urn:beat"}}, algorithm.Attrs)
+
+	set := series.GetAnnotationSets()[0]
+	rng := set.GetAnnotationByCode("QT_RANGE").Value
+	assert.Nil(t, rng.Typed)
+	assert.Equal(t, "IVL_PQ", rng.XsiType)
+	assert.Equal(t, `<low value="390" unit="ms"/>`, string(rng.RawXML))
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "acme:method"}, Value: "auto"}}, rng.ExtraAttrs)
+
+	qt := set.GetAnnotationByCode("MDC_ECG_TIME_PD_QT").Value
+	assert.Equal(t, "398", qt.Typed.(*PhysicalQuantity).Value)
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "nullFlavor"}, Value: "OTH"}}, qt.ExtraAttrs)
+}
+
+// TestHL7AEcg_MarshalExtensions tests that unmodeled content is written back
+func TestHL7AEcg_MarshalExtensions(t *testing.T) {
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(extensionsDoc), &doc))
+	data, err := xml.Marshal(&doc)
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, `xmlns:acme="urn:acme"`)
+	assert.Contains(t, out, `<code code="93000" codeSystem="2.16.840.1.113883.6.12" acme:source="cart"></code>`)
+	assert.Contains(t, out, `<acme:station id="ST-1">Ward 3</acme:station>`)
+	assert.Contains(t, out, `<value xsi:type="IVL_PQ" acme:method="auto"><low value="390" unit="ms"/></value>`)
+	assert.Contains(t, out, `<value xsi:type="PQ" value="398" unit="ms" nullFlavor="OTH"></value>`)
+	assert.Contains(t, out, `<algorithm xmlns="urn:beat"><template>median</template></algorithm>`)
+
+	var back HL7AEcg
+	require.NoError(t, xml.Unmarshal(data, &back))
+	assert.True(t, back.Equal(&doc))
+}
+
+// TestHL7AEcg_MarshalPositions tests that unmodeled elements are
+// written back among their siblings where they were read
+func TestHL7AEcg_MarshalPositions(t *testing.T) {
+	const positionsDoc = `<AnnotatedECG xmlns="urn:hl7-org:v3" xmlns:acme="urn:acme">
+  <acme:header/>
+  <id root="2.16.840.1.113883.3.1"/>
+  <code code="93000" codeSystem="2.16.840.1.113883.6.12"/>
+  <component>
+    <series>
+      <code code="RHYTHM" codeSystem="2.16.840.1.113883.5.4"/>
+    </series>
+  </component>
+  <component>
+    <series>
+      <code code="REPRESENTATIVE_BEAT" codeSystem="2.16.840.1.113883.5.4"/>
+    </series>
+  </component>
+  <acme:between>1</acme:between>
+  <acme:between>2</acme:between>
+  <component>
+    <series>
+      <code code="RHYTHM" codeSystem="2.16.840.1.113883.5.4"/>
+    </series>
+  </component>
+</AnnotatedECG>`
+
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(positionsDoc), &doc))
+	require.Len(t, doc.ExtraElements, 3)
+	assert.Equal(t, &Sibling{}, doc.ExtraElements[0].After)
+	assert.Equal(t, &Sibling{Name: "component", Index: 1}, doc.ExtraElements[1].After)
+	assert.Equal(t, &Sibling{Name: "component", Index: 1}, doc.ExtraElements[2].After)
+
+	data, err := xml.MarshalIndent(&doc, "", "  ")
+	require.NoError(t, err)
+	out := string(data)
+	assert.Less(t, strings.Index(out, "<acme:header"), strings.Index(out, "<id "))
+	assert.Less(t, strings.Index(out, "REPRESENTATIVE_BEAT"), strings.Index(out, ">1</acme:between>"))
+	assert.Less(t, strings.Index(out, ">1</acme:between>"), strings.Index(out, ">2</acme:between>"))
+	assert.Less(t, strings.Index(out, ">2</acme:between>"), strings.LastIndex(out, "<component>"))
+
+	var back HL7AEcg
+	require.NoError(t, xml.Unmarshal(data, &back))
+	assert.Equal(t, doc.ExtraElements[1].After, back.ExtraElements[1].After)
+	assert.True(t, back.Equal(&doc))
+
+	// Without positions, unmodeled elements follow the modeled content
+	doc.ExtraElements[0].After = nil
+	data, err = xml.MarshalIndent(&doc, "", "  ")
+	require.NoError(t, err)
+	assert.Greater(t, strings.Index(string(data), "<acme:header"), strings.LastIndex(string(data), "<component>"))
+}
+
+// TestID_Extensions tests that the unmodeled attributes of an id are
+// written back
+func TestID_Extensions(t *testing.T) {
+	const idDoc = `<AnnotatedECG xmlns="urn:hl7-org:v3"><id root="1.2.3" extension="x" assigningAuthorityName="ACME"/></AnnotatedECG>`
+
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(idDoc), &doc))
+	require.NotNil(t, doc.ID)
+	assert.Equal(t, []xml.Attr{{Name: xml.Name{Local: "assigningAuthorityName"}, Value: "ACME"}}, doc.ID.ExtraAttrs)
+	assert.True(t, doc.ID.Same(ID{Root: "1.2.3", Extension: "x"}))
+
+	data, err := xml.Marshal(&doc)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), `<id root="1.2.3" extension="x" assigningAuthorityName="ACME"></id>`)
+}
+
+// TestHL7AEcg_StripExtensions tests that every unmodeled attribute and element is removed
+func TestHL7AEcg_StripExtensions(t *testing.T) {
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(extensionsDoc), &doc))
+
+	assert.Equal(t, 8, doc.StripExtensions())
+	assert.False(t, doc.HasExtensions())
+	assert.False(t, doc.Code.HasExtensions())
+	assert.Nil(t, doc.Component[0].Series.ExtraElements)
+	assert.Equal(t, 0, doc.StripExtensions())
+	assert.Equal(t, 0, (*HL7AEcg)(nil).StripExtensions())
+}
@@ -20,3 +20,18 @@ func (t Time) IsEmpty() bool {
 func isInvalidFloat(value float64) bool {
 	return math.IsNaN(value) || math.IsInf(value, 0)
 }
+
+// GetTrialSubject returns the trial subject of the document.
+//
+// The subject is looked up in the ComponentOf structure
+// (timepointEvent → subjectAssignment → subject) first, then in the direct
+// Subject element. Returns nil if neither is present.
+func (a *HL7AEcg) GetTrialSubject() *TrialSubject {
+	if a == nil {
+		return nil
+	}
+	if a.ComponentOf != nil {
+		return &a.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.Subject.TrialSubject
+	}
+	return a.Subject
+}
@@ -0,0 +1,367 @@
+package types
+
+import "strings"
+
+// =============================================================================
+// MDC ECG Nomenclature (ISO/IEEE 11073-10101 / 10102)
+// =============================================================================
+// The table below embeds the ECG terms of the MDC nomenclature used in aECG
+// documents: leads, wave components, beats, rhythms, intervals, amplitudes,
+// angles and rates. Each term carries its reference ID (the code written in
+// aECG files), its numeric context-free code, a display name and the
+// canonical unit of its measurement value.
+//
+// Reference: ISO/IEEE 11073-10101:2004 (partition 2, SCADA) and
+// ISO/IEEE 11073-10102:2012 (partition 10, annotated ECG).
+
+// MDCPartition is a partition of the MDC nomenclature.
+type MDCPartition uint16
+
+const (
+	MDC_PART_SCADA    MDCPartition = 2  // Measurements and leads
+	MDC_PART_ECG_EXTN MDCPartition = 10 // Annotated ECG extensions (waves, beats, rhythms)
+)
+
+// MDCCategory groups MDC ECG terms by kind.
+type MDCCategory string
+
+const (
+	MDC_CATEGORY_LEAD      MDCCategory = "LEAD"      // ECG leads
+	MDC_CATEGORY_WAVE      MDCCategory = "WAVE"      // Wave components and fiducial points
+	MDC_CATEGORY_BEAT      MDCCategory = "BEAT"      // Beat classifications
+	MDC_CATEGORY_RHYTHM    MDCCategory = "RHYTHM"    // Rhythm and conduction statements
+	MDC_CATEGORY_INTERVAL  MDCCategory = "INTERVAL"  // Durations and intervals (ms)
+	MDC_CATEGORY_AMPLITUDE MDCCategory = "AMPLITUDE" // Wave amplitudes (uV)
+	MDC_CATEGORY_ANGLE     MDCCategory = "ANGLE"     // Electrical axes (deg)
+	MDC_CATEGORY_RATE      MDCCategory = "RATE"      // Heart rates (bpm)
+)
+
+// MDCTerm is one term of the MDC ECG nomenclature.
+type MDCTerm struct {
+	// RefID is the reference identifier used as code in aECG (e.g., "MDC_ECG_LEAD_I").
+	RefID string
+
+	// Partition is the nomenclature partition of the term.
+	Partition MDCPartition
+
+	// TermCode is the numeric code of the term within its partition.
+	TermCode uint16
+
+	// DisplayName is the human-readable name of the term.
+	DisplayName string
+
+	// Category is the kind of term.
+	Category MDCCategory
+
+	// Unit is the canonical UCUM unit of the term's value, empty for terms
+	// that carry no physical quantity (leads, waves, beats, rhythms).
+	Unit string
+}
+
+// CFCode returns the 32-bit context-free code of the term (partition << 16 | term code).
+func (t MDCTerm) CFCode() uint32 {
+	return uint32(t.Partition)<<16 | uint32(t.TermCode)
+}
+
+// AcceptsUnit reports whether a value expressed in unit is valid for the term.
+//
+// Units of the same dimension as the canonical unit are accepted (e.g., "s"
+// for an interval whose canonical unit is "ms"). Terms without a canonical
+// unit accept any unit.
+func (t MDCTerm) AcceptsUnit(unit string) bool {
+	if t.Unit == "" || unit == t.Unit {
+		return true
+	}
+	dim, ok := unitDimensions[t.Unit]
+	return ok && unitDimensions[unit] == dim
+}
+
+// unitDimensions maps the UCUM units used in aECG to their physical dimension.
+var unitDimensions = map[string]string{
+	UNIT_SECOND:      "time",
+	UNIT_MILLISECOND: "time",
+	"us":             "time",
+	UNIT_MICROVOLT:   "voltage",
+	UNIT_MILLIVOLT:   "voltage",
+	UNIT_VOLT:        "voltage",
+	"nV":             "voltage",
+	UNIT_BPM:         "rate",
+	"/min":           "rate",
+	"{beat}/min":     "rate",
+	"{beats}/min":    "rate",
+	"deg":            "angle",
+	"rad":            "angle",
+}
+
+// =============================================================================
+// Term Table
+// =============================================================================
+
+// leadTerm builds a lead term from its lead number (term code = 256 + number).
+func leadTerm(refID LeadCode, number uint16, display string) MDCTerm {
+	return MDCTerm{
+		RefID:       string(refID),
+		Partition:   MDC_PART_SCADA,
+		TermCode:    0x100 + number,
+		DisplayName: display,
+		Category:    MDC_CATEGORY_LEAD,
+	}
+}
+
+// mdcTerms is the embedded ECG partition of the MDC nomenclature.
+var mdcTerms = []MDCTerm{
+	// Standard 12-lead ECG
+	leadTerm(MDC_ECG_LEAD_I, 1, "Lead I"),
+	leadTerm(MDC_ECG_LEAD_II, 2, "Lead II"),
+	leadTerm(MDC_ECG_LEAD_III, 61, "Lead III"),
+	leadTerm(MDC_ECG_LEAD_AVR, 62, "Lead aVR"),
+	leadTerm(MDC_ECG_LEAD_AVL, 63, "Lead aVL"),
+	leadTerm(MDC_ECG_LEAD_AVF, 64, "Lead aVF"),
+	leadTerm(MDC_ECG_LEAD_V1, 3, "Lead V1"),
+	leadTerm(MDC_ECG_LEAD_V2, 4, "Lead V2"),
+	leadTerm(MDC_ECG_LEAD_V3, 5, "Lead V3"),
+	leadTerm(MDC_ECG_LEAD_V4, 6, "Lead V4"),
+	leadTerm(MDC_ECG_LEAD_V5, 7, "Lead V5"),
+	leadTerm(MDC_ECG_LEAD_V6, 8, "Lead V6"),
+	leadTerm("MDC_ECG_LEAD_AVRneg", 65, "Lead -aVR"),
+
+	// Extended precordial leads (15-lead, posterior and right-sided)
+	leadTerm(MDC_ECG_LEAD_V7, 9, "Lead V7"),
+	leadTerm(MDC_ECG_LEAD_V8, 66, "Lead V8"),
+	leadTerm(MDC_ECG_LEAD_V9, 67, "Lead V9"),
+	leadTerm("MDC_ECG_LEAD_V10", 108, "Lead V10"),
+	leadTerm("MDC_ECG_LEAD_V2R", 10, "Lead V2R"),
+	leadTerm(MDC_ECG_LEAD_V3R, 11, "Lead V3R"),
+	leadTerm(MDC_ECG_LEAD_V4R, 12, "Lead V4R"),
+	leadTerm("MDC_ECG_LEAD_V5R", 13, "Lead V5R"),
+	leadTerm("MDC_ECG_LEAD_V6R", 14, "Lead V6R"),
+	leadTerm("MDC_ECG_LEAD_V7R", 15, "Lead V7R"),
+	leadTerm("MDC_ECG_LEAD_V8R", 68, "Lead V8R"),
+	leadTerm("MDC_ECG_LEAD_V9R", 69, "Lead V9R"),
+
+	// Frank orthogonal leads
+	leadTerm(MDC_ECG_LEAD_X, 16, "Frank lead X"),
+	leadTerm(MDC_ECG_LEAD_Y, 17, "Frank lead Y"),
+	leadTerm(MDC_ECG_LEAD_Z, 18, "Frank lead Z"),
+
+	// Nehb leads
+	leadTerm("MDC_ECG_LEAD_D", 70, "Nehb dorsal lead D"),
+	leadTerm("MDC_ECG_LEAD_A", 71, "Nehb anterior lead A"),
+	leadTerm("MDC_ECG_LEAD_J", 72, "Nehb inferior lead J"),
+
+	// EASI leads
+	leadTerm(MDC_ECG_LEAD_ES, 100, "EASI lead E-S"),
+	leadTerm(MDC_ECG_LEAD_AS, 101, "EASI lead A-S"),
+	leadTerm(MDC_ECG_LEAD_AI, 102, "EASI lead A-I"),
+	leadTerm("MDC_ECG_LEAD_S", 103, "EASI reference electrode S"),
+
+	// Holter bipolar chest leads (CM/CC) and modified limb leads (ML)
+	leadTerm(MDC_ECG_LEAD_CC5, 19, "Holter lead CC5"),
+	leadTerm(MDC_ECG_LEAD_CM5, 20, "Holter lead CM5"),
+	leadTerm("MDC_ECG_LEAD_CM1", 109, "Holter lead CM1"),
+	leadTerm("MDC_ECG_LEAD_CM2", 110, "Holter lead CM2"),
+	leadTerm("MDC_ECG_LEAD_CM3", 111, "Holter lead CM3"),
+	leadTerm("MDC_ECG_LEAD_CM4", 112, "Holter lead CM4"),
+	leadTerm("MDC_ECG_LEAD_CM6", 113, "Holter lead CM6"),
+	leadTerm("MDC_ECG_LEAD_CV5RL", 105, "Holter lead CV5RL"),
+	leadTerm("MDC_ECG_LEAD_CV6LL", 106, "Holter lead CV6LL"),
+	leadTerm("MDC_ECG_LEAD_CV6LU", 107, "Holter lead CV6LU"),
+	leadTerm("MDC_ECG_LEAD_MLI", 114, "Modified limb lead I"),
+	leadTerm(MDC_ECG_LEAD_MLII, 115, "Modified limb lead II"),
+	leadTerm("MDC_ECG_LEAD_MLIII", 116, "Modified limb lead III"),
+	leadTerm("MDC_ECG_LEAD_MLAVR", 117, "Modified limb lead aVR"),
+	leadTerm("MDC_ECG_LEAD_MLAVL", 118, "Modified limb lead aVL"),
+	leadTerm("MDC_ECG_LEAD_MLAVF", 119, "Modified limb lead aVF"),
+
+	// Special leads
+	leadTerm("MDC_ECG_LEAD_DEFIB", 73, "Defibrillator lead"),
+	leadTerm("MDC_ECG_LEAD_EXTERN", 74, "External lead"),
+	leadTerm("MDC_ECG_LEAD_A1", 75, "Auxiliary lead 1"),
+	leadTerm("MDC_ECG_LEAD_A2", 76, "Auxiliary lead 2"),
+	leadTerm("MDC_ECG_LEAD_A3", 77, "Auxiliary lead 3"),
+	leadTerm("MDC_ECG_LEAD_A4", 78, "Auxiliary lead 4"),
+	leadTerm("MDC_ECG_LEAD_RL", 104, "Right leg electrode"),
+
+	// Wave components and fiducial points
+	{string(MDC_ECG_WAVC), MDC_PART_ECG_EXTN, 512, "Wave component", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_PWAVE), MDC_PART_ECG_EXTN, 513, "P wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_PPWAVE), MDC_PART_ECG_EXTN, 514, "P' wave", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_PPPWAVE", MDC_PART_ECG_EXTN, 515, "P'' wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_QRSWAVE), MDC_PART_ECG_EXTN, 516, "QRS complex", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_QWAVE), MDC_PART_ECG_EXTN, 517, "Q wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_QSWAVE), MDC_PART_ECG_EXTN, 518, "QS wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_RWAVE), MDC_PART_ECG_EXTN, 519, "R wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_RRWAVE), MDC_PART_ECG_EXTN, 520, "R' wave", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_RRRWAVE", MDC_PART_ECG_EXTN, 521, "R'' wave", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_NOTCH", MDC_PART_ECG_EXTN, 522, "Notch", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_SWAVE), MDC_PART_ECG_EXTN, 523, "S wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_SSWAVE), MDC_PART_ECG_EXTN, 524, "S' wave", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_SSSWAVE", MDC_PART_ECG_EXTN, 525, "S'' wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_TWAVE), MDC_PART_ECG_EXTN, 526, "T wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_TTWAVE), MDC_PART_ECG_EXTN, 527, "T' wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_UWAVE), MDC_PART_ECG_EXTN, 528, "U wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_DELTA), MDC_PART_ECG_EXTN, 529, "Delta wave", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_IDEFLECTION", MDC_PART_ECG_EXTN, 530, "Intrinsicoid deflection", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_JPOINT), MDC_PART_ECG_EXTN, 531, "J point", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_STJ), MDC_PART_ECG_EXTN, 532, "ST-J point", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_STWAVE), MDC_PART_ECG_EXTN, 533, "ST segment", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_STTWAVE", MDC_PART_ECG_EXTN, 534, "ST-T segment", MDC_CATEGORY_WAVE, ""},
+	{"MDC_ECG_WAVC_FWAVE", MDC_PART_ECG_EXTN, 535, "Fibrillation or flutter wave", MDC_CATEGORY_WAVE, ""},
+	{string(MDC_ECG_WAVC_PACE), MDC_PART_ECG_EXTN, 536, "Pacemaker spike", MDC_CATEGORY_WAVE, ""},
+
+	// Beats
+	{"MDC_ECG_BEAT", MDC_PART_ECG_EXTN, 256, "Beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_NORMAL", MDC_PART_ECG_EXTN, 257, "Normal beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_ABNORMAL", MDC_PART_ECG_EXTN, 258, "Abnormal beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_DOMINANT", MDC_PART_ECG_EXTN, 259, "Dominant beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_SV_P_C", MDC_PART_ECG_EXTN, 260, "Supraventricular premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_ATR_P_C", MDC_PART_ECG_EXTN, 261, "Atrial premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_JUNC_P_C", MDC_PART_ECG_EXTN, 262, "Junctional premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_ATR_P_C_ABERRANT", MDC_PART_ECG_EXTN, 263, "Aberrated atrial premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_ATR_P_C_BLOCKED", MDC_PART_ECG_EXTN, 264, "Blocked atrial premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_SV_ESC", MDC_PART_ECG_EXTN, 265, "Supraventricular escape beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_ATR_ESC", MDC_PART_ECG_EXTN, 266, "Atrial escape beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_JUNC_ESC", MDC_PART_ECG_EXTN, 267, "Junctional escape beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_V_P_C", MDC_PART_ECG_EXTN, 268, "Ventricular premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_V_P_C_RonT", MDC_PART_ECG_EXTN, 269, "R-on-T ventricular premature contraction", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_V_ESC", MDC_PART_ECG_EXTN, 270, "Ventricular escape beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_FUSION", MDC_PART_ECG_EXTN, 271, "Fusion of ventricular and normal beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_PACED", MDC_PART_ECG_EXTN, 272, "Paced beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_PACED_FUSION", MDC_PART_ECG_EXTN, 273, "Fusion of paced and normal beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_BUNDLE_BRANCH_BLOCK", MDC_PART_ECG_EXTN, 274, "Bundle branch block beat", MDC_CATEGORY_BEAT, ""},
+	{"MDC_ECG_BEAT_UNCLASSIFIED", MDC_PART_ECG_EXTN, 275, "Unclassified beat", MDC_CATEGORY_BEAT, ""},
+
+	// Rhythms and conduction
+	{"MDC_ECG_RHY", MDC_PART_ECG_EXTN, 1024, "Rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SINUS_RHY", MDC_PART_ECG_EXTN, 1025, "Sinus rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SINUS_TACHY", MDC_PART_ECG_EXTN, 1026, "Sinus tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SINUS_BRADY", MDC_PART_ECG_EXTN, 1027, "Sinus bradycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SINUS_ARRHY", MDC_PART_ECG_EXTN, 1028, "Sinus arrhythmia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SINUS_PAUSE", MDC_PART_ECG_EXTN, 1029, "Sinus pause", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_ATR_RHY", MDC_PART_ECG_EXTN, 1030, "Ectopic atrial rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_ATR_TACHY", MDC_PART_ECG_EXTN, 1031, "Atrial tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_ATR_FIB", MDC_PART_ECG_EXTN, 1032, "Atrial fibrillation", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_ATR_FLUT", MDC_PART_ECG_EXTN, 1033, "Atrial flutter", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_SV_TACHY", MDC_PART_ECG_EXTN, 1034, "Supraventricular tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_JUNC_RHY", MDC_PART_ECG_EXTN, 1035, "Junctional rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_JUNC_TACHY", MDC_PART_ECG_EXTN, 1036, "Junctional tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_RHY", MDC_PART_ECG_EXTN, 1037, "Idioventricular rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_TACHY", MDC_PART_ECG_EXTN, 1038, "Ventricular tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_TACHY_NONSUST", MDC_PART_ECG_EXTN, 1039, "Non-sustained ventricular tachycardia", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_TORSADES", MDC_PART_ECG_EXTN, 1040, "Torsade de pointes", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_FLUT", MDC_PART_ECG_EXTN, 1041, "Ventricular flutter", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_FIB", MDC_PART_ECG_EXTN, 1042, "Ventricular fibrillation", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_BIGEM", MDC_PART_ECG_EXTN, 1043, "Ventricular bigeminy", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_V_TRIGEM", MDC_PART_ECG_EXTN, 1044, "Ventricular trigeminy", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_PACED_RHY", MDC_PART_ECG_EXTN, 1045, "Paced rhythm", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_ASYSTOLE", MDC_PART_ECG_EXTN, 1046, "Asystole", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_AV_BLOCK_1ST", MDC_PART_ECG_EXTN, 1047, "First degree AV block", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_AV_BLOCK_2ND_TYPE1", MDC_PART_ECG_EXTN, 1048, "Second degree AV block, Mobitz I", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_AV_BLOCK_2ND_TYPE2", MDC_PART_ECG_EXTN, 1049, "Second degree AV block, Mobitz II", MDC_CATEGORY_RHYTHM, ""},
+	{"MDC_ECG_AV_BLOCK_3RD", MDC_PART_ECG_EXTN, 1050, "Third degree AV block", MDC_CATEGORY_RHYTHM, ""},
+
+	// Intervals and durations
+	{"MDC_ECG_TIME_PD_P", MDC_PART_SCADA, 16132, "P wave duration", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_PP), MDC_PART_SCADA, 16136, "PP interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_PR), MDC_PART_SCADA, 16144, "PR interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{"MDC_ECG_TIME_PD_PQ", MDC_PART_SCADA, 16148, "PQ interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_QRS), MDC_PART_SCADA, 16156, "QRS duration", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_QT), MDC_PART_SCADA, 16160, "QT interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_QTc), MDC_PART_SCADA, 16164, "QT interval corrected", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_RR), MDC_PART_SCADA, 16168, "RR interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{"MDC_ECG_TIME_PD_JT", MDC_PART_SCADA, 16172, "JT interval", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{string(MDC_ECG_TIME_PD_QT_DISPERSION), MDC_PART_SCADA, 16176, "QT dispersion", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+	{"MDC_ECG_TIME_PD_QTc_DISPERSION", MDC_PART_SCADA, 16180, "QTc dispersion", MDC_CATEGORY_INTERVAL, UNIT_MILLISECOND},
+
+	// Angles
+	{string(MDC_ECG_ANGLE_P_FRONT), MDC_PART_SCADA, 16184, "P axis, frontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{string(MDC_ECG_ANGLE_QRS_FRONT), MDC_PART_SCADA, 16188, "QRS axis, frontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{string(MDC_ECG_ANGLE_T_FRONT), MDC_PART_SCADA, 16192, "T axis, frontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{string(MDC_ECG_ANGLE_ST_FRONT), MDC_PART_SCADA, 16196, "ST axis, frontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{"MDC_ECG_ANGLE_P_HORIZ", MDC_PART_SCADA, 16200, "P axis, horizontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{"MDC_ECG_ANGLE_QRS_HORIZ", MDC_PART_SCADA, 16204, "QRS axis, horizontal plane", MDC_CATEGORY_ANGLE, "deg"},
+	{"MDC_ECG_ANGLE_T_HORIZ", MDC_PART_SCADA, 16208, "T axis, horizontal plane", MDC_CATEGORY_ANGLE, "deg"},
+
+	// Amplitudes
+	{string(MDC_ECG_AMPL_ST), MDC_PART_SCADA, 768, "ST amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{string(MDC_ECG_AMPL_P), MDC_PART_SCADA, 16212, "P wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{"MDC_ECG_AMPL_Q", MDC_PART_SCADA, 16216, "Q wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{"MDC_ECG_AMPL_R", MDC_PART_SCADA, 16220, "R wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{"MDC_ECG_AMPL_S", MDC_PART_SCADA, 16224, "S wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{string(MDC_ECG_AMPL_T), MDC_PART_SCADA, 16228, "T wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{"MDC_ECG_AMPL_U", MDC_PART_SCADA, 16232, "U wave amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{string(MDC_ECG_AMPL_QRS), MDC_PART_SCADA, 16236, "QRS peak-to-peak amplitude", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+	{"MDC_ECG_AMPL_ST_J", MDC_PART_SCADA, 16240, "ST amplitude at J point", MDC_CATEGORY_AMPLITUDE, UNIT_MICROVOLT},
+
+	// Rates
+	{string(MDC_ECG_HEART_RATE), MDC_PART_SCADA, 16770, "Heart rate", MDC_CATEGORY_RATE, UNIT_BPM},
+	{string(MDC_ECG_HEART_RATE_ATRIAL), MDC_PART_SCADA, 16774, "Atrial rate", MDC_CATEGORY_RATE, UNIT_BPM},
+	{"MDC_ECG_HEART_RATE_VENT", MDC_PART_SCADA, 16778, "Ventricular rate", MDC_CATEGORY_RATE, UNIT_BPM},
+}
+
+// Indexes of the term table by reference ID, upper-cased reference ID and
+// context-free code.
+var mdcByRefID, mdcByUpperRefID, mdcByCode = indexMDCTerms()
+
+// indexMDCTerms builds the lookup indexes of the term table.
+func indexMDCTerms() (map[string]int, map[string]int, map[uint32]int) {
+	byRefID := make(map[string]int, len(mdcTerms))
+	byUpper := make(map[string]int, len(mdcTerms))
+	byCode := make(map[uint32]int, len(mdcTerms))
+	for i, t := range mdcTerms {
+		byRefID[t.RefID] = i
+		byUpper[strings.ToUpper(t.RefID)] = i
+		byCode[t.CFCode()] = i
+	}
+	return byRefID, byUpper, byCode
+}
+
+// =============================================================================
+// Lookup Functions
+// =============================================================================
+
+// LookupMDC returns the MDC term for a reference ID.
+//
+// The lookup is exact first, then case-insensitive, so that spelling variants
+// found in vendor files (e.g., MDC_ECG_TIME_PD_QTC for MDC_ECG_TIME_PD_QTc)
+// resolve to the same term.
+func LookupMDC(refID string) (MDCTerm, bool) {
+	if i, ok := mdcByRefID[refID]; ok {
+		return mdcTerms[i], true
+	}
+	if i, ok := mdcByUpperRefID[strings.ToUpper(refID)]; ok {
+		return mdcTerms[i], true
+	}
+	return MDCTerm{}, false
+}
+
+// LookupMDCByCode returns the MDC term for a 32-bit context-free code.
+func LookupMDCByCode(cfCode uint32) (MDCTerm, bool) {
+	if i, ok := mdcByCode[cfCode]; ok {
+		return mdcTerms[i], true
+	}
+	return MDCTerm{}, false
+}
+
+// GetMDCTerms returns all terms of a category in table order.
+func GetMDCTerms(category MDCCategory) []MDCTerm {
+	var terms []MDCTerm
+	for _, t := range mdcTerms {
+		if t.Category == category {
+			terms = append(terms, t)
+		}
+	}
+	return terms
+}
+
+// IsMDCLead checks if a code is a lead of the MDC nomenclature.
+func IsMDCLead(code string) bool {
+	t, ok := LookupMDC(code)
+	return ok && t.Category == MDC_CATEGORY_LEAD
+}
+
+// IsMDCWave checks if a code is a wave component of the MDC nomenclature.
+func IsMDCWave(code string) bool {
+	t, ok := LookupMDC(code)
+	return ok && t.Category == MDC_CATEGORY_WAVE
+}
@@ -0,0 +1,93 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestMDCTerms_Unique tests that reference IDs and context-free codes are unique
+func TestMDCTerms_Unique(t *testing.T) {
+	refIDs := make(map[string]bool)
+	codes := make(map[uint32]string)
+	for _, term := range mdcTerms {
+		assert.False(t, refIDs[term.RefID], "duplicate reference ID %s", term.RefID)
+		refIDs[term.RefID] = true
+
+		other, dup := codes[term.CFCode()]
+		assert.False(t, dup, "%s and %s share code %d", term.RefID, other, term.CFCode())
+		codes[term.CFCode()] = term.RefID
+
+		assert.NotEmpty(t, term.DisplayName, term.RefID)
+	}
+}
+
+// TestLookupMDC tests lookup by reference ID and by code
+func TestLookupMDC(t *testing.T) {
+	lead, ok := LookupMDC("MDC_ECG_LEAD_I")
+	require.True(t, ok)
+	assert.Equal(t, MDC_CATEGORY_LEAD, lead.Category)
+	assert.Equal(t, uint32(131329), lead.CFCode())
+
+	byCode, ok := LookupMDCByCode(131329)
+	require.True(t, ok)
+	assert.Equal(t, lead, byCode)
+
+	// Case-insensitive fallback resolves spelling variants
+	qtc, ok := LookupMDC(string(MDC_ECG_TIME_PD_QTC))
+	require.True(t, ok)
+	assert.Equal(t, string(MDC_ECG_TIME_PD_QTc), qtc.RefID)
+	assert.Equal(t, UNIT_MILLISECOND, qtc.Unit)
+
+	_, ok = LookupMDC("MDC_ECG_UNKNOWN")
+	assert.False(t, ok)
+	_, ok = LookupMDCByCode(0)
+	assert.False(t, ok)
+}
+
+// TestGetMDCTerms tests that every category of the ECG partition is populated
+func TestGetMDCTerms(t *testing.T) {
+	categories := []MDCCategory{
+		MDC_CATEGORY_LEAD, MDC_CATEGORY_WAVE, MDC_CATEGORY_BEAT, MDC_CATEGORY_RHYTHM,
+		MDC_CATEGORY_INTERVAL, MDC_CATEGORY_AMPLITUDE, MDC_CATEGORY_ANGLE, MDC_CATEGORY_RATE,
+	}
+	for _, category := range categories {
+		assert.NotEmpty(t, GetMDCTerms(category), category)
+	}
+
+	for _, lead := range GetStandardLeads() {
+		assert.True(t, IsMDCLead(string(lead)), lead)
+	}
+	assert.GreaterOrEqual(t, len(GetMDCTerms(MDC_CATEGORY_WAVE)), 20)
+	assert.True(t, IsMDCWave(string(MDC_ECG_WAVC_JPOINT)))
+	assert.False(t, IsMDCWave(string(MDC_ECG_LEAD_I)))
+}
+
+// TestMDCTerm_AcceptsUnit tests unit compatibility checks
+func TestMDCTerm_AcceptsUnit(t *testing.T) {
+	tests := []struct {
+		refID string
+		unit  string
+		want  bool
+	}{
+		{"MDC_ECG_TIME_PD_QT", "ms", true},
+		{"MDC_ECG_TIME_PD_QT", "s", true},
+		{"MDC_ECG_TIME_PD_QT", "uV", false},
+		{"MDC_ECG_TIME_PD_QT", "", false},
+		{"MDC_ECG_AMPL_T", "mV", true},
+		{"MDC_ECG_AMPL_T", "ms", false},
+		{"MDC_ECG_HEART_RATE", "bpm", true},
+		{"MDC_ECG_HEART_RATE", "/min", true},
+		{"MDC_ECG_ANGLE_QRS_FRONT", "deg", true},
+		{"MDC_ECG_ANGLE_QRS_FRONT", "bpm", false},
+		{"MDC_ECG_LEAD_I", "anything", true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.refID+"/"+tt.unit, func(t *testing.T) {
+			term, ok := LookupMDC(tt.refID)
+			require.True(t, ok)
+			assert.Equal(t, tt.want, term.AcceptsUnit(tt.unit))
+		})
+	}
+}
@@ -0,0 +1,57 @@
+package types
+
+import (
+	"encoding/xml"
+	"reflect"
+)
+
+// =============================================================================
+// XML Namespaces
+// =============================================================================
+
+const (
+	// NAMESPACE_HL7 is the default namespace of every element of an aECG
+	// document.
+	NAMESPACE_HL7 = "urn:hl7-org:v3"
+
+	// NAMESPACE_VOC is the HL7 v3 vocabulary namespace, bound to the "voc"
+	// prefix.
+	NAMESPACE_VOC = "urn:hl7-org:v3/voc"
+
+	// NAMESPACE_XSI is the XML Schema instance namespace, bound to the "xsi"
+	// prefix of xsi:type and xsi:schemaLocation.
+	NAMESPACE_XSI = "http://www.w3.org/2001/XMLSchema-instance"
+)
+
+// MarshalXML encodes the document with the namespace declarations its
+// content relies on.
+//
+// The types write element names without a namespace and the xsi:type and
+// xsi:schemaLocation attributes with their "xsi" prefix, so the root always
+// declares NAMESPACE_HL7 as the default namespace and NAMESPACE_XSI as the
+// "xsi" prefix, even when Xmlns or XmlnsXsi is empty. XmlnsVoc and
+// SchemaLocation are written only when set. Unmodeled elements are written
+// at their position among their siblings (see Extensions).
+func (h *HL7AEcg) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	type alias HL7AEcg
+	doc := alias(*h)
+	if doc.Xmlns == "" {
+		doc.Xmlns = NAMESPACE_HL7
+	}
+	if doc.XmlnsXsi == "" {
+		doc.XmlnsXsi = NAMESPACE_XSI
+	}
+	return marshalElement(e, reflect.ValueOf(&doc).Elem(), xml.StartElement{Name: xml.Name{Local: "AnnotatedECG"}})
+}
+
+// xsiTypeAttr returns the xsi:type attribute of a value.
+func xsiTypeAttr(value string) xml.Attr {
+	return xml.Attr{Name: xml.Name{Local: "xsi:type"}, Value: value}
+}
+
+// isXsiType reports whether the attribute is xsi:type, whatever the prefix
+// it is bound to. Values decoded apart from the document leave the "xsi"
+// prefix unresolved, which is accepted too.
+func isXsiType(name xml.Name) bool {
+	return name.Local == "type" && (name.Space == NAMESPACE_XSI || name.Space == "xsi")
+}
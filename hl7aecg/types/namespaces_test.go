@@ -0,0 +1,153 @@
+package types
+
+import (
+	"encoding/xml"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const prefixedDoc = `<v3:AnnotatedECG xmlns:v3="urn:hl7-org:v3" xmlns:xs="http://www.w3.org/2001/XMLSchema-instance"
+    xs:schemaLocation="urn:hl7-org:v3 PORT_MT020001.xsd">
+  <v3:id root="2.16.840.1.113883.3.1" extension="ECG-1"/>
+  <v3:code code="93000" codeSystem="2.16.840.1.113883.6.12"/>
+  <v3:component>
+    <v3:series>
+      <v3:code code="RHYTHM" codeSystem="2.16.840.1.113883.5.4"/>
+      <v3:controlVariable>
+        <v3:controlVariable>
+          <v3:code code="MDC_ECG_CTL_VBL_ATTR_FILTER_CUTOFF_FREQ"/>
+          <v3:value xs:type="PQ" value="150" unit="Hz"/>
+        </v3:controlVariable>
+      </v3:controlVariable>
+      <v3:component>
+        <v3:sequenceSet>
+          <v3:component>
+            <v3:sequence>
+              <v3:code code="MDC_ECG_LEAD_II" codeSystem="2.16.840.1.113883.6.24"/>
+              <v3:value xs:type="SLIST_PQ">
+                <v3:origin value="0" unit="uV"/>
+                <v3:scale value="5" unit="uV"/>
+                <v3:digits>1 2 3</v3:digits>
+              </v3:value>
+            </v3:sequence>
+          </v3:component>
+        </v3:sequenceSet>
+      </v3:component>
+      <v3:subjectOf>
+        <v3:annotationSet>
+          <v3:component>
+            <v3:annotation>
+              <v3:code code="MDC_ECG_HEART_RATE"/>
+              <v3:value xs:type="PQ" value="72" unit="bpm"/>
+            </v3:annotation>
+          </v3:component>
+          <v3:component>
+            <v3:annotation>
+              <v3:code code="QT_RANGE"/>
+              <v3:value xs:type="IVL_PQ"><v3:low value="390" unit="ms"/></v3:value>
+            </v3:annotation>
+          </v3:component>
+        </v3:annotationSet>
+      </v3:subjectOf>
+      <v3:note>unmodeled</v3:note>
+    </v3:series>
+  </v3:component>
+</v3:AnnotatedECG>`
+
+// TestHL7AEcg_UnmarshalPrefixed tests that a document using prefixes for the
+// HL7 v3 and schema instance namespaces is read as one using the default ones
+func TestHL7AEcg_UnmarshalPrefixed(t *testing.T) {
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(prefixedDoc), &doc))
+
+	assert.Equal(t, "ECG-1", doc.ID.Extension)
+	assert.Equal(t, "urn:hl7-org:v3 PORT_MT020001.xsd", doc.SchemaLocation)
+
+	series := doc.Component[0].Series
+	cv := series.ControlVariable[0].ControlVariable
+	assert.Equal(t, "PQ", cv.Value.XsiType)
+	assert.Nil(t, cv.Value.ExtraAttrs)
+
+	seq := series.Component[0].SequenceSet.Component[0].Sequence
+	require.IsType(t, &SLIST_PQ{}, seq.Value.Typed)
+	assert.Equal(t, "1 2 3", seq.Value.Typed.(*SLIST_PQ).Digits)
+	assert.Nil(t, seq.Value.ExtraAttrs)
+
+	set := series.GetAnnotationSets()[0]
+	hr := set.GetAnnotationByCode("MDC_ECG_HEART_RATE").Value
+	require.IsType(t, &PhysicalQuantity{}, hr.Typed)
+	assert.Equal(t, "72", hr.Typed.(*PhysicalQuantity).Value)
+	assert.Nil(t, hr.ExtraAttrs)
+	assert.Equal(t, "IVL_PQ", set.GetAnnotationByCode("QT_RANGE").Value.XsiType)
+
+	require.Len(t, series.ExtraElements, 1)
+	assert.Equal(t, xml.Name{Local: "note"}, series.ExtraElements[0].XMLName, "HL7 v3 names are unprefixed")
+}
+
+// TestHL7AEcg_MarshalNamespaces tests the namespace declarations of the root element
+func TestHL7AEcg_MarshalNamespaces(t *testing.T) {
+	tests := []struct {
+		name     string
+		doc      HL7AEcg
+		want     []string
+		unwanted []string
+	}{
+		{
+			name:     "defaults",
+			doc:      HL7AEcg{},
+			want:     []string{`xmlns="urn:hl7-org:v3"`, `xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`},
+			unwanted: []string{"xmlns:voc", "schemaLocation"},
+		},
+		{
+			name: "declared",
+			doc: HL7AEcg{
+				Xmlns: NAMESPACE_HL7, XmlnsVoc: NAMESPACE_VOC, XmlnsXsi: NAMESPACE_XSI,
+				SchemaLocation: "urn:hl7-org:v3 PORT_MT020001.xsd",
+			},
+			want: []string{
+				`xmlns="urn:hl7-org:v3"`, `xmlns:voc="urn:hl7-org:v3/voc"`,
+				`xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance"`,
+				`xsi:schemaLocation="urn:hl7-org:v3 PORT_MT020001.xsd"`,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			data, err := xml.Marshal(&tt.doc)
+			require.NoError(t, err)
+			root, _, _ := strings.Cut(string(data), ">")
+
+			assert.True(t, strings.HasPrefix(root, "<AnnotatedECG "))
+			for _, want := range tt.want {
+				assert.Contains(t, root, want)
+				assert.Equal(t, 1, strings.Count(root, want), "declared once")
+			}
+			for _, unwanted := range tt.unwanted {
+				assert.NotContains(t, root, unwanted)
+			}
+		})
+	}
+}
+
+// TestHL7AEcg_MarshalPrefixed tests that a prefixed document is written back
+// in the default namespaces, and reads back the same
+func TestHL7AEcg_MarshalPrefixed(t *testing.T) {
+	var doc HL7AEcg
+	require.NoError(t, xml.Unmarshal([]byte(prefixedDoc), &doc))
+	data, err := xml.Marshal(&doc)
+	require.NoError(t, err)
+
+	out := string(data)
+	assert.Contains(t, out, `<value xsi:type="SLIST_PQ">`)
+	assert.Contains(t, out, `<value xsi:type="PQ" value="150" unit="Hz">`)
+	assert.Contains(t, out, `<note>unmodeled</note>`)
+	assert.NotContains(t, out, "xs:type")
+
+	var back HL7AEcg
+	require.NoError(t, xml.Unmarshal(data, &back))
+	assert.True(t, back.Equal(&doc))
+}
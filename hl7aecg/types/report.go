@@ -0,0 +1,74 @@
+package types
+
+import (
+	"errors"
+	"strings"
+)
+
+// =============================================================================
+// Validation Report
+// =============================================================================
+
+// ValidationIssue is one validation error in a ValidationReport.
+type ValidationIssue struct {
+	// Field is the field that failed validation (e.g. "effectiveTime").
+	// Issues with the same Field come from the same rule.
+	Field string `json:"field"`
+
+	// Path locates the field when the error was reported by a nested
+	// structure (e.g. "annotationSet.component[2]"); empty otherwise.
+	Path string `json:"path,omitempty"`
+
+	// Message describes the failure.
+	Message string `json:"message"`
+
+	// Value is the invalid value, if any.
+	Value string `json:"value,omitempty"`
+}
+
+// ValidationReport is a structured form of the errors and warnings
+// collected in a ValidationContext, suitable for JSON output.
+type ValidationReport struct {
+	Valid    bool              `json:"valid"`
+	Errors   []ValidationIssue `json:"errors"`
+	Warnings []string          `json:"warnings"`
+}
+
+// Report returns the errors and warnings collected so far.
+//
+// Errors wrapping a *ValidationError keep its field, message and value; the
+// wrapping prefix becomes the issue path. Other errors are reported with an
+// empty field.
+func (ctx *ValidationContext) Report() *ValidationReport {
+	report := &ValidationReport{
+		Valid:    !ctx.HasErrors(),
+		Errors:   make([]ValidationIssue, 0, len(ctx.Errors)),
+		Warnings: append([]string{}, ctx.Warnings...),
+	}
+	for _, err := range ctx.Errors {
+		report.Errors = append(report.Errors, newValidationIssue(err))
+	}
+	return report
+}
+
+// newValidationIssue converts a collected error to an issue.
+func newValidationIssue(err error) ValidationIssue {
+	var ve *ValidationError
+	if !errors.As(err, &ve) {
+		return ValidationIssue{Message: err.Error()}
+	}
+	issue := ValidationIssue{Field: ve.Field, Message: ve.Message, Value: ve.Value}
+	if prefix, ok := strings.CutSuffix(err.Error(), ve.Error()); ok {
+		issue.Path = strings.TrimSuffix(prefix, ": ")
+	}
+	return issue
+}
+
+// Rules returns the number of errors per field, for aggregate reports.
+func (r *ValidationReport) Rules() map[string]int {
+	counts := make(map[string]int)
+	for _, issue := range r.Errors {
+		counts[issue.Field]++
+	}
+	return counts
+}
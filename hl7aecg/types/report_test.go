@@ -0,0 +1,34 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestValidationContext_Report tests the conversion of collected errors to issues
+func TestValidationContext_Report(t *testing.T) {
+	vctx := NewValidationContext(true)
+	report := vctx.Report()
+	assert.True(t, report.Valid)
+	assert.NotNil(t, report.Errors)
+	assert.NotNil(t, report.Warnings)
+
+	vctx.AddError(ErrMissingID)
+	vctx.AddError(fmt.Errorf("annotationSet.component[2]: %w",
+		NewValidationErrorWithValue("Annotation.Value", "unit not allowed", "furlong")))
+	vctx.AddError(errors.New("something else"))
+	vctx.AddWarning("no device")
+
+	report = vctx.Report()
+	assert.False(t, report.Valid)
+	assert.Equal(t, []ValidationIssue{
+		{Field: "ID", Message: "ID.Root is required"},
+		{Field: "Annotation.Value", Path: "annotationSet.component[2]", Message: "unit not allowed", Value: "furlong"},
+		{Message: "something else"},
+	}, report.Errors)
+	assert.Equal(t, []string{"no device"}, report.Warnings)
+	assert.Equal(t, map[string]int{"ID": 1, "Annotation.Value": 1, "": 1}, report.Rules())
+}
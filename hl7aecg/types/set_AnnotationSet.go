@@ -0,0 +1,107 @@
+package types
+
+// AddPersonAuthor attributes the annotation set to a person (e.g., a core lab reader).
+//
+// Parameters:
+//   - function: The author function (e.g., AUTHOR_FUNCTION_PRIMARY_READER); empty to omit
+//   - root: The root OID or UUID of the reader ID
+//   - extension: The reader identifier
+//   - name: The reader name (can be empty for blinded reads)
+//
+// Returns the AnnotationSet for method chaining.
+func (as *AnnotationSet) AddPersonAuthor(function AuthorFunctionCode, root, extension, name string) *AnnotationSet {
+	if as == nil {
+		return nil
+	}
+	author := newAnnotationSetAuthor(function, root, extension)
+	author.AssignedEntity.AssignedPerson = &AssignedPerson{}
+	if name != "" {
+		author.AssignedEntity.AssignedPerson.Name = &name
+	}
+	as.Author = append(as.Author, author)
+	return as
+}
+
+// AddDeviceAuthor attributes the annotation set to a device or algorithm.
+//
+// Parameters:
+//   - function: The author function (e.g., AUTHOR_FUNCTION_ALGORITHM); empty to omit
+//   - root: The root OID or UUID of the device ID
+//   - extension: The device or algorithm identifier (e.g., version)
+//   - modelName: The manufacturer model name (optional)
+//   - softwareName: The software name (optional)
+//
+// Returns the AnnotationSet for method chaining.
+func (as *AnnotationSet) AddDeviceAuthor(function AuthorFunctionCode, root, extension, modelName, softwareName string) *AnnotationSet {
+	if as == nil {
+		return nil
+	}
+	author := newAnnotationSetAuthor(function, root, extension)
+	author.AssignedEntity.AssignedDevice = &ManufacturedSeriesDevice{}
+	if modelName != "" {
+		author.AssignedEntity.AssignedDevice.ManufacturerModelName = &modelName
+	}
+	if softwareName != "" {
+		author.AssignedEntity.AssignedDevice.SoftwareName = &softwareName
+	}
+	as.Author = append(as.Author, author)
+	return as
+}
+
+// newAnnotationSetAuthor builds an author with an optional function code and ID.
+func newAnnotationSetAuthor(function AuthorFunctionCode, root, extension string) AnnotationSetAuthor {
+	author := AnnotationSetAuthor{}
+	if function != "" {
+		author.FunctionCode = &Code[AuthorFunctionCode, CodeSystemOID]{}
+		author.FunctionCode.SetCode(function, "", "", "")
+	}
+	if root != "" || extension != "" {
+		author.AssignedEntity.ID = &ID{}
+		author.AssignedEntity.ID.SetID(root, extension)
+	}
+	return author
+}
+
+// HasAuthorFunction reports whether one of the authors has the given function code.
+func (as *AnnotationSet) HasAuthorFunction(function AuthorFunctionCode) bool {
+	if as == nil {
+		return false
+	}
+	for _, author := range as.Author {
+		if author.FunctionCode != nil && author.FunctionCode.Code == function {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAuthorID reports whether one of the authors has the given ID.
+// An empty extension matches any extension.
+func (as *AnnotationSet) HasAuthorID(root, extension string) bool {
+	if as == nil {
+		return false
+	}
+	for _, author := range as.Author {
+		id := author.AssignedEntity.ID
+		if id == nil || id.Root != root {
+			continue
+		}
+		if extension == "" || id.Extension == extension {
+			return true
+		}
+	}
+	return false
+}
+
+// IsDeviceAuthored reports whether the annotation set was produced by a device or algorithm.
+func (as *AnnotationSet) IsDeviceAuthored() bool {
+	if as == nil {
+		return false
+	}
+	for _, author := range as.Author {
+		if author.AssignedEntity.AssignedDevice != nil {
+			return true
+		}
+	}
+	return false
+}
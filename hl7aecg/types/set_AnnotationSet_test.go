@@ -0,0 +1,152 @@
+package types
+
+import (
+	"context"
+	"encoding/xml"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testReaderRoot = "2.16.840.1.113883.3.5"
+
+// newCoreLabSeries builds a series with a machine read, two human reads and an adjudication.
+func newCoreLabSeries() *Series {
+	series := &Series{}
+	series.AddAnnotationSet("20250923103600").
+		AddDeviceAuthor(AUTHOR_FUNCTION_ALGORITHM, testReaderRoot, "MEASURE-1.0", "", "hl7v3-aecg").
+		AddQTInterval(402)
+	series.AddAnnotationSet("20250924090000").
+		AddPersonAuthor(AUTHOR_FUNCTION_PRIMARY_READER, testReaderRoot, "READER-01", "").
+		AddQTInterval(398)
+	series.AddAnnotationSet("20250924093000").
+		AddPersonAuthor(AUTHOR_FUNCTION_SECONDARY_READER, testReaderRoot, "READER-02", "").
+		AddQTInterval(410)
+	series.AddAnnotationSet("20250925080000").
+		AddPersonAuthor(AUTHOR_FUNCTION_ADJUDICATOR, testReaderRoot, "ADJ-01", "Dr. Adjudicator").
+		AddQTInterval(404)
+	return series
+}
+
+// TestSeries_MultipleAnnotationSets tests that annotation sets accumulate instead of overwriting
+func TestSeries_MultipleAnnotationSets(t *testing.T) {
+	series := newCoreLabSeries()
+
+	sets := series.GetAnnotationSets()
+	require.Len(t, sets, 4)
+	require.Len(t, series.SubjectOf, 4)
+	assert.Equal(t, "20250923103600", sets[0].ActivityTime.Value)
+	assert.Equal(t, "20250925080000", sets[3].ActivityTime.Value)
+
+	// GetOrCreateAnnotationSet returns the first set without creating a new one
+	assert.Same(t, sets[0], series.GetOrCreateAnnotationSet("20990101000000"))
+	assert.Len(t, series.GetAnnotationSets(), 4)
+
+	// InitAnnotationSet reuses an empty SubjectOf slot
+	series.SubjectOf = append(series.SubjectOf, SubjectOf{})
+	series.InitAnnotationSet("20250926000000")
+	assert.Len(t, series.SubjectOf, 5)
+	assert.Len(t, series.GetAnnotationSets(), 5)
+}
+
+// TestSeries_AnnotationSetLookup tests lookup by author function and ID
+func TestSeries_AnnotationSetLookup(t *testing.T) {
+	series := newCoreLabSeries()
+
+	machine := series.GetAnnotationSetsByAuthorFunction(AUTHOR_FUNCTION_ALGORITHM)
+	require.Len(t, machine, 1)
+	assert.True(t, machine[0].IsDeviceAuthored())
+
+	primary := series.GetAnnotationSetsByAuthorFunction(AUTHOR_FUNCTION_PRIMARY_READER)
+	require.Len(t, primary, 1)
+	assert.False(t, primary[0].IsDeviceAuthored())
+	qt, _ := primary[0].GetAnnotationByCode(string(MDC_ECG_TIME_PD_QT)).GetValueFloat()
+	assert.Equal(t, 398.0, qt)
+
+	reader2 := series.GetAnnotationSetByAuthorID(testReaderRoot, "READER-02")
+	require.NotNil(t, reader2)
+	assert.True(t, reader2.HasAuthorFunction(AUTHOR_FUNCTION_SECONDARY_READER))
+
+	assert.NotNil(t, series.GetAnnotationSetByAuthorID(testReaderRoot, ""))
+	assert.Nil(t, series.GetAnnotationSetByAuthorID(testReaderRoot, "UNKNOWN"))
+	assert.Empty(t, series.GetAnnotationSetsByAuthorFunction("UNKNOWN"))
+}
+
+// TestSeries_AnnotationSetAuthors_RoundTrip tests that authors survive marshal and unmarshal
+func TestSeries_AnnotationSetAuthors_RoundTrip(t *testing.T) {
+	series := newCoreLabSeries()
+
+	data, err := xml.Marshal(series)
+	require.NoError(t, err)
+	xmlStr := string(data)
+	assert.Contains(t, xmlStr, `<functionCode code="ALGORITHM" codeSystem=""></functionCode>`)
+	assert.Contains(t, xmlStr, `<softwareName>hl7v3-aecg</softwareName>`)
+	assert.Contains(t, xmlStr, `<name>Dr. Adjudicator</name>`)
+
+	var decoded Series
+	require.NoError(t, xml.Unmarshal(data, &decoded))
+
+	sets := decoded.GetAnnotationSets()
+	require.Len(t, sets, 4)
+	for i, original := range series.GetAnnotationSets() {
+		assert.Equal(t, original.Author, sets[i].Author, "annotation set %d", i)
+	}
+
+	adjudication := decoded.GetAnnotationSetByAuthorID(testReaderRoot, "ADJ-01")
+	require.NotNil(t, adjudication)
+	require.NotNil(t, adjudication.Author[0].AssignedEntity.AssignedPerson.Name)
+	assert.Equal(t, "Dr. Adjudicator", *adjudication.Author[0].AssignedEntity.AssignedPerson.Name)
+}
+
+// TestAnnotationSet_ValidateAuthor tests author validation
+func TestAnnotationSet_ValidateAuthor(t *testing.T) {
+	tests := []struct {
+		name    string
+		author  AnnotationSetAuthor
+		wantErr bool
+	}{
+		{
+			name: "Person author",
+			author: AnnotationSetAuthor{AssignedEntity: AnnotationAssignedEntity{
+				AssignedPerson: &AssignedPerson{},
+			}},
+		},
+		{
+			name:    "Neither person nor device",
+			author:  AnnotationSetAuthor{},
+			wantErr: true,
+		},
+		{
+			name: "Both person and device",
+			author: AnnotationSetAuthor{AssignedEntity: AnnotationAssignedEntity{
+				AssignedPerson: &AssignedPerson{},
+				AssignedDevice: &ManufacturedSeriesDevice{},
+			}},
+			wantErr: true,
+		},
+		{
+			name: "Empty function code",
+			author: AnnotationSetAuthor{
+				FunctionCode:   &Code[AuthorFunctionCode, CodeSystemOID]{},
+				AssignedEntity: AnnotationAssignedEntity{AssignedPerson: &AssignedPerson{}},
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			as := &AnnotationSet{
+				ActivityTime: &Time{Value: "20250923103600"},
+				Author:       []AnnotationSetAuthor{tt.author},
+			}
+			err := as.Validate(context.Background(), NewValidationContext(false))
+			if tt.wantErr {
+				assert.Error(t, err)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
@@ -53,3 +53,104 @@ func (ct *ClinicalTrial) SetActivityTimeHigh(high string) *ClinicalTrial {
 	ct.ActivityTime.High = Time{Value: high}
 	return ct
 }
+
+// SetTitle sets the human-readable name of the clinical trial.
+//
+// Example:
+//
+//	ct.SetTitle("Cardiac Safety Trial 1 for Compound PUK-123")
+//
+// Returns the ClinicalTrial for method chaining.
+func (ct *ClinicalTrial) SetTitle(title string) *ClinicalTrial {
+	ct.Title = &title
+	return ct
+}
+
+// =============================================================================
+// Protocol and Sponsor
+// =============================================================================
+
+// SetProtocol sets the protocol the clinical trial follows.
+//
+// Parameters:
+//   - root: Protocol OID; it is also the code system of protocol-defined codes
+//     (treatment groups, timepoints, reference events)
+//   - extension: Traditional protocol identifier (e.g., "PUK-123-PROT-A")
+//   - title: Protocol title, empty to omit
+//
+// Example:
+//
+//	ct.SetProtocol("2.16.840.1.113883.3.2", "PUK-123-PROT-A", "Cardiac Safety Protocol for Compound PUK-123")
+//
+// XML Output:
+//
+//	<definition>
+//	  <clinicalTrialProtocol>
+//	    <id root="2.16.840.1.113883.3.2" extension="PUK-123-PROT-A"/>
+//	    <title>Cardiac Safety Protocol for Compound PUK-123</title>
+//	  </clinicalTrialProtocol>
+//	</definition>
+//
+// Returns the ClinicalTrial for method chaining.
+func (ct *ClinicalTrial) SetProtocol(root, extension, title string) *ClinicalTrial {
+	if ct.Definition == nil {
+		ct.Definition = &ClinicalTrialDefinition{}
+	}
+	protocol := &ct.Definition.ClinicalTrialProtocol
+	protocol.SetID(root, extension)
+	if title != "" {
+		protocol.Title = &title
+	}
+	return ct
+}
+
+// GetProtocol returns the protocol of the clinical trial, or nil if not set.
+func (ct *ClinicalTrial) GetProtocol() *ClinicalTrialProtocol {
+	if ct == nil || ct.Definition == nil {
+		return nil
+	}
+	return &ct.Definition.ClinicalTrialProtocol
+}
+
+// SetSponsor sets the organization sponsoring the clinical trial.
+//
+// Parameters:
+//   - root: Sponsor organization OID
+//   - extension: Well-known sponsor identifier (e.g., "ABC-PHARMA"), empty to omit
+//   - name: Organization name, empty to omit
+//
+// Example:
+//
+//	ct.SetSponsor("2.16.840.1.113883.3", "ABC-PHARMA", "ABC Drug Company")
+//
+// XML Output:
+//
+//	<author>
+//	  <clinicalTrialSponsor>
+//	    <sponsorOrganization>
+//	      <id root="2.16.840.1.113883.3" extension="ABC-PHARMA"/>
+//	      <name>ABC Drug Company</name>
+//	    </sponsorOrganization>
+//	  </clinicalTrialSponsor>
+//	</author>
+//
+// Returns the ClinicalTrial for method chaining.
+func (ct *ClinicalTrial) SetSponsor(root, extension, name string) *ClinicalTrial {
+	if ct.Author == nil {
+		ct.Author = &ClinicalTrialAuthor{}
+	}
+	org := &ct.Author.ClinicalTrialSponsor.SponsorOrganization
+	org.ID.SetID(root, extension)
+	if name != "" {
+		org.Name = &name
+	}
+	return ct
+}
+
+// GetSponsor returns the sponsoring organization, or nil if not set.
+func (ct *ClinicalTrial) GetSponsor() *SponsorOrganization {
+	if ct == nil || ct.Author == nil {
+		return nil
+	}
+	return &ct.Author.ClinicalTrialSponsor.SponsorOrganization
+}
@@ -0,0 +1,183 @@
+package types
+
+// =============================================================================
+// Adding Related Observations
+// =============================================================================
+
+// AddRelatedObservation adds a numeric observation about the subject.
+//
+// Parameters:
+//   - code: The observation code (e.g., OBS_AGE)
+//   - codeSystem: The code system OID; empty defaults to LOINC
+//   - value: The numeric value
+//   - unit: The UCUM unit (e.g., "a", "kg", "mm[Hg]")
+//
+// Returns:
+//   - int: Index of the new observation (use GetRelatedObservation to retrieve safely),
+//     or -1 if the code is empty or the value is not a finite number
+//
+// Example:
+//
+//	idx := sa.AddRelatedObservation(OBS_WEIGHT, "", 72.5, UNIT_KILOGRAM)
+func (sa *SubjectAssignment) AddRelatedObservation(code ObservationCode, codeSystem CodeSystemOID, value float64, unit string) int {
+	if sa == nil || code == "" || isInvalidFloat(value) {
+		return -1
+	}
+	obs := newRelatedObservation(code, codeSystem)
+	obs.Value = &AnnotationValue{
+		XsiType: "PQ",
+		Typed: &PhysicalQuantity{
+			XsiType: "PQ",
+			Value:   formatFloat(value),
+			Unit:    unit,
+		},
+	}
+	sa.SubjectOf = append(sa.SubjectOf, SubjectAssignmentSubjectOf{RelatedObservation: obs})
+	return len(sa.SubjectOf) - 1
+}
+
+// AddCodedObservation adds a coded observation about the subject.
+//
+// Returns the index of the new observation, or -1 if the code or the value code is empty.
+//
+// Example:
+//
+//	idx := sa.AddCodedObservation("72166-2", "", CodedValue{
+//	    Code: "LA18978-9", CodeSystem: string(LOINC_OID), DisplayName: "Never smoker",
+//	})
+func (sa *SubjectAssignment) AddCodedObservation(code ObservationCode, codeSystem CodeSystemOID, value CodedValue) int {
+	if sa == nil || code == "" || value.Code == "" {
+		return -1
+	}
+	obs := newRelatedObservation(code, codeSystem)
+	value.XsiType = "CE"
+	obs.Value = &AnnotationValue{XsiType: "CE", Typed: &value}
+	sa.SubjectOf = append(sa.SubjectOf, SubjectAssignmentSubjectOf{RelatedObservation: obs})
+	return len(sa.SubjectOf) - 1
+}
+
+// AddTextObservation adds a free-text observation about the subject
+// (e.g., a concomitant medication).
+//
+// Returns the index of the new observation, or -1 if the code or the text is empty.
+func (sa *SubjectAssignment) AddTextObservation(code ObservationCode, codeSystem CodeSystemOID, text string) int {
+	if sa == nil || code == "" || text == "" {
+		return -1
+	}
+	obs := newRelatedObservation(code, codeSystem)
+	obs.Value = &AnnotationValue{XsiType: "ST", Typed: &StringValue{XsiType: "ST", Value: text}}
+	sa.SubjectOf = append(sa.SubjectOf, SubjectAssignmentSubjectOf{RelatedObservation: obs})
+	return len(sa.SubjectOf) - 1
+}
+
+// newRelatedObservation builds an observation with its code, defaulting to LOINC.
+func newRelatedObservation(code ObservationCode, codeSystem CodeSystemOID) RelatedObservation {
+	if codeSystem == "" {
+		codeSystem = LOINC_OID
+	}
+	obs := RelatedObservation{Code: &Code[ObservationCode, CodeSystemOID]{}}
+	obs.Code.SetCode(code, codeSystem, "", GetObservationDisplayName(code))
+	return obs
+}
+
+// =============================================================================
+// Reading Related Observations
+// =============================================================================
+
+// GetRelatedObservation returns the observation at the given index, or nil if out of range.
+func (sa *SubjectAssignment) GetRelatedObservation(index int) *RelatedObservation {
+	if sa == nil || index < 0 || index >= len(sa.SubjectOf) {
+		return nil
+	}
+	return &sa.SubjectOf[index].RelatedObservation
+}
+
+// GetRelatedObservationByCode returns the first observation with the given code, or nil.
+func (sa *SubjectAssignment) GetRelatedObservationByCode(code ObservationCode) *RelatedObservation {
+	if sa == nil {
+		return nil
+	}
+	for i := range sa.SubjectOf {
+		obs := &sa.SubjectOf[i].RelatedObservation
+		if obs.Code != nil && obs.Code.Code == code {
+			return obs
+		}
+	}
+	return nil
+}
+
+// GetValueFloat returns the numeric value of the observation.
+// Returns (value, true) if the value is numeric, (0, false) otherwise.
+func (ro *RelatedObservation) GetValueFloat() (float64, bool) {
+	if ro == nil || ro.Value == nil {
+		return 0, false
+	}
+	return ro.Value.GetValueFloat()
+}
+
+// GetValueUnit returns the unit of the observation value, or "" if not numeric.
+func (ro *RelatedObservation) GetValueUnit() string {
+	if ro == nil || ro.Value == nil {
+		return ""
+	}
+	return ro.Value.GetValueUnit()
+}
+
+// =============================================================================
+// Observation Authors
+// =============================================================================
+
+// SetPersonAuthor attributes the observation to a person.
+//
+// Parameters:
+//   - time: When the observation was made (YYYYMMDDHHmmss); empty to omit
+//   - root: The root OID or UUID of the person ID; empty to omit the ID
+//   - extension: The person identifier
+//   - name: The person name (optional)
+//
+// Returns the RelatedObservation for method chaining.
+func (ro *RelatedObservation) SetPersonAuthor(time, root, extension, name string) *RelatedObservation {
+	if ro == nil {
+		return nil
+	}
+	ro.Author = newRelatedObservationAuthor(time, root, extension)
+	ro.Author.AssignedEntity.AssignedPerson = &AssignedPerson{}
+	if name != "" {
+		ro.Author.AssignedEntity.AssignedPerson.Name = &name
+	}
+	return ro
+}
+
+// SetDeviceAuthor attributes the observation to a device (e.g., a blood pressure monitor).
+//
+// Parameters:
+//   - time: When the observation was made (YYYYMMDDHHmmss); empty to omit
+//   - root: The root OID of the device ID; empty to omit the ID
+//   - extension: The device identifier (e.g., serial number)
+//   - modelName: The manufacturer model name (optional)
+//
+// Returns the RelatedObservation for method chaining.
+func (ro *RelatedObservation) SetDeviceAuthor(time, root, extension, modelName string) *RelatedObservation {
+	if ro == nil {
+		return nil
+	}
+	ro.Author = newRelatedObservationAuthor(time, root, extension)
+	ro.Author.AssignedEntity.AssignedDevice = &ManufacturedSeriesDevice{}
+	if modelName != "" {
+		ro.Author.AssignedEntity.AssignedDevice.ManufacturerModelName = &modelName
+	}
+	return ro
+}
+
+// newRelatedObservationAuthor builds an author with an optional time and ID.
+func newRelatedObservationAuthor(time, root, extension string) *RelatedObservationAuthor {
+	author := &RelatedObservationAuthor{}
+	if time != "" {
+		author.Time = &Time{Value: time}
+	}
+	if root != "" || extension != "" {
+		author.AssignedEntity.ID = &ID{}
+		author.AssignedEntity.ID.SetID(root, extension)
+	}
+	return author
+}
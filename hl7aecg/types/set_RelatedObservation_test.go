@@ -0,0 +1,109 @@
+package types
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestSubjectAssignment_AddRelatedObservation tests adding observations of each value type
+func TestSubjectAssignment_AddRelatedObservation(t *testing.T) {
+	sa := &SubjectAssignment{}
+
+	idx := sa.AddRelatedObservation(OBS_WEIGHT, "", 72.5, UNIT_KILOGRAM)
+	require.Equal(t, 0, idx)
+	weight := sa.GetRelatedObservation(idx)
+	assert.Equal(t, LOINC_OID, weight.Code.CodeSystem)
+	assert.Equal(t, "Body weight", weight.Code.DisplayName)
+	v, ok := weight.GetValueFloat()
+	assert.True(t, ok)
+	assert.Equal(t, 72.5, v)
+
+	idx = sa.AddCodedObservation("72166-2", "", CodedValue{Code: "LA18978-9", DisplayName: "Never smoker"})
+	require.Equal(t, 1, idx)
+	ce, ok := sa.GetRelatedObservation(idx).Value.GetCoded()
+	require.True(t, ok)
+	assert.Equal(t, "CE", ce.XsiType)
+
+	idx = sa.AddTextObservation("10160-0", "", "Metoprolol 50 mg")
+	require.Equal(t, 2, idx)
+	text, _ := sa.GetRelatedObservation(idx).Value.GetText()
+	assert.Equal(t, "Metoprolol 50 mg", text)
+
+	// Invalid inputs
+	assert.Equal(t, -1, sa.AddRelatedObservation("", "", 1, "a"))
+	assert.Equal(t, -1, sa.AddRelatedObservation(OBS_AGE, "", math.Inf(1), "a"))
+	assert.Equal(t, -1, sa.AddCodedObservation(OBS_AGE, "", CodedValue{}))
+	assert.Equal(t, -1, sa.AddTextObservation(OBS_AGE, "", ""))
+	assert.Nil(t, sa.GetRelatedObservation(3))
+	assert.Nil(t, sa.GetRelatedObservationByCode(OBS_AGE))
+	assert.Same(t, sa.GetRelatedObservation(0), sa.GetRelatedObservationByCode(OBS_WEIGHT))
+}
+
+// TestRelatedObservation_Validate tests related observation validation
+func TestRelatedObservation_Validate(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name    string
+		obs     func() *RelatedObservation
+		wantErr bool
+	}{
+		{
+			name: "valid person-authored observation",
+			obs: func() *RelatedObservation {
+				sa := &SubjectAssignment{}
+				return sa.GetRelatedObservation(sa.AddRelatedObservation(OBS_AGE, "", 34, UNIT_YEAR)).
+					SetPersonAuthor("20021122091000", "", "NURSE-12", "")
+			},
+		},
+		{
+			name: "valid device-authored observation",
+			obs: func() *RelatedObservation {
+				sa := &SubjectAssignment{}
+				return sa.GetRelatedObservation(sa.AddRelatedObservation(OBS_BP_SYSTOLIC, "", 120, UNIT_MMHG)).
+					SetDeviceAuthor("", "", "", "BP-200")
+			},
+		},
+		{
+			name:    "missing code",
+			obs:     func() *RelatedObservation { return &RelatedObservation{} },
+			wantErr: true,
+		},
+		{
+			name: "non-numeric PQ value",
+			obs: func() *RelatedObservation {
+				return &RelatedObservation{
+					Code:  &Code[ObservationCode, CodeSystemOID]{Code: OBS_AGE, CodeSystem: LOINC_OID},
+					Value: &AnnotationValue{XsiType: "PQ", Typed: &PhysicalQuantity{Value: "thirty"}},
+				}
+			},
+			wantErr: true,
+		},
+		{
+			name: "author without person or device",
+			obs: func() *RelatedObservation {
+				return &RelatedObservation{
+					Code:   &Code[ObservationCode, CodeSystemOID]{Code: OBS_AGE, CodeSystem: LOINC_OID},
+					Author: &RelatedObservationAuthor{},
+				}
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vctx := NewValidationContext(false)
+			tt.obs().Validate(ctx, vctx)
+			if tt.wantErr {
+				assert.Error(t, vctx.GetError())
+			} else {
+				assert.NoError(t, vctx.GetError())
+			}
+		})
+	}
+}
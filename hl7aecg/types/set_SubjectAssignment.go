@@ -0,0 +1,59 @@
+package types
+
+// =============================================================================
+// Treatment Group Assignment
+// =============================================================================
+
+// SetTreatmentGroup assigns the subject to a sponsor-defined treatment arm.
+//
+// Parameters:
+//   - code: The arm code (e.g., "PLACEBO", "MOXI_400")
+//   - codeSystem: The OID of the protocol or trial defining the arm
+//   - displayName: Human-readable arm name (e.g., "Moxifloxacin 400 mg"), empty to omit
+//
+// Example:
+//
+//	sa.SetTreatmentGroup("MOXI_400", "2.16.840.1.113883.3.1", "Moxifloxacin 400 mg")
+//
+// XML Output:
+//
+//	<definition>
+//	  <treatmentGroupAssignment>
+//	    <code code="MOXI_400" codeSystem="2.16.840.1.113883.3.1" displayName="Moxifloxacin 400 mg"/>
+//	  </treatmentGroupAssignment>
+//	</definition>
+//
+// Returns the SubjectAssignment for method chaining.
+func (sa *SubjectAssignment) SetTreatmentGroup(code TreatmentGroupCode, codeSystem CodeSystemOID, displayName string) *SubjectAssignment {
+	if sa.Definition == nil {
+		sa.Definition = &SubjectAssignmentDefinition{}
+	}
+	sa.Definition.TreatmentGroupAssignment.Code.SetCode(code, codeSystem, "", displayName)
+	return sa
+}
+
+// SetBlindedTreatmentGroup assigns the subject to the masked treatment group
+// (TREATMENT_GROUP_BLINDED), hiding the actual arm.
+//
+// Use this in documents whose ConfidentialityCode blinds the sponsor or the
+// investigator.
+//
+// Returns the SubjectAssignment for method chaining.
+func (sa *SubjectAssignment) SetBlindedTreatmentGroup(codeSystem CodeSystemOID) *SubjectAssignment {
+	return sa.SetTreatmentGroup(TREATMENT_GROUP_BLINDED, codeSystem, "Blinded")
+}
+
+// GetTreatmentGroup returns the treatment group code, or nil if the subject
+// is not assigned to a group.
+func (sa *SubjectAssignment) GetTreatmentGroup() *Code[TreatmentGroupCode, CodeSystemOID] {
+	if sa == nil || sa.Definition == nil {
+		return nil
+	}
+	return &sa.Definition.TreatmentGroupAssignment.Code
+}
+
+// IsTreatmentGroupBlinded reports whether the treatment group is masked.
+func (sa *SubjectAssignment) IsTreatmentGroupBlinded() bool {
+	group := sa.GetTreatmentGroup()
+	return group != nil && group.Code == TREATMENT_GROUP_BLINDED
+}
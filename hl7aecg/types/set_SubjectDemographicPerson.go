@@ -105,7 +105,10 @@ func (s *SubjectDemographicPerson) SetPaced(paced bool) *SubjectDemographicPerso
 //	AddMedication("Aspirin 100mg").
 //	AddMedication("Metoprolol 50mg")
 func (s *SubjectDemographicPerson) AddMedication(medication string) *SubjectDemographicPerson {
-	// Remove empty default entry if present
+	if s.Medications == nil {
+		s.Medications = &Medications{}
+	}
+	// Remove empty placeholder entry if present
 	if len(s.Medications.Medication) == 1 && s.Medications.Medication[0] == "" {
 		s.Medications.Medication = []string{}
 	}
@@ -119,11 +122,9 @@ func (s *SubjectDemographicPerson) AddMedication(medication string) *SubjectDemo
 //
 // Example: SetMedications().AddMedication("Aspirin")
 func (s *SubjectDemographicPerson) SetMedications() *SubjectDemographicPerson {
-	s.Medications = Medications{
+	s.Medications = &Medications{
 		Medication: []string{},
 	}
-	// Add one empty entry by default
-	s.Medications.Medication = append(s.Medications.Medication, "")
 	return s
 }
 
@@ -136,7 +137,10 @@ func (s *SubjectDemographicPerson) SetMedications() *SubjectDemographicPerson {
 //	AddClinicalClassification("Hypertension").
 //	AddClinicalClassification("Diabetes Type 2")
 func (s *SubjectDemographicPerson) AddClinicalClassification(classification string) *SubjectDemographicPerson {
-	// Remove empty default entry if present
+	if s.ClinicalClassifications == nil {
+		s.ClinicalClassifications = &ClinicalClassifications{}
+	}
+	// Remove empty placeholder entry if present
 	if len(s.ClinicalClassifications.ClinicalClassification) == 1 && s.ClinicalClassifications.ClinicalClassification[0] == "" {
 		s.ClinicalClassifications.ClinicalClassification = []string{}
 	}
@@ -150,16 +154,16 @@ func (s *SubjectDemographicPerson) AddClinicalClassification(classification stri
 //
 // Example: SetClinicalClassifications().AddClinicalClassification("Hypertension")
 func (s *SubjectDemographicPerson) SetClinicalClassifications() *SubjectDemographicPerson {
-	s.ClinicalClassifications = ClinicalClassifications{
+	s.ClinicalClassifications = &ClinicalClassifications{
 		ClinicalClassification: []string{},
 	}
-	s.ClinicalClassifications.ClinicalClassification = append(s.ClinicalClassifications.ClinicalClassification, "")
 	return s
 }
 
 // SetEmptyClinicalClassifications initializes the classifications list with a specific number of empty entries.
 //
 // This is useful to match XML structures that require empty <ClinicalClassification/> elements.
+// The elements are vendor extensions and are only written under COMPLIANCE_LEGACY.
 //
 // Example: SetEmptyClinicalClassifications(2) generates:
 //
@@ -168,7 +172,7 @@ func (s *SubjectDemographicPerson) SetClinicalClassifications() *SubjectDemograp
 //	  <ClinicalClassification/>
 //	</ClinicalClassifications>
 func (s *SubjectDemographicPerson) SetEmptyClinicalClassifications(count int) *SubjectDemographicPerson {
-	s.ClinicalClassifications = ClinicalClassifications{
+	s.ClinicalClassifications = &ClinicalClassifications{
 		ClinicalClassification: make([]string, count),
 	}
 	return s
@@ -0,0 +1,173 @@
+package types
+
+import "strconv"
+
+// =============================================================================
+// TimepointEvent Setter Methods
+// =============================================================================
+
+// SetCode sets the visit code of the timepoint event.
+//
+// Parameters:
+//   - code: The visit code defined by the protocol (e.g., "VISIT_2", "DAY_1")
+//   - codeSystem: The OID naming the protocol that defines the code
+//
+// Example:
+//
+//	te.SetCode("VISIT_2", "2.16.840.1.113883.3.1")
+//
+// Returns the TimepointEvent for method chaining.
+func (te *TimepointEvent) SetCode(code string, codeSystem CodeSystemOID) *TimepointEvent {
+	if te.Code == nil {
+		te.Code = &Code[string, CodeSystemOID]{}
+	}
+	te.Code.SetCode(code, codeSystem, "", "")
+	return te
+}
+
+// SetEffectiveTime sets the time range during which the visit took place.
+//
+// Parameters:
+//   - low: Start of the visit (e.g., "20020509100700")
+//   - high: End of the visit (e.g., "20020509134600"), empty if unknown
+//
+// Returns the TimepointEvent for method chaining.
+func (te *TimepointEvent) SetEffectiveTime(low, high string) *TimepointEvent {
+	te.EffectiveTime = &EffectiveTime{
+		Low:  Time{Value: low},
+		High: Time{Value: high},
+	}
+	return te
+}
+
+// SetReasonCode sets whether the visit was scheduled or not.
+//
+// Valid codes (suggested from CDISC, no code system):
+//   - VISIT_TYPE_SCHEDULED ("S")
+//   - VISIT_TYPE_UNSCHEDULED ("U")
+//
+// Returns the TimepointEvent for method chaining.
+func (te *TimepointEvent) SetReasonCode(reason VisitTypeCode) *TimepointEvent {
+	if te.ReasonCode == nil {
+		te.ReasonCode = &Code[VisitTypeCode, string]{}
+	}
+	te.ReasonCode.SetCode(reason, "", "", "")
+	return te
+}
+
+// SetPerformer sets the person primarily responsible for the timepoint event
+// (typically the technician who recorded the ECG).
+//
+// Parameters:
+//   - root: OID of the identifier assigning authority (empty to omit the id)
+//   - extension: The performer identifier (e.g., "TECH_1")
+//   - name: The performer name (e.g., "Julie Tech"), empty to omit
+//
+// Example:
+//
+//	te.SetPerformer("2.16.840.1.113883.3.5", "TECH_1", "Julie Tech")
+//
+// Returns the TimepointEvent for method chaining.
+func (te *TimepointEvent) SetPerformer(root, extension, name string) *TimepointEvent {
+	performer := StudyEventPerformer{}
+	if root != "" || extension != "" {
+		performer.ID = &ID{}
+		performer.ID.SetID(root, extension)
+	}
+	if name != "" {
+		performer.AssignedPerson = &AssignedPerson{Name: &name}
+	}
+	te.Performer = &TimepointEventPerformer{StudyEventPerformer: performer}
+	return te
+}
+
+// =============================================================================
+// RelativeTimepoint Setter Methods
+// =============================================================================
+
+// NewRelativeTimepoint creates a relative timepoint with its code.
+//
+// Parameters:
+//   - code: The planned timepoint code (e.g., "POST_DOSE_2H")
+//   - codeSystem: The OID naming the protocol that defines the code
+//
+// Example:
+//
+//	rt := types.NewRelativeTimepoint("POST_DOSE_2H", "2.16.840.1.113883.3.1").
+//	    SetPauseQuantity(2, types.UNIT_HOUR).
+//	    SetProtocolTimepointEvent("VISIT_2", "2.16.840.1.113883.3.1").
+//	    SetReferenceEvent("DOSAGE_1", "2.16.840.1.113883.3.1")
+func NewRelativeTimepoint(code string, codeSystem CodeSystemOID) *RelativeTimepoint {
+	rt := &RelativeTimepoint{}
+	rt.Code.SetCode(code, codeSystem, "", "")
+	return rt
+}
+
+// SetPauseQuantity sets the delay between the reference event and the timepoint.
+//
+// Parameters:
+//   - value: The delay (e.g., 2)
+//   - unit: A UCUM time unit (e.g., UNIT_HOUR, UNIT_MINUTE, UNIT_SECOND)
+//
+// Invalid values (NaN, Inf) are ignored. The unit is checked by Validate.
+//
+// Returns the RelativeTimepoint for method chaining.
+func (rt *RelativeTimepoint) SetPauseQuantity(value float64, unit string) *RelativeTimepoint {
+	if isInvalidFloat(value) {
+		return rt
+	}
+	rt.ComponentOf.PauseQuantity = &Quantity{Value: formatFloat(value), Unit: unit}
+	return rt
+}
+
+// SetProtocolTimepointEvent sets the visit code as defined in the protocol.
+//
+// This is usually the same code as the TimepointEvent; it differs when the
+// ECG was not recorded at the planned visit.
+//
+// Returns the RelativeTimepoint for method chaining.
+func (rt *RelativeTimepoint) SetProtocolTimepointEvent(code string, codeSystem CodeSystemOID) *RelativeTimepoint {
+	rt.ComponentOf.ProtocolTimepointEvent.Code.SetCode(code, codeSystem, "", "")
+	return rt
+}
+
+// SetReferenceEvent sets the benchmark event of the timepoint (e.g., "DOSAGE_1").
+//
+// Returns the RelativeTimepoint for method chaining.
+func (rt *RelativeTimepoint) SetReferenceEvent(code string, codeSystem CodeSystemOID) *RelativeTimepoint {
+	ptv := &rt.ComponentOf.ProtocolTimepointEvent
+	if ptv.Component == nil {
+		ptv.Component = &ProtocolTimepointEventComponent{}
+	}
+	ptv.Component.ReferenceEvent.Code.SetCode(code, codeSystem, "", "")
+	return rt
+}
+
+// GetReferenceEvent returns the reference event, or nil if not set.
+func (rt *RelativeTimepoint) GetReferenceEvent() *ReferenceEvent {
+	if rt == nil || rt.ComponentOf.ProtocolTimepointEvent.Component == nil {
+		return nil
+	}
+	return &rt.ComponentOf.ProtocolTimepointEvent.Component.ReferenceEvent
+}
+
+// =============================================================================
+// Quantity Helpers
+// =============================================================================
+
+// Seconds returns the quantity converted to seconds.
+// Returns (0, false) if the value is not a number or the unit is not a UCUM time unit.
+func (q *Quantity) Seconds() (float64, bool) {
+	if q == nil {
+		return 0, false
+	}
+	factor, ok := UCUMTimeUnitSeconds(q.Unit)
+	if !ok {
+		return 0, false
+	}
+	value, err := strconv.ParseFloat(q.Value, 64)
+	if err != nil || isInvalidFloat(value) {
+		return 0, false
+	}
+	return value * factor, true
+}
@@ -75,3 +75,9 @@ func (id ID) String() string {
 	}
 	return id.Root
 }
+
+// Same reports whether two IDs identify the same thing: same Root and
+// Extension, whatever their unmodeled attributes.
+func (id ID) Same(other ID) bool {
+	return id.Root == other.Root && id.Extension == other.Extension
+}
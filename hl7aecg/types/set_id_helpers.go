@@ -10,6 +10,13 @@ func (ct *ClinicalTrial) SetID(root, extension string) *ClinicalTrial {
 	return ct
 }
 
+// SetID sets the ID of the ClinicalTrialProtocol with automatic default extension.
+// If extension is empty, uses "clinicalTrialProtocol" as default.
+func (p *ClinicalTrialProtocol) SetID(root, extension string) *ClinicalTrialProtocol {
+	p.ID.SetID(root, extension, "clinicalTrialProtocol")
+	return p
+}
+
 // SetID sets the ID of the TrialSubject with automatic default extension.
 // If extension is empty, uses "trialSubject" as default.
 func (ts *TrialSubject) SetID(root, extension string) *TrialSubject {
@@ -0,0 +1,199 @@
+package types
+
+import "strings"
+
+// =============================================================================
+// Document Summary
+// =============================================================================
+
+// Summary gives an overview of a document: subject, trial, series, leads,
+// sampling and measurements. It is meant for listings and reports; fields
+// absent from the document are left empty.
+type Summary struct {
+	ID              string          `json:"id,omitempty"`
+	Code            string          `json:"code,omitempty"`
+	Start           string          `json:"start,omitempty"`
+	End             string          `json:"end,omitempty"`
+	Confidentiality string          `json:"confidentiality,omitempty"`
+	Subject         SubjectSummary  `json:"subject"`
+	Trial           TrialSummary    `json:"trial"`
+	Series          []SeriesSummary `json:"series"`
+}
+
+// SubjectSummary summarizes the trial subject.
+type SubjectSummary struct {
+	ID        string `json:"id,omitempty"`
+	Role      string `json:"role,omitempty"`
+	Gender    string `json:"gender,omitempty"`
+	BirthTime string `json:"birthTime,omitempty"`
+}
+
+// TrialSummary summarizes the clinical trial.
+type TrialSummary struct {
+	ID       string `json:"id,omitempty"`
+	Title    string `json:"title,omitempty"`
+	Protocol string `json:"protocol,omitempty"`
+	Sponsor  string `json:"sponsor,omitempty"`
+	Site     string `json:"site,omitempty"`
+	Visit    string `json:"visit,omitempty"`
+}
+
+// SeriesSummary summarizes a series and its derived series.
+type SeriesSummary struct {
+	Code         string               `json:"code,omitempty"`
+	Start        string               `json:"start,omitempty"`
+	End          string               `json:"end,omitempty"`
+	SampleRate   float64              `json:"sampleRate,omitempty"`
+	Samples      int                  `json:"samples"`
+	Duration     float64              `json:"duration"`
+	Leads        []LeadCode           `json:"leads"`
+	Measurements []MeasurementSummary `json:"measurements,omitempty"`
+	Derived      []SeriesSummary      `json:"derived,omitempty"`
+}
+
+// MeasurementSummary is one numeric annotation of a series.
+type MeasurementSummary struct {
+	// Set is the index of the annotation set holding the measurement.
+	Set   int     `json:"set"`
+	Code  string  `json:"code"`
+	Lead  string  `json:"lead,omitempty"`
+	Value float64 `json:"value"`
+	Unit  string  `json:"unit,omitempty"`
+}
+
+// Summary returns an overview of the document.
+func (h *HL7AEcg) Summary() *Summary {
+	s := &Summary{Series: []SeriesSummary{}}
+	if h.ID != nil {
+		s.ID = h.ID.String()
+	}
+	if h.Code != nil {
+		s.Code = string(h.Code.Code)
+	}
+	if h.EffectiveTime != nil {
+		s.Start, s.End = h.EffectiveTime.Low.Value, h.EffectiveTime.High.Value
+	}
+	if h.ConfidentialityCode != nil {
+		s.Confidentiality = string(h.ConfidentialityCode.Code)
+	}
+
+	if ts := h.GetTrialSubject(); ts != nil {
+		if ts.ID != nil {
+			s.Subject.ID = ts.ID.String()
+		}
+		if ts.Code != nil {
+			s.Subject.Role = string(ts.Code.Code)
+		}
+		if demo := ts.SubjectDemographicPerson; demo != nil {
+			if demo.AdministrativeGenderCode != nil {
+				s.Subject.Gender = string(demo.AdministrativeGenderCode.Code)
+			}
+			if demo.BirthTime != nil {
+				s.Subject.BirthTime = demo.BirthTime.Value
+			}
+		}
+	}
+
+	if h.ComponentOf != nil {
+		te := &h.ComponentOf.TimepointEvent
+		if te.Code != nil {
+			s.Trial.Visit = te.Code.Code
+		}
+		ct := &te.ComponentOf.SubjectAssignment.ComponentOf.ClinicalTrial
+		s.Trial.ID = ct.ID.String()
+		if ct.Title != nil {
+			s.Trial.Title = *ct.Title
+		}
+		if p := ct.GetProtocol(); p != nil {
+			s.Trial.Protocol = p.ID.String()
+		}
+		if sp := ct.GetSponsor(); sp != nil {
+			if sp.Name != nil && *sp.Name != "" {
+				s.Trial.Sponsor = *sp.Name
+			} else {
+				s.Trial.Sponsor = sp.ID.String()
+			}
+		}
+		if ct.Location != nil {
+			s.Trial.Site = ct.Location.TrialSite.ID.String()
+		}
+	}
+
+	for i := range h.Component {
+		s.Series = append(s.Series, summarizeSeries(&h.Component[i].Series))
+	}
+	return s
+}
+
+// summarizeSeries returns the summary of a series and its derived series.
+func summarizeSeries(series *Series) SeriesSummary {
+	ss := SeriesSummary{
+		Start: series.EffectiveTime.Low.Value,
+		End:   series.EffectiveTime.High.Value,
+		Leads: series.GetLeadCodes(),
+	}
+	if series.Code != nil {
+		ss.Code = string(series.Code.Code)
+	}
+	if ss.Leads == nil {
+		ss.Leads = []LeadCode{}
+	}
+	if rate, err := series.GetSampleRate(); err == nil {
+		ss.SampleRate = rate
+	}
+	if len(ss.Leads) > 0 {
+		if values, err := series.GetLeadValues(ss.Leads[0]); err == nil {
+			ss.Samples = len(values)
+		}
+	}
+	if ss.SampleRate > 0 {
+		ss.Duration = float64(ss.Samples) / ss.SampleRate
+	}
+
+	for i, as := range series.GetAnnotationSets() {
+		ss.Measurements = appendMeasurements(ss.Measurements, i, "", as.Component)
+	}
+	for i := range series.Derivation {
+		ss.Derived = append(ss.Derived, summarizeSeries(&series.Derivation[i].DerivedSeries))
+	}
+	return ss
+}
+
+// appendMeasurements appends the PQ annotations of the components,
+// recursing into nested annotations. Nested annotations of a lead
+// annotation are attributed to its lead.
+func appendMeasurements(out []MeasurementSummary, set int, lead string, components []AnnotationComponent) []MeasurementSummary {
+	for i := range components {
+		ann := &components[i].Annotation
+		if ann.Code != nil && ann.Value != nil {
+			if value, ok := ann.Value.GetValueFloat(); ok {
+				out = append(out, MeasurementSummary{
+					Set:   set,
+					Code:  ann.Code.Code,
+					Lead:  lead,
+					Value: value,
+					Unit:  ann.Value.GetValueUnit(),
+				})
+			}
+		}
+		nestedLead := lead
+		if l := annotationLead(ann); l != "" {
+			nestedLead = l
+		}
+		out = appendMeasurements(out, set, nestedLead, ann.Component)
+	}
+	return out
+}
+
+// annotationLead returns the lead of a lead annotation, or "".
+func annotationLead(ann *Annotation) string {
+	if ann.Support == nil {
+		return ""
+	}
+	for _, comp := range ann.Support.SupportingROI.Component {
+		if code := comp.Boundary.Code.Code; strings.HasPrefix(code, "MDC_ECG_LEAD_") {
+			return code
+		}
+	}
+	return ""
+}
@@ -0,0 +1,60 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHL7AEcg_Summary tests the document overview
+func TestHL7AEcg_Summary(t *testing.T) {
+	doc := newBlindedDoc(CONFIDENTIALITY_BOTH)
+	doc.ID = &ID{Root: "2.16.840.1.113883.3.1", Extension: "ECG_1"}
+	sa := &doc.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment
+	sa.Subject.TrialSubject.ID = &ID{Root: "2.16.840.1.113883.3.6", Extension: "SUBJ_1"}
+	sa.ComponentOf.ClinicalTrial.SetID("2.16.840.1.113883.3.1", "TQT-001").SetSponsor("2.16.840.1.113883.3", "", "ABC Pharma")
+
+	waveforms := newWaveformSeries(
+		&SequenceValue{XsiType: "GLIST_TS", Typed: &GLIST_TS{Increment: Increment{Value: "0.002", Unit: "s"}}},
+		map[LeadCode]*SequenceValue{
+			MDC_ECG_LEAD_I: {XsiType: "SLIST_PQ", Typed: &SLIST_PQ{
+				Origin: PhysicalQuantity{Value: "0", Unit: "uV"},
+				Scale:  PhysicalQuantity{Value: "5", Unit: "uV"},
+				Digits: "1 2 3 4",
+			}},
+		}, MDC_ECG_LEAD_I)
+	doc.Component[0].Series.Component = waveforms.Component
+
+	s := doc.Summary()
+	assert.Equal(t, "2.16.840.1.113883.3.1^ECG_1", s.ID)
+	assert.Equal(t, "B", s.Confidentiality)
+	assert.Equal(t, "2.16.840.1.113883.3.6^SUBJ_1", s.Subject.ID)
+	assert.Equal(t, "2.16.840.1.113883.3.1^TQT-001", s.Trial.ID)
+	assert.Equal(t, "ABC Pharma", s.Trial.Sponsor)
+	assert.Equal(t, "VISIT_2", s.Trial.Visit)
+
+	require.Len(t, s.Series, 1)
+	series := s.Series[0]
+	assert.Equal(t, 500.0, series.SampleRate)
+	assert.Equal(t, 4, series.Samples)
+	assert.InDelta(t, 0.008, series.Duration, 1e-12)
+	assert.Equal(t, []LeadCode{MDC_ECG_LEAD_I}, series.Leads)
+	assert.Equal(t, []MeasurementSummary{
+		{Code: string(MDC_ECG_TIME_PD_QT), Value: 398, Unit: UNIT_MILLISECOND},
+		{Code: "DRUG_CONC", Value: 12, Unit: "ng/mL"},
+		{Code: "DRUG_CONC", Lead: "MDC_ECG_LEAD_V2", Value: 11, Unit: "ng/mL"},
+	}, series.Measurements)
+
+	require.Len(t, series.Derived, 1)
+	assert.Empty(t, series.Derived[0].Leads)
+	assert.Len(t, series.Derived[0].Measurements, 1)
+}
+
+// TestHL7AEcg_SummaryEmpty tests that an empty document has an empty summary
+func TestHL7AEcg_SummaryEmpty(t *testing.T) {
+	s := (&HL7AEcg{}).Summary()
+	assert.Empty(t, s.ID)
+	assert.Empty(t, s.Trial)
+	assert.NotNil(t, s.Series)
+}
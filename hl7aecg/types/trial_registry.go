@@ -0,0 +1,107 @@
+package types
+
+// =============================================================================
+// Trial Registry
+// =============================================================================
+
+// TrialRegistryEntry describes a registered protocol and the trial it belongs to.
+type TrialRegistryEntry struct {
+	// ProtocolID identifies the protocol (root and extension must match).
+	ProtocolID ID
+
+	// TrialID identifies the trial running the protocol.
+	// Empty Root means any trial is accepted.
+	TrialID ID
+
+	// Title is an optional human-readable protocol title.
+	Title string
+}
+
+// TrialRegistry lists the protocols a document may reference.
+//
+// When a registry is set on the ValidationContext, validation reports
+// documents whose protocol ID is not registered, or whose clinical trial ID
+// differs from the registered one.
+//
+// Example:
+//
+//	reg := types.NewTrialRegistry().
+//	    Register(types.TrialRegistryEntry{
+//	        ProtocolID: types.ID{Root: "2.16.840.1.113883.3.2", Extension: "PUK-123-PROT-A"},
+//	        TrialID:    types.ID{Root: "2.16.840.1.113883.3.4", Extension: "PUK-123-TRL-1"},
+//	    })
+//	vctx.TrialRegistry = reg
+type TrialRegistry struct {
+	entries map[idKey]TrialRegistryEntry
+}
+
+// idKey identifies an ID in the registry: its root and extension.
+type idKey struct {
+	root, extension string
+}
+
+// keyOf returns the registry key of an ID.
+func keyOf(id ID) idKey {
+	return idKey{root: id.Root, extension: id.Extension}
+}
+
+// NewTrialRegistry creates an empty registry.
+func NewTrialRegistry() *TrialRegistry {
+	return &TrialRegistry{entries: make(map[idKey]TrialRegistryEntry)}
+}
+
+// Register adds or replaces the entry for its protocol ID.
+// Entries without a protocol root are ignored.
+//
+// Returns the TrialRegistry for method chaining.
+func (r *TrialRegistry) Register(entry TrialRegistryEntry) *TrialRegistry {
+	if entry.ProtocolID.Root == "" {
+		return r
+	}
+	r.entries[keyOf(entry.ProtocolID)] = entry
+	return r
+}
+
+// Lookup returns the entry registered for a protocol ID.
+func (r *TrialRegistry) Lookup(protocolID ID) (TrialRegistryEntry, bool) {
+	if r == nil {
+		return TrialRegistryEntry{}, false
+	}
+	entry, ok := r.entries[keyOf(protocolID)]
+	return entry, ok
+}
+
+// Len returns the number of registered protocols.
+func (r *TrialRegistry) Len() int {
+	if r == nil {
+		return 0
+	}
+	return len(r.entries)
+}
+
+// validateTrialRegistry cross-checks the trial's protocol against the registry.
+func validateTrialRegistry(vctx *ValidationContext, ct *ClinicalTrial) {
+	protocol := ct.GetProtocol()
+	if protocol == nil || protocol.ID.Root == "" {
+		vctx.AddError(NewValidationError("clinicalTrialProtocol.id", "Protocol ID is required by the trial registry"))
+		return
+	}
+
+	entry, ok := vctx.TrialRegistry.Lookup(protocol.ID)
+	if !ok {
+		vctx.AddError(NewValidationErrorWithValue(
+			"clinicalTrialProtocol.id",
+			"Protocol ID is not registered in the trial registry",
+			protocol.ID.String(),
+		))
+		return
+	}
+
+	if entry.TrialID.Root != "" && !entry.TrialID.Same(ct.ID) {
+		vctx.AddError(NewValidationErrorWithValue(
+			"clinicalTrial.id",
+			"Clinical trial ID does not match the trial registered for protocol "+protocol.ID.String(),
+			ct.ID.String(),
+		))
+	}
+}
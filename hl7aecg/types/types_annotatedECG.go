@@ -35,9 +35,11 @@ import (
 type HL7AEcg struct {
 	XMLName xml.Name `xml:"AnnotatedECG"`
 
-	// Attribute to specify XML Schema Instance namespace.
+	// Namespace declarations of the root element. Encoding falls back to
+	// NAMESPACE_HL7 and NAMESPACE_XSI when Xmlns or XmlnsXsi is empty, and
+	// omits xmlns:voc when XmlnsVoc is (see MarshalXML).
 	Xmlns    string `xml:"xmlns,attr"`
-	XmlnsVoc string `xml:"xmlns:voc,attr"`
+	XmlnsVoc string `xml:"xmlns:voc,attr,omitempty"`
 	XmlnsXsi string `xml:"xmlns:xsi,attr"`
 
 	// Type specifies the HL7 v3 RIM class type for this clinical document.
@@ -48,7 +50,7 @@ type HL7AEcg struct {
 	// SchemaLocation specifies the path to the HL7 aECG XML schema file.
 	// Required for XML schema validation. Uses xsi namespace prefix.
 	// Default: "urn:hl7-org:v3 ../schema/PORT_MT020001.xsd"
-	SchemaLocation string `xml:"xsi:schemaLocation,attr"`
+	SchemaLocation string `xml:"xsi:schemaLocation,attr,omitempty"`
 
 	// ID is the unique identifier for this aECG document.
 	// Must be globally unique using UUID.
@@ -135,6 +137,16 @@ type HL7AEcg struct {
 	// study procedures or was acquired for other clinical reasons.
 	ReasonCode *Code[ReasonCode, string] `xml:"reasonCode,omitempty"`
 
+	// Definition names the planned relative timepoint (e.g., "2 hours post dose")
+	// at which this ECG was collected.
+	//
+	// Structure: definition > relativeTimepoint > componentOf >
+	//   pauseQuantity + protocolTimepointEvent > component > referenceEvent
+	//
+	// XML Tag: <definition>...</definition>
+	// Cardinality: Optional
+	Definition *AnnotatedECGDefinition `xml:"definition,omitempty"`
+
 	// ComponentOf links this AnnotatedECG to a TimepointEvent (visit/study event).
 	//
 	// This is the complete HL7 aECG structure that includes timepoint event information
@@ -205,6 +217,8 @@ type HL7AEcg struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional but strongly recommended (0..*)
 	Component []Component `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // NewHL7AEcg creates a new HL7AEcg instance with a unique ID if none is provided.
@@ -258,6 +272,8 @@ type ID struct {
 	//
 	// Required: No (omitted in XML when empty)
 	Extension string `xml:"extension,attr,omitempty"`
+
+	Extensions
 }
 
 // Code represents an HL7 Coded Value (CE datatype - Coded Element).
@@ -340,6 +356,8 @@ type Code[T ~string, U ~string] struct {
 	//
 	// Required: No (omitted in XML when empty)
 	DisplayName string `xml:"displayName,attr,omitempty"`
+
+	Extensions
 }
 
 // NewConfidentialityCode creates a new confidentiality code instance.
@@ -370,6 +388,7 @@ func (c *Code[T, U]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 		CodeSystem     string `xml:"codeSystem,attr"`
 		CodeSystemName string `xml:"codeSystemName,attr"`
 		DisplayName    string `xml:"displayName,attr"`
+		Extensions
 	}
 
 	// Decode the XML element into the raw struct
@@ -382,6 +401,7 @@ func (c *Code[T, U]) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error
 	c.CodeSystem = U(raw.CodeSystem)
 	c.CodeSystemName = raw.CodeSystemName
 	c.DisplayName = raw.DisplayName
+	c.Extensions = raw.Extensions
 
 	return nil
 }
@@ -450,6 +470,8 @@ type EffectiveTime struct {
 	//
 	// Required: Recommended (may be omitted by some devices)
 	High Time `xml:"high"`
+
+	Extensions
 }
 
 func NewEffectiveTime(low, high string, low_inclusive, high_inclusive *bool) *EffectiveTime {
@@ -470,6 +492,8 @@ func NewEffectiveTime(low, high string, low_inclusive, high_inclusive *bool) *Ef
 type Time struct {
 	Value     string `xml:"value,attr"`
 	Inclusive *bool  `xml:"inclusive,attr,omitempty"`
+
+	Extensions
 }
 
 // ComponentOfTimepointEvent links the AnnotatedECG to a TimepointEvent.
@@ -491,4 +515,6 @@ type ComponentOfTimepointEvent struct {
 	// XML Tag: <timepointEvent>...</timepointEvent>
 	// Cardinality: Required (within ComponentOf)
 	TimepointEvent TimepointEvent `xml:"timepointEvent"`
+
+	Extensions
 }
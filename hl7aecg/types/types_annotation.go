@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/xml"
+	"slices"
 	"strconv"
 )
 
@@ -14,13 +15,25 @@ import (
 //
 // Annotations include measurements such as heart rate, PR interval, QRS duration,
 // QT interval, and lead-specific measurements. The annotationSet groups all
-// annotations that share the same activity time.
+// annotations that share the same activity time and author.
+//
+// A series may carry any number of annotation sets, e.g. a machine read,
+// two blinded human reads and an adjudication, each attributed to its author.
 //
 // XML Structure:
 //
 //	<subjectOf>
 //	  <annotationSet>
 //	    <activityTime value="20250923103600"/>
+//	    <author>
+//	      <functionCode code="PRIMARY_READER" codeSystem=""/>
+//	      <assignedEntity>
+//	        <id root="2.16.840.1.113883.3.5" extension="READER-01"/>
+//	        <assignedPerson>
+//	          <name>Dr. Reader</name>
+//	        </assignedPerson>
+//	      </assignedEntity>
+//	    </author>
 //	    <component>
 //	      <annotation>
 //	        <code code="MDC_ECG_HEART_RATE" codeSystem="2.16.840.1.113883.6.24"/>
@@ -39,11 +52,84 @@ type AnnotationSet struct {
 	// Cardinality: Optional
 	ActivityTime *Time `xml:"activityTime,omitempty"`
 
+	// Author identifies who or what produced the annotations.
+	//
+	// XML Tag: <author>...</author>
+	// Cardinality: Optional (0..*)
+	Author []AnnotationSetAuthor `xml:"author,omitempty"`
+
 	// Component contains the individual annotations.
 	//
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional (0..*)
 	Component []AnnotationComponent `xml:"component,omitempty"`
+
+	Extensions
+}
+
+// AnnotationSetAuthor attributes an annotation set to a person or a device.
+//
+// XML Structure:
+//
+//	<author>
+//	  <functionCode code="ALGORITHM" codeSystem=""/>
+//	  <assignedEntity>
+//	    <id root="2.16.840.1.113883.3.5" extension="MEASURE-1.0"/>
+//	    <assignedDevice>
+//	      <manufacturerModelName>Measurement engine</manufacturerModelName>
+//	      <softwareName>hl7v3-aecg measure</softwareName>
+//	    </assignedDevice>
+//	  </assignedEntity>
+//	</author>
+//
+// Cardinality: Optional (0..* within AnnotationSet)
+type AnnotationSetAuthor struct {
+	// FunctionCode describes the role of the author in the reading workflow.
+	//
+	// Suggested values (no formal vocabulary):
+	//   - "ALGORITHM": Automated machine read
+	//   - "PRIMARY_READER": First human read
+	//   - "SECONDARY_READER": Second human read
+	//   - "ADJUDICATOR": Adjudication of discordant reads
+	//
+	// XML Tag: <functionCode code="..." codeSystem="..."/>
+	// Cardinality: Optional
+	FunctionCode *Code[AuthorFunctionCode, CodeSystemOID] `xml:"functionCode,omitempty"`
+
+	// AssignedEntity identifies the author.
+	//
+	// XML Tag: <assignedEntity>...</assignedEntity>
+	// Cardinality: Required (within AnnotationSetAuthor)
+	AssignedEntity AnnotationAssignedEntity `xml:"assignedEntity"`
+
+	Extensions
+}
+
+// AnnotationAssignedEntity identifies the author of an annotation set.
+//
+// Exactly one of AssignedPerson or AssignedDevice should be present.
+//
+// Cardinality: Required (within AnnotationSetAuthor)
+type AnnotationAssignedEntity struct {
+	// ID uniquely identifies the author (reader ID or algorithm version).
+	//
+	// XML Tag: <id root="..." extension="..."/>
+	// Cardinality: Optional
+	ID *ID `xml:"id,omitempty"`
+
+	// AssignedPerson is set when the annotations were made by a person.
+	//
+	// XML Tag: <assignedPerson>...</assignedPerson>
+	// Cardinality: Optional (choice with AssignedDevice)
+	AssignedPerson *AssignedPerson `xml:"assignedPerson,omitempty"`
+
+	// AssignedDevice is set when the annotations were made by a device or algorithm.
+	//
+	// XML Tag: <assignedDevice>...</assignedDevice>
+	// Cardinality: Optional (choice with AssignedPerson)
+	AssignedDevice *ManufacturedSeriesDevice `xml:"assignedDevice,omitempty"`
+
+	Extensions
 }
 
 // AnnotationComponent wraps an Annotation to provide the correct XML structure.
@@ -61,6 +147,8 @@ type AnnotationComponent struct {
 	// XML Tag: <annotation>...</annotation>
 	// Cardinality: Required (within AnnotationComponent)
 	Annotation Annotation `xml:"annotation"`
+
+	Extensions
 }
 
 // Annotation represents an ECG measurement or observation.
@@ -139,6 +227,8 @@ type Annotation struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional (0..*)
 	Component []AnnotationComponent `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // AnnotationSupport contains supporting region of interest information for annotations.
@@ -165,6 +255,8 @@ type AnnotationSupport struct {
 	// XML Tag: <supportingROI classCode="...">...</supportingROI>
 	// Cardinality: Required (within AnnotationSupport)
 	SupportingROI AnnotationSupportingROI `xml:"supportingROI"`
+
+	Extensions
 }
 
 // AnnotationSupportingROI defines the region of interest for lead-specific annotations.
@@ -206,6 +298,8 @@ type AnnotationSupportingROI struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional (0..*)
 	Component []AnnotationBoundaryComponent `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // AnnotationBoundaryComponent wraps an AnnotationBoundary.
@@ -225,6 +319,8 @@ type AnnotationBoundaryComponent struct {
 	// XML Tag: <boundary>...</boundary>
 	// Cardinality: Required (within AnnotationBoundaryComponent)
 	Boundary AnnotationBoundary `xml:"boundary"`
+
+	Extensions
 }
 
 // AnnotationBoundary identifies a specific lead for lead-specific annotations.
@@ -247,6 +343,72 @@ type AnnotationBoundary struct {
 	// XML Tag: <code code="..." codeSystem="..." codeSystemName="..."/>
 	// Cardinality: Required
 	Code Code[string, string] `xml:"code"`
+
+	// Value bounds the region along the boundary dimension.
+	//
+	// Used by wave annotations (fiducial points) whose boundary code is
+	// TIME_RELATIVE or TIME_ABSOLUTE; the interval gives the wave onset and offset.
+	//
+	// XML Tag: <value xsi:type="IVL_PQ"><low .../><high .../></value>
+	// Cardinality: Optional
+	Value *AnnotationBoundaryValue `xml:"value,omitempty"`
+
+	Extensions
+}
+
+// AnnotationBoundaryValue is an interval of physical quantities (IVL_PQ)
+// bounding an annotation region, typically the onset and offset of a wave.
+//
+// XML Structure:
+//
+//	<value xsi:type="IVL_PQ">
+//	  <low value="112" unit="ms"/>
+//	  <high value="214" unit="ms"/>
+//	</value>
+//
+// Cardinality: Optional (within AnnotationBoundary)
+type AnnotationBoundaryValue struct {
+	// XsiType specifies the interval type, typically "IVL_PQ".
+	//
+	// XML Tag: xsi:type="IVL_PQ"
+	// Cardinality: Optional
+	XsiType string `xml:"xsi:type,attr,omitempty"`
+
+	// Low is the start of the interval (e.g., wave onset).
+	//
+	// XML Tag: <low value="..." unit="..."/>
+	// Cardinality: Optional
+	Low *PhysicalQuantity `xml:"low,omitempty"`
+
+	// High is the end of the interval (e.g., wave offset).
+	//
+	// XML Tag: <high value="..." unit="..."/>
+	// Cardinality: Optional
+	High *PhysicalQuantity `xml:"high,omitempty"`
+
+	Extensions
+}
+
+// UnmarshalXML decodes the boundary interval, preserving the xsi:type attribute.
+func (bv *AnnotationBoundaryValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type Alias AnnotationBoundaryValue
+	aux := &Alias{}
+	if err := d.DecodeElement(aux, &start); err != nil {
+		return err
+	}
+	*bv = AnnotationBoundaryValue(*aux)
+
+	bv.ExtraAttrs = slices.DeleteFunc(bv.ExtraAttrs, func(attr xml.Attr) bool {
+		if isXsiType(attr.Name) {
+			bv.XsiType = attr.Value
+			return true
+		}
+		return false
+	})
+	if len(bv.ExtraAttrs) == 0 {
+		bv.ExtraAttrs = nil
+	}
+	return nil
 }
 
 // StringValue represents a string text value for annotations.
@@ -273,13 +435,61 @@ type StringValue struct {
 	Value string `xml:",chardata"`
 }
 
-// AnnotationValue represents a polymorphic value that can be either a PhysicalQuantity or StringValue.
+// CodedValue represents a coded annotation value (CE).
+//
+// Used for wave annotations, where the value names the wave kind, and for
+// method annotations such as the QTc correction formula.
+//
+// XML Structure:
+//
+//	<value xsi:type="CE" code="MDC_ECG_WAVC_PWAVE" codeSystem="2.16.840.1.113883.6.24"/>
+//
+// Cardinality: Optional (within Annotation)
+// XML Attribute: xsi:type="CE"
+type CodedValue struct {
+	// XsiType specifies the type as "CE" for Coded with Equivalents.
+	//
+	// XML Tag: xsi:type="CE"
+	// Cardinality: Required
+	XsiType string `xml:"xsi:type,attr"`
+
+	// Code is the coded value.
+	//
+	// XML Tag: code="..."
+	// Cardinality: Required
+	Code string `xml:"code,attr"`
+
+	// CodeSystem is the OID of the vocabulary defining Code.
+	//
+	// XML Tag: codeSystem="..."
+	// Cardinality: Optional
+	CodeSystem string `xml:"codeSystem,attr,omitempty"`
+
+	// CodeSystemName is the human-readable name of the vocabulary.
+	//
+	// XML Tag: codeSystemName="..."
+	// Cardinality: Optional
+	CodeSystemName string `xml:"codeSystemName,attr,omitempty"`
+
+	// DisplayName is a human-readable name for the code.
+	//
+	// XML Tag: displayName="..."
+	// Cardinality: Optional
+	DisplayName string `xml:"displayName,attr,omitempty"`
+}
+
+// AnnotationValue represents a polymorphic value that can be a PhysicalQuantity,
+// a StringValue or a CodedValue.
 //
 // This type handles the xsi:type discrimination for annotation values.
 //
 // Supported types:
 //   - xsi:type="PQ": PhysicalQuantity (numeric with unit)
 //   - xsi:type="ST": StringValue (text content)
+//   - xsi:type="CE": CodedValue (coded concept)
+//
+// INT and REAL values are decoded as a PhysicalQuantity without unit, and CD,
+// CV and CO values as a CodedValue. The original xsi:type is preserved.
 //
 // XML Structure (PQ):
 //
@@ -288,6 +498,10 @@ type StringValue struct {
 // XML Structure (ST):
 //
 //	<value xsi:type="ST">Rythme sinusal avec ESA</value>
+//
+// XML Structure (CE):
+//
+//	<value xsi:type="CE" code="MDC_ECG_WAVC_QRSWAVE" codeSystem="2.16.840.1.113883.6.24"/>
 type AnnotationValue struct {
 	XMLName xml.Name `xml:"value"`
 	XsiType string   `xml:"xsi:type,attr,omitempty"`
@@ -296,56 +510,87 @@ type AnnotationValue struct {
 	// Typed holds the decoded value as one of:
 	//   - *PhysicalQuantity (xsi:type="PQ")
 	//   - *StringValue (xsi:type="ST")
+	//   - *CodedValue (xsi:type="CE")
 	Typed any `xml:"-"`
+
+	Extensions `xml:"-"`
 }
 
 // UnmarshalXML decodes the annotation value based on xsi:type.
+//
+// Attributes and child elements the typed value does not model are kept in
+// Extensions. The content of a value of another xsi:type is kept in RawXML.
 func (av *AnnotationValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var raw struct {
+		Text  string `xml:",chardata"`
+		Inner []byte `xml:",innerxml"`
+		Extensions
+	}
+	if err := d.DecodeElement(&raw, &start); err != nil {
+		return err
+	}
+
 	// Extract xsi:type attribute
 	for _, attr := range start.Attr {
-		// Check for xsi:type attribute (namespace can be empty or xsi)
-		if attr.Name.Local == "type" || (attr.Name.Space == "xsi" && attr.Name.Local == "type") {
+		if isXsiType(attr.Name) {
 			av.XsiType = attr.Value
 			break
 		}
 	}
 
+	// Attributes decoded into the typed value
+	var known map[string]*string
+
 	// Decode based on type
 	switch av.XsiType {
-	case "PQ":
-		// For PhysicalQuantity, attributes are on the element itself
+	case "PQ", "INT", "REAL":
+		// For PhysicalQuantity, attributes are on the element itself.
+		// INT and REAL values (e.g., an age in sponsor files) are read as
+		// unitless quantities.
 		pq := &PhysicalQuantity{
-			XsiType: "PQ",
-		}
-		for _, attr := range start.Attr {
-			switch attr.Name.Local {
-			case "value":
-				pq.Value = attr.Value
-			case "unit":
-				pq.Unit = attr.Value
-			}
+			XsiType: av.XsiType,
 		}
+		known = map[string]*string{"value": &pq.Value, "unit": &pq.Unit}
 		av.Typed = pq
-		// Consume the end element
-		return d.Skip()
 
 	case "ST":
 		// For String, content is character data
-		var text string
-		if err := d.DecodeElement(&text, &start); err != nil {
-			return err
-		}
-		st := &StringValue{
+		av.Typed = &StringValue{
 			XsiType: "ST",
-			Value:   text,
+			Value:   raw.Text,
 		}
-		av.Typed = st
-		return nil
+
+	case "CE", "CD", "CV", "CO":
+		// For coded values, attributes are on the element itself
+		ce := &CodedValue{
+			XsiType: av.XsiType,
+		}
+		known = map[string]*string{
+			"code":           &ce.Code,
+			"codeSystem":     &ce.CodeSystem,
+			"codeSystemName": &ce.CodeSystemName,
+			"displayName":    &ce.DisplayName,
+		}
+		av.Typed = ce
 
 	default:
-		// Unknown type - skip the element
-		return d.Skip()
+		// Unknown type - keep the content as is
+		av.RawXML = raw.Inner
+		raw.ExtraElements = nil
 	}
+
+	for _, attr := range raw.ExtraAttrs {
+		if isXsiType(attr.Name) {
+			continue
+		}
+		if field, ok := known[attr.Name.Local]; ok && attr.Name.Space == "" {
+			*field = attr.Value
+			continue
+		}
+		av.ExtraAttrs = append(av.ExtraAttrs, attr)
+	}
+	av.ExtraElements = raw.ExtraElements
+	return nil
 }
 
 // MarshalXML encodes the annotation value based on its typed content.
@@ -354,13 +599,11 @@ func (av *AnnotationValue) MarshalXML(e *xml.Encoder, start xml.StartElement) er
 
 	// Add xsi:type attribute
 	if av.XsiType != "" {
-		start.Attr = append(start.Attr, xml.Attr{
-			Name:  xml.Name{Local: "xsi:type"},
-			Value: av.XsiType,
-		})
+		start.Attr = append(start.Attr, xsiTypeAttr(av.XsiType))
 	}
 
 	// Encode based on typed value
+	var text string
 	switch typed := av.Typed.(type) {
 	case *PhysicalQuantity:
 		// For PQ, we need to encode as attributes
@@ -376,16 +619,39 @@ func (av *AnnotationValue) MarshalXML(e *xml.Encoder, start xml.StartElement) er
 				Value: typed.Unit,
 			})
 		}
-		return e.EncodeElement("", start)
 
 	case *StringValue:
 		// For ST, encode as character data
-		return e.EncodeElement(typed.Value, start)
+		text = typed.Value
+
+	case *CodedValue:
+		// For CE, encode as attributes
+		for _, attr := range []struct{ name, value string }{
+			{"code", typed.Code},
+			{"codeSystem", typed.CodeSystem},
+			{"codeSystemName", typed.CodeSystemName},
+			{"displayName", typed.DisplayName},
+		} {
+			if attr.value != "" {
+				start.Attr = append(start.Attr, xml.Attr{
+					Name:  xml.Name{Local: attr.name},
+					Value: attr.value,
+				})
+			}
+		}
 
 	default:
-		// If no typed value, encode empty element
-		return e.EncodeElement("", start)
+		// If no typed value, encode the raw content, if any
+		return e.EncodeElement(struct {
+			Inner []byte `xml:",innerxml"`
+			Extensions
+		}{av.RawXML, av.Extensions}, start)
 	}
+
+	return e.EncodeElement(struct {
+		Text string `xml:",chardata"`
+		Extensions
+	}{text, av.Extensions}, start)
 }
 
 // GetValueFloat returns the numeric value if this is a PhysicalQuantity.
@@ -427,6 +693,19 @@ func (av *AnnotationValue) IsST() bool {
 	return ok
 }
 
+// GetCoded returns the coded value if this is a CodedValue.
+// Returns (value, true) if successful, (nil, false) otherwise.
+func (av *AnnotationValue) GetCoded() (*CodedValue, bool) {
+	ce, ok := av.Typed.(*CodedValue)
+	return ce, ok
+}
+
+// IsCE returns true if this annotation value is a CodedValue.
+func (av *AnnotationValue) IsCE() bool {
+	_, ok := av.Typed.(*CodedValue)
+	return ok
+}
+
 // =============================================================================
 // Helper Functions for Annotations
 // =============================================================================
@@ -971,6 +1250,80 @@ func (a *Annotation) AddNestedTextAnnotationWithCodeSystemName(code, codeSystemN
 	return len(a.Component) - 1
 }
 
+// =============================================================================
+// Coded Annotations
+// =============================================================================
+
+// AddCodedAnnotation adds a global annotation whose value is a coded concept (CE).
+//
+// Parameters:
+//   - code: The annotation code
+//   - codeSystem: The code system OID of the annotation code (can be empty for vendor codes)
+//   - value: The coded value; XsiType is set to "CE" automatically
+//
+// Returns:
+//   - int: Index of the annotation, or -1 if code or value.Code is empty
+//
+// Example:
+//
+//	idx := annotationSet.AddCodedAnnotation("MDC_ECG_WAVC", string(MDC_OID),
+//		CodedValue{Code: "MDC_ECG_WAVC_PWAVE", CodeSystem: string(MDC_OID)})
+func (as *AnnotationSet) AddCodedAnnotation(code, codeSystem string, value CodedValue) int {
+	if as == nil {
+		return -1
+	}
+
+	ann, ok := newCodedAnnotation(code, codeSystem, value)
+	if !ok {
+		return -1
+	}
+
+	as.Component = append(as.Component, AnnotationComponent{Annotation: ann})
+	return len(as.Component) - 1
+}
+
+// AddNestedCodedAnnotation adds a nested annotation whose value is a coded concept (CE).
+//
+// Used to record provenance on a measurement, such as the correction
+// formula of a QTc value.
+//
+// Returns:
+//   - int: Index of the nested annotation, or -1 if code or value.Code is empty
+func (a *Annotation) AddNestedCodedAnnotation(code, codeSystem string, value CodedValue) int {
+	if a == nil {
+		return -1
+	}
+
+	nested, ok := newCodedAnnotation(code, codeSystem, value)
+	if !ok {
+		return -1
+	}
+
+	a.Component = append(a.Component, AnnotationComponent{Annotation: nested})
+	return len(a.Component) - 1
+}
+
+// newCodedAnnotation builds an annotation with a CE value.
+func newCodedAnnotation(code, codeSystem string, value CodedValue) (Annotation, bool) {
+	// Input validation
+	if code == "" || value.Code == "" {
+		return Annotation{}, false
+	}
+	// Note: codeSystem can be empty for vendor codes
+
+	value.XsiType = "CE"
+	return Annotation{
+		Code: &Code[string, string]{
+			Code:       code,
+			CodeSystem: codeSystem,
+		},
+		Value: &AnnotationValue{
+			XsiType: "CE",
+			Typed:   &value,
+		},
+	}, true
+}
+
 // =============================================================================
 // Safe Accessor Methods
 // =============================================================================
@@ -993,6 +1346,148 @@ func (a *Annotation) GetNestedAnnotation(index int) *Annotation {
 	return &a.Component[index].Annotation
 }
 
+// =============================================================================
+// Wave Annotations (Fiducial Points)
+// =============================================================================
+
+// AddWaveAnnotation adds a wave annotation marking the onset and offset of a wave.
+//
+// The annotation uses the MDC_ECG_WAVC code with a coded value naming the wave,
+// and a supportingROI whose TIME_RELATIVE boundary carries the interval.
+//
+// XML Structure:
+//
+//	<annotation>
+//	  <code code="MDC_ECG_WAVC" codeSystem="2.16.840.1.113883.6.24"/>
+//	  <value xsi:type="CE" code="MDC_ECG_WAVC_PWAVE" codeSystem="2.16.840.1.113883.6.24"/>
+//	  <support>
+//	    <supportingROI classCode="ROIBND">
+//	      <code code="ROIPS" codeSystem="2.16.840.1.113883.5.4"/>
+//	      <component>
+//	        <boundary>
+//	          <code code="TIME_RELATIVE" codeSystem="2.16.840.1.113883.5.4"/>
+//	          <value xsi:type="IVL_PQ">
+//	            <low value="112" unit="ms"/>
+//	            <high value="214" unit="ms"/>
+//	          </value>
+//	        </boundary>
+//	      </component>
+//	    </supportingROI>
+//	  </support>
+//	</annotation>
+//
+// Parameters:
+//   - wave: The wave code (e.g., MDC_ECG_WAVC_PWAVE)
+//   - onset: Wave onset relative to the start of the series
+//   - offset: Wave offset relative to the start of the series
+//   - unit: The time unit (e.g., "ms")
+//
+// Returns:
+//   - int: Index of the wave annotation, or -1 if the input is invalid
+//
+// Reference: HL7 aECG Implementation Guide, Section 6: Annotations
+func (as *AnnotationSet) AddWaveAnnotation(wave WaveformAnnotationCode, onset, offset float64, unit string) int {
+	if as == nil {
+		return -1
+	}
+
+	// Input validation
+	if wave == "" || unit == "" {
+		return -1
+	}
+	if isInvalidFloat(onset) || isInvalidFloat(offset) || offset < onset {
+		return -1
+	}
+
+	ann := Annotation{
+		Code: &Code[string, string]{
+			Code:       string(MDC_ECG_WAVC),
+			CodeSystem: string(MDC_OID),
+		},
+		Value: &AnnotationValue{
+			XsiType: "CE",
+			Typed: &CodedValue{
+				XsiType:    "CE",
+				Code:       string(wave),
+				CodeSystem: string(MDC_OID),
+			},
+		},
+		Support: &AnnotationSupport{
+			SupportingROI: AnnotationSupportingROI{
+				ClassCode: "ROIBND",
+				Code: &Code[string, string]{
+					Code:       string(ROIPS),
+					CodeSystem: string(HL7_ActCode_OID),
+				},
+				Component: []AnnotationBoundaryComponent{{
+					Boundary: AnnotationBoundary{
+						Code: Code[string, string]{
+							Code:       string(TIME_RELATIVE_CODE),
+							CodeSystem: string(HL7_ActCode_OID),
+						},
+						Value: &AnnotationBoundaryValue{
+							XsiType: "IVL_PQ",
+							Low:     &PhysicalQuantity{Value: formatFloat(onset), Unit: unit},
+							High:    &PhysicalQuantity{Value: formatFloat(offset), Unit: unit},
+						},
+					},
+				}},
+			},
+		},
+	}
+
+	as.Component = append(as.Component, AnnotationComponent{Annotation: ann})
+	return len(as.Component) - 1
+}
+
+// GetWaveAnnotations returns all wave annotations of the given kind.
+// Returns an empty slice if none are found.
+func (as *AnnotationSet) GetWaveAnnotations(wave WaveformAnnotationCode) []*Annotation {
+	waves := []*Annotation{}
+	if as == nil {
+		return waves
+	}
+	for i := range as.Component {
+		ann := &as.Component[i].Annotation
+		if ann.Code == nil || ann.Code.Code != string(MDC_ECG_WAVC) || ann.Value == nil {
+			continue
+		}
+		if ce, ok := ann.Value.GetCoded(); ok && ce.Code == string(wave) {
+			waves = append(waves, ann)
+		}
+	}
+	return waves
+}
+
+// GetTimeBoundary returns the onset and offset carried by a wave annotation.
+//
+// Returns:
+//   - onset, offset: The interval bounds
+//   - unit: The unit of the interval (taken from the low bound)
+//   - ok: false if the annotation has no time boundary interval
+func (a *Annotation) GetTimeBoundary() (onset, offset float64, unit string, ok bool) {
+	if a == nil || a.Support == nil {
+		return 0, 0, "", false
+	}
+	for _, comp := range a.Support.SupportingROI.Component {
+		code := comp.Boundary.Code.Code
+		if code != string(TIME_RELATIVE_CODE) && code != string(TIME_ABSOLUTE_CODE) {
+			continue
+		}
+		bv := comp.Boundary.Value
+		if bv == nil || bv.Low == nil || bv.High == nil {
+			return 0, 0, "", false
+		}
+		low, okLow := bv.Low.GetValueFloat()
+		high, okHigh := bv.High.GetValueFloat()
+		if !okLow || !okHigh {
+			return 0, 0, "", false
+		}
+		return low, high, bv.Low.Unit, true
+	}
+	return 0, 0, "", false
+}
+
 // =============================================================================
 // Convenience Methods for Common Annotations
 // =============================================================================
@@ -1047,6 +1542,21 @@ func (as *AnnotationSet) AddSTAxis(value float64) int {
 	return as.AddAnnotation(string(MDC_ECG_ANGLE_ST_FRONT), string(MDC_OID), value, "deg")
 }
 
+// AddPAxis adds a P wave frontal axis annotation in degrees.
+func (as *AnnotationSet) AddPAxis(value float64) int {
+	return as.AddAnnotation(string(MDC_ECG_ANGLE_P_FRONT), string(MDC_OID), value, "deg")
+}
+
+// AddQRSAxis adds a QRS frontal axis annotation in degrees.
+func (as *AnnotationSet) AddQRSAxis(value float64) int {
+	return as.AddAnnotation(string(MDC_ECG_ANGLE_QRS_FRONT), string(MDC_OID), value, "deg")
+}
+
+// AddTAxis adds a T wave frontal axis annotation in degrees.
+func (as *AnnotationSet) AddTAxis(value float64) int {
+	return as.AddAnnotation(string(MDC_ECG_ANGLE_T_FRONT), string(MDC_OID), value, "deg")
+}
+
 // formatFloat converts a float64 to a string for PhysicalQuantity.Value.
 // Removes trailing zeros and decimal point if not needed.
 func formatFloat(f float64) string {
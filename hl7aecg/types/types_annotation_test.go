@@ -1,6 +1,7 @@
 package types
 
 import (
+	"context"
 	"encoding/xml"
 	"testing"
 
@@ -570,3 +571,86 @@ func TestFormatFloat(t *testing.T) {
 		})
 	}
 }
+
+// TestAnnotationSet_WaveAnnotation tests wave annotations with a time boundary interval
+func TestAnnotationSet_WaveAnnotation(t *testing.T) {
+	annSet := &AnnotationSet{}
+
+	assert.Equal(t, -1, annSet.AddWaveAnnotation("", 0, 10, "ms"))
+	assert.Equal(t, -1, annSet.AddWaveAnnotation(MDC_ECG_WAVC_PWAVE, 10, 0, "ms"))
+	assert.Equal(t, -1, annSet.AddWaveAnnotation(MDC_ECG_WAVC_PWAVE, 0, 10, ""))
+
+	idx := annSet.AddWaveAnnotation(MDC_ECG_WAVC_QRSWAVE, 112.5, 214, "ms")
+	require.Equal(t, 0, idx)
+
+	data, err := xml.Marshal(annSet)
+	require.NoError(t, err)
+	xmlStr := string(data)
+	assert.Contains(t, xmlStr, `code="MDC_ECG_WAVC"`)
+	assert.Contains(t, xmlStr, `xsi:type="CE" code="MDC_ECG_WAVC_QRSWAVE"`)
+	assert.Contains(t, xmlStr, `code="TIME_RELATIVE"`)
+	assert.Contains(t, xmlStr, `xsi:type="IVL_PQ"`)
+	assert.Contains(t, xmlStr, `<low value="112.5" unit="ms"></low>`)
+
+	var decoded AnnotationSet
+	require.NoError(t, xml.Unmarshal(data, &decoded))
+
+	waves := decoded.GetWaveAnnotations(MDC_ECG_WAVC_QRSWAVE)
+	require.Len(t, waves, 1)
+	assert.Empty(t, decoded.GetWaveAnnotations(MDC_ECG_WAVC_PWAVE))
+	assert.True(t, waves[0].Value.IsCE())
+
+	onset, offset, unit, ok := waves[0].GetTimeBoundary()
+	require.True(t, ok)
+	assert.Equal(t, 112.5, onset)
+	assert.Equal(t, 214.0, offset)
+	assert.Equal(t, "ms", unit)
+
+	vctx := NewValidationContext(false)
+	assert.NoError(t, decoded.Validate(context.Background(), vctx))
+}
+
+// TestAnnotationValue_CodedValue tests CE annotation values
+func TestAnnotationValue_CodedValue(t *testing.T) {
+	xmlData := `<annotation xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance">
+		<code code="MDC_ECG_WAVC" codeSystem="2.16.840.1.113883.6.24"/>
+		<value xsi:type="CE" code="MDC_ECG_WAVC_TWAVE" codeSystem="2.16.840.1.113883.6.24" displayName="T wave"/>
+	</annotation>`
+
+	var ann Annotation
+	require.NoError(t, xml.Unmarshal([]byte(xmlData), &ann))
+	require.NotNil(t, ann.Value)
+
+	ce, ok := ann.Value.GetCoded()
+	require.True(t, ok)
+	assert.Equal(t, "MDC_ECG_WAVC_TWAVE", ce.Code)
+	assert.Equal(t, "2.16.840.1.113883.6.24", ce.CodeSystem)
+	assert.Equal(t, "T wave", ce.DisplayName)
+	assert.False(t, ann.Value.IsPQ())
+	assert.False(t, ann.Value.IsST())
+
+	ce.Code = ""
+	vctx := NewValidationContext(false)
+	assert.Error(t, ann.Validate(context.Background(), vctx))
+}
+
+// TestAnnotationSet_AddCodedAnnotation tests coded annotation builders
+func TestAnnotationSet_AddCodedAnnotation(t *testing.T) {
+	annSet := &AnnotationSet{}
+
+	assert.Equal(t, -1, annSet.AddCodedAnnotation("", "", CodedValue{Code: "X"}))
+	assert.Equal(t, -1, annSet.AddCodedAnnotation("METHOD", "", CodedValue{}))
+
+	idx := annSet.AddQTcInterval(412)
+	qtc := annSet.GetAnnotation(idx)
+	require.NotNil(t, qtc)
+	nestedIdx := qtc.AddNestedCodedAnnotation("QTC_CORRECTION_METHOD", "", CodedValue{Code: "QTcF", DisplayName: "Fridericia"})
+	require.Equal(t, 0, nestedIdx)
+
+	nested := qtc.GetNestedAnnotation(nestedIdx)
+	require.NotNil(t, nested)
+	ce, ok := nested.Value.GetCoded()
+	require.True(t, ok)
+	assert.Equal(t, "CE", ce.XsiType)
+	assert.Equal(t, "QTcF", ce.Code)
+}
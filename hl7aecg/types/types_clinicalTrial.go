@@ -19,6 +19,13 @@ import "fmt"
 //	    <low value="20010509"/>
 //	    <high value="20020316"/>
 //	  </activityTime>
+//	  <author>
+//	    <clinicalTrialSponsor>...</clinicalTrialSponsor>
+//	  </author>
+//	  <location>...</location>
+//	  <definition>
+//	    <clinicalTrialProtocol>...</clinicalTrialProtocol>
+//	  </definition>
 //	</clinicalTrial>
 //
 // Cardinality: Required
@@ -85,6 +92,12 @@ type ClinicalTrial struct {
 	// Cardinality: Optional
 	ActivityTime *EffectiveTime `xml:"activityTime"`
 
+	// Author identifies the sponsor of the clinical trial.
+	//
+	// XML Tag: <author><clinicalTrialSponsor>...</clinicalTrialSponsor></author>
+	// Cardinality: Optional
+	Author *ClinicalTrialAuthor `xml:"author,omitempty"`
+
 	// Location identifies the trial site location where ECG waveforms were acquired.
 	//
 	// This provides information about the physical location and facility
@@ -93,6 +106,14 @@ type ClinicalTrial struct {
 	// XML Tag: <location>...</location>
 	// Cardinality: Optional
 	Location *Location `xml:"location,omitempty"`
+
+	// Definition names the protocol the clinical trial follows.
+	//
+	// XML Tag: <definition><clinicalTrialProtocol>...</clinicalTrialProtocol></definition>
+	// Cardinality: Optional
+	Definition *ClinicalTrialDefinition `xml:"definition,omitempty"`
+
+	Extensions
 }
 
 // GetIdentifier returns a human-readable string representation of the trial identifier.
@@ -1,5 +1,24 @@
 package types
 
+// ClinicalTrialDefinition wraps the protocol of a clinical trial.
+//
+// XML Structure:
+//
+//	<definition>
+//	  <clinicalTrialProtocol>...</clinicalTrialProtocol>
+//	</definition>
+//
+// Cardinality: Optional (within ClinicalTrial)
+type ClinicalTrialDefinition struct {
+	// ClinicalTrialProtocol identifies the protocol.
+	//
+	// XML Tag: <clinicalTrialProtocol>...</clinicalTrialProtocol>
+	// Cardinality: Required (within Definition)
+	ClinicalTrialProtocol ClinicalTrialProtocol `xml:"clinicalTrialProtocol"`
+
+	Extensions
+}
+
 // ClinicalTrialProtocol represents the clinicalTrialProtocol element in an HL7 aECG document.
 // It contains information about the clinical trial protocol used to define the trial.
 //
@@ -47,4 +66,6 @@ type ClinicalTrialProtocol struct {
 	// XML Tag: <title>...</title>
 	// Cardinality: Optional
 	Title *string `xml:"title,omitempty"`
+
+	Extensions
 }
@@ -1,5 +1,24 @@
 package types
 
+// ClinicalTrialAuthor wraps the sponsor of a clinical trial.
+//
+// XML Structure:
+//
+//	<author>
+//	  <clinicalTrialSponsor>...</clinicalTrialSponsor>
+//	</author>
+//
+// Cardinality: Optional (within ClinicalTrial)
+type ClinicalTrialAuthor struct {
+	// ClinicalTrialSponsor identifies the sponsoring organization.
+	//
+	// XML Tag: <clinicalTrialSponsor>...</clinicalTrialSponsor>
+	// Cardinality: Required (within Author)
+	ClinicalTrialSponsor ClinicalTrialSponsor `xml:"clinicalTrialSponsor"`
+
+	Extensions
+}
+
 // ClinicalTrialSponsor represents the author of a clinical trial.
 // It identifies the sponsoring organization of the clinical trial.
 //
@@ -23,6 +42,8 @@ type ClinicalTrialSponsor struct {
 	// XML Tag: <sponsorOrganization>...</sponsorOrganization>
 	// Cardinality: Required (within ClinicalTrialSponsor)
 	SponsorOrganization SponsorOrganization `xml:"sponsorOrganization"`
+
+	Extensions
 }
 
 // SponsorOrganization represents the organization that sponsors a clinical trial.
@@ -63,15 +84,6 @@ type SponsorOrganization struct {
 	// Cardinality: Optional
 	ID ID `xml:"id,omitempty"`
 
-	// Extension provides an additional identifier for the organization.
-	//
-	// Note: This field appears to be redundant with ID.Extension.
-	// Consider removing this field or clarifying its purpose.
-	//
-	// XML Tag: <extension>...</extension>
-	// Cardinality: Optional
-	Extension *string `xml:"extension,omitempty"`
-
 	// Name is the human-readable name of the sponsoring organization.
 	//
 	// This is the official or commonly used name of the organization
@@ -82,4 +94,6 @@ type SponsorOrganization struct {
 	// XML Tag: <name>...</name>
 	// Cardinality: Optional
 	Name *string `xml:"name,omitempty"`
+
+	Extensions
 }
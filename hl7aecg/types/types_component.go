@@ -20,4 +20,6 @@ type Component struct {
 	// XML Tag: <series>...</series>
 	// Cardinality: Required (within Component)
 	Series Series `xml:"series"`
+
+	Extensions
 }
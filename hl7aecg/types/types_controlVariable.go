@@ -55,6 +55,8 @@ type ControlVariable struct {
 	// XML Tag: <controlVariable>...</controlVariable>
 	// Cardinality: Optional (but typically present when ControlVariable is used)
 	ControlVariable *ControlVariableInner `xml:"controlVariable,omitempty"`
+
+	Extensions
 }
 
 // ControlVariableInner represents the actual control variable data with code, value, and nested components.
@@ -123,6 +125,8 @@ type ControlVariableInner struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional (0..*)
 	Component []ControlVariableComponent `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // ControlVariableComponent represents a component that contains a nested control variable.
@@ -147,4 +151,6 @@ type ControlVariableComponent struct {
 	// XML Tag: <controlVariable>...</controlVariable>
 	// Cardinality: Required (within ControlVariableComponent)
 	ControlVariable *ControlVariableInner `xml:"controlVariable,omitempty"`
+
+	Extensions
 }
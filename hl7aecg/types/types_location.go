@@ -27,6 +27,8 @@ type Location struct {
 	// XML Tag: <trialSite>...</trialSite>
 	// Cardinality: Required (within Location)
 	TrialSite TrialSite `xml:"trialSite"`
+
+	Extensions
 }
 
 // TrialSite represents a specific location where clinical trial activities take place.
@@ -77,6 +79,8 @@ type TrialSite struct {
 	// XML Tag: <responsibleParty>...</responsibleParty>
 	// Cardinality: Optional
 	ResponsibleParty *ResponsibleParty `xml:"responsibleParty,omitempty"`
+
+	Extensions
 }
 
 // SiteLocation represents the physical location details of a trial site.
@@ -107,6 +111,8 @@ type SiteLocation struct {
 	// XML Tag: <addr>...</addr>
 	// Cardinality: Optional
 	Addr *Address `xml:"addr,omitempty"`
+
+	Extensions
 }
 
 // Address represents a physical address.
@@ -151,4 +157,6 @@ type Address struct {
 	// XML Tag: <country>...</country>
 	// Cardinality: Optional
 	Country *string `xml:"country,omitempty"`
+
+	Extensions
 }
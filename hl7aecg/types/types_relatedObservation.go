@@ -0,0 +1,107 @@
+package types
+
+// SubjectAssignmentSubjectOf wraps an observation about the trial subject.
+//
+// XML Structure:
+//
+//	<subjectOf>
+//	  <relatedObservation>...</relatedObservation>
+//	</subjectOf>
+//
+// Cardinality: Optional (0..* within SubjectAssignment)
+type SubjectAssignmentSubjectOf struct {
+	// RelatedObservation is the observation about the subject.
+	//
+	// XML Tag: <relatedObservation>...</relatedObservation>
+	// Cardinality: Required (within SubjectOf)
+	RelatedObservation RelatedObservation `xml:"relatedObservation"`
+
+	Extensions
+}
+
+// RelatedObservation represents an observation about the subject that is
+// relevant to the ECG, such as age, height, weight or vital signs.
+//
+// These observations replace the non-standard Age and Medications elements
+// found on SubjectDemographicPerson in vendor files.
+//
+// XML Structure:
+//
+//	<relatedObservation>
+//	  <code code="21612-7" codeSystem="2.16.840.1.113883.6.1" displayName="Age"/>
+//	  <value xsi:type="PQ" value="34" unit="a"/>
+//	  <author>
+//	    <time value="20021122091000"/>
+//	    <assignedEntity>
+//	      <assignedPerson><name>Julie Tech</name></assignedPerson>
+//	    </assignedEntity>
+//	  </author>
+//	</relatedObservation>
+//
+// Cardinality: Required (within SubjectAssignmentSubjectOf)
+// Reference: HL7 aECG Implementation Guide, RelatedObservation
+type RelatedObservation struct {
+	// Code identifies the observation.
+	//
+	// LOINC codes are recommended (code system 2.16.840.1.113883.6.1):
+	//   - "21612-7": Age
+	//   - "8302-2": Body height
+	//   - "29463-7": Body weight
+	//   - "8867-4": Heart rate
+	//   - "8480-6": Systolic blood pressure
+	//   - "8462-4": Diastolic blood pressure
+	//
+	// XML Tag: <code code="..." codeSystem="..." displayName="..."/>
+	// Cardinality: Required
+	Code *Code[ObservationCode, CodeSystemOID] `xml:"code"`
+
+	// Value is the observed value.
+	//
+	// Supported types:
+	//   - xsi:type="PQ": numeric value with unit (e.g., 34 a, 72 kg)
+	//   - xsi:type="ST": free text (e.g., medication name)
+	//   - xsi:type="CE": coded value
+	//
+	// XML Tag: <value xsi:type="..." .../>
+	// Cardinality: Optional
+	Value *AnnotationValue `xml:"value,omitempty"`
+
+	// Author identifies who or what made the observation, and when.
+	//
+	// XML Tag: <author>...</author>
+	// Cardinality: Optional
+	Author *RelatedObservationAuthor `xml:"author,omitempty"`
+
+	Extensions
+}
+
+// RelatedObservationAuthor attributes a related observation to a person or a device.
+//
+// XML Structure:
+//
+//	<author>
+//	  <time value="20021122091000"/>
+//	  <assignedEntity>
+//	    <id root="2.16.840.1.113883.3.5" extension="NURSE-12"/>
+//	    <assignedPerson><name>Julie Tech</name></assignedPerson>
+//	  </assignedEntity>
+//	</author>
+//
+// Cardinality: Optional (within RelatedObservation)
+type RelatedObservationAuthor struct {
+	// Time is when the observation was made.
+	//
+	// Format: YYYYMMDDHHmmss
+	//
+	// XML Tag: <time value="..."/>
+	// Cardinality: Optional
+	Time *Time `xml:"time,omitempty"`
+
+	// AssignedEntity identifies the author.
+	//
+	// XML Tag: <assignedEntity>...</assignedEntity>
+	// Cardinality: Required (within RelatedObservationAuthor)
+	AssignedEntity AnnotationAssignedEntity `xml:"assignedEntity"`
+
+	Extensions
+}
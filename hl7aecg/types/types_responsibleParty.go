@@ -31,6 +31,8 @@ type ResponsibleParty struct {
 	// XML Tag: <trialInvestigator>...</trialInvestigator>
 	// Cardinality: Required (within ResponsibleParty)
 	TrialInvestigator TrialInvestigator `xml:"trialInvestigator"`
+
+	Extensions
 }
 
 // TrialInvestigator represents the investigator responsible for acquiring
@@ -74,6 +76,8 @@ type TrialInvestigator struct {
 	// XML Tag: <investigatorPerson>...</investigatorPerson>
 	// Cardinality: Optional
 	InvestigatorPerson *InvestigatorPerson `xml:"investigatorPerson,omitempty"`
+
+	Extensions
 }
 
 // InvestigatorPerson represents personal information about a trial investigator.
@@ -100,6 +104,8 @@ type InvestigatorPerson struct {
 	// XML Tag: <name>...</name>
 	// Cardinality: Optional
 	Name *PersonName `xml:"name,omitempty"`
+
+	Extensions
 }
 
 // PersonName represents a person's name with its various components.
@@ -149,4 +155,6 @@ type PersonName struct {
 	// XML Tag: <suffix>...</suffix>
 	// Cardinality: Optional
 	Suffix *string `xml:"suffix,omitempty"`
+
+	Extensions
 }
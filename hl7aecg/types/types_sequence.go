@@ -4,7 +4,6 @@ import (
 	"encoding/xml"
 	"fmt"
 	"strconv"
-	"strings"
 )
 
 // =============================================================================
@@ -53,6 +52,8 @@ type SequenceSet struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Required (1..*)
 	Component []SequenceComponent `xml:"component"`
+
+	Extensions
 }
 
 // SequenceComponent contains a single sequence within a sequence set.
@@ -70,6 +71,8 @@ type SequenceComponent struct {
 	// XML Tag: <sequence>...</sequence>
 	// Cardinality: Required
 	Sequence Sequence `xml:"sequence"`
+
+	Extensions
 }
 
 // Sequence is an ordered list of values having a common code (or dimension).
@@ -131,11 +134,17 @@ type Sequence struct {
 	// XML Tag: <value xsi:type="...">...</value>
 	// Cardinality: Required
 	Value *SequenceValue `xml:"value,omitempty"`
+
+	Extensions
 }
 
 type SequenceCode struct {
 	Time *Code[TimeSequenceCode, CodeSystemOID] `xml:",omitempty"`
 	Lead *Code[LeadCode, CodeSystemOID]         `xml:",omitempty"`
+
+	// Other holds a code that is neither a time nor an MDC code (e.g. a
+	// vendor sequence), kept so that the sequence is written back.
+	Other *Code[string, CodeSystemOID] `xml:",omitempty"`
 }
 
 // MarshalXML handles encoding of SequenceCode to avoid the wrapper element.
@@ -151,6 +160,9 @@ func (sc SequenceCode) MarshalXML(e *xml.Encoder, start xml.StartElement) error
 	if sc.Lead != nil {
 		return e.EncodeElement(sc.Lead, start)
 	}
+	if sc.Other != nil {
+		return e.EncodeElement(sc.Other, start)
+	}
 
 	// Empty code element
 	return e.EncodeElement("", start)
@@ -181,8 +193,9 @@ func (sc *SequenceCode) UnmarshalXML(d *xml.Decoder, start xml.StartElement) err
 		return d.DecodeElement(sc.Lead, &start)
 	}
 
-	// Unknown code type, skip
-	return d.Skip()
+	// Unknown code type, kept as is
+	sc.Other = &Code[string, CodeSystemOID]{}
+	return d.DecodeElement(sc.Other, &start)
 }
 
 // SequenceValue represents the polymorphic value field in a Sequence.
@@ -205,6 +218,8 @@ type SequenceValue struct {
 	//   - *SLIST_PQ
 	//   - *SLIST_INT
 	Typed any `xml:"-"`
+
+	Extensions `xml:"-"`
 }
 
 // =============================================================================
@@ -262,6 +277,8 @@ type GLIST_TS struct {
 	// XML Tag: <increment value="..." unit="..."/>
 	// Cardinality: Required
 	Increment Increment `xml:"increment"`
+
+	Extensions
 }
 
 // HeadTimestamp represents the head timestamp in GLIST_TS with value and unit.
@@ -283,6 +300,8 @@ type HeadTimestamp struct {
 	// XML Tag: unit="..."
 	// Cardinality: Optional (defaults to "s")
 	Unit string `xml:"unit,attr,omitempty"`
+
+	Extensions
 }
 
 // Increment represents a time increment with value and unit.
@@ -308,6 +327,8 @@ type Increment struct {
 	// XML Tag: unit="..."
 	// Cardinality: Required
 	Unit string `xml:"unit,attr"`
+
+	Extensions
 }
 
 // =============================================================================
@@ -353,6 +374,8 @@ type GLIST_PQ struct {
 	// XML Tag: <increment value="..." unit="..."/>
 	// Cardinality: Required
 	Increment PhysicalQuantity `xml:"increment"`
+
+	Extensions
 }
 
 // GetValues calculates all values in the GLIST_PQ given a length.
@@ -459,11 +482,19 @@ type SLIST_PQ struct {
 	//
 	// Example: "1 2 3 4 5" or "-2 -2 -2 -2 -3 -4 -3 -5 -5 -4 -6 -9 -9"
 	//
-	// The string may contain thousands of values separated by spaces.
+	// The string may contain thousands of values separated by spaces. When
+	// decoded, it is parsed once, on first access (see GetDigitValues); it
+	// is left empty by SetDigitValues: the values are then formatted on
+	// encoding.
 	//
 	// XML Tag: <digits>...</digits>
 	// Cardinality: Required
 	Digits string `xml:"digits"`
+
+	Extensions
+
+	// digits are the Digits as integers.
+	digits digitCache
 }
 
 // PhysicalQuantity represents a physical measurement with value and unit.
@@ -502,6 +533,8 @@ type PhysicalQuantity struct {
 	// XML Tag: unit="..."
 	// Cardinality: Required in SLIST_PQ, Optional elsewhere
 	Unit string `xml:"unit,attr,omitempty"`
+
+	Extensions
 }
 
 // GetValueFloat parses the Value string as a float64.
@@ -519,35 +552,71 @@ func (pq *PhysicalQuantity) GetValueFloat() (float64, bool) {
 	return val, true
 }
 
-// GetDigits parses the Digits string into a slice of integers.
+// GetDigitValues returns the digits, parsed from Digits on the first call
+// only. It can be called from concurrent goroutines.
 //
-// Returns a slice of integer values parsed from the space-separated string.
-// Returns error if parsing fails.
-func (s *SLIST_PQ) GetDigits() ([]int, error) {
-	if s.Digits == "" {
-		return []int{}, nil
-	}
+// The slice is the one held by the sequence, not a copy: it must not be
+// modified. Returns error if parsing fails (a digit is not an integer in
+// the int32 range).
+func (s *SLIST_PQ) GetDigitValues() ([]int32, error) {
+	return s.digits.get(s.Digits)
+}
 
-	digitStrs := strings.Fields(s.Digits)
-	digits := make([]int, len(digitStrs))
+// SetDigitValues replaces the digits by values, and empties Digits: the
+// values are formatted only when the sequence is encoded.
+//
+// The slice is kept, not copied: it must not be modified afterwards.
+func (s *SLIST_PQ) SetDigitValues(values []int32) *SLIST_PQ {
+	s.Digits = ""
+	s.digits.set(values)
+	return s
+}
 
-	for i, str := range digitStrs {
-		val, err := strconv.Atoi(str)
-		if err != nil {
-			return nil, err
-		}
-		digits[i] = val
+// GetDigits returns the digits as a new slice of integers.
+//
+// Returns error if parsing fails.
+func (s *SLIST_PQ) GetDigits() ([]int, error) {
+	values, err := s.GetDigitValues()
+	if err != nil {
+		return nil, err
 	}
-
-	return digits, nil
+	return int32sToInts(values), nil
 }
 
 // GetLength returns the number of samples in this sequence.
 func (s *SLIST_PQ) GetLength() int {
+	return s.digits.length(s.Digits)
+}
+
+// cache returns the digit cache of the sequence.
+func (s *SLIST_PQ) cache() *digitCache {
+	return &s.digits
+}
+
+// MarshalXML encodes the sequence, with its digits formatted if they were
+// set with SetDigitValues.
+func (s *SLIST_PQ) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	out := struct {
+		Origin PhysicalQuantity `xml:"origin"`
+		Scale  PhysicalQuantity `xml:"scale"`
+		Digits digitsXML        `xml:"digits"`
+		Extensions
+	}{Origin: s.Origin, Scale: s.Scale, Digits: digitsXML{text: s.Digits}, Extensions: s.Extensions}
 	if s.Digits == "" {
-		return 0
+		out.Digits.values, _ = s.GetDigitValues()
 	}
-	return len(strings.Fields(s.Digits))
+	return e.EncodeElement(&out, start)
+}
+
+// UnmarshalXML decodes the sequence, and records Digits for its first
+// access.
+func (s *SLIST_PQ) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type alias SLIST_PQ
+	if err := d.DecodeElement((*alias)(s), &start); err != nil {
+		return err
+	}
+	s.digits.setText(s.Digits)
+	return nil
 }
 
 // GetActualValues calculates the actual physical values from digits.
@@ -557,7 +626,7 @@ func (s *SLIST_PQ) GetLength() int {
 // Returns a slice of floating-point values in the units specified by Origin/Scale.
 // Returns error if parsing fails.
 func (s *SLIST_PQ) GetActualValues() ([]float64, error) {
-	digits, err := s.GetDigits()
+	digits, err := s.GetDigitValues()
 	if err != nil {
 		return nil, err
 	}
@@ -580,6 +649,15 @@ func (s *SLIST_PQ) GetActualValues() ([]float64, error) {
 	return values, nil
 }
 
+// int32sToInts returns the values as a new slice of int.
+func int32sToInts(values []int32) []int {
+	ints := make([]int, len(values))
+	for i, v := range values {
+		ints[i] = int(v)
+	}
+	return ints
+}
+
 // =============================================================================
 // SLIST_INT - Scaled List of Integers
 // =============================================================================
@@ -598,52 +676,104 @@ type SLIST_INT struct {
 	//
 	// XML Tag: <origin value="..."/>
 	// Cardinality: Required
-	Origin int `xml:"origin,attr"`
+	Origin int `xml:"origin"`
 
 	// Scale is the multiplication factor applied to raw digits.
 	//
 	// XML Tag: <scale value="..."/>
 	// Cardinality: Required
-	Scale int `xml:"scale,attr"`
+	Scale int `xml:"scale"`
 
 	// Digits are the raw integer values.
 	//
-	// Space-separated integers that are scaled and offset.
+	// Space-separated integers that are scaled and offset. Parsed and
+	// formatted as the digits of SLIST_PQ.
 	//
 	// XML Tag: <digits>...</digits>
 	// Cardinality: Required
 	Digits string `xml:"digits"`
+
+	Extensions
+
+	// digits are the Digits as integers.
+	digits digitCache
 }
 
-// GetDigits parses the Digits string into a slice of integers.
-//
-// Returns a slice of integer values parsed from the space-separated string.
-// Returns error if parsing fails.
-func (s *SLIST_INT) GetDigits() ([]int, error) {
+// slistIntXML is the XML form of SLIST_INT, whose origin and scale are
+// elements with a value attribute.
+type slistIntXML struct {
+	Origin struct {
+		Value int `xml:"value,attr"`
+	} `xml:"origin"`
+	Scale struct {
+		Value int `xml:"value,attr"`
+	} `xml:"scale"`
+	Digits digitsXML `xml:"digits"`
+	Extensions
+}
+
+// MarshalXML encodes the origin and scale as <origin value="..."/> and
+// <scale value="..."/> elements.
+func (s *SLIST_INT) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	var out slistIntXML
+	out.Origin.Value, out.Scale.Value, out.Extensions = s.Origin, s.Scale, s.Extensions
+	out.Digits.text = s.Digits
 	if s.Digits == "" {
-		return []int{}, nil
+		out.Digits.values, _ = s.GetDigitValues()
 	}
+	return e.EncodeElement(&out, start)
+}
 
-	digitStrs := strings.Fields(s.Digits)
-	digits := make([]int, len(digitStrs))
-
-	for i, str := range digitStrs {
-		val, err := strconv.Atoi(str)
-		if err != nil {
-			return nil, err
-		}
-		digits[i] = val
+// UnmarshalXML decodes the origin and scale elements, and records Digits
+// for its first access.
+func (s *SLIST_INT) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	var in slistIntXML
+	if err := d.DecodeElement(&in, &start); err != nil {
+		return err
 	}
+	*s = SLIST_INT{Origin: in.Origin.Value, Scale: in.Scale.Value, Digits: in.Digits.text, Extensions: in.Extensions}
+	s.digits.setText(s.Digits)
+	return nil
+}
+
+// GetDigitValues returns the digits, parsed from Digits on the first call
+// only. It can be called from concurrent goroutines.
+//
+// The slice is the one held by the sequence, not a copy: it must not be
+// modified. Returns error if parsing fails.
+func (s *SLIST_INT) GetDigitValues() ([]int32, error) {
+	return s.digits.get(s.Digits)
+}
+
+// SetDigitValues replaces the digits by values, and empties Digits: the
+// values are formatted only when the sequence is encoded.
+//
+// The slice is kept, not copied: it must not be modified afterwards.
+func (s *SLIST_INT) SetDigitValues(values []int32) *SLIST_INT {
+	s.Digits = ""
+	s.digits.set(values)
+	return s
+}
 
-	return digits, nil
+// GetDigits returns the digits as a new slice of integers.
+//
+// Returns error if parsing fails.
+func (s *SLIST_INT) GetDigits() ([]int, error) {
+	values, err := s.GetDigitValues()
+	if err != nil {
+		return nil, err
+	}
+	return int32sToInts(values), nil
 }
 
 // GetLength returns the number of values in this sequence.
 func (s *SLIST_INT) GetLength() int {
-	if s.Digits == "" {
-		return 0
-	}
-	return len(strings.Fields(s.Digits))
+	return s.digits.length(s.Digits)
+}
+
+// cache returns the digit cache of the sequence.
+func (s *SLIST_INT) cache() *digitCache {
+	return &s.digits
 }
 
 // GetActualValues calculates the actual integer values from digits.
@@ -670,15 +800,14 @@ func (s *SLIST_INT) GetActualValues() ([]int, error) {
 func (sv *SequenceValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
 	// Extract xsi:type attribute from start element.
 	// Go's encoding/xml doesn't handle namespaced attributes with prefix notation,
-	// so we must manually extract attributes where Local="type" and the namespace
-	// is "http://www.w3.org/2001/XMLSchema-instance" or the prefix is "xsi".
+	// so we must manually extract it (see isXsiType).
+	var extraAttrs []xml.Attr
 	for _, attr := range start.Attr {
-		if attr.Name.Local == "type" &&
-			(attr.Name.Space == "http://www.w3.org/2001/XMLSchema-instance" ||
-				attr.Name.Space == "xsi") {
+		if isXsiType(attr.Name) {
 			sv.XsiType = attr.Value
-			break
+			continue
 		}
+		extraAttrs = append(extraAttrs, attr)
 	}
 
 	type Alias SequenceValue
@@ -691,51 +820,40 @@ func (sv *SequenceValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) er
 	xsiType := sv.XsiType
 	*sv = SequenceValue(*aux)
 	sv.XsiType = xsiType
+	sv.ExtraAttrs = extraAttrs
 
 	// RawXML contains just child elements without a root, so we need to wrap it
 	// in a temporary root element for xml.Unmarshal to work correctly.
 	wrappedXML := append([]byte("<root>"), sv.RawXML...)
 	wrappedXML = append(wrappedXML, []byte("</root>")...)
 
+	// Child elements the typed value does not model are kept in its
+	// Extensions.
 	switch sv.XsiType {
 	case "GLIST_TS":
-		var wrapper struct {
-			Head      HeadTimestamp `xml:"head"`
-			Increment Increment     `xml:"increment"`
-		}
-		if err := xml.Unmarshal(wrappedXML, &wrapper); err != nil {
+		typed := &GLIST_TS{}
+		if err := xml.Unmarshal(wrappedXML, typed); err != nil {
 			return err
 		}
-		sv.Typed = &GLIST_TS{Head: wrapper.Head, Increment: wrapper.Increment}
+		sv.Typed = typed
 	case "GLIST_PQ":
-		var wrapper struct {
-			Head      PhysicalQuantity `xml:"head"`
-			Increment PhysicalQuantity `xml:"increment"`
-		}
-		if err := xml.Unmarshal(wrappedXML, &wrapper); err != nil {
+		typed := &GLIST_PQ{}
+		if err := xml.Unmarshal(wrappedXML, typed); err != nil {
 			return err
 		}
-		sv.Typed = &GLIST_PQ{Head: wrapper.Head, Increment: wrapper.Increment}
+		sv.Typed = typed
 	case "SLIST_PQ":
-		var wrapper struct {
-			Origin PhysicalQuantity `xml:"origin"`
-			Scale  PhysicalQuantity `xml:"scale"`
-			Digits string           `xml:"digits"`
-		}
-		if err := xml.Unmarshal(wrappedXML, &wrapper); err != nil {
+		typed := &SLIST_PQ{}
+		if err := xml.Unmarshal(wrappedXML, typed); err != nil {
 			return err
 		}
-		sv.Typed = &SLIST_PQ{Origin: wrapper.Origin, Scale: wrapper.Scale, Digits: wrapper.Digits}
+		sv.Typed = typed
 	case "SLIST_INT":
-		var wrapper struct {
-			Origin int    `xml:"origin"`
-			Scale  int    `xml:"scale"`
-			Digits string `xml:"digits"`
-		}
-		if err := xml.Unmarshal(wrappedXML, &wrapper); err != nil {
+		typed := &SLIST_INT{}
+		if err := xml.Unmarshal(wrappedXML, typed); err != nil {
 			return err
 		}
-		sv.Typed = &SLIST_INT{Origin: wrapper.Origin, Scale: wrapper.Scale, Digits: wrapper.Digits}
+		sv.Typed = typed
 	default:
 		// Unknown type — keep raw XML
 		sv.Typed = nil
@@ -748,10 +866,7 @@ func (sv *SequenceValue) UnmarshalXML(d *xml.Decoder, start xml.StartElement) er
 func (sv *SequenceValue) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
 	start.Name = xml.Name{Local: "value"}
 	if sv.XsiType != "" {
-		start.Attr = append(start.Attr, xml.Attr{
-			Name:  xml.Name{Local: "xsi:type"},
-			Value: sv.XsiType,
-		})
+		start.Attr = append(start.Attr, xsiTypeAttr(sv.XsiType))
 	}
 
 	var inner any
@@ -767,15 +882,13 @@ func (sv *SequenceValue) MarshalXML(e *xml.Encoder, start xml.StartElement) erro
 		inner, _ = sv.Typed.(*SLIST_INT)
 	}
 
+	start.Attr = append(start.Attr, sv.ExtraAttrs...)
+
 	// If type not recognized or Typed is nil, fallback to raw XML
 	if inner == nil {
-		if len(sv.RawXML) > 0 {
-			e.EncodeToken(start)
-			e.EncodeToken(xml.CharData(sv.RawXML))
-			e.EncodeToken(xml.EndElement{Name: start.Name})
-			return nil
-		}
-		return e.EncodeElement(nil, start)
+		return e.EncodeElement(struct {
+			Inner []byte `xml:",innerxml"`
+		}{sv.RawXML}, start)
 	}
 
 	// Encode with the correct struct
@@ -1,5 +1,10 @@
 package types
 
+import (
+	"fmt"
+	"strconv"
+)
+
 // =============================================================================
 // Series Types
 // =============================================================================
@@ -152,6 +157,8 @@ type Series struct {
 	// Example: Comments about signal quality, artifacts, or other observations
 	//	XML Tag: <subjectOf>...</subjectOf>
 	SubjectOf []SubjectOf `xml:"subjectOf,omitempty"`
+
+	Extensions
 }
 
 func NewSeries() *Series {
@@ -176,6 +183,8 @@ type Author struct {
 	// XML Tag: <seriesAuthor>...</seriesAuthor>
 	// Cardinality: Required (within Author)
 	SeriesAuthor SeriesAuthor `xml:"seriesAuthor"`
+
+	Extensions
 }
 
 // SeriesAuthor describes the device that authored (recorded) the series waveforms.
@@ -229,6 +238,8 @@ type SeriesAuthor struct {
 	// XML Tag: <manufacturerOrganization>...</manufacturerOrganization>
 	// Cardinality: Optional
 	ManufacturerOrganization *ManufacturerOrganization `xml:"manufacturerOrganization,omitempty"`
+
+	Extensions
 }
 
 // ManufacturedSeriesDevice represents the ECG device specifications.
@@ -298,6 +309,8 @@ type ManufacturedSeriesDevice struct {
 	// XML Tag: <softwareName>...</softwareName>
 	// Cardinality: Optional
 	SoftwareName *string `xml:"softwareName,omitempty"`
+
+	Extensions
 }
 
 // ManufacturerOrganization represents the organization that manufactured the device.
@@ -328,6 +341,8 @@ type ManufacturerOrganization struct {
 	// XML Tag: <n>...</n>
 	// Cardinality: Optional
 	Name *string `xml:"name,omitempty"`
+
+	Extensions
 }
 
 // SecondaryPerformer describes a technician operating the device that captured
@@ -386,6 +401,8 @@ type SecondaryPerformer struct {
 	// XML Tag: <seriesPerformer>...</seriesPerformer>
 	// Cardinality: Required (within SecondaryPerformer)
 	SeriesPerformer SeriesPerformer `xml:"seriesPerformer"`
+
+	Extensions
 }
 
 // SeriesPerformer represents a technician who performed ECG acquisition.
@@ -417,6 +434,8 @@ type SeriesPerformer struct {
 	// XML Tag: <assignedPerson>...</assignedPerson>
 	// Cardinality: Optional
 	AssignedPerson *SeriesAssignedPerson `xml:"assignedPerson,omitempty"`
+
+	Extensions
 }
 
 // SeriesAssignedPerson represents a person assigned to perform ECG tasks.
@@ -439,6 +458,8 @@ type SeriesAssignedPerson struct {
 	// XML Tag: <n>...</n>
 	// Cardinality: Optional
 	Name *string `xml:"name,omitempty"`
+
+	Extensions
 }
 
 // SeriesSupport defines the region of interest if this series is derived
@@ -465,6 +486,8 @@ type SeriesSupport struct {
 	// XML Tag: <supportingROI>...</supportingROI>
 	// Cardinality: Required (within Support)
 	SupportingROI SupportingROI `xml:"supportingROI"`
+
+	Extensions
 }
 
 // SupportingROI represents a region of interest in a parent series.
@@ -488,6 +511,8 @@ type SupportingROI struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional (0..*)
 	Component []ROIComponent `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // ROIComponent contains a boundary definition for a region of interest.
@@ -499,6 +524,8 @@ type ROIComponent struct {
 	// XML Tag: <boundary>...</boundary>
 	// Cardinality: Required (within Component)
 	Boundary Boundary `xml:"boundary"`
+
+	Extensions
 }
 
 // Boundary defines the limits of a region of interest.
@@ -525,6 +552,8 @@ type Boundary struct {
 	// XML Tag: <value>...</value>
 	// Cardinality: Optional
 	Value *BoundaryValue `xml:"value,omitempty"`
+
+	Extensions
 }
 
 // BoundaryValue represents a range value for a boundary.
@@ -544,6 +573,8 @@ type BoundaryValue struct {
 	// XML Tag: <high value="..."/>
 	// Cardinality: Optional
 	High *int `xml:"high,attr,omitempty"`
+
+	Extensions
 }
 
 // SeriesComponent contains a sequence set with waveform data.
@@ -561,6 +592,8 @@ type SeriesComponent struct {
 	// XML Tag: <sequenceSet>...</sequenceSet>
 	// Cardinality: Required
 	SequenceSet SequenceSet `xml:"sequenceSet"`
+
+	Extensions
 }
 
 // Derivation wraps a DerivedSeries to provide the correct XML structure.
@@ -595,6 +628,8 @@ type Derivation struct {
 	// XML Tag: <derivedSeries>...</derivedSeries>
 	// Cardinality: Required (within Derivation)
 	DerivedSeries Series `xml:"derivedSeries"`
+
+	Extensions
 }
 
 // SubjectOf represents annotations or observations about the series.
@@ -624,6 +659,8 @@ type SubjectOf struct {
 	// XML Tag: <annotationSet>...</annotationSet>
 	// Cardinality: Optional
 	AnnotationSet *AnnotationSet `xml:"annotationSet,omitempty"`
+
+	Extensions
 }
 
 // =============================================================================
@@ -632,9 +669,9 @@ type SubjectOf struct {
 
 // InitAnnotationSet creates and initializes a new AnnotationSet for the series.
 //
-// If the series already has SubjectOf elements, this method initializes the
-// AnnotationSet on the first SubjectOf element. Otherwise, it creates a new
-// SubjectOf element.
+// The new set is attached to the first SubjectOf element that has no
+// AnnotationSet, or to a new SubjectOf element. Existing annotation sets are
+// never overwritten, so each call adds one more set to the series.
 //
 // Parameters:
 //   - activityTime: The time when annotations were made (format: YYYYMMDDHHmmss)
@@ -653,24 +690,48 @@ func (s *Series) InitAnnotationSet(activityTime string) *AnnotationSet {
 		return nil
 	}
 
-	// Create SubjectOf if it doesn't exist
-	if len(s.SubjectOf) == 0 {
-		s.SubjectOf = []SubjectOf{{}}
-	}
-
-	// Initialize AnnotationSet with activity time
-	s.SubjectOf[0].AnnotationSet = &AnnotationSet{
+	annSet := &AnnotationSet{
 		ActivityTime: &Time{Value: activityTime},
 		Component:    []AnnotationComponent{},
 	}
 
-	return s.SubjectOf[0].AnnotationSet
+	// Reuse an empty SubjectOf if present
+	for i := range s.SubjectOf {
+		if s.SubjectOf[i].AnnotationSet == nil {
+			s.SubjectOf[i].AnnotationSet = annSet
+			return annSet
+		}
+	}
+
+	s.SubjectOf = append(s.SubjectOf, SubjectOf{AnnotationSet: annSet})
+	return annSet
 }
 
-// GetOrCreateAnnotationSet returns the existing AnnotationSet or creates a new one.
+// AddAnnotationSet adds a new AnnotationSet attributed to the given authors.
 //
-// If the series already has an AnnotationSet, this method returns it.
-// Otherwise, it creates a new one with the given activityTime.
+// Equivalent to InitAnnotationSet followed by appending the authors. Use
+// AnnotationSet.AddPersonAuthor and AddDeviceAuthor for the common cases.
+//
+// Example:
+//
+//	machine := series.AddAnnotationSet("20250923103600")
+//	machine.AddDeviceAuthor(AUTHOR_FUNCTION_ALGORITHM, "2.16.840.1.113883.3.5", "MEASURE-1.0", "", "hl7v3-aecg")
+//
+//	reader := series.AddAnnotationSet("20250924090000")
+//	reader.AddPersonAuthor(AUTHOR_FUNCTION_PRIMARY_READER, "2.16.840.1.113883.3.5", "READER-01", "")
+func (s *Series) AddAnnotationSet(activityTime string, authors ...AnnotationSetAuthor) *AnnotationSet {
+	annSet := s.InitAnnotationSet(activityTime)
+	if annSet == nil {
+		return nil
+	}
+	annSet.Author = append(annSet.Author, authors...)
+	return annSet
+}
+
+// GetOrCreateAnnotationSet returns the first existing AnnotationSet or creates a new one.
+//
+// All SubjectOf elements are searched, not only the first one.
+// If none holds an AnnotationSet, a new one is created with the given activityTime.
 //
 // Parameters:
 //   - activityTime: The time to use if creating a new AnnotationSet
@@ -683,10 +744,233 @@ func (s *Series) GetOrCreateAnnotationSet(activityTime string) *AnnotationSet {
 	}
 
 	// Return existing if present
-	if len(s.SubjectOf) > 0 && s.SubjectOf[0].AnnotationSet != nil {
-		return s.SubjectOf[0].AnnotationSet
+	if sets := s.GetAnnotationSets(); len(sets) > 0 {
+		return sets[0]
 	}
 
 	// Otherwise create new
 	return s.InitAnnotationSet(activityTime)
 }
+
+// GetAnnotationSets returns all annotation sets of the series in document order.
+func (s *Series) GetAnnotationSets() []*AnnotationSet {
+	sets := []*AnnotationSet{}
+	if s == nil {
+		return sets
+	}
+	for i := range s.SubjectOf {
+		if s.SubjectOf[i].AnnotationSet != nil {
+			sets = append(sets, s.SubjectOf[i].AnnotationSet)
+		}
+	}
+	return sets
+}
+
+// GetAnnotationSetsByAuthorFunction returns the annotation sets having an
+// author with the given function code (e.g., AUTHOR_FUNCTION_PRIMARY_READER).
+func (s *Series) GetAnnotationSetsByAuthorFunction(function AuthorFunctionCode) []*AnnotationSet {
+	sets := []*AnnotationSet{}
+	for _, as := range s.GetAnnotationSets() {
+		if as.HasAuthorFunction(function) {
+			sets = append(sets, as)
+		}
+	}
+	return sets
+}
+
+// GetAnnotationSetByAuthorID returns the first annotation set authored by the
+// entity with the given ID. An empty extension matches any extension.
+// Returns nil if not found.
+func (s *Series) GetAnnotationSetByAuthorID(root, extension string) *AnnotationSet {
+	for _, as := range s.GetAnnotationSets() {
+		if as.HasAuthorID(root, extension) {
+			return as
+		}
+	}
+	return nil
+}
+
+// =============================================================================
+// Series Waveform Accessors
+// =============================================================================
+
+// GetSampleRate returns the sampling frequency of the series in Hz.
+//
+// The rate is derived from the increment of the first time sequence found in
+// the series (GLIST_TS for TIME_ABSOLUTE or GLIST_PQ for TIME_RELATIVE), or
+// from the spacing of the times listed by an SLIST_PQ sequence.
+// Increments may be expressed in any UCUM time unit (see IsUCUMTimeUnit).
+//
+// Returns:
+//   - float64: Sampling frequency in Hz
+//   - error: ErrMissingTimeSequence, ErrInvalidIncrement or ErrIrregularSampling
+func (s *Series) GetSampleRate() (float64, error) {
+	if s == nil {
+		return 0, ErrMissingTimeSequence
+	}
+
+	for _, comp := range s.Component {
+		for _, seqComp := range comp.SequenceSet.Component {
+			seq := seqComp.Sequence
+			if seq.Code.Time == nil || seq.Value == nil {
+				continue
+			}
+
+			var value, unit string
+			switch typed := seq.Value.Typed.(type) {
+			case *GLIST_TS:
+				value, unit = typed.Increment.Value, typed.Increment.Unit
+			case *GLIST_PQ:
+				value, unit = typed.Increment.Value, typed.Increment.Unit
+			case *SLIST_PQ:
+				return slistSampleRate(typed)
+			default:
+				continue
+			}
+
+			increment, err := strconv.ParseFloat(value, 64)
+			if err != nil || increment <= 0 {
+				return 0, ErrInvalidIncrement
+			}
+			factor, ok := UCUMTimeUnitSeconds(unit)
+			if !ok {
+				return 0, ErrInvalidIncrement
+			}
+			return 1 / (increment * factor), nil
+		}
+	}
+
+	return 0, ErrMissingTimeSequence
+}
+
+// slistSampleRate returns the sampling frequency of an SLIST_PQ time
+// sequence, whose digits must increase by a constant step.
+func slistSampleRate(s *SLIST_PQ) (float64, error) {
+	scale, err := strconv.ParseFloat(s.Scale.Value, 64)
+	if err != nil || scale <= 0 {
+		return 0, ErrInvalidIncrement
+	}
+	factor, ok := UCUMTimeUnitSeconds(s.Scale.Unit)
+	if !ok {
+		return 0, ErrInvalidIncrement
+	}
+	times, err := s.GetDigitValues()
+	if err != nil || len(times) < 2 {
+		return 0, ErrInvalidIncrement
+	}
+
+	step := int64(times[1]) - int64(times[0])
+	if step <= 0 {
+		return 0, ErrInvalidIncrement
+	}
+	for i := 2; i < len(times); i++ {
+		if int64(times[i])-int64(times[i-1]) != step {
+			return 0, ErrIrregularSampling
+		}
+	}
+	return 1 / (float64(step) * scale * factor), nil
+}
+
+// GetLeadCodes returns the lead codes present in the series, in document order.
+//
+// Leads appearing in more than one sequence set are reported once.
+func (s *Series) GetLeadCodes() []LeadCode {
+	if s == nil {
+		return nil
+	}
+
+	var leads []LeadCode
+	seen := make(map[LeadCode]bool)
+	for _, comp := range s.Component {
+		for _, seqComp := range comp.SequenceSet.Component {
+			lead := seqComp.Sequence.Code.Lead
+			if lead == nil || seen[lead.Code] {
+				continue
+			}
+			seen[lead.Code] = true
+			leads = append(leads, lead.Code)
+		}
+	}
+	return leads
+}
+
+// GetLeadValues returns the decoded samples of a lead in microvolts.
+//
+// SLIST_PQ sequences are converted from their scale unit (uV, mV or V).
+// SLIST_INT sequences carry no unit and are returned as-is.
+//
+// Parameters:
+//   - lead: The lead code to look up (e.g., MDC_ECG_LEAD_II)
+//
+// Returns:
+//   - []float64: Sample values in µV
+//   - error: ErrMissingLeadSequence if the lead is absent, or a decoding error
+func (s *Series) GetLeadValues(lead LeadCode) ([]float64, error) {
+	if s == nil {
+		return nil, ErrMissingLeadSequence
+	}
+
+	for _, comp := range s.Component {
+		for _, seqComp := range comp.SequenceSet.Component {
+			seq := seqComp.Sequence
+			if seq.Code.Lead == nil || seq.Code.Lead.Code != lead || seq.Value == nil {
+				continue
+			}
+			return sequenceValuesMicrovolts(seq.Value)
+		}
+	}
+
+	return nil, ErrMissingLeadSequence
+}
+
+// GetLeadValuesMap returns the decoded samples of every lead in microvolts, keyed by lead code.
+func (s *Series) GetLeadValuesMap() (map[LeadCode][]float64, error) {
+	leads := s.GetLeadCodes()
+	if len(leads) == 0 {
+		return nil, ErrMissingLeadSequence
+	}
+
+	values := make(map[LeadCode][]float64, len(leads))
+	for _, lead := range leads {
+		v, err := s.GetLeadValues(lead)
+		if err != nil {
+			return nil, fmt.Errorf("lead %s: %w", lead, err)
+		}
+		values[lead] = v
+	}
+	return values, nil
+}
+
+// sequenceValuesMicrovolts decodes an SLIST_PQ or SLIST_INT value to µV.
+func sequenceValuesMicrovolts(sv *SequenceValue) ([]float64, error) {
+	switch typed := sv.Typed.(type) {
+	case *SLIST_PQ:
+		unit := typed.Scale.Unit
+		if unit == "" {
+			unit = UNIT_MICROVOLT // Assumed when missing
+		}
+		factor, ok := UCUMVoltageUnitMicrovolts(unit)
+		if !ok {
+			return nil, ErrInvalidScale
+		}
+		values, err := typed.GetActualValues()
+		if err != nil {
+			return nil, err
+		}
+		for i := range values {
+			values[i] *= factor
+		}
+		return values, nil
+	case *SLIST_INT:
+		ints, err := typed.GetActualValues()
+		if err != nil {
+			return nil, err
+		}
+		values := make([]float64, len(ints))
+		for i, v := range ints {
+			values[i] = float64(v)
+		}
+		return values, nil
+	}
+	return nil, fmt.Errorf("unsupported sequence value type %q", sv.XsiType)
+}
@@ -0,0 +1,143 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWaveformSeries builds a series with one time sequence and the given lead sequences.
+func newWaveformSeries(timeValue *SequenceValue, leads map[LeadCode]*SequenceValue, order ...LeadCode) *Series {
+	set := SequenceSet{}
+	if timeValue != nil {
+		set.Component = append(set.Component, SequenceComponent{Sequence: Sequence{
+			Code:  SequenceCode{Time: &Code[TimeSequenceCode, CodeSystemOID]{Code: TIME_RELATIVE_CODE}},
+			Value: timeValue,
+		}})
+	}
+	for _, lead := range order {
+		set.Component = append(set.Component, SequenceComponent{Sequence: Sequence{
+			Code:  SequenceCode{Lead: &Code[LeadCode, CodeSystemOID]{Code: lead, CodeSystem: MDC_OID}},
+			Value: leads[lead],
+		}})
+	}
+	return &Series{Component: []SeriesComponent{{SequenceSet: set}}}
+}
+
+// TestSeries_GetSampleRate tests sample rate extraction from time sequences
+func TestSeries_GetSampleRate(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   *SequenceValue
+		want    float64
+		wantErr error
+	}{
+		{
+			name:  "GLIST_TS in seconds",
+			value: &SequenceValue{XsiType: "GLIST_TS", Typed: &GLIST_TS{Increment: Increment{Value: "0.002", Unit: "s"}}},
+			want:  500,
+		},
+		{
+			name:  "GLIST_PQ in milliseconds",
+			value: &SequenceValue{XsiType: "GLIST_PQ", Typed: &GLIST_PQ{Increment: PhysicalQuantity{Value: "1", Unit: "ms"}}},
+			want:  1000,
+		},
+		{
+			name:    "Zero increment",
+			value:   &SequenceValue{XsiType: "GLIST_PQ", Typed: &GLIST_PQ{Increment: PhysicalQuantity{Value: "0", Unit: "s"}}},
+			wantErr: ErrInvalidIncrement,
+		},
+		{
+			name:  "GLIST_PQ in microseconds",
+			value: &SequenceValue{XsiType: "GLIST_PQ", Typed: &GLIST_PQ{Increment: PhysicalQuantity{Value: "2000", Unit: "us"}}},
+			want:  500,
+		},
+		{
+			name:    "Missing unit",
+			value:   &SequenceValue{XsiType: "GLIST_PQ", Typed: &GLIST_PQ{Increment: PhysicalQuantity{Value: "0.002"}}},
+			wantErr: ErrInvalidIncrement,
+		},
+		{
+			name:    "Unknown unit",
+			value:   &SequenceValue{XsiType: "GLIST_PQ", Typed: &GLIST_PQ{Increment: PhysicalQuantity{Value: "1", Unit: "furlong"}}},
+			wantErr: ErrInvalidIncrement,
+		},
+		{
+			name: "SLIST_PQ evenly spaced",
+			value: &SequenceValue{XsiType: "SLIST_PQ", Typed: &SLIST_PQ{
+				Scale: PhysicalQuantity{Value: "1", Unit: "ms"}, Digits: "0 4 8 12",
+			}},
+			want: 250,
+		},
+		{
+			name: "SLIST_PQ irregular",
+			value: &SequenceValue{XsiType: "SLIST_PQ", Typed: &SLIST_PQ{
+				Scale: PhysicalQuantity{Value: "1", Unit: "ms"}, Digits: "0 2 5 7",
+			}},
+			wantErr: ErrIrregularSampling,
+		},
+		{
+			name:    "No time sequence",
+			value:   nil,
+			wantErr: ErrMissingTimeSequence,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			series := newWaveformSeries(tt.value, nil)
+			got, err := series.GetSampleRate()
+			if tt.wantErr != nil {
+				assert.True(t, errors.Is(err, tt.wantErr), "error = %v, want %v", err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.InDelta(t, tt.want, got, 1e-9)
+		})
+	}
+}
+
+// TestSeries_GetLeadValues tests lead decoding and unit conversion to microvolts
+func TestSeries_GetLeadValues(t *testing.T) {
+	leads := map[LeadCode]*SequenceValue{
+		MDC_ECG_LEAD_I: {XsiType: "SLIST_PQ", Typed: &SLIST_PQ{
+			Origin: PhysicalQuantity{Value: "0", Unit: "uV"},
+			Scale:  PhysicalQuantity{Value: "5", Unit: "uV"},
+			Digits: "1 2 -3",
+		}},
+		MDC_ECG_LEAD_II: {XsiType: "SLIST_PQ", Typed: &SLIST_PQ{
+			Origin: PhysicalQuantity{Value: "0", Unit: "mV"},
+			Scale:  PhysicalQuantity{Value: "0.01", Unit: "mV"},
+			Digits: "10 0 -10",
+		}},
+		MDC_ECG_LEAD_V1: {XsiType: "SLIST_INT", Typed: &SLIST_INT{Origin: 1, Scale: 2, Digits: "0 1 2"}},
+	}
+	series := newWaveformSeries(nil, leads, MDC_ECG_LEAD_II, MDC_ECG_LEAD_I, MDC_ECG_LEAD_V1)
+
+	assert.Equal(t, []LeadCode{MDC_ECG_LEAD_II, MDC_ECG_LEAD_I, MDC_ECG_LEAD_V1}, series.GetLeadCodes())
+
+	values, err := series.GetLeadValues(MDC_ECG_LEAD_I)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{5, 10, -15}, values)
+
+	values, err = series.GetLeadValues(MDC_ECG_LEAD_II)
+	require.NoError(t, err)
+	assert.InDeltaSlice(t, []float64{100, 0, -100}, values, 1e-9)
+
+	values, err = series.GetLeadValues(MDC_ECG_LEAD_V1)
+	require.NoError(t, err)
+	assert.Equal(t, []float64{1, 3, 5}, values)
+
+	_, err = series.GetLeadValues(MDC_ECG_LEAD_V6)
+	assert.ErrorIs(t, err, ErrMissingLeadSequence)
+
+	all, err := series.GetLeadValuesMap()
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+
+	leads[MDC_ECG_LEAD_I].Typed.(*SLIST_PQ).Scale.Unit = "furlong"
+	_, err = series.GetLeadValues(MDC_ECG_LEAD_I)
+	assert.ErrorIs(t, err, ErrInvalidScale)
+}
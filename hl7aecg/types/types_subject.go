@@ -24,6 +24,8 @@ type Subject struct {
 	// XML Tag: <trialSubject>...</trialSubject>
 	// Cardinality: Required (within Subject)
 	TrialSubject TrialSubject `xml:"trialSubject"`
+
+	Extensions
 }
 
 // TrialSubject represents a subject participating in a clinical trial.
@@ -83,6 +85,8 @@ type TrialSubject struct {
 	// XML Tag: <subjectDemographicPerson>...</subjectDemographicPerson>
 	// Cardinality: Optional
 	SubjectDemographicPerson *SubjectDemographicPerson `xml:"subjectDemographicPerson,omitempty"`
+
+	Extensions
 }
 
 // SubjectDemographicPerson represents demographic information about a trial subject.
@@ -164,32 +168,48 @@ type SubjectDemographicPerson struct {
 	// Cardinality: Optional
 	RaceCode *Code[RaceCode, CodeSystemOID] `xml:"raceCode,omitempty"`
 
+	// =========================================================================
+	// Vendor Extensions
+	// =========================================================================
+	//
+	// The fields below are not part of the aECG schema. They are read from
+	// legacy vendor files and only written under COMPLIANCE_LEGACY; the
+	// default COMPLIANCE_STRICT profile maps them to standard constructs or
+	// drops them (see HL7AEcg.Compliant).
+
 	// PatientID is the primary patient identifier.
 	//
 	// This is typically the hospital or institution's internal patient ID.
 	//
 	// Example: "25060897140"
 	//
+	// Strict profile: becomes the trialSubject id extension when that is empty, dropped otherwise
+	//
 	// XML Tag: <PatientID>...</PatientID>
 	// Cardinality: Optional
-	PatientID string `xml:"PatientID"`
+	PatientID string `xml:"PatientID,omitempty"`
 
 	// SecondPatientID is an optional secondary patient identifier.
 	//
 	// Used when the patient has multiple identification numbers
 	// (e.g., different hospital systems).
 	//
+	// Strict profile: dropped
+	//
 	// XML Tag: <SecondPatientID>...</SecondPatientID>
 	// Cardinality: Optional
-	SecondPatientID string `xml:"SecondPatientID"`
+	SecondPatientID string `xml:"SecondPatientID,omitempty"`
 
 	// Age is the subject's age at the time of ECG acquisition.
 	//
 	// Can be represented as a number (years) or other format.
 	//
+	// Strict profile: related observation OBS_AGE (unit "a") when numeric,
+	// dropped with a warning otherwise
+	//
 	// XML Tag: <Age>...</Age>
 	// Cardinality: Optional
-	Age string `xml:"Age"`
+	Age string `xml:"Age,omitempty"`
 
 	// Paced indicates whether the patient has a cardiac pacemaker.
 	//
@@ -197,49 +217,64 @@ type SubjectDemographicPerson struct {
 	//
 	// Example: true
 	//
+	// Strict profile: SNOMED CT "Cardiac pacemaker in situ" (441509002)
+	// control variable on every series when true
+	//
 	// XML Tag: <Paced>...</Paced>
 	// Cardinality: Optional
-	Paced bool `xml:"Paced"`
+	Paced bool `xml:"Paced,omitempty"`
 
 	// Medications contains the list of medications the patient is taking.
 	//
 	// Each medication can include name, dosage, etc.
 	//
+	// Strict profile: one OBS_MEDICATION related observation per entry
+	//
 	// XML Tag: <Medications>...</Medications>
 	// Cardinality: Optional
-	Medications Medications `xml:"Medications"`
+	Medications *Medications `xml:"Medications,omitempty"`
 
 	// ClinicalClassifications contains clinical classification information.
 	//
 	// Used to categorize the patient's clinical status or conditions.
 	//
+	// Strict profile: one OBS_PROBLEM related observation per entry
+	//
 	// XML Tag: <ClinicalClassifications>...</ClinicalClassifications>
 	// Cardinality: Optional
-	ClinicalClassifications ClinicalClassifications `xml:"ClinicalClassifications"`
+	ClinicalClassifications *ClinicalClassifications `xml:"ClinicalClassifications,omitempty"`
 
 	// Bed is the patient's bed location within the facility.
 	//
 	// Example: "12A"
 	//
+	// Strict profile: dropped
+	//
 	// XML Tag: <Bed>...</Bed>
 	// Cardinality: Optional
-	Bed string `xml:"Bed"`
+	Bed string `xml:"Bed,omitempty"`
 
 	// Room is the patient's room number or identifier.
 	//
 	// Example: "302"
 	//
+	// Strict profile: dropped
+	//
 	// XML Tag: <Room>...</Room>
 	// Cardinality: Optional
-	Room string `xml:"Room"`
+	Room string `xml:"Room,omitempty"`
 
 	// PointOfCare identifies the care unit or department.
 	//
 	// Example: "Cardiology ICU", "Emergency Department"
 	//
+	// Strict profile: dropped
+	//
 	// XML Tag: <PointOfCare>...</PointOfCare>
 	// Cardinality: Optional
-	PointOfCare string `xml:"PointOfCare"`
+	PointOfCare string `xml:"PointOfCare,omitempty"`
+
+	Extensions
 }
 
 // Medications represents a list of medications the patient is taking.
@@ -260,6 +295,8 @@ type Medications struct {
 	// XML Tag: <Medication>...</Medication>
 	// Cardinality: 0..* (multiple medications allowed)
 	Medication []string `xml:"Medication"`
+
+	Extensions
 }
 
 // ClinicalClassifications represents clinical classification information.
@@ -282,4 +319,6 @@ type ClinicalClassifications struct {
 	// XML Tag: <ClinicalClassification>...</ClinicalClassification>
 	// Cardinality: 0..* (multiple classifications allowed)
 	ClinicalClassification []string `xml:"ClinicalClassification"`
+
+	Extensions
 }
@@ -14,6 +14,9 @@ package types
 //	      <code code="GRP_003" codeSystem="2.16.840.1.113883.3.1"/>
 //	    </treatmentGroupAssignment>
 //	  </definition>
+//	  <subjectOf>
+//	    <relatedObservation>...</relatedObservation>
+//	  </subjectOf>
 //	  <componentOf>
 //	    <clinicalTrial>...</clinicalTrial>
 //	  </componentOf>
@@ -35,11 +38,20 @@ type SubjectAssignment struct {
 	// Cardinality: Optional
 	Definition *SubjectAssignmentDefinition `xml:"definition,omitempty"`
 
+	// SubjectOf holds observations about the subject made around the time of
+	// the ECG (e.g., age, weight, blood pressure).
+	//
+	// XML Tag: <subjectOf>...</subjectOf>
+	// Cardinality: Optional (0..*)
+	SubjectOf []SubjectAssignmentSubjectOf `xml:"subjectOf,omitempty"`
+
 	// ComponentOf links this subject assignment to the clinical trial.
 	//
 	// XML Tag: <componentOf>...</componentOf>
 	// Cardinality: Required
 	ComponentOf ComponentOfClinicalTrial `xml:"componentOf"`
+
+	Extensions
 }
 
 // SubjectAssignmentDefinition defines the subject's association with a trial
@@ -60,6 +72,8 @@ type SubjectAssignmentDefinition struct {
 	// XML Tag: <treatmentGroupAssignment>...</treatmentGroupAssignment>
 	// Cardinality: Required (within Definition)
 	TreatmentGroupAssignment TreatmentGroupAssignment `xml:"treatmentGroupAssignment"`
+
+	Extensions
 }
 
 // TreatmentGroupAssignment identifies a group of subjects that went through
@@ -96,6 +110,8 @@ type TreatmentGroupAssignment struct {
 	// XML Tag: <code code="..." codeSystem="..."/>
 	// Cardinality: Required
 	Code Code[TreatmentGroupCode, CodeSystemOID] `xml:"code"`
+
+	Extensions
 }
 
 // ComponentOfClinicalTrial links the subject assignment to the clinical trial.
@@ -113,4 +129,6 @@ type ComponentOfClinicalTrial struct {
 	// XML Tag: <clinicalTrial>...</clinicalTrial>
 	// Cardinality: Required
 	ClinicalTrial ClinicalTrial `xml:"clinicalTrial"`
+
+	Extensions
 }
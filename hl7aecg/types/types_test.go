@@ -778,3 +778,22 @@ func TestCode_XMLRoundTrip(t *testing.T) {
 		})
 	}
 }
+
+// TestHL7AEcg_GetTrialSubject tests subject lookup in both document layouts
+func TestHL7AEcg_GetTrialSubject(t *testing.T) {
+	var nilDoc *HL7AEcg
+	if nilDoc.GetTrialSubject() != nil {
+		t.Errorf("GetTrialSubject() on nil document should return nil")
+	}
+
+	direct := &HL7AEcg{Subject: &TrialSubject{ID: &ID{Extension: "DIRECT"}}}
+	if got := direct.GetTrialSubject(); got == nil || got.ID.Extension != "DIRECT" {
+		t.Errorf("GetTrialSubject() = %+v, want direct subject", got)
+	}
+
+	nested := &HL7AEcg{ComponentOf: &ComponentOfTimepointEvent{}}
+	nested.ComponentOf.TimepointEvent.ComponentOf.SubjectAssignment.Subject.TrialSubject.ID = &ID{Extension: "NESTED"}
+	if got := nested.GetTrialSubject(); got == nil || got.ID.Extension != "NESTED" {
+		t.Errorf("GetTrialSubject() = %+v, want nested subject", got)
+	}
+}
@@ -86,6 +86,8 @@ type TimepointEvent struct {
 	// XML Tag: <componentOf>...</componentOf>
 	// Cardinality: Required
 	ComponentOf ComponentOfSubjectAssignment `xml:"componentOf"`
+
+	Extensions
 }
 
 // TimepointEventPerformer identifies the person primarily responsible for this timepoint event.
@@ -112,6 +114,8 @@ type TimepointEventPerformer struct {
 	// XML Tag: <studyEventPerformer>...</studyEventPerformer>
 	// Cardinality: Required (within Performer)
 	StudyEventPerformer StudyEventPerformer `xml:"studyEventPerformer"`
+
+	Extensions
 }
 
 // StudyEventPerformer represents a person involved in conducting the study event.
@@ -144,6 +148,8 @@ type StudyEventPerformer struct {
 	// XML Tag: <assignedPerson>...</assignedPerson>
 	// Cardinality: Optional
 	AssignedPerson *AssignedPerson `xml:"assignedPerson,omitempty"`
+
+	Extensions
 }
 
 // AssignedPerson represents a person assigned to perform a study event.
@@ -163,6 +169,8 @@ type AssignedPerson struct {
 	// XML Tag: <name>...</name>
 	// Cardinality: Optional
 	Name *string `xml:"name,omitempty"`
+
+	Extensions
 }
 
 // ComponentOfSubjectAssignment links the timepoint event to the subject assignment.
@@ -180,12 +188,34 @@ type ComponentOfSubjectAssignment struct {
 	// XML Tag: <subjectAssignment>...</subjectAssignment>
 	// Cardinality: Required
 	SubjectAssignment SubjectAssignment `xml:"subjectAssignment"`
+
+	Extensions
 }
 
 // =============================================================================
 // Relative Timepoint Types
 // =============================================================================
 
+// AnnotatedECGDefinition links the AnnotatedECG to its planned relative timepoint.
+//
+// XML Structure:
+//
+//	<definition>
+//	  <relativeTimepoint>...</relativeTimepoint>
+//	</definition>
+//
+// Cardinality: Optional (within AnnotatedECG)
+// Reference: HL7 aECG Implementation Guide, Page 19-20
+type AnnotatedECGDefinition struct {
+	// RelativeTimepoint identifies the planned timepoint relative to a reference event.
+	//
+	// XML Tag: <relativeTimepoint>...</relativeTimepoint>
+	// Cardinality: Required (within Definition)
+	RelativeTimepoint RelativeTimepoint `xml:"relativeTimepoint"`
+
+	Extensions
+}
+
 // RelativeTimepoint identifies a timepoint relative to a reference event.
 //
 // For example, if the protocol specifies an ECG assessment 30 minutes after
@@ -231,6 +261,8 @@ type RelativeTimepoint struct {
 	// XML Tag: <componentOf>...</componentOf>
 	// Cardinality: Required
 	ComponentOf RelativeTimepointComponentOf `xml:"componentOf"`
+
+	Extensions
 }
 
 // RelativeTimepointComponentOf links the relative timepoint to protocol information.
@@ -265,6 +297,8 @@ type RelativeTimepointComponentOf struct {
 	// XML Tag: <protocolTimepointEvent>...</protocolTimepointEvent>
 	// Cardinality: Required
 	ProtocolTimepointEvent ProtocolTimepointEvent `xml:"protocolTimepointEvent"`
+
+	Extensions
 }
 
 // Quantity represents a physical quantity with value and unit.
@@ -290,6 +324,8 @@ type Quantity struct {
 	// XML Tag: unit="..."
 	// Cardinality: Required
 	Unit string `xml:"unit,attr"`
+
+	Extensions
 }
 
 // ProtocolTimepointEvent identifies the timepoint event as defined in the protocol.
@@ -327,6 +363,8 @@ type ProtocolTimepointEvent struct {
 	// XML Tag: <component>...</component>
 	// Cardinality: Optional
 	Component *ProtocolTimepointEventComponent `xml:"component,omitempty"`
+
+	Extensions
 }
 
 // ProtocolTimepointEventComponent contains the reference event.
@@ -344,6 +382,8 @@ type ProtocolTimepointEventComponent struct {
 	// XML Tag: <referenceEvent>...</referenceEvent>
 	// Cardinality: Required (within Component)
 	ReferenceEvent ReferenceEvent `xml:"referenceEvent"`
+
+	Extensions
 }
 
 // ReferenceEvent identifies the reference or benchmark event for the ECG assessment
@@ -383,4 +423,6 @@ type ReferenceEvent struct {
 	// XML Tag: <code code="..." codeSystem="..."/>
 	// Cardinality: Required
 	Code Code[string, CodeSystemOID] `xml:"code"`
+
+	Extensions
 }
@@ -48,10 +48,16 @@ func (e *HL7AEcg) Validate(ctx context.Context, vctx *ValidationContext) error {
 		e.ReasonCode.ValidateCode(ctx, vctx, "ReasonCode")
 	}
 
+	// Validate the planned relative timepoint if present
+	if e.Definition != nil {
+		e.Definition.Validate(ctx, vctx)
+	}
+
 	// Validate ComponentOf structure if present
 	if e.ComponentOf != nil {
 		// Validate TimepointEvent and nested structures
 		te := &e.ComponentOf.TimepointEvent
+		te.Validate(ctx, vctx)
 		sa := &te.ComponentOf.SubjectAssignment
 
 		// Validate Subject within ComponentOf
@@ -59,6 +65,17 @@ func (e *HL7AEcg) Validate(ctx context.Context, vctx *ValidationContext) error {
 			return err
 		}
 
+		// Validate treatment group and its blinding within ComponentOf
+		if sa.Definition != nil {
+			sa.Definition.Validate(ctx, vctx)
+			validateTreatmentBlinding(vctx, e.ConfidentialityCode, sa)
+		}
+
+		// Validate related observations within ComponentOf
+		for i := range sa.SubjectOf {
+			sa.SubjectOf[i].RelatedObservation.Validate(ctx, vctx)
+		}
+
 		// Validate ClinicalTrial within ComponentOf
 		if err := sa.ComponentOf.ClinicalTrial.Validate(ctx, vctx); err != nil {
 			return err
@@ -3,6 +3,7 @@ package types
 import (
 	"context"
 	"fmt"
+	"strings"
 )
 
 // Validate validates an AnnotationSet and all its components.
@@ -34,6 +35,29 @@ func (as *AnnotationSet) Validate(ctx context.Context, vctx *ValidationContext)
 		}
 	}
 
+	// Validate authors
+	for i, author := range as.Author {
+		entity := author.AssignedEntity
+		if entity.AssignedPerson == nil && entity.AssignedDevice == nil {
+			vctx.AddError(NewValidationError(
+				fmt.Sprintf("annotationSet.author[%d].assignedEntity", i),
+				"Author must be an assigned person or an assigned device",
+			))
+		}
+		if entity.AssignedPerson != nil && entity.AssignedDevice != nil {
+			vctx.AddError(NewValidationError(
+				fmt.Sprintf("annotationSet.author[%d].assignedEntity", i),
+				"Author cannot be both an assigned person and an assigned device",
+			))
+		}
+		if author.FunctionCode != nil && author.FunctionCode.Code == "" {
+			vctx.AddError(NewValidationError(
+				fmt.Sprintf("annotationSet.author[%d].functionCode", i),
+				"Author function code cannot be empty",
+			))
+		}
+	}
+
 	// Validate each annotation component
 	for i := range as.Component {
 		if err := as.Component[i].Annotation.Validate(ctx, vctx); err != nil {
@@ -85,6 +109,15 @@ func (a *Annotation) Validate(ctx context.Context, vctx *ValidationContext) erro
 						"Annotation PQ value must be a valid number",
 					))
 				}
+				// Check the unit against the MDC nomenclature for known codes
+				if a.Code != nil {
+					if term, ok := LookupMDC(a.Code.Code); ok && !term.AcceptsUnit(pq.Unit) {
+						vctx.AddError(NewValidationError(
+							"annotation.value.unit",
+							fmt.Sprintf("Unit %q is not valid for %s (expected %s)", pq.Unit, term.RefID, term.Unit),
+						))
+					}
+				}
 			}
 		} else if a.Value.IsST() {
 			// String value validation
@@ -94,6 +127,19 @@ func (a *Annotation) Validate(ctx context.Context, vctx *ValidationContext) erro
 					"Annotation ST value cannot be empty",
 				))
 			}
+		} else if a.Value.IsCE() {
+			// Coded value validation
+			if ce, _ := a.Value.GetCoded(); ce.Code == "" {
+				vctx.AddError(NewValidationError(
+					"annotation.value",
+					"Annotation CE value code cannot be empty",
+				))
+			} else if a.Code != nil && a.Code.Code == string(MDC_ECG_WAVC) && !IsMDCWave(ce.Code) {
+				vctx.AddError(NewValidationError(
+					"annotation.value",
+					fmt.Sprintf("Unknown MDC wave component %q", ce.Code),
+				))
+			}
 		}
 	}
 
@@ -142,11 +188,17 @@ func (roi *AnnotationSupportingROI) Validate(ctx context.Context, vctx *Validati
 
 	// Validate boundary components
 	for i := range roi.Component {
-		if roi.Component[i].Boundary.Code.Code == "" {
+		code := roi.Component[i].Boundary.Code.Code
+		if code == "" {
 			vctx.AddError(NewValidationError(
 				fmt.Sprintf("supportingROI.component[%d].boundary.code", i),
 				"Boundary lead code cannot be empty",
 			))
+		} else if strings.HasPrefix(code, "MDC_ECG_LEAD_") && !IsMDCLead(code) {
+			vctx.AddError(NewValidationError(
+				fmt.Sprintf("supportingROI.component[%d].boundary.code", i),
+				fmt.Sprintf("Unknown MDC lead code %q", code),
+			))
 		}
 	}
 
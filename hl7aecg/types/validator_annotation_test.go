@@ -181,6 +181,44 @@ func TestAnnotation_Validate(t *testing.T) {
 		assert.NoError(t, err)
 	})
 
+	t.Run("MDC unit mismatch", func(t *testing.T) {
+		ann := &Annotation{
+			Code:  &Code[string, string]{Code: "MDC_ECG_TIME_PD_QT", CodeSystem: "2.16.840.1.113883.6.24"},
+			Value: makePQValue("400", "uV"),
+		}
+
+		vctx := NewValidationContext(false)
+		err := ann.Validate(ctx, vctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not valid for MDC_ECG_TIME_PD_QT")
+	})
+
+	t.Run("MDC compatible unit", func(t *testing.T) {
+		ann := &Annotation{
+			Code:  &Code[string, string]{Code: "MDC_ECG_TIME_PD_QT", CodeSystem: "2.16.840.1.113883.6.24"},
+			Value: makePQValue("0.4", "s"),
+		}
+
+		vctx := NewValidationContext(false)
+		err := ann.Validate(ctx, vctx)
+		assert.NoError(t, err)
+	})
+
+	t.Run("unknown MDC wave component", func(t *testing.T) {
+		ann := &Annotation{
+			Code: &Code[string, string]{Code: "MDC_ECG_WAVC", CodeSystem: "2.16.840.1.113883.6.24"},
+			Value: &AnnotationValue{
+				XsiType: "CE",
+				Typed:   &CodedValue{XsiType: "CE", Code: "MDC_ECG_WAVC_XWAVE"},
+			},
+		}
+
+		vctx := NewValidationContext(false)
+		err := ann.Validate(ctx, vctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Unknown MDC wave component")
+	})
+
 	t.Run("nil annotation", func(t *testing.T) {
 		var ann *Annotation
 		vctx := NewValidationContext(false)
@@ -291,4 +329,24 @@ func TestAnnotationSupportingROI_Validate(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "lead code cannot be empty")
 	})
+	t.Run("unknown MDC lead code", func(t *testing.T) {
+		roi := &AnnotationSupportingROI{
+			ClassCode: "ROIBND",
+			Component: []AnnotationBoundaryComponent{
+				{
+					Boundary: AnnotationBoundary{
+						Code: Code[string, string]{
+							Code:       "MDC_ECG_LEAD_V13",
+							CodeSystem: "2.16.840.1.113883.6.24",
+						},
+					},
+				},
+			},
+		}
+
+		vctx := NewValidationContext(false)
+		err := roi.Validate(ctx, vctx)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "Unknown MDC lead code")
+	})
 }
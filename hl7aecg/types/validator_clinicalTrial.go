@@ -3,7 +3,8 @@ package types
 import "context"
 
 // Validate validates the ClinicalTrial structure.
-// Validates ID (required), ActivityTime (optional), and Location (optional).
+// Validates ID (required), ActivityTime, Location, protocol and sponsor (optional),
+// and the protocol against the trial registry when one is configured.
 func (ct *ClinicalTrial) Validate(ctx context.Context, vctx *ValidationContext) error {
 	select {
 	case <-ctx.Done():
@@ -24,6 +25,21 @@ func (ct *ClinicalTrial) Validate(ctx context.Context, vctx *ValidationContext)
 		ct.Location.Validate(ctx, vctx)
 	}
 
+	// Protocol is optional but its ID root is required if present
+	if protocol := ct.GetProtocol(); protocol != nil && protocol.ID.Root == "" {
+		vctx.AddError(NewValidationError("clinicalTrialProtocol.id", "Protocol ID root is required"))
+	}
+
+	// Sponsor is optional but must be identified if present
+	if sponsor := ct.GetSponsor(); sponsor != nil && sponsor.ID.Root == "" && (sponsor.Name == nil || *sponsor.Name == "") {
+		vctx.AddError(NewValidationError("sponsorOrganization", "Sponsor organization requires an ID or a name"))
+	}
+
+	// Cross-check the protocol against the configured trial registry
+	if vctx.TrialRegistry != nil {
+		validateTrialRegistry(vctx, ct)
+	}
+
 	return nil
 }
 
@@ -46,8 +46,9 @@ func (s *SubjectDemographicPerson) Validate(ctx context.Context, vctx *Validatio
 	if s.RaceCode != nil {
 		s.RaceCode.ValidateCode(ctx, vctx, "RaceCode")
 	}
-	s.Medications.Validate(ctx, vctx)
-	s.ClinicalClassifications.Validate(ctx, vctx)
+
+	// Vendor extensions (PatientID, Age, Medications, ...) carry no schema
+	// constraints; the encoder's compliance profile decides how they are written.
 	return nil
 }
 
@@ -61,12 +62,44 @@ func (s *SubjectAssignment) Validate(ctx context.Context, vctx *ValidationContex
 		s.Definition.Validate(ctx, vctx)
 	}
 
+	// Related observations are optional
+	for i := range s.SubjectOf {
+		s.SubjectOf[i].RelatedObservation.Validate(ctx, vctx)
+	}
+
 	// ComponentOf is required (links to ClinicalTrial)
 	s.ComponentOf.Validate(ctx, vctx)
 
 	return nil
 }
 
+// Validate validates a RelatedObservation.
+// Code is required; a numeric value must be a valid number; an author must be
+// either a person or a device.
+func (r *RelatedObservation) Validate(ctx context.Context, vctx *ValidationContext) error {
+	if r.Code == nil || r.Code.Code == "" {
+		vctx.AddError(NewValidationError("relatedObservation.code", "Related observation code is required"))
+	}
+
+	if r.Value != nil && r.Value.IsPQ() {
+		if _, ok := r.Value.GetValueFloat(); !ok {
+			vctx.AddError(NewValidationError("relatedObservation.value", "Related observation value must be a valid number"))
+		}
+	}
+
+	if r.Author != nil {
+		entity := r.Author.AssignedEntity
+		if (entity.AssignedPerson == nil) == (entity.AssignedDevice == nil) {
+			vctx.AddError(NewValidationError(
+				"relatedObservation.author.assignedEntity",
+				"Author must be either an assigned person or an assigned device",
+			))
+		}
+	}
+
+	return nil
+}
+
 // Validate validates SubjectAssignmentDefinition structure.
 func (s *SubjectAssignmentDefinition) Validate(ctx context.Context, vctx *ValidationContext) error {
 	// TreatmentGroupAssignment is required within Definition
@@ -75,35 +108,39 @@ func (s *SubjectAssignmentDefinition) Validate(ctx context.Context, vctx *Valida
 }
 
 // Validate validates TreatmentGroupAssignment structure.
+// Codes are sponsor-defined, so only their presence is checked.
 func (t *TreatmentGroupAssignment) Validate(ctx context.Context, vctx *ValidationContext) error {
 	// Code is required
-	t.Code.ValidateCode(ctx, vctx, "TreatmentGroupCode")
+	if t.Code.Code == "" {
+		vctx.AddError(NewValidationError("treatmentGroupAssignment.code", "Treatment group code is required"))
+	}
 	return nil
 }
 
+// validateTreatmentBlinding checks that the treatment group agrees with the
+// document's blinding: a document blinded to the sponsor or investigator must
+// not reveal the actual arm.
+func validateTreatmentBlinding(vctx *ValidationContext, confidentiality *Code[ConfidentialityCode, string], sa *SubjectAssignment) {
+	group := sa.GetTreatmentGroup()
+	if group == nil || group.Code == "" {
+		return
+	}
+	unset := confidentiality == nil || confidentiality.Code == ""
+	switch {
+	case !unset && IsBlinding(confidentiality.Code) && group.Code != TREATMENT_GROUP_BLINDED:
+		vctx.AddError(NewValidationErrorWithValue(
+			"treatmentGroupAssignment.code",
+			"Treatment group must be BLINDED when the confidentiality code blinds the document (S, I or B)",
+			string(group.Code),
+		))
+	case unset && group.Code == TREATMENT_GROUP_BLINDED:
+		vctx.AddWarning("treatmentGroupAssignment.code: treatment group is BLINDED but the document has no confidentiality code")
+	}
+}
+
 // Validate validates ComponentOfClinicalTrial structure.
 func (c *ComponentOfClinicalTrial) Validate(ctx context.Context, vctx *ValidationContext) error {
 	// ClinicalTrial is required
 	c.ClinicalTrial.Validate(ctx, vctx)
 	return nil
 }
-
-// Validate Medications structure.
-// Ensures at least one Medication entry is present.
-func (m *Medications) Validate(ctx context.Context, vctx *ValidationContext) error {
-	if len(m.Medication) == 0 {
-		m.Medication = []string{}
-		m.Medication = append(m.Medication, "")
-	}
-	return nil
-}
-
-// Validate ClinicalClassifications structure.
-// Ensures at least one ClinicalClassification entry is present.
-func (c *ClinicalClassifications) Validate(ctx context.Context, vctx *ValidationContext) error {
-	if len(c.ClinicalClassification) == 0 {
-		c.ClinicalClassification = []string{}
-		c.ClinicalClassification = append(c.ClinicalClassification, "")
-	}
-	return nil
-}
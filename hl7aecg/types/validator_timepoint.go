@@ -0,0 +1,83 @@
+package types
+
+import (
+	"context"
+	"strconv"
+)
+
+// Validate validates the TimepointEvent structure.
+// All fields are optional; the reason code must be a known visit type if present.
+func (te *TimepointEvent) Validate(ctx context.Context, vctx *ValidationContext) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if te.EffectiveTime != nil && (te.EffectiveTime.Low.Value != "" || te.EffectiveTime.High.Value != "") {
+		te.EffectiveTime.Validate(ctx, vctx)
+	}
+
+	if te.ReasonCode != nil && te.ReasonCode.Code != "" {
+		switch te.ReasonCode.Code {
+		case VISIT_TYPE_SCHEDULED, VISIT_TYPE_UNSCHEDULED:
+		default:
+			vctx.AddError(NewValidationErrorWithValue(
+				"timepointEvent.reasonCode",
+				"Visit type must be one of \n\t- 'S'\n\t- 'U'",
+				string(te.ReasonCode.Code),
+			))
+		}
+	}
+
+	return nil
+}
+
+// Validate validates the AnnotatedECGDefinition structure.
+func (d *AnnotatedECGDefinition) Validate(ctx context.Context, vctx *ValidationContext) error {
+	// RelativeTimepoint is required within Definition
+	d.RelativeTimepoint.Validate(ctx, vctx)
+	return nil
+}
+
+// Validate validates the RelativeTimepoint structure.
+// Code and protocol timepoint code are required; the pause quantity must be a
+// number with a UCUM time unit; a reference event must carry a code.
+func (rt *RelativeTimepoint) Validate(ctx context.Context, vctx *ValidationContext) error {
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+	}
+
+	if rt.Code.Code == "" {
+		vctx.AddError(NewValidationError("relativeTimepoint.code", "Relative timepoint code is required"))
+	}
+
+	if pq := rt.ComponentOf.PauseQuantity; pq != nil {
+		if _, err := strconv.ParseFloat(pq.Value, 64); err != nil {
+			vctx.AddError(NewValidationErrorWithValue(
+				"relativeTimepoint.pauseQuantity.value",
+				"Pause quantity value must be a valid number",
+				pq.Value,
+			))
+		}
+		if !IsUCUMTimeUnit(pq.Unit) {
+			vctx.AddError(NewValidationErrorWithValue(
+				"relativeTimepoint.pauseQuantity.unit",
+				"Pause quantity unit must be a UCUM time unit (us, ms, s, min, h, d, wk)",
+				pq.Unit,
+			))
+		}
+	}
+
+	ptv := &rt.ComponentOf.ProtocolTimepointEvent
+	if ptv.Code.Code == "" {
+		vctx.AddError(NewValidationError("protocolTimepointEvent.code", "Protocol timepoint event code is required"))
+	}
+	if ptv.Component != nil && ptv.Component.ReferenceEvent.Code.Code == "" {
+		vctx.AddError(NewValidationError("referenceEvent.code", "Reference event code is required"))
+	}
+
+	return nil
+}
@@ -0,0 +1,101 @@
+package types
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestRelativeTimepoint_Validate tests pause quantity and protocol code validation
+func TestRelativeTimepoint_Validate(t *testing.T) {
+	tests := []struct {
+		name   string
+		rt     *RelativeTimepoint
+		fields []string
+	}{
+		{
+			name: "valid",
+			rt: NewRelativeTimepoint("POST_DOSE_2H", "2.16.840.1.113883.3.1").
+				SetPauseQuantity(2, UNIT_HOUR).
+				SetProtocolTimepointEvent("VISIT_2", "2.16.840.1.113883.3.1").
+				SetReferenceEvent("DOSAGE_1", "2.16.840.1.113883.3.1"),
+		},
+		{
+			name: "non UCUM unit",
+			rt: NewRelativeTimepoint("POST_DOSE_2H", "").
+				SetPauseQuantity(2, "hours").
+				SetProtocolTimepointEvent("VISIT_2", ""),
+			fields: []string{"relativeTimepoint.pauseQuantity.unit"},
+		},
+		{
+			name: "case-sensitive unit",
+			rt: NewRelativeTimepoint("POST_DOSE_2H", "").
+				SetPauseQuantity(2, "H").
+				SetProtocolTimepointEvent("VISIT_2", ""),
+			fields: []string{"relativeTimepoint.pauseQuantity.unit"},
+		},
+		{
+			name: "non numeric value",
+			rt: &RelativeTimepoint{
+				Code: Code[string, CodeSystemOID]{Code: "T1"},
+				ComponentOf: RelativeTimepointComponentOf{
+					PauseQuantity:          &Quantity{Value: "two", Unit: UNIT_HOUR},
+					ProtocolTimepointEvent: ProtocolTimepointEvent{Code: Code[string, CodeSystemOID]{Code: "VISIT_2"}},
+				},
+			},
+			fields: []string{"relativeTimepoint.pauseQuantity.value"},
+		},
+		{
+			name:   "missing codes",
+			rt:     (&RelativeTimepoint{}).SetReferenceEvent("", ""),
+			fields: []string{"relativeTimepoint.code", "protocolTimepointEvent.code", "referenceEvent.code"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			vctx := NewValidationContext(false)
+			tt.rt.Validate(context.Background(), vctx)
+
+			var fields []string
+			for _, err := range vctx.Errors {
+				fields = append(fields, err.(*ValidationError).Field)
+			}
+			assert.ElementsMatch(t, tt.fields, fields)
+		})
+	}
+}
+
+// TestTimepointEvent_Validate tests visit type validation
+func TestTimepointEvent_Validate(t *testing.T) {
+	vctx := NewValidationContext(false)
+	(&TimepointEvent{}).SetReasonCode(VISIT_TYPE_UNSCHEDULED).Validate(context.Background(), vctx)
+	assert.Empty(t, vctx.Errors)
+
+	vctx = NewValidationContext(false)
+	(&TimepointEvent{}).SetReasonCode("X").Validate(context.Background(), vctx)
+	assert.Len(t, vctx.Errors, 1)
+}
+
+// TestQuantity_Seconds tests UCUM time unit conversion
+func TestQuantity_Seconds(t *testing.T) {
+	tests := []struct {
+		q    *Quantity
+		want float64
+		ok   bool
+	}{
+		{&Quantity{Value: "1800", Unit: UNIT_SECOND}, 1800, true},
+		{&Quantity{Value: "30", Unit: UNIT_MINUTE}, 1800, true},
+		{&Quantity{Value: "0.5", Unit: UNIT_HOUR}, 1800, true},
+		{&Quantity{Value: "1", Unit: UNIT_DAY}, 86400, true},
+		{&Quantity{Value: "1", Unit: "mo"}, 0, false},
+		{&Quantity{Value: "x", Unit: UNIT_SECOND}, 0, false},
+		{nil, 0, false},
+	}
+	for _, tt := range tests {
+		got, ok := tt.q.Seconds()
+		assert.Equal(t, tt.ok, ok)
+		assert.InDelta(t, tt.want, got, 1e-9)
+	}
+}
@@ -2,6 +2,7 @@ package hl7aecg
 
 import (
 	"encoding/xml"
+	"errors"
 	"fmt"
 	"io"
 	"os"
@@ -49,3 +50,31 @@ func (h *Hl7xml) UnmarshalAndValidate(data []byte) error {
 	}
 	return h.Validate()
 }
+
+// ParseErrorField is the field of the validation issue reporting a document
+// that could not be parsed.
+const ParseErrorField = "xml"
+
+// UnmarshalAndReport parses and validates aECG XML under the context set with
+// SetContext, and returns the validation report.
+//
+// A document that cannot be parsed is reported as invalid, with a single
+// ParseErrorField issue holding the parse error. The returned error is the
+// context error if the context is done before validation completes, and nil
+// otherwise.
+func (h *Hl7xml) UnmarshalAndReport(data []byte) (*types.ValidationReport, error) {
+	err := h.UnmarshalAndValidate(data)
+	if err != nil && h.ctx.Err() != nil && errors.Is(err, h.ctx.Err()) {
+		return nil, err
+	}
+
+	report := h.ValidationReport()
+	if err != nil && report.Valid {
+		// Not a validation error: the document could not be parsed
+		report = &types.ValidationReport{
+			Errors:   []types.ValidationIssue{{Field: ParseErrorField, Message: err.Error()}},
+			Warnings: []string{},
+		}
+	}
+	return report, nil
+}
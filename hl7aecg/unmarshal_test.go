@@ -1,7 +1,12 @@
 package hl7aecg
 
 import (
+	"context"
+	"errors"
+	"strings"
 	"testing"
+
+	"github.com/LIRYC-IHU/hl7v3-aecg/hl7aecg/types"
 )
 
 // TestHl7xml_Unmarshal tests the Unmarshal method on Hl7xml
@@ -183,3 +188,54 @@ func TestHl7xml_AccessSubjectInformation(t *testing.T) {
 		})
 	}
 }
+
+// TestHl7xml_MarshalRoundTrip tests that Marshal output reads back with its waveforms
+func TestHl7xml_MarshalRoundTrip(t *testing.T) {
+	h := NewHl7xml("")
+	h.Initialize(types.CPT_CODE_ECG_Routine, types.CPT_OID, "CPT-4", "").
+		SetEffectiveTime("20240315101500", "20240315101510", nil, nil).
+		AddRhythmSeries("20240315101500.000", "20240315101510.000", nil, nil, 500,
+			map[types.LeadCode][]int{types.MDC_ECG_LEAD_II: {1, 2, 3}}, 0, 5)
+
+	data, err := h.Marshal()
+	if err != nil {
+		t.Fatalf("Marshal() error = %v", err)
+	}
+	if !strings.HasPrefix(string(data), "<?xml") {
+		t.Errorf("Marshal() output has no XML declaration")
+	}
+
+	back := NewHl7xml("")
+	if err := back.Unmarshal(data); err != nil {
+		t.Fatalf("Unmarshal() error = %v", err)
+	}
+	values, err := back.HL7AEcg.Component[0].Series.GetLeadValues(types.MDC_ECG_LEAD_II)
+	if err != nil {
+		t.Fatalf("GetLeadValues() error = %v", err)
+	}
+	if len(values) != 3 || values[2] != 15 {
+		t.Errorf("GetLeadValues() = %v, want [5 10 15]", values)
+	}
+	if !back.HL7AEcg.Equal(&h.HL7AEcg) {
+		t.Errorf("Unmarshal(Marshal()) is not Equal to the original document")
+	}
+}
+
+// TestHl7xml_UnmarshalAndReport tests the report of a document that cannot be
+// parsed, and the context error of a cancelled validation
+func TestHl7xml_UnmarshalAndReport(t *testing.T) {
+	report, err := NewHl7xml("").UnmarshalAndReport([]byte("<AnnotatedECG"))
+	if err != nil {
+		t.Fatalf("UnmarshalAndReport() error = %v", err)
+	}
+	if report.Valid || len(report.Errors) != 1 || report.Errors[0].Field != ParseErrorField {
+		t.Errorf("report = %+v, want one %q error", report, ParseErrorField)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	h := NewHl7xml("").SetContext(ctx)
+	if _, err := h.UnmarshalAndReport([]byte(`<AnnotatedECG xmlns="urn:hl7-org:v3"/>`)); !errors.Is(err, context.Canceled) {
+		t.Errorf("UnmarshalAndReport() error = %v, want context.Canceled", err)
+	}
+}
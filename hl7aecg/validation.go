@@ -10,13 +10,38 @@ type Validator interface {
 	Validate(ctx context.Context, vctx *types.ValidationContext) error
 }
 
+// Validate validates the document under the context set with SetContext.
+//
+// Vendor values that the compliance profile drops on encoding (see
+// types.HL7AEcg.Compliant) are reported as warnings.
+//
+// Returns the context error if the context is done before validation
+// completes, or the collected validation errors otherwise.
 func (e *Hl7xml) Validate() error {
-	e.validateAll(&e.HL7AEcg)
+	if err := e.validateAll(&e.HL7AEcg); err != nil {
+		return err
+	}
+	_, warnings := e.HL7AEcg.Compliant(e.profile)
+	for _, w := range warnings {
+		e.vctx.AddWarning(w)
+	}
 	return e.vctx.GetError()
 }
 
-func (e *Hl7xml) validateAll(objs ...Validator) {
+// validateAll validates the objects in order and returns the first error
+// returned by a validator, which is a context error: validation errors are
+// collected in the validation context.
+func (e *Hl7xml) validateAll(objs ...Validator) error {
 	for _, obj := range objs {
-		obj.Validate(e.ctx, e.vctx)
+		if err := obj.Validate(e.ctx, e.vctx); err != nil {
+			return err
+		}
 	}
+	return nil
+}
+
+// ValidationReport returns the errors and warnings collected by Validate
+// in a structured form, e.g. for JSON output.
+func (e *Hl7xml) ValidationReport() *types.ValidationReport {
+	return e.vctx.Report()
 }